@@ -50,6 +50,7 @@ func init() {
 	common.AddIntFlag(Command, "enforcer.maxConcurrentActions", "enforcer-max-concurrent-actions", "", 30, envPrefix+"_ENFORCER_MAX_CONCURRENT_ACTIONS", "Desired state enforcer max concurrent actions")
 	common.AddDurationFlag(Command, "updater.interval", "updater-interval", "", 60*time.Second, envPrefix+"_UPDATER_INTERVAL", "Actual state updater interval")
 	common.AddIntFlag(Command, "updater.maxConcurrentActions", "updater-max-concurrent-actions", "", 30, envPrefix+"_UPDATER_MAX_CONCURRENT_ACTIONS", "Actual state updater max concurrent actions")
+	common.AddDurationFlag(Command, "claimExpirer.interval", "claim-expirer-interval", "", 60*time.Second, envPrefix+"_CLAIM_EXPIRER_INTERVAL", "Claim expirer interval")
 	common.AddStringFlag(Command, "profile.cpu", "cpuprofile", "", "", envPrefix+"_CPU_PROFILE", "File to write debug CPU profiling information using Go runtime/pprof")
 	common.AddStringFlag(Command, "profile.trace", "traceprofile", "", "", envPrefix+"_TRACE_PROFILE", "File to write debug tracing information using Go runtime/trace")
 
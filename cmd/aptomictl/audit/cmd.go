@@ -0,0 +1,76 @@
+package audit
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Aptomi/aptomi/cmd/common"
+	"github.com/Aptomi/aptomi/pkg/client/rest"
+	"github.com/Aptomi/aptomi/pkg/client/rest/http"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// NewCommand returns cobra command for audit subcommand
+func NewCommand(cfg *config.Client) *cobra.Command {
+	filter := &engine.AuditFilter{}
+	var since string
+	var until string
+
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Show audit log",
+		Long:  "Show audit log of all API calls changing the state of Aptomi and all enforcement actions (domain admins only)",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			filter.Since = parseTime(since, "since")
+			filter.Until = parseTime(until, "until")
+
+			result, err := rest.New(cfg, http.NewClient(cfg)).Audit().List(filter)
+			if err != nil {
+				log.Fatalf("error while getting audit log: %s", err)
+			}
+
+			if len(result.Items) == 0 {
+				fmt.Println("No audit entries found")
+				return
+			}
+
+			displayable := make([]runtime.Displayable, 0, len(result.Items))
+			for _, entry := range result.Items {
+				displayable = append(displayable, entry)
+			}
+			data, err := common.Format(cfg.Output, true, displayable...)
+			if err != nil {
+				log.Fatalf("error while formatting audit log: %s", err)
+			}
+			fmt.Println(string(data))
+		},
+	}
+
+	cmd.Flags().StringVar(&filter.User, "user", "", "Show only entries for a given user")
+	cmd.Flags().StringVar(&filter.Action, "action", "", "Show only entries for a given action (e.g. policy-update)")
+	cmd.Flags().StringVar(&since, "since", "", "Show only entries after a given time (RFC3339 timestamp or duration, e.g. 24h)")
+	cmd.Flags().StringVar(&until, "until", "", "Show only entries before a given time (RFC3339 timestamp or duration, e.g. 1h)")
+	cmd.Flags().IntVar(&filter.Limit, "limit", 100, "Show only a given number of the most recent entries (0 means no limit)")
+
+	return cmd
+}
+
+// parseTime parses either RFC3339 timestamp or duration, which is treated as time in the past relative to now
+func parseTime(value string, name string) time.Time {
+	if len(value) <= 0 {
+		return time.Time{}
+	}
+	if duration, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-duration)
+	}
+	result, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		log.Fatalf("invalid --%s '%s', must be either RFC3339 timestamp or duration", name, value)
+	}
+	return result
+}
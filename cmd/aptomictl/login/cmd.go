@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"time"
 
 	"github.com/Aptomi/aptomi/cmd/common"
+	"github.com/Aptomi/aptomi/pkg/api"
+	"github.com/Aptomi/aptomi/pkg/client"
 	"github.com/Aptomi/aptomi/pkg/client/rest"
 	"github.com/Aptomi/aptomi/pkg/client/rest/http"
 	"github.com/Aptomi/aptomi/pkg/config"
@@ -18,16 +21,23 @@ import (
 // NewCommand returns instance of cobra command that allows to login into aptomi
 func NewCommand(cfg *config.Client, cfgFile *string) *cobra.Command {
 	var username, password string
+	var oidc bool
 
 	cmd := &cobra.Command{
 		Use:   "login",
 		Short: "Login into the Aptomi",
+		Long:  "Login into the Aptomi with username and password, or via OpenID Connect provider (--oidc) using device flow",
 		Run: func(cmd *cobra.Command, args []string) {
-			if len(username) == 0 || len(password) == 0 {
-				log.Fatalf("username and password should not be both empty")
+			var authSuccess *api.AuthSuccess
+			var err error
+			if oidc {
+				authSuccess, err = loginDevice(rest.New(cfg, http.NewClient(cfg)).User())
+			} else {
+				if len(username) == 0 || len(password) == 0 {
+					log.Fatalf("username and password should not be both empty")
+				}
+				authSuccess, err = rest.New(cfg, http.NewClient(cfg)).User().Login(username, password)
 			}
-
-			authSuccess, err := rest.New(cfg, http.NewClient(cfg)).User().Login(username, password)
 			if err != nil {
 				log.Fatalf("error while user login: %s", err)
 			}
@@ -41,17 +51,49 @@ func NewCommand(cfg *config.Client, cfgFile *string) *cobra.Command {
 	}
 
 	cmd.Flags().StringVarP(&username, "username", "u", "", "Username")
-	if err := cmd.MarkFlagRequired("username"); err != nil {
-		panic(err)
-	}
 	cmd.Flags().StringVarP(&password, "password", "p", "", "Password")
-	if err := cmd.MarkFlagRequired("password"); err != nil {
-		panic(err)
-	}
+	cmd.Flags().BoolVar(&oidc, "oidc", false, "Login via OpenID Connect provider, by approving this device in the browser")
 
 	return cmd
 }
 
+// loginDevice logs in via OpenID Connect provider using device flow. It asks user to approve the device in the
+// browser and waits until it gets approved
+func loginDevice(user client.User) (*api.AuthSuccess, error) {
+	deviceAuth, err := user.LoginDevice()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(deviceAuth.VerificationURIComplete) > 0 {
+		fmt.Printf("To login, open %s in the browser and confirm code %s\n", deviceAuth.VerificationURIComplete, deviceAuth.UserCode)
+	} else {
+		fmt.Printf("To login, open %s in the browser and enter code %s\n", deviceAuth.VerificationURI, deviceAuth.UserCode)
+	}
+
+	interval := time.Duration(deviceAuth.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	expires := time.Now().Add(time.Duration(deviceAuth.ExpiresIn) * time.Second)
+	for deviceAuth.ExpiresIn <= 0 || time.Now().Before(expires) {
+		time.Sleep(interval)
+
+		authSuccess, pending, tokenErr := user.LoginDeviceToken(deviceAuth.DeviceCode)
+		if tokenErr != nil {
+			return nil, tokenErr
+		}
+		if authSuccess != nil {
+			return authSuccess, nil
+		}
+		if pending.SlowDown {
+			interval += 5 * time.Second
+		}
+	}
+
+	return nil, fmt.Errorf("device authorization expired, login wasn't confirmed in time")
+}
+
 func writeConfig(cfg *config.Client, cfgFile *string) {
 	cleanupDefaultsFromConfig(cfg)
 
@@ -87,6 +129,8 @@ func cleanupDefaultsFromConfig(cfg *config.Client) {
 	if cfg.API.APIPrefix == common.DefaultAPIPrefix {
 		cfg.API.APIPrefix = ""
 	}
+	// impersonation should be requested explicitly every time, so it never gets saved
+	cfg.Auth.Impersonate = ""
 }
 
 func backupConfigIfDiffers(cfgFile string, newData []byte) {
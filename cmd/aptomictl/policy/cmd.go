@@ -17,6 +17,8 @@ func NewCommand(cfg *config.Client) *cobra.Command {
 		newShowCommand(cfg),                       // show
 		newHandlePolicyChangesCommand(cfg, true),  // apply
 		newHandlePolicyChangesCommand(cfg, false), // delete
+		newLintCommand(cfg),                       // lint
+		newResolveCommand(cfg),                    // resolve
 	)
 
 	return cmd
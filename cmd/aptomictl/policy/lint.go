@@ -0,0 +1,73 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Aptomi/aptomi/cmd/aptomictl/io"
+	"github.com/Aptomi/aptomi/cmd/common"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func newLintCommand(cfg *config.Client) *cobra.Command {
+	paths := make([]string, 0)
+	var failOnWarnings bool
+
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Short: "policy lint",
+		Long:  "Validate policy files locally and report warnings (unused objects, unreachable contexts, etc), without contacting the server",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			allObjects, err := io.ReadLangObjects(paths)
+			if err != nil {
+				log.Fatalf("error while reading policy files: %s", err)
+			}
+
+			policy := lang.NewPolicy()
+			for _, obj := range allObjects {
+				err = policy.AddObject(obj.(lang.Base))
+				if err != nil {
+					log.Fatalf("error while adding object to policy: %s", err)
+				}
+			}
+
+			err = policy.Validate()
+			if err != nil {
+				log.Fatalf("policy is invalid: %s", err)
+			}
+
+			warnings := policy.Lint()
+			if len(warnings) <= 0 {
+				fmt.Println("No warnings found")
+				return
+			}
+
+			displayable := make([]runtime.Displayable, len(warnings))
+			for idx, warning := range warnings {
+				displayable[idx] = warning
+			}
+			data, err := common.Format(cfg.Output, true, displayable...)
+			if err != nil {
+				log.Fatalf("error while formatting lint warnings: %s", err)
+			}
+			fmt.Println(string(data))
+
+			if failOnWarnings {
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&paths, "policyPaths", "f", make([]string, 0), "Paths to files/dirs with policy files")
+	if err := cmd.MarkFlagRequired("policyPaths"); err != nil {
+		panic(err)
+	}
+	cmd.Flags().BoolVar(&failOnWarnings, "fail-on-warnings", false, "Exit with non-zero code if any warnings are found")
+
+	return cmd
+}
@@ -0,0 +1,236 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Aptomi/aptomi/cmd/aptomictl/io"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/engine/diff"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
+	"github.com/Aptomi/aptomi/pkg/event"
+	"github.com/Aptomi/aptomi/pkg/external"
+	"github.com/Aptomi/aptomi/pkg/external/secrets"
+	"github.com/Aptomi/aptomi/pkg/external/users"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/gosuri/uitable"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+func newResolveCommand(cfg *config.Client) *cobra.Command {
+	paths := make([]string, 0)
+	var usersFile string
+	var secretsDir string
+	var logLevel string
+	var saveFile string
+	var diffFile string
+	var failOnErrors bool
+
+	cmd := &cobra.Command{
+		Use:   "resolve",
+		Short: "policy resolve",
+		Long:  "Resolve policy files locally and show the resulting component instances, without contacting the server",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			allObjects, err := io.ReadLangObjects(paths)
+			if err != nil {
+				log.Fatalf("error while reading policy files: %s", err)
+			}
+
+			policy := lang.NewPolicy()
+			for _, obj := range allObjects {
+				err = policy.AddObject(obj.(lang.Base))
+				if err != nil {
+					log.Fatalf("error while adding object to policy: %s", err)
+				}
+			}
+
+			err = policy.Validate()
+			if err != nil {
+				log.Fatalf("policy is invalid: %s", err)
+			}
+
+			logLevelObj, err := log.ParseLevel(logLevel)
+			if err != nil {
+				logLevelObj = log.WarnLevel
+			}
+
+			// resolve policy locally, using users and secrets from files
+			externalData := external.NewData(
+				users.NewUserLoaderFromFile(usersFile, make(map[string]bool)),
+				secrets.NewSecretLoaderFromDir(secretsDir),
+			)
+			eventLog := event.NewLog(logLevelObj, "resolve")
+			resolution := resolve.NewPolicyResolver(policy, externalData, eventLog).ResolveAllClaims()
+
+			printEventLog(eventLog, logLevelObj)
+			unresolved := printClaimResolution(policy, resolution)
+			printComponentInstances(resolution)
+
+			// show what would change compared to the previously saved resolution
+			if len(diffFile) > 0 {
+				prevResolution, loadErr := loadResolution(diffFile)
+				if loadErr != nil {
+					log.Fatalf("error while loading previous policy resolution: %s", loadErr)
+				}
+				planStr := diff.NewPolicyResolutionDiff(resolution, prevResolution).ActionPlan.AsText().String()
+				if len(planStr) <= 0 {
+					planStr = "(none)"
+				}
+				fmt.Printf("Action Plan (compared to %s):\n%s\n", diffFile, planStr)
+			}
+
+			if len(saveFile) > 0 {
+				saveErr := saveResolution(saveFile, resolution)
+				if saveErr != nil {
+					log.Fatalf("error while saving policy resolution: %s", saveErr)
+				}
+				fmt.Printf("Policy resolution saved to %s\n", saveFile)
+			}
+
+			if failOnErrors && (unresolved > 0 || resolution.Validate(policy) != nil) {
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&paths, "policyPaths", "f", make([]string, 0), "Paths to files/dirs with policy files")
+	if err := cmd.MarkFlagRequired("policyPaths"); err != nil {
+		panic(err)
+	}
+	cmd.Flags().StringVar(&usersFile, "users", "", "Path to the file with users")
+	if err := cmd.MarkFlagRequired("users"); err != nil {
+		panic(err)
+	}
+	cmd.Flags().StringVar(&secretsDir, "secrets", "", "Path to the directory with secrets")
+	cmd.Flags().StringVar(&logLevel, "log-level", log.WarnLevel.String(), fmt.Sprintf("Show policy resolution log using the specified log level (%s)", log.AllLevels))
+	cmd.Flags().StringVar(&saveFile, "save", "", "Save policy resolution into a given file, so it can be compared against later")
+	cmd.Flags().StringVar(&diffFile, "diff", "", "Compare policy resolution against the one previously saved into a given file and show the action plan")
+	cmd.Flags().BoolVar(&failOnErrors, "fail-on-errors", false, "Exit with non-zero code if any claims can't be resolved or component instances have errors")
+
+	return cmd
+}
+
+func printEventLog(eventLog *event.Log, logLevelObj log.Level) {
+	fmt.Printf("Event Log (>%s):\n", logLevelObj.String())
+	entries := eventLog.AsAPIEvents()
+	if len(entries) > 0 {
+		for _, entry := range entries {
+			fmt.Printf("[%s] %s\n", entry.LogLevel, entry.Message)
+		}
+	} else {
+		fmt.Println("* no entries")
+	}
+}
+
+// printClaimResolution prints resolution status for every claim and returns the number of unresolved claims
+func printClaimResolution(policy *lang.Policy, resolution *resolve.PolicyResolution) int {
+	claims := policy.GetObjectsByKind(lang.TypeClaim.Kind)
+	sort.Slice(claims, func(i, j int) bool {
+		return runtime.KeyForStorable(claims[i]) < runtime.KeyForStorable(claims[j])
+	})
+
+	unresolved := 0
+	table := uitable.New()
+	table.MaxColWidth = 120
+	table.Wrap = true
+	table.AddRow("Claim", "Resolved", "Component Instance")
+	for _, obj := range claims {
+		claimResolution := resolution.GetClaimResolution(obj.(*lang.Claim))
+		if !claimResolution.Resolved {
+			unresolved++
+		}
+		table.AddRow(runtime.KeyForStorable(obj), claimResolution.Resolved, claimResolution.ComponentInstanceKey)
+	}
+	fmt.Printf("Claims:\n%s\n", table)
+
+	return unresolved
+}
+
+func printComponentInstances(resolution *resolve.PolicyResolution) {
+	keys := make([]string, 0, len(resolution.ComponentInstanceMap))
+	for key := range resolution.ComponentInstanceMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	table := uitable.New()
+	table.MaxColWidth = 120
+	table.Wrap = true
+	table.AddRow("Component Instance", "Code", "Claims", "Error")
+	for _, key := range keys {
+		instance := resolution.ComponentInstanceMap[key]
+		claimKeys := make([]string, 0, len(instance.ClaimKeys))
+		for claimKey := range instance.ClaimKeys {
+			claimKeys = append(claimKeys, claimKey)
+		}
+		sort.Strings(claimKeys)
+		errStr := ""
+		if instance.Error != nil {
+			errStr = instance.Error.Error()
+		}
+		table.AddRow(key, instance.IsCode, strings.Join(claimKeys, ", "), errStr)
+	}
+	fmt.Printf("Component Instances:\n%s\n", table)
+}
+
+// savedResolution is a snapshot of policy resolution, which gets saved into a file. Component instance errors can't be
+// marshaled as they are, so they are stored separately as strings (component instance key -> error message)
+type savedResolution struct {
+	resolve.PolicyResolution `yaml:",inline"`
+
+	InstanceErrors map[string]string `yaml:"instanceerrors,omitempty"`
+}
+
+// saveResolution saves policy resolution into a file in the same format it gets stored as a part of desired state
+func saveResolution(fileName string, resolution *resolve.PolicyResolution) error {
+	saved := &savedResolution{
+		PolicyResolution: *resolve.NewPolicyResolution(),
+		InstanceErrors:   make(map[string]string),
+	}
+	saved.DeletionPolicies = resolution.DeletionPolicies
+	for key, instance := range resolution.ComponentInstanceMap {
+		instanceCopy := *instance
+		if instanceCopy.Error != nil {
+			saved.InstanceErrors[key] = instanceCopy.Error.Error()
+			instanceCopy.Error = nil
+		}
+		saved.ComponentInstanceMap[key] = &instanceCopy
+	}
+
+	data, err := yaml.Marshal(saved)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fileName, data, 0644)
+}
+
+// loadResolution loads policy resolution previously saved by saveResolution
+func loadResolution(fileName string) (*resolve.PolicyResolution, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	saved := &savedResolution{PolicyResolution: *resolve.NewPolicyResolution()}
+	err = yaml.Unmarshal(data, saved)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &saved.PolicyResolution
+	for key, errStr := range saved.InstanceErrors {
+		instance, ok := result.ComponentInstanceMap[key]
+		if !ok {
+			return nil, fmt.Errorf("error saved for unknown component instance: %s", key)
+		}
+		instance.Error = errors.New(errStr)
+	}
+	return result, nil
+}
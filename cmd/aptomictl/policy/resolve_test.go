@@ -0,0 +1,76 @@
+package policy
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
+	"github.com/Aptomi/aptomi/pkg/event"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/lang/builder"
+	"github.com/Aptomi/aptomi/pkg/util"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveLoadResolution(t *testing.T) {
+	b := builder.NewPolicyBuilder()
+	bundle := b.AddBundle()
+	b.AddBundleComponent(bundle, b.CodeComponent(util.NestedParameterMap{"param": "value"}, nil))
+	service := b.AddService(bundle, b.CriteriaTrue())
+	clusterObj := b.AddCluster()
+	b.AddRule(b.CriteriaTrue(), b.RuleActions(lang.NewLabelOperationsSetSingleLabel(lang.LabelTarget, clusterObj.Name)))
+	b.AddClaim(b.AddUser(), service)
+
+	resolution := resolve.NewPolicyResolver(b.Policy(), b.External(), event.NewLog(logrus.WarnLevel, "test-resolve")).ResolveAllClaims()
+	if !assert.Equal(t, 2, len(resolution.ComponentInstanceMap), "Policy should be resolved into component instances") {
+		t.FailNow()
+	}
+
+	// put an error into one of the component instances
+	var errKey string
+	for key := range resolution.ComponentInstanceMap {
+		errKey = key
+		break
+	}
+	resolution.ComponentInstanceMap[errKey].Error = errors.New("conflict of parameters")
+
+	dir, err := ioutil.TempDir("", "aptomi-resolve-test")
+	if !assert.NoError(t, err, "Temp dir should be created") {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir) // nolint: errcheck
+	fileName := filepath.Join(dir, "resolution.yaml")
+
+	err = saveResolution(fileName, resolution)
+	if !assert.NoError(t, err, "Policy resolution should be saved") {
+		t.FailNow()
+	}
+	assert.NotNil(t, resolution.ComponentInstanceMap[errKey].Error, "Saving policy resolution should not modify it")
+
+	loaded, err := loadResolution(fileName)
+	if !assert.NoError(t, err, "Policy resolution should be loaded") {
+		t.FailNow()
+	}
+
+	assert.Equal(t, len(resolution.ComponentInstanceMap), len(loaded.ComponentInstanceMap), "Loaded policy resolution should have all component instances")
+	for key, instance := range resolution.ComponentInstanceMap {
+		loadedInstance, ok := loaded.ComponentInstanceMap[key]
+		if !assert.True(t, ok, "Component instance %s should be loaded", key) {
+			continue
+		}
+		assert.Equal(t, instance.ClaimKeys, loadedInstance.ClaimKeys, "Claims of component instance %s should be loaded", key)
+		assert.Equal(t, instance.IsCode, loadedInstance.IsCode, "Component instance %s should be loaded", key)
+		if key == errKey {
+			if assert.NotNil(t, loadedInstance.Error, "Error of component instance %s should be loaded", key) {
+				assert.Equal(t, "conflict of parameters", loadedInstance.Error.Error(), "Error of component instance %s should be loaded", key)
+			}
+		} else {
+			assert.Nil(t, loadedInstance.Error, "Component instance %s should be loaded without error", key)
+		}
+	}
+	assert.Equal(t, resolution.DeletionPolicies, loaded.DeletionPolicies, "Deletion policies should be loaded")
+}
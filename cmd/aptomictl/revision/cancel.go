@@ -0,0 +1,43 @@
+package revision
+
+import (
+	"fmt"
+
+	"github.com/Aptomi/aptomi/pkg/client/rest"
+	"github.com/Aptomi/aptomi/pkg/client/rest/http"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func newCancelCommand(cfg *config.Client) *cobra.Command {
+	var gen uint64
+
+	cmd := &cobra.Command{
+		Use:   "cancel",
+		Short: "revision cancel",
+		Long:  "Cancel revision, which hasn't been fully applied yet. If it's being applied, no new actions will be started and the remaining actions will be skipped",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			result, err := rest.New(cfg, http.NewClient(cfg)).Revision().Cancel(runtime.Generation(gen))
+			if err != nil {
+				log.Fatalf("error while cancelling revision: %s", err)
+			}
+
+			if result.Status == engine.RevisionStatusCancelled {
+				fmt.Printf("Revision %d cancelled\n", result.GetGeneration())
+			} else {
+				fmt.Printf("Revision %d is being applied, cancellation requested. Remaining actions will be skipped\n", result.GetGeneration())
+			}
+		},
+	}
+
+	cmd.Flags().Uint64VarP(&gen, "generation", "g", 0, "Revision generation")
+	if err := cmd.MarkFlagRequired("generation"); err != nil {
+		panic(err)
+	}
+
+	return cmd
+}
@@ -15,6 +15,9 @@ func NewCommand(cfg *config.Client) *cobra.Command {
 
 	cmd.AddCommand(
 		newShowCommand(cfg),
+		newApproveCommand(cfg),
+		newRejectCommand(cfg),
+		newCancelCommand(cfg),
 	)
 
 	return cmd
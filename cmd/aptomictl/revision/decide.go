@@ -0,0 +1,73 @@
+package revision
+
+import (
+	"fmt"
+
+	"github.com/Aptomi/aptomi/pkg/client/rest"
+	"github.com/Aptomi/aptomi/pkg/client/rest/http"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func newApproveCommand(cfg *config.Client) *cobra.Command {
+	var gen uint64
+
+	cmd := &cobra.Command{
+		Use:   "approve",
+		Short: "revision approve",
+		Long:  "Approve revision, which is pending manual approval, so its action plan gets applied",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			result, err := rest.New(cfg, http.NewClient(cfg)).Revision().Approve(runtime.Generation(gen))
+			if err != nil {
+				log.Fatalf("error while approving revision: %s", err)
+			}
+
+			printDecision(result)
+		},
+	}
+
+	cmd.Flags().Uint64VarP(&gen, "generation", "g", 0, "Revision generation")
+	if err := cmd.MarkFlagRequired("generation"); err != nil {
+		panic(err)
+	}
+
+	return cmd
+}
+
+func newRejectCommand(cfg *config.Client) *cobra.Command {
+	var gen uint64
+
+	cmd := &cobra.Command{
+		Use:   "reject",
+		Short: "revision reject",
+		Long:  "Reject revision, which is pending manual approval, so its action plan will not be applied",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			result, err := rest.New(cfg, http.NewClient(cfg)).Revision().Reject(runtime.Generation(gen))
+			if err != nil {
+				log.Fatalf("error while rejecting revision: %s", err)
+			}
+
+			printDecision(result)
+		},
+	}
+
+	cmd.Flags().Uint64VarP(&gen, "generation", "g", 0, "Revision generation")
+	if err := cmd.MarkFlagRequired("generation"); err != nil {
+		panic(err)
+	}
+
+	return cmd
+}
+
+func printDecision(revision *engine.Revision) {
+	decision := "rejected"
+	if revision.IsApproved() {
+		decision = "approved"
+	}
+	fmt.Printf("Revision %d %s by %s (namespaces: %s, clusters: %s)\n", revision.GetGeneration(), decision, revision.Approval.DecidedBy, revision.Approval.Namespaces, revision.Approval.Clusters)
+}
@@ -4,12 +4,16 @@ import (
 	"path"
 	"time"
 
+	"github.com/Aptomi/aptomi/cmd/aptomictl/audit"
 	"github.com/Aptomi/aptomi/cmd/aptomictl/claim"
 	"github.com/Aptomi/aptomi/cmd/aptomictl/gen"
 	"github.com/Aptomi/aptomi/cmd/aptomictl/login"
 	"github.com/Aptomi/aptomi/cmd/aptomictl/policy"
 	"github.com/Aptomi/aptomi/cmd/aptomictl/revision"
+	"github.com/Aptomi/aptomi/cmd/aptomictl/secret"
+	"github.com/Aptomi/aptomi/cmd/aptomictl/serviceaccount"
 	"github.com/Aptomi/aptomi/cmd/aptomictl/state"
+	"github.com/Aptomi/aptomi/cmd/aptomictl/token"
 	"github.com/Aptomi/aptomi/cmd/aptomictl/version"
 	"github.com/Aptomi/aptomi/cmd/common"
 	"github.com/Aptomi/aptomi/pkg/config"
@@ -53,8 +57,20 @@ func init() {
 
 	common.AddStringFlag(Command, "output", "output", "o", "text", EnvPrefix+"_OUTPUT", "Output format. One of: text (default), json, yaml")
 
+	common.AddStringFlag(Command, "auth.token", "token", "", "", EnvPrefix+"_TOKEN", "Auth token, e.g. API token for CI pipelines (overrides the one saved by login)")
+
+	common.AddStringFlag(Command, "auth.impersonate", "as", "", "", EnvPrefix+"_AS", "User to make read-only requests on behalf of, e.g. to see claims and diagrams the way they see them (domain admins only)")
+
 	common.AddDurationFlag(Command, "http.timeout", "timeout", "", 60*time.Second, EnvPrefix+"_TIMEOUT", "Specifies time limit for receiving a reply from the server")
 
+	common.AddStringFlag(Command, "http.caCert", "ca-cert", "", "", EnvPrefix+"_CA_CERT", "CA certificate file to verify server certificate against")
+
+	common.AddStringFlag(Command, "http.clientCert", "client-cert", "", "", EnvPrefix+"_CLIENT_CERT", "Client certificate file to authenticate with (certificate CN should match user name)")
+
+	common.AddStringFlag(Command, "http.clientKey", "client-key", "", "", EnvPrefix+"_CLIENT_KEY", "Client key file to authenticate with")
+
+	common.AddBoolFlag(Command, "http.insecureSkipVerify", "insecure-skip-verify", "", false, EnvPrefix+"_INSECURE_SKIP_VERIFY", "Don't verify server certificate (insecure)")
+
 	// Add sub commands
 	Command.AddCommand(
 		login.NewCommand(Config, ConfigFile),
@@ -62,6 +78,10 @@ func init() {
 		policy.NewCommand(Config),
 		revision.NewCommand(Config),
 		state.NewCommand(Config),
+		serviceaccount.NewCommand(Config),
+		token.NewCommand(Config),
+		secret.NewCommand(Config),
+		audit.NewCommand(Config),
 		gen.NewCommand(Config),
 		version.NewCommand(Config),
 	)
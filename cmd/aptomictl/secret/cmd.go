@@ -0,0 +1,129 @@
+package secret
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/Aptomi/aptomi/cmd/common"
+	"github.com/Aptomi/aptomi/pkg/client/rest"
+	"github.com/Aptomi/aptomi/pkg/client/rest/http"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// NewCommand returns cobra command for secret subcommand
+func NewCommand(cfg *config.Client) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "secret",
+		Short: "Secret subcommand",
+		Long:  "Manage secrets, which can be referred to from code params of contracts and services",
+	}
+
+	cmd.AddCommand(
+		newListCommand(cfg),
+		newSetCommand(cfg),
+		newDeleteCommand(cfg),
+	)
+
+	return cmd
+}
+
+func newListCommand(cfg *config.Client) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "secret list",
+		Long:  "List secrets without their values (all secrets for domain admins, own secrets for everyone else)",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			result, err := rest.New(cfg, http.NewClient(cfg)).Secret().List()
+			if err != nil {
+				log.Fatalf("error while listing secrets: %s", err)
+			}
+
+			if len(result.Items) == 0 {
+				fmt.Println("No secrets found")
+				return
+			}
+
+			displayable := make([]runtime.Displayable, 0, len(result.Items))
+			for _, secret := range result.Items {
+				displayable = append(displayable, secret)
+			}
+			data, err := common.Format(cfg.Output, true, displayable...)
+			if err != nil {
+				log.Fatalf("error while formatting secrets: %s", err)
+			}
+			fmt.Println(string(data))
+		},
+	}
+}
+
+func newSetCommand(cfg *config.Client) *cobra.Command {
+	var user string
+	var name string
+	var value string
+
+	cmd := &cobra.Command{
+		Use:   "set",
+		Short: "secret set",
+		Long:  "Set secret value. If value isn't provided as a flag, it's read from stdin, so it doesn't end up in shell history",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			if !cmd.Flags().Changed("value") {
+				data, err := ioutil.ReadAll(os.Stdin)
+				if err != nil {
+					log.Fatalf("error while reading secret value from stdin: %s", err)
+				}
+				value = strings.TrimRight(string(data), "\r\n")
+			}
+
+			result, err := rest.New(cfg, http.NewClient(cfg)).Secret().Set(user, name, value)
+			if err != nil {
+				log.Fatalf("error while setting secret: %s", err)
+			}
+
+			fmt.Printf("Secret %s of %s set\n", result.Name, result.User)
+		},
+	}
+
+	cmd.Flags().StringVarP(&user, "user", "", "", "User or service account the secret belongs to (current user by default)")
+	cmd.Flags().StringVarP(&name, "name", "", "", "Secret name")
+	cmd.Flags().StringVarP(&value, "value", "", "", "Secret value (read from stdin by default)")
+	if err := cmd.MarkFlagRequired("name"); err != nil {
+		panic(err)
+	}
+
+	return cmd
+}
+
+func newDeleteCommand(cfg *config.Client) *cobra.Command {
+	var user string
+	var name string
+
+	cmd := &cobra.Command{
+		Use:   "delete",
+		Short: "secret delete",
+		Long:  "Delete secret",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			result, err := rest.New(cfg, http.NewClient(cfg)).Secret().Delete(user, name)
+			if err != nil {
+				log.Fatalf("error while deleting secret: %s", err)
+			}
+
+			fmt.Printf("Secret %s of %s deleted\n", result.Name, result.User)
+		},
+	}
+
+	cmd.Flags().StringVarP(&user, "user", "", "", "User or service account the secret belongs to (current user by default)")
+	cmd.Flags().StringVarP(&name, "name", "", "", "Secret name")
+	if err := cmd.MarkFlagRequired("name"); err != nil {
+		panic(err)
+	}
+
+	return cmd
+}
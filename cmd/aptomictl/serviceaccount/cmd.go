@@ -0,0 +1,124 @@
+package serviceaccount
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Aptomi/aptomi/cmd/common"
+	"github.com/Aptomi/aptomi/pkg/client/rest"
+	"github.com/Aptomi/aptomi/pkg/client/rest/http"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// NewCommand returns cobra command for serviceaccount subcommand
+func NewCommand(cfg *config.Client) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serviceaccount",
+		Short: "Service account subcommand",
+		Long:  "Manage service accounts, which are non-human identities (e.g. CI pipelines) calling API with API tokens",
+	}
+
+	cmd.AddCommand(
+		newListCommand(cfg),
+		newCreateCommand(cfg),
+		newDeleteCommand(cfg),
+	)
+
+	return cmd
+}
+
+func newListCommand(cfg *config.Client) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "serviceaccount list",
+		Long:  "List all service accounts",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			result, err := rest.New(cfg, http.NewClient(cfg)).ServiceAccount().List()
+			if err != nil {
+				log.Fatalf("error while listing service accounts: %s", err)
+			}
+
+			if len(result.Items) == 0 {
+				fmt.Println("No service accounts found")
+				return
+			}
+
+			displayable := make([]runtime.Displayable, 0, len(result.Items))
+			for _, sa := range result.Items {
+				displayable = append(displayable, sa)
+			}
+			data, err := common.Format(cfg.Output, true, displayable...)
+			if err != nil {
+				log.Fatalf("error while formatting service accounts: %s", err)
+			}
+			fmt.Println(string(data))
+		},
+	}
+}
+
+func newCreateCommand(cfg *config.Client) *cobra.Command {
+	var name string
+	var labels []string
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "serviceaccount create",
+		Long:  "Create service account with a given name and labels, which can be matched by ACL rule criteria",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			labelMap := make(map[string]string)
+			for _, label := range labels {
+				parts := strings.SplitN(label, "=", 2)
+				if len(parts) != 2 {
+					log.Fatalf("invalid label '%s', expected name=value", label)
+				}
+				labelMap[parts[0]] = parts[1]
+			}
+
+			result, err := rest.New(cfg, http.NewClient(cfg)).ServiceAccount().Create(name, labelMap)
+			if err != nil {
+				log.Fatalf("error while creating service account: %s", err)
+			}
+
+			fmt.Printf("Service account %s created\n", result.Name)
+		},
+	}
+
+	cmd.Flags().StringVarP(&name, "name", "n", "", "Service account name")
+	if err := cmd.MarkFlagRequired("name"); err != nil {
+		panic(err)
+	}
+	cmd.Flags().StringArrayVarP(&labels, "label", "l", nil, "Service account label in name=value format (can be repeated)")
+
+	return cmd
+}
+
+func newDeleteCommand(cfg *config.Client) *cobra.Command {
+	var name string
+
+	cmd := &cobra.Command{
+		Use:   "delete",
+		Short: "serviceaccount delete",
+		Long:  "Delete service account and revoke all of its API tokens",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			result, err := rest.New(cfg, http.NewClient(cfg)).ServiceAccount().Delete(name)
+			if err != nil {
+				log.Fatalf("error while deleting service account: %s", err)
+			}
+
+			fmt.Printf("Service account %s deleted\n", result.Name)
+		},
+	}
+
+	cmd.Flags().StringVarP(&name, "name", "n", "", "Service account name")
+	if err := cmd.MarkFlagRequired("name"); err != nil {
+		panic(err)
+	}
+
+	return cmd
+}
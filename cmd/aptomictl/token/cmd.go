@@ -0,0 +1,115 @@
+package token
+
+import (
+	"fmt"
+
+	"github.com/Aptomi/aptomi/cmd/common"
+	"github.com/Aptomi/aptomi/pkg/client/rest"
+	"github.com/Aptomi/aptomi/pkg/client/rest/http"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// NewCommand returns cobra command for token subcommand
+func NewCommand(cfg *config.Client) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "token",
+		Short: "API token subcommand",
+		Long:  "Manage long-lived API tokens for users and service accounts",
+	}
+
+	cmd.AddCommand(
+		newListCommand(cfg),
+		newCreateCommand(cfg),
+		newRevokeCommand(cfg),
+	)
+
+	return cmd
+}
+
+func newListCommand(cfg *config.Client) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "token list",
+		Long:  "List API tokens (all tokens for domain admins, own tokens for everyone else)",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			result, err := rest.New(cfg, http.NewClient(cfg)).APIToken().List()
+			if err != nil {
+				log.Fatalf("error while listing API tokens: %s", err)
+			}
+
+			if len(result.Items) == 0 {
+				fmt.Println("No API tokens found")
+				return
+			}
+
+			displayable := make([]runtime.Displayable, 0, len(result.Items))
+			for _, token := range result.Items {
+				displayable = append(displayable, token)
+			}
+			data, err := common.Format(cfg.Output, true, displayable...)
+			if err != nil {
+				log.Fatalf("error while formatting API tokens: %s", err)
+			}
+			fmt.Println(string(data))
+		},
+	}
+}
+
+func newCreateCommand(cfg *config.Client) *cobra.Command {
+	var owner string
+	var description string
+	var scopes []string
+	var ttl string
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "token create",
+		Long:  "Create API token. Token is printed only once, so it should be saved right away",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			result, err := rest.New(cfg, http.NewClient(cfg)).APIToken().Create(owner, description, scopes, ttl)
+			if err != nil {
+				log.Fatalf("error while creating API token: %s", err)
+			}
+
+			fmt.Printf("API token %s created for %s:\n%s\n", result.APIToken.ID, result.APIToken.Owner, result.Token)
+		},
+	}
+
+	cmd.Flags().StringVarP(&owner, "owner", "", "", "User or service account the token is issued for (current user by default)")
+	cmd.Flags().StringVarP(&description, "description", "", "", "Token description")
+	cmd.Flags().StringArrayVarP(&scopes, "scope", "s", nil, "Token scope: read-only, claims-only or namespace:<name> (can be repeated)")
+	cmd.Flags().StringVarP(&ttl, "ttl", "", "", "Token TTL, e.g. 720h (token never expires by default)")
+
+	return cmd
+}
+
+func newRevokeCommand(cfg *config.Client) *cobra.Command {
+	var id string
+
+	cmd := &cobra.Command{
+		Use:   "revoke",
+		Short: "token revoke",
+		Long:  "Revoke API token, so it can't be used anymore",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			result, err := rest.New(cfg, http.NewClient(cfg)).APIToken().Revoke(id)
+			if err != nil {
+				log.Fatalf("error while revoking API token: %s", err)
+			}
+
+			fmt.Printf("API token %s revoked\n", result.ID)
+		},
+	}
+
+	cmd.Flags().StringVarP(&id, "id", "", "", "API token ID")
+	if err := cmd.MarkFlagRequired("id"); err != nil {
+		panic(err)
+	}
+
+	return cmd
+}
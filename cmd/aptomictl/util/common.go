@@ -2,6 +2,7 @@ package util
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/Aptomi/aptomi/cmd/common"
@@ -50,14 +51,14 @@ func WaitForRevisionActionsToFinish(maxTime time.Duration, interval time.Duratio
 					progressBar.SetTotal(int(rev.Result.Total))
 				}
 			}
-			for progressBar != nil && progressLast < int(rev.Result.Success+rev.Result.Failed+rev.Result.Skipped) {
+			for progressBar != nil && progressLast < int(rev.Result.Processed()) {
 				progressBar.Advance()
 				progressLast++
 			}
 		}
 
-		// exit when revision is in completed or error status
-		return rev.Status == engine.RevisionStatusCompleted || rev.Status == engine.RevisionStatusError
+		// exit when revision is in completed, error or cancelled status, or when it requires manual approval
+		return rev.Status == engine.RevisionStatusCompleted || rev.Status == engine.RevisionStatusFailed || rev.Status == engine.RevisionStatusError || rev.Status == engine.RevisionStatusCancelled || rev.Status == engine.RevisionStatusPendingApproval || rev.Status == engine.RevisionStatusRejected
 	})
 
 	// stop progress bar
@@ -70,18 +71,44 @@ func WaitForRevisionActionsToFinish(maxTime time.Duration, interval time.Duratio
 		log.Fatalf("Revision %d timeout! Has not been applied in %s\n", rev.GetGeneration(), maxTime)
 	} else if rev.Status == engine.RevisionStatusCompleted {
 		if rev.Result.Total > 0 {
-			fmt.Printf("Revision %d completed. Actions: %d succeeded, %d failed, %d skipped\n", rev.GetGeneration(), rev.Result.Success, rev.Result.Failed, rev.Result.Skipped)
+			fmt.Printf("Revision %d completed. Actions: %d succeeded, %d failed, %d timed out, %d held, %d blocked, %d skipped\n", rev.GetGeneration(), rev.Result.Success, rev.Result.Failed, rev.Result.TimedOut, rev.Result.Held, rev.Result.Blocked, rev.Result.Skipped)
+			printHeldActions(rev)
 		} else {
 			fmt.Printf("Revision %d completed\n", rev.GetGeneration())
 		}
+	} else if rev.Status == engine.RevisionStatusFailed {
+		log.Fatalf("Revision %d failed. Actions: %d succeeded, %d failed, %d timed out (%d after all retries), %d skipped\n", rev.GetGeneration(), rev.Result.Success, rev.Result.Failed, rev.Result.TimedOut, rev.Result.Exhausted, rev.Result.Skipped)
 	} else if rev.Status == engine.RevisionStatusError {
 		log.Fatalf("Revision %d failed\n", rev.GetGeneration())
+	} else if rev.Status == engine.RevisionStatusCancelled {
+		log.Fatalf("Revision %d cancelled by %s. Actions: %d succeeded, %d failed, %d timed out, %d skipped\n", rev.GetGeneration(), rev.CancelledBy, rev.Result.Success, rev.Result.Failed, rev.Result.TimedOut, rev.Result.Skipped)
+	} else if rev.Status == engine.RevisionStatusPendingApproval {
+		fmt.Printf("Revision %d requires manual approval (namespaces: %s, clusters: %s). It will be applied once approved by another user via 'aptomictl revision approve -g %d'\n", rev.GetGeneration(), rev.Approval.Namespaces, rev.Approval.Clusters, rev.GetGeneration())
+	} else if rev.Status == engine.RevisionStatusRejected {
+		log.Fatalf("Revision %d rejected by %s\n", rev.GetGeneration(), rev.Approval.DecidedBy)
 	} else {
 		log.Fatalf("Unexpected revision status '%s' for revision %d\n", rev.Status, rev.GetGeneration())
 	}
 
 }
 
+// printHeldActions prints all actions which have been held or blocked and the reasons for holding them
+func printHeldActions(rev *engine.Revision) {
+	printActionReasons("held", rev.Result.HeldActions)
+	printActionReasons("blocked", rev.Result.BlockedActions)
+}
+
+func printActionReasons(prefix string, reasons map[string]string) {
+	names := []string{}
+	for name := range reasons {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("  %s %s: %s\n", prefix, name, reasons[name])
+	}
+}
+
 // PrintPolicyUpdateResult prints PolicyUpdateResult to the console
 func PrintPolicyUpdateResult(result *api.PolicyUpdateResult, logLevelObj log.Level, cfg *config.Client) { // nolint: interfacer
 	fmt.Printf("Event Log (>%s):\n", logLevelObj.String())
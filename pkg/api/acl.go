@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"net/http"
 
-	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/Aptomi/aptomi/pkg/runtime"
 	"github.com/julienschmidt/httprouter"
 )
@@ -23,13 +22,7 @@ func (api *coreAPI) handleUserRoles(writer http.ResponseWriter, request *http.Re
 		panic(fmt.Sprintf("error while getting policy: %s", err))
 	}
 
-	systemNamespace := policy.Namespace[runtime.SystemNS]
-	var aclResolver *lang.ACLResolver
-	if systemNamespace != nil {
-		aclResolver = lang.NewACLResolver(systemNamespace.ACLRules)
-	} else {
-		aclResolver = lang.NewACLResolver(make(map[string]*lang.ACLRule))
-	}
+	aclResolver := policy.NewACLResolver()
 
 	data := make(map[string]map[string]map[string]bool)
 	users := api.externalData.UserLoader.LoadUsersAll().Users
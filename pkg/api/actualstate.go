@@ -16,20 +16,14 @@ import (
 )
 
 func isDomainAdmin(user *lang.User, policy *lang.Policy) bool {
-	systemNamespace := policy.Namespace[runtime.SystemNS]
-	var aclResolver *lang.ACLResolver
-	if systemNamespace != nil {
-		aclResolver = lang.NewACLResolver(systemNamespace.ACLRules)
-	} else {
-		aclResolver = lang.NewACLResolver(make(map[string]*lang.ACLRule))
-	}
+	aclResolver := policy.NewACLResolver()
 
 	roleMap, errRoleMap := aclResolver.GetUserRoleMap(user)
 	if errRoleMap != nil {
 		panic(fmt.Sprintf("error while getting user role map: %s", errRoleMap))
 	}
 
-	if _, ok := roleMap[lang.DomainAdmin.ID]; ok {
+	if _, ok := roleMap[lang.DomainAdmin.Name]; ok {
 		return true
 	}
 
@@ -62,6 +56,7 @@ func (api *coreAPI) handleStateEnforce(writer http.ResponseWriter, request *http
 
 	// If we are in noop mode, just return expected changes in a form of an action plan
 	if noop {
+		api.skipAudit(request)
 		api.contentType.WriteOne(writer, request, &PolicyUpdateResult{
 			TypeKind:         TypePolicyUpdateResult.GetTypeKind(),
 			PolicyGeneration: policyGen,                // policy generation didn't change
@@ -75,6 +70,7 @@ func (api *coreAPI) handleStateEnforce(writer http.ResponseWriter, request *http
 
 	// Keep policy the same, but create another special revision for it to enforce the state
 	revisionGen := api.createStateEnforceRevision(policyGen, desiredState, actionPlan)
+	api.auditResult(request, policyGen, revisionGen)
 
 	api.contentType.WriteOne(writer, request, &PolicyUpdateResult{
 		TypeKind:         TypePolicyUpdateResult.GetTypeKind(),
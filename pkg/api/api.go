@@ -2,9 +2,16 @@ package api
 
 import (
 	"sync"
+	"time"
 
 	"github.com/Aptomi/aptomi/pkg/api/codec"
+	"github.com/Aptomi/aptomi/pkg/audit"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/engine"
 	"github.com/Aptomi/aptomi/pkg/external"
+	"github.com/Aptomi/aptomi/pkg/external/oidc"
+	"github.com/Aptomi/aptomi/pkg/external/scim"
+	"github.com/Aptomi/aptomi/pkg/external/secrets"
 	"github.com/Aptomi/aptomi/pkg/plugin"
 	"github.com/Aptomi/aptomi/pkg/runtime"
 	"github.com/Aptomi/aptomi/pkg/runtime/registry"
@@ -13,45 +20,113 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// RevisionCancelFunc cancels revision with a given generation on behalf of a given user. It returns nil if revision
+// doesn't exist
+type RevisionCancelFunc func(gen runtime.Generation, user string) (*engine.Revision, error)
+
 type coreAPI struct {
 	contentType                  *codec.ContentTypeHandler
 	registry                     registry.Interface
 	externalData                 *external.Data
 	pluginRegistryFactory        plugin.RegistryFactory
 	secret                       string
+	tokenTTL                     time.Duration
+	oidc                         *oidc.Provider
+	oidcCfg                      *config.OIDC
+	oidcUsers                    *registry.OIDCUserLoader
+	scimCfg                      *config.SCIM
+	scimMutex                    sync.Mutex
+	auditLog                     *audit.Log
+	secretEnvelope               *secrets.Envelope
 	logLevel                     logrus.Level
 	runDesiredStateEnforcement   chan bool
-	policyAndRevisionUpdateMutex sync.Mutex
+	cancelRevision               RevisionCancelFunc
+	policyAndRevisionUpdateMutex *sync.Mutex
 }
 
-// Serve initializes everything needed by REST API and registers all API endpoints in the provided http router
-func Serve(router *httprouter.Router, registry registry.Interface, externalData *external.Data, pluginRegistryFactory plugin.RegistryFactory, secret string, logLevel logrus.Level, runDesiredStateEnforcement chan bool) {
+// Serve initializes everything needed by REST API and registers all API endpoints in the provided http router. If
+// OpenID Connect is configured in auth config, users logging in via provider get recorded into a given OIDC user loader.
+// If SCIM config is given, identity providers can push users and groups into the registry via SCIM endpoint.
+// All API calls changing the state of Aptomi get recorded into a given audit log. Secrets are encrypted with a given
+// envelope, if it's nil then secret store is disabled. Policy and revision changes are serialized with a given mutex,
+// which is shared with the server
+func Serve(router *httprouter.Router, registry registry.Interface, externalData *external.Data, pluginRegistryFactory plugin.RegistryFactory, auth config.ServerAuth, oidcUsers *registry.OIDCUserLoader, scimCfg *config.SCIM, auditLog *audit.Log, secretEnvelope *secrets.Envelope, logLevel logrus.Level, runDesiredStateEnforcement chan bool, cancelRevision RevisionCancelFunc, policyAndRevisionUpdateMutex *sync.Mutex) {
 	contentTypeHandler := codec.NewContentTypeHandler(runtime.NewTypes().Append(Types...))
 	api := &coreAPI{
-		contentType:                contentTypeHandler,
-		registry:                   registry,
-		externalData:               externalData,
-		pluginRegistryFactory:      pluginRegistryFactory,
-		secret:                     secret,
-		logLevel:                   logLevel,
-		runDesiredStateEnforcement: runDesiredStateEnforcement,
+		contentType:                  contentTypeHandler,
+		registry:                     registry,
+		externalData:                 externalData,
+		pluginRegistryFactory:        pluginRegistryFactory,
+		secret:                       auth.Secret,
+		tokenTTL:                     auth.GetTokenTTL(),
+		oidcCfg:                      auth.OIDC,
+		oidcUsers:                    oidcUsers,
+		scimCfg:                      scimCfg,
+		auditLog:                     auditLog,
+		secretEnvelope:               secretEnvelope,
+		logLevel:                     logLevel,
+		runDesiredStateEnforcement:   runDesiredStateEnforcement,
+		cancelRevision:               cancelRevision,
+		policyAndRevisionUpdateMutex: policyAndRevisionUpdateMutex,
+	}
+	if auth.OIDC != nil {
+		api.oidc = oidc.NewProvider(auth.OIDC)
 	}
 	api.serve(router)
 }
 
 func (api *coreAPI) serve(router *httprouter.Router) {
 	auth := api.auth
+	authPolicy := api.authPolicy
+	audited := api.audited
 
 	// todo consider moving to a separate port for security (should be nothing sensetive?)
 	// prometheus metrics handler
 	router.Handler("GET", "/metrics", promhttp.Handler())
 
 	// authenticate user
-	router.POST("/api/v1/user/login", api.handleLogin)
+	router.POST("/api/v1/user/login", audited(engine.AuditActionLogin, api.handleLogin))
+
+	// authenticate user via OpenID Connect provider (authorization code flow for web UI, device flow for aptomictl)
+	if api.oidc != nil {
+		router.GET("/api/v1/user/login/oidc", api.handleOIDCLogin)
+		router.GET("/api/v1/user/login/oidc/callback", audited(engine.AuditActionLogin, api.handleOIDCCallback))
+		router.POST("/api/v1/user/login/device", api.handleDeviceLogin)
+		router.POST("/api/v1/user/login/device/token", audited(engine.AuditActionLogin, api.handleDeviceToken))
+	}
+
+	// SCIM endpoint for identity providers to push users and groups (authenticated with SCIM token from the config)
+	if api.scimCfg != nil {
+		scimAuth := api.scimAuth
+		router.GET(scimBasePath+"/ServiceProviderConfig", scimAuth(api.handleSCIMServiceProviderConfig))
+		for path, resourceType := range map[string]string{"/Users": scim.ResourceUser, "/Groups": scim.ResourceGroup} {
+			router.GET(scimBasePath+path, scimAuth(api.handleSCIMList(resourceType)))
+			router.POST(scimBasePath+path, scimAuth(audited(engine.AuditActionSCIMUpdate, api.handleSCIMCreate(resourceType))))
+			router.GET(scimBasePath+path+"/:id", scimAuth(api.handleSCIMGet(resourceType)))
+			router.PUT(scimBasePath+path+"/:id", scimAuth(audited(engine.AuditActionSCIMUpdate, api.handleSCIMReplace(resourceType))))
+			router.Handle("PATCH", scimBasePath+path+"/:id", scimAuth(audited(engine.AuditActionSCIMUpdate, api.handleSCIMPatch(resourceType))))
+			router.DELETE(scimBasePath+path+"/:id", scimAuth(audited(engine.AuditActionSCIMDelete, api.handleSCIMDelete(resourceType))))
+		}
+	}
 
 	// get all users and their roles
 	router.GET("/api/v1/user/roles", auth(api.handleUserRoles))
 
+	// manage service accounts (domain admins only)
+	router.GET("/api/v1/serviceaccount", auth(api.handleServiceAccountList))
+	router.POST("/api/v1/serviceaccount", auth(audited(engine.AuditActionServiceAccountCreate, api.handleServiceAccountCreate)))
+	router.DELETE("/api/v1/serviceaccount/:name", auth(audited(engine.AuditActionServiceAccountDelete, api.handleServiceAccountDelete)))
+
+	// manage API tokens (users can manage their own tokens, domain admins can manage tokens of anyone)
+	router.GET("/api/v1/token", auth(api.handleAPITokenList))
+	router.POST("/api/v1/token", auth(audited(engine.AuditActionAPITokenCreate, api.handleAPITokenCreate)))
+	router.DELETE("/api/v1/token/:id", auth(audited(engine.AuditActionAPITokenRevoke, api.handleAPITokenRevoke)))
+
+	// manage secrets (users can manage their own secrets, domain admins can manage secrets of anyone)
+	router.GET("/api/v1/secret", auth(api.handleSecretList))
+	router.POST("/api/v1/secret", auth(audited(engine.AuditActionSecretSet, api.handleSecretSet)))
+	router.DELETE("/api/v1/secret/:name", auth(audited(engine.AuditActionSecretDelete, api.handleSecretDelete)))
+
 	// retrieve policy (latest + by a given generation)
 	router.GET("/api/v1/policy", auth(api.handlePolicyGet))
 	router.GET("/api/v1/policy/gen/:gen", auth(api.handlePolicyGet))
@@ -60,10 +135,14 @@ func (api *coreAPI) serve(router *httprouter.Router) {
 	router.GET("/api/v1/policy/gen/:gen/object/:ns/:kind/:name", auth(api.handlePolicyObjectGet))
 
 	// update policy
-	router.POST("/api/v1/policy", auth(api.handlePolicyUpdate))
-	router.POST("/api/v1/policy/noop/:noop/loglevel/:loglevel", auth(api.handlePolicyUpdate))
-	router.DELETE("/api/v1/policy", auth(api.handlePolicyDelete))
-	router.DELETE("/api/v1/policy/noop/:noop/loglevel/:loglevel", auth(api.handlePolicyDelete))
+	router.POST("/api/v1/policy", authPolicy(audited(engine.AuditActionPolicyUpdate, api.handlePolicyUpdate)))
+	router.POST("/api/v1/policy/noop/:noop/loglevel/:loglevel", authPolicy(audited(engine.AuditActionPolicyUpdate, api.handlePolicyUpdate)))
+	router.DELETE("/api/v1/policy", authPolicy(audited(engine.AuditActionPolicyDelete, api.handlePolicyDelete)))
+	router.DELETE("/api/v1/policy/noop/:noop/loglevel/:loglevel", authPolicy(audited(engine.AuditActionPolicyDelete, api.handlePolicyDelete)))
+
+	// lint policy (latest + with given objects added to it)
+	router.GET("/api/v1/policy/lint", auth(api.handlePolicyLint))
+	router.POST("/api/v1/policy/lint", authPolicy(api.handlePolicyLint))
 
 	// policy & object diagrams
 	router.GET("/api/v1/policy/diagram/object/:ns/:kind/:name", auth(api.handleObjectDiagram))
@@ -79,10 +158,20 @@ func (api *coreAPI) serve(router *httprouter.Router) {
 	router.GET("/api/v1/revision", auth(api.handleRevisionGet))
 	router.GET("/api/v1/revision/gen/:gen", auth(api.handleRevisionGet))
 
+	// approve or reject revision, which is pending approval
+	router.POST("/api/v1/revision/gen/:gen/approve", auth(audited(engine.AuditActionRevisionApprove, api.handleRevisionApprove)))
+	router.POST("/api/v1/revision/gen/:gen/reject", auth(audited(engine.AuditActionRevisionReject, api.handleRevisionReject)))
+
+	// cancel revision, which hasn't been fully applied yet
+	router.POST("/api/v1/revision/gen/:gen/cancel", auth(audited(engine.AuditActionRevisionCancel, api.handleRevisionCancel)))
+
 	// retrieve revision(s) (for a given policy)
 	router.GET("/api/v1/revisions/policy/:policy", auth(api.handleRevisionsGetByPolicy))
 
-	router.POST("/api/v1/state/enforce/noop/:noop", auth(api.handleStateEnforce))
+	// retrieve audit log (domain admins only)
+	router.GET("/api/v1/audit", auth(api.handleAuditGet))
+
+	router.POST("/api/v1/state/enforce/noop/:noop", auth(audited(engine.AuditActionStateReset, api.handleStateEnforce)))
 
 	// return aptomi version
 	router.GET("/version", api.handleVersion)
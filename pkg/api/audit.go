@@ -0,0 +1,165 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/julienschmidt/httprouter"
+)
+
+// TypeAuditEntryList contains TypeInfo for the AuditEntryList type
+var TypeAuditEntryList = &runtime.TypeInfo{
+	Kind:        "audit-entry-list",
+	Constructor: func() runtime.Object { return &AuditEntryList{} },
+}
+
+// AuditEntryList is a list of audit entries
+type AuditEntryList struct {
+	runtime.TypeKind `yaml:",inline"`
+	Items            []*engine.AuditEntry
+}
+
+// auditRecord is the audit entry of the request, which is being audited
+type auditRecord struct {
+	entry *engine.AuditEntry
+	skip  bool
+}
+
+// audited wraps handler, so that its call gets recorded into the audit log. Handler can add details (e.g. resulting
+// policy generation) to the audit entry of the request, which can be retrieved via getAuditEntry. If handler panics,
+// entry gets recorded as failed
+func (api *coreAPI) audited(action string, handle httprouter.Handle) httprouter.Handle {
+	return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		entry := api.newAuditEntry(action, request)
+		record := &auditRecord{entry: entry}
+		defer func() {
+			if err := recover(); err != nil {
+				entry.Fail(err)
+				api.auditLog.Record(entry)
+				panic(err)
+			}
+			if !record.skip {
+				api.auditLog.Record(entry)
+			}
+		}()
+
+		ctx := context.WithValue(request.Context(), ctxAuditRecordKey, record)
+		handle(writer, request.WithContext(ctx), params)
+	}
+}
+
+// newAuditEntry creates audit entry for a given action performed by the user making the request. If domain admin
+// impersonates another user, then the entry is recorded on behalf of the admin, referring to the impersonated user
+func (api *coreAPI) newAuditEntry(action string, request *http.Request) *engine.AuditEntry {
+	entry := engine.NewAuditEntry(action, "")
+	entry.SourceIP = getSourceIP(request)
+	entry.Request = request.Method + " " + request.URL.Path
+	if user := api.getUserOptional(request); user != nil {
+		entry.User = user.Name
+	}
+	if impersonator := api.getImpersonator(request); impersonator != nil {
+		entry.User = impersonator.Name
+		entry.Impersonated = api.getUserRequired(request).Name
+	}
+	return entry
+}
+
+// getAuditEntry returns audit entry of the request. If request is not being audited, then detached entry is returned,
+// so callers don't have to check for it
+func (api *coreAPI) getAuditEntry(request *http.Request) *engine.AuditEntry {
+	if record, ok := request.Context().Value(ctxAuditRecordKey).(*auditRecord); ok {
+		return record.entry
+	}
+	return &engine.AuditEntry{}
+}
+
+// skipAudit makes sure the request doesn't get recorded into the audit log (e.g. when it runs in noop mode and
+// doesn't change anything)
+func (api *coreAPI) skipAudit(request *http.Request) {
+	if record, ok := request.Context().Value(ctxAuditRecordKey).(*auditRecord); ok {
+		record.skip = true
+	}
+}
+
+// auditObjects adds given policy objects to the audit entry of the request
+func (api *coreAPI) auditObjects(request *http.Request, objects []lang.Base) {
+	for _, obj := range objects {
+		api.auditObject(request, obj)
+	}
+}
+
+// auditObject adds a given object to the audit entry of the request
+func (api *coreAPI) auditObject(request *http.Request, obj runtime.Storable) {
+	entry := api.getAuditEntry(request)
+	entry.Objects = append(entry.Objects, runtime.KeyFromParts(obj.GetNamespace(), obj.GetKind(), obj.GetName()))
+}
+
+// auditResult adds resulting policy and revision generations to the audit entry of the request
+func (api *coreAPI) auditResult(request *http.Request, policyGen runtime.Generation, revisionGen runtime.Generation) {
+	entry := api.getAuditEntry(request)
+	entry.PolicyGen = policyGen
+	if revisionGen != runtime.MaxGeneration {
+		entry.RevisionGen = revisionGen
+	}
+}
+
+func (api *coreAPI) handleAuditGet(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	api.checkDomainAdmin(request, "view audit log")
+
+	query := request.URL.Query()
+	filter := &engine.AuditFilter{
+		User:   query.Get("user"),
+		Action: query.Get("action"),
+		Since:  parseAuditTime(query.Get("since"), "since"),
+		Until:  parseAuditTime(query.Get("until"), "until"),
+	}
+	if limit := query.Get("limit"); len(limit) > 0 {
+		var err error
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil || filter.Limit < 0 {
+			panic(fmt.Sprintf("invalid limit '%s', must be a non-negative number", limit))
+		}
+	}
+
+	entries, err := api.registry.GetAuditEntries(filter)
+	if err != nil {
+		panic(fmt.Sprintf("error while getting audit entries: %s", err))
+	}
+
+	api.contentType.WriteOne(writer, request, &AuditEntryList{
+		TypeKind: TypeAuditEntryList.GetTypeKind(),
+		Items:    entries,
+	})
+}
+
+// parseAuditTime parses time from the audit query. It can be either RFC3339 timestamp or a duration, which is
+// treated as time in the past relative to now (e.g. 24h)
+func parseAuditTime(value string, name string) time.Time {
+	if len(value) <= 0 {
+		return time.Time{}
+	}
+	if duration, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-duration)
+	}
+	result, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		panic(fmt.Sprintf("invalid %s '%s', must be either RFC3339 timestamp or duration", name, value))
+	}
+	return result
+}
+
+// getSourceIP returns IP address the request came from
+func getSourceIP(request *http.Request) string {
+	host, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		return request.RemoteAddr
+	}
+	return host
+}
@@ -2,10 +2,13 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/Aptomi/aptomi/pkg/engine"
 	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/Aptomi/aptomi/pkg/runtime"
 	"github.com/dgrijalva/jwt-go"
@@ -44,10 +47,10 @@ func (api *coreAPI) handleLogin(writer http.ResponseWriter, request *http.Reques
 		panic(fmt.Sprintf("Unexpected object received: %v", authReq))
 	}
 
+	api.getAuditEntry(request).User = authReq.Username
 	user, err := api.externalData.UserLoader.Authenticate(authReq.Username, authReq.Password)
 	if err != nil {
-		serverErr := NewServerError(fmt.Sprintf("Authentication error: %s", err))
-		api.contentType.WriteOne(writer, request, serverErr)
+		api.writeAuthError(writer, request, fmt.Sprintf("Authentication error: %s", err))
 	} else {
 		api.contentType.WriteOne(writer, request, &AuthSuccess{
 			TypeKind: TypeAuthSuccess.GetTypeKind(),
@@ -56,6 +59,12 @@ func (api *coreAPI) handleLogin(writer http.ResponseWriter, request *http.Reques
 	}
 }
 
+// writeAuthError writes authentication error and records failed login attempt into the audit log
+func (api *coreAPI) writeAuthError(writer http.ResponseWriter, request *http.Request, message string) {
+	api.getAuditEntry(request).Fail(message)
+	api.contentType.WriteOne(writer, request, NewServerError(message))
+}
+
 // Claims represent Aptomi JWT Claims
 type Claims struct {
 	Name string `json:"name"`
@@ -76,7 +85,7 @@ func (api *coreAPI) newToken(user *lang.User) string {
 		Name: user.Name,
 		StandardClaims: jwt.StandardClaims{
 			IssuedAt:  time.Now().Unix(),
-			ExpiresAt: time.Now().Add(30 * 24 * time.Hour).Unix(),
+			ExpiresAt: time.Now().Add(api.tokenTTL).Unix(),
 		},
 	})
 
@@ -89,54 +98,197 @@ func (api *coreAPI) newToken(user *lang.User) string {
 	return tokenString
 }
 
+// auth wraps handler, so it can only be called with a valid token. API tokens with scopes are only allowed to read
 func (api *coreAPI) auth(handle httprouter.Handle) httprouter.Handle {
+	return api.authWithScopes(handle, false)
+}
+
+// authPolicy wraps handler, which changes policy, so it can only be called with a valid token. API tokens with scopes
+// are allowed as well, and the handler is responsible for checking every changed object against them
+func (api *coreAPI) authPolicy(handle httprouter.Handle) httprouter.Handle {
+	return api.authWithScopes(handle, true)
+}
+
+func (api *coreAPI) authWithScopes(handle httprouter.Handle, policyChange bool) httprouter.Handle {
 	return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
 		err := api.checkToken(request)
+		if err == nil && !policyChange && request.Method != http.MethodGet {
+			err = api.checkAPITokenAllowsWrite(request)
+		}
+		impersonate := request.Header.Get(ImpersonateHeader)
+		if err == nil && len(impersonate) > 0 {
+			err = api.impersonate(request, impersonate)
+			if err != nil {
+				// failed attempt to impersonate gets recorded into the audit log as well
+				entry := api.newAuditEntry(engine.AuditActionImpersonate, request)
+				entry.Impersonated = impersonate
+				entry.Fail(err)
+				api.auditLog.Record(entry)
+			}
+		}
 		if err != nil {
 			authErr := NewServerError(fmt.Sprintf("Authentication error: %s", err))
 			api.contentType.WriteOneWithStatus(writer, request, authErr, http.StatusUnauthorized)
 			return
 		}
 
+		if len(impersonate) > 0 {
+			// every call made on behalf of impersonated user gets recorded into the audit log
+			api.audited(engine.AuditActionImpersonate, handle)(writer, request, params)
+			return
+		}
+
 		handle(writer, request, params)
 	}
 }
 
+// ImpersonateHeader is the request header, which allows domain admins to call API on behalf of another user (e.g. to
+// see claim status, claim resources and diagrams exactly the same way as that user sees them)
+const ImpersonateHeader = "X-Aptomi-Impersonate"
+
+// impersonate replaces the user of the request with a given user, keeping the original one as impersonator. Only
+// domain admins are allowed to impersonate, and impersonated requests are read-only. API tokens with scopes are never
+// allowed to impersonate, as impersonated user may have permissions beyond the scopes of the token
+func (api *coreAPI) impersonate(request *http.Request, name string) error {
+	if token := api.getAPIToken(request); token != nil && len(token.Scopes) > 0 {
+		return fmt.Errorf("API token %s with scopes [%s] is not allowed to impersonate other users", token.ID, strings.Join(token.Scopes, ","))
+	}
+
+	user := api.getUserRequired(request)
+	if !api.isDomainAdmin(user) {
+		return fmt.Errorf("user '%s' is not allowed to impersonate other users", user.Name)
+	}
+	if request.Method != http.MethodGet {
+		return fmt.Errorf("only read-only requests can be made on behalf of impersonated user")
+	}
+
+	impersonated := api.externalData.UserLoader.LoadUserByName(name)
+	if impersonated == nil {
+		return fmt.Errorf("impersonated user doesn't exist: %s", name)
+	}
+
+	ctx := context.WithValue(request.Context(), ctxUserKey, impersonated)
+	ctx = context.WithValue(ctx, ctxImpersonatorKey, user)
+	newRequest := request.WithContext(ctx)
+	*request = *newRequest
+
+	return nil
+}
+
 // The key type is unexported to prevent collisions with context keys defined in other packages
 type key int
 
 const (
 	// ctxUserKey is the context key for user
 	ctxUserKey key = iota
+
+	// ctxAPITokenKey is the context key for API token, if request has been authenticated with it
+	ctxAPITokenKey
+
+	// ctxAuditRecordKey is the context key for audit entry of the request, if request is being audited
+	ctxAuditRecordKey
+
+	// ctxImpersonatorKey is the context key for domain admin, if request is made on behalf of impersonated user
+	ctxImpersonatorKey
 )
 
 func (api *coreAPI) checkToken(request *http.Request) error {
-	token, err := jwtreq.ParseFromRequestWithClaims(request, jwtreq.AuthorizationHeaderExtractor, &Claims{},
+	var user *lang.User
+	var apiToken *engine.APIToken
+	tokenString, err := jwtreq.AuthorizationHeaderExtractor.ExtractToken(request)
+	if err != nil {
+		if !hasClientCert(request) {
+			return err
+		}
+		// no token, but client has presented verified TLS certificate
+		user, err = api.checkClientCert(request)
+	} else if id, secret, ok := engine.ParseAPIToken(tokenString); ok {
+		// long-lived API token, issued to user or service account
+		user, apiToken, err = api.checkAPIToken(id, secret)
+	} else if api.oidc != nil && tokenSigningAlg(tokenString) == jwt.SigningMethodRS256.Alg() {
+		// token is issued by OpenID Connect provider
+		user, err = api.checkIDToken(tokenString)
+	} else {
+		// token is issued by Aptomi
+		user, err = api.checkAptomiToken(tokenString)
+	}
+	if err != nil {
+		return err
+	}
+
+	// registry user into the request
+	ctx := context.WithValue(request.Context(), ctxUserKey, user)
+	if apiToken != nil {
+		ctx = context.WithValue(ctx, ctxAPITokenKey, apiToken)
+	}
+	newRequest := request.WithContext(ctx)
+	*request = *newRequest
+
+	return nil
+}
+
+func (api *coreAPI) checkAptomiToken(tokenString string) (*lang.User, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{},
 		func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, fmt.Errorf("unexpected token signing method: %s", token.Header["alg"])
+			}
 			return []byte(api.secret), nil
 		})
 	if err != nil {
-		return err
-	}
-	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-		return fmt.Errorf("unexpected token signing method: %s", token.Header["alg"])
+		return nil, err
 	}
 
 	claims, ok := token.Claims.(*Claims)
 	if !ok {
-		return fmt.Errorf("unexpected token claims, can't be casted to *Claims: %s", token.Claims)
+		return nil, fmt.Errorf("unexpected token claims, can't be casted to *Claims: %s", token.Claims)
 	}
 
 	user := api.externalData.UserLoader.LoadUserByName(claims.Name)
 	if user == nil {
-		return fmt.Errorf("token refers to non-existing user: %s", claims.Name)
+		return nil, fmt.Errorf("token refers to non-existing user: %s", claims.Name)
 	}
 
-	// registry user into the request
-	newRequest := request.WithContext(context.WithValue(request.Context(), ctxUserKey, user))
-	*request = *newRequest
+	return user, nil
+}
 
-	return nil
+// hasClientCert returns true if request came over TLS connection with client certificate verified against client CA
+func hasClientCert(request *http.Request) bool {
+	return request.TLS != nil && len(request.TLS.VerifiedChains) > 0 && len(request.TLS.VerifiedChains[0]) > 0
+}
+
+// checkClientCert returns user, which is referred by CN of the verified client certificate
+func (api *coreAPI) checkClientCert(request *http.Request) (*lang.User, error) {
+	name := request.TLS.VerifiedChains[0][0].Subject.CommonName
+	if len(name) == 0 {
+		return nil, fmt.Errorf("client certificate should contain non-empty common name")
+	}
+
+	user := api.externalData.UserLoader.LoadUserByName(name)
+	if user == nil {
+		return nil, fmt.Errorf("client certificate refers to non-existing user: %s", name)
+	}
+
+	return user, nil
+}
+
+// tokenSigningAlg returns signing algorithm from the header of a given token without verifying it
+func tokenSigningAlg(tokenString string) string {
+	parts := strings.Split(tokenString, ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	data, err := jwt.DecodeSegment(parts[0])
+	if err != nil {
+		return ""
+	}
+	header := struct {
+		Alg string `json:"alg"`
+	}{}
+	if json.Unmarshal(data, &header) != nil {
+		return ""
+	}
+	return header.Alg
 }
 
 func (api *coreAPI) getUserOptional(request *http.Request) *lang.User {
@@ -159,3 +311,12 @@ func (api *coreAPI) getUserRequired(request *http.Request) *lang.User {
 
 	return user
 }
+
+// getImpersonator returns domain admin, who made the request on behalf of impersonated user. If request is made
+// without impersonation, then nil is returned
+func (api *coreAPI) getImpersonator(request *http.Request) *lang.User {
+	if user, ok := request.Context().Value(ctxImpersonatorKey).(*lang.User); ok {
+		return user
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/external/oidc"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/julienschmidt/httprouter"
+)
+
+const (
+	// oidcStateAudience is the audience of state tokens, which protect authorization code flow from CSRF
+	oidcStateAudience = "aptomi-oidc-state"
+
+	// oidcStateCookie is the cookie, which binds state token to the browser which has started the login
+	oidcStateCookie = "aptomi-oidc-state"
+
+	// oidcStateTTL is the amount of time user has to log in via provider
+	oidcStateTTL = 10 * time.Minute
+)
+
+// TypeDeviceAuth contains TypeInfo for the DeviceAuth type
+var TypeDeviceAuth = &runtime.TypeInfo{
+	Kind:        "device-auth",
+	Constructor: func() runtime.Object { return &DeviceAuth{} },
+}
+
+// DeviceAuth represents device authorization for logging in via OpenID Connect provider. User has to open
+// VerificationURI and enter UserCode there, while the client polls for Aptomi token using DeviceCode
+type DeviceAuth struct {
+	runtime.TypeKind        `yaml:",inline"`
+	DeviceCode              string
+	UserCode                string
+	VerificationURI         string
+	VerificationURIComplete string
+	ExpiresIn               int
+	Interval                int
+}
+
+// TypeDeviceTokenRequest contains TypeInfo for the DeviceTokenRequest type
+var TypeDeviceTokenRequest = &runtime.TypeInfo{
+	Kind:        "device-token-request",
+	Constructor: func() runtime.Object { return &DeviceTokenRequest{} },
+}
+
+// DeviceTokenRequest represents request for Aptomi token after device authorization
+type DeviceTokenRequest struct {
+	runtime.TypeKind `yaml:",inline"`
+	DeviceCode       string
+}
+
+// TypeDeviceAuthPending contains TypeInfo for the DeviceAuthPending type
+var TypeDeviceAuthPending = &runtime.TypeInfo{
+	Kind:        "device-auth-pending",
+	Constructor: func() runtime.Object { return &DeviceAuthPending{} },
+}
+
+// DeviceAuthPending is returned when device authorization hasn't been approved by the user yet. If SlowDown is true,
+// client should poll less often
+type DeviceAuthPending struct {
+	runtime.TypeKind `yaml:",inline"`
+	SlowDown         bool
+}
+
+func (api *coreAPI) handleOIDCLogin(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	state := api.newOIDCState()
+	authURL, err := api.oidc.AuthCodeURL(state)
+	if err != nil {
+		api.writeAuthError(writer, request, fmt.Sprintf("Authentication error: %s", err))
+		return
+	}
+
+	http.SetCookie(writer, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     "/",
+		Expires:  time.Now().Add(oidcStateTTL),
+		HttpOnly: true,
+	})
+
+	http.Redirect(writer, request, authURL, http.StatusFound)
+}
+
+func (api *coreAPI) handleOIDCCallback(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	query := request.URL.Query()
+	if errCode := query.Get("error"); len(errCode) > 0 {
+		api.writeAuthError(writer, request, fmt.Sprintf("Authentication error: %s %s", errCode, query.Get("error_description")))
+		return
+	}
+
+	err := api.checkOIDCState(request, query.Get("state"))
+	if err != nil {
+		api.writeAuthError(writer, request, fmt.Sprintf("Authentication error: invalid state: %s", err))
+		return
+	}
+
+	tokens, err := api.oidc.Exchange(query.Get("code"))
+	if err != nil {
+		api.writeAuthError(writer, request, fmt.Sprintf("Authentication error: %s", err))
+		return
+	}
+
+	user, err := api.loginWithIDToken(tokens.IDToken)
+	if err != nil {
+		api.writeAuthError(writer, request, fmt.Sprintf("Authentication error: %s", err))
+		return
+	}
+
+	api.getAuditEntry(request).User = user.Name
+	token := api.newToken(user)
+	if len(api.oidcCfg.UIRedirectURL) > 0 {
+		http.Redirect(writer, request, api.oidcCfg.UIRedirectURL+"#token="+token, http.StatusFound)
+		return
+	}
+
+	api.contentType.WriteOne(writer, request, &AuthSuccess{
+		TypeKind: TypeAuthSuccess.GetTypeKind(),
+		Token:    token,
+	})
+}
+
+func (api *coreAPI) handleDeviceLogin(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	device, err := api.oidc.AuthorizeDevice()
+	if err != nil {
+		api.writeAuthError(writer, request, fmt.Sprintf("Authentication error: %s", err))
+		return
+	}
+
+	api.contentType.WriteOne(writer, request, &DeviceAuth{
+		TypeKind:                TypeDeviceAuth.GetTypeKind(),
+		DeviceCode:              device.DeviceCode,
+		UserCode:                device.UserCode,
+		VerificationURI:         device.VerificationURI,
+		VerificationURIComplete: device.VerificationURIComplete,
+		ExpiresIn:               device.ExpiresIn,
+		Interval:                device.Interval,
+	})
+}
+
+func (api *coreAPI) handleDeviceToken(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	tokenReq, ok := api.contentType.ReadOne(request).(*DeviceTokenRequest)
+	if !ok {
+		panic(fmt.Sprintf("Unexpected object received: %v", tokenReq))
+	}
+
+	tokens, err := api.oidc.DeviceToken(tokenReq.DeviceCode)
+	if err == oidc.ErrAuthorizationPending || err == oidc.ErrSlowDown {
+		// user hasn't approved the device yet, so there is nothing to audit
+		api.skipAudit(request)
+		api.contentType.WriteOne(writer, request, &DeviceAuthPending{
+			TypeKind: TypeDeviceAuthPending.GetTypeKind(),
+			SlowDown: err == oidc.ErrSlowDown,
+		})
+		return
+	}
+	if err != nil {
+		api.writeAuthError(writer, request, fmt.Sprintf("Authentication error: %s", err))
+		return
+	}
+
+	user, err := api.loginWithIDToken(tokens.IDToken)
+	if err != nil {
+		api.writeAuthError(writer, request, fmt.Sprintf("Authentication error: %s", err))
+		return
+	}
+
+	api.getAuditEntry(request).User = user.Name
+	api.contentType.WriteOne(writer, request, &AuthSuccess{
+		TypeKind: TypeAuthSuccess.GetTypeKind(),
+		Token:    api.newToken(user),
+	})
+}
+
+// loginWithIDToken verifies ID token issued by OpenID Connect provider, maps its claims into a user and stores the
+// user in the registry, so that it can be referred to from the policy (e.g. in claims) and from tokens issued by Aptomi
+func (api *coreAPI) loginWithIDToken(idToken string) (*lang.User, error) {
+	claims, err := api.oidc.Verify(idToken)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := api.oidc.User(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	err = api.oidcUsers.AddUser(user)
+	if err != nil {
+		return nil, err
+	}
+	return api.externalData.UserLoader.LoadUserByName(user.Name), nil
+}
+
+// checkIDToken verifies ID token issued by OpenID Connect provider and returns the user, who has logged in with it
+// before. Unlike loginWithIDToken, it doesn't store anything, as it gets called on every request
+func (api *coreAPI) checkIDToken(idToken string) (*lang.User, error) {
+	claims, err := api.oidc.Verify(idToken)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := api.oidc.User(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return api.oidcUsers.LoggedInUser(user.Name)
+}
+
+// newOIDCState returns a short-lived state token, which gets passed through the provider in authorization code flow
+func (api *coreAPI) newOIDCState() string {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.StandardClaims{
+		Audience:  oidcStateAudience,
+		IssuedAt:  time.Now().Unix(),
+		ExpiresAt: time.Now().Add(oidcStateTTL).Unix(),
+	})
+
+	tokenString, err := token.SignedString([]byte(api.secret))
+	if err != nil {
+		panic(fmt.Errorf("error while signing state: %s", err))
+	}
+
+	return tokenString
+}
+
+func (api *coreAPI) checkOIDCState(request *http.Request, state string) error {
+	cookie, err := request.Cookie(oidcStateCookie)
+	if err != nil || cookie.Value != state {
+		return fmt.Errorf("state doesn't match the one issued to the browser")
+	}
+
+	claims := &jwt.StandardClaims{}
+	_, err = jwt.ParseWithClaims(state, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected state signing method: %s", token.Header["alg"])
+		}
+		return []byte(api.secret), nil
+	})
+	if err != nil {
+		return err
+	}
+	if !claims.VerifyAudience(oidcStateAudience, true) {
+		return fmt.Errorf("unexpected state audience: %s", claims.Audience)
+	}
+	return nil
+}
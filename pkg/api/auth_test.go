@@ -0,0 +1,141 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/api/codec"
+	"github.com/Aptomi/aptomi/pkg/audit"
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/external"
+	"github.com/Aptomi/aptomi/pkg/external/users"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/runtime/registry"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+// registryMock implements only the registry operations needed for authentication, audit, revisions and SCIM, the rest
+// panic
+type registryMock struct {
+	registry.Interface
+
+	tokens        map[string]*engine.APIToken
+	auditEntries  []*engine.AuditEntry
+	revisions     map[runtime.Generation]*engine.Revision
+	policyData    map[runtime.Generation]*engine.PolicyData
+	scimResources map[string]*engine.SCIMResource
+}
+
+func (reg *registryMock) GetPolicy(gen runtime.Generation) (*lang.Policy, runtime.Generation, error) {
+	return lang.NewPolicy(), runtime.FirstGen, nil
+}
+
+func (reg *registryMock) GetAPIToken(id string) (*engine.APIToken, error) {
+	return reg.tokens[id], nil
+}
+
+func (reg *registryMock) GetRevision(gen runtime.Generation) (*engine.Revision, error) {
+	return reg.revisions[gen], nil
+}
+
+func (reg *registryMock) GetPolicyData(gen runtime.Generation) (*engine.PolicyData, error) {
+	return reg.policyData[gen], nil
+}
+
+func (reg *registryMock) GetSCIMResource(resourceType string, id string) (*engine.SCIMResource, error) {
+	return reg.scimResources[engine.SCIMResourceName(resourceType, id)], nil
+}
+
+func (reg *registryMock) GetSCIMResources(resourceType string) ([]*engine.SCIMResource, error) {
+	result := []*engine.SCIMResource{}
+	for _, resource := range reg.scimResources {
+		if resource.ResourceType == resourceType {
+			result = append(result, resource)
+		}
+	}
+	return result, nil
+}
+
+func (reg *registryMock) SaveSCIMResource(resource *engine.SCIMResource) error {
+	reg.scimResources[resource.GetName()] = resource
+	return nil
+}
+
+func (reg *registryMock) DeleteSCIMResource(resourceType string, id string) error {
+	delete(reg.scimResources, engine.SCIMResourceName(resourceType, id))
+	return nil
+}
+
+func (reg *registryMock) SaveAuditEntry(entry *engine.AuditEntry) error {
+	reg.auditEntries = append(reg.auditEntries, entry)
+	return nil
+}
+
+func TestImpersonate(t *testing.T) {
+	userLoader := users.NewUserLoaderMock()
+	userLoader.AddUser(&lang.User{Name: "admin", DomainAdmin: true})
+	userLoader.AddUser(&lang.User{Name: "alice"})
+	userLoader.AddUser(&lang.User{Name: "bob"})
+
+	scopedToken, scopedTokenString, err := engine.NewAPIToken("admin", "ci", []string{engine.APITokenScopeReadOnly}, 0, "admin")
+	if !assert.NoError(t, err, "API token should be created") {
+		t.FailNow()
+	}
+
+	reg := &registryMock{tokens: map[string]*engine.APIToken{scopedToken.ID: scopedToken}}
+	api := &coreAPI{
+		contentType:  codec.NewContentTypeHandler(runtime.NewTypes().Append(Types...)),
+		registry:     reg,
+		externalData: external.NewData(userLoader, nil),
+		secret:       "secret",
+		tokenTTL:     time.Hour,
+		auditLog:     audit.NewLog(reg),
+	}
+
+	testCases := []struct {
+		name         string
+		method       string
+		token        string
+		impersonate  string
+		expectedUser string
+		success      bool
+	}{
+		{"admin impersonates another user", http.MethodGet, api.newToken(&lang.User{Name: "admin"}), "bob", "bob", true},
+		{"non-admin is not allowed to impersonate", http.MethodGet, api.newToken(&lang.User{Name: "alice"}), "bob", "", false},
+		{"impersonated request can't change anything", http.MethodPost, api.newToken(&lang.User{Name: "admin"}), "bob", "", false},
+		{"API token with scopes is not allowed to impersonate", http.MethodGet, scopedTokenString, "bob", "", false},
+		{"impersonated user should exist", http.MethodGet, api.newToken(&lang.User{Name: "admin"}), "carol", "", false},
+	}
+	for _, tc := range testCases {
+		reg.auditEntries = nil
+		calledBy := ""
+		handle := api.auth(func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+			calledBy = api.getUserRequired(request).Name
+		})
+
+		request := httptest.NewRequest(tc.method, "/api/v1/policy", nil)
+		request.Header.Set("Authorization", "Bearer "+tc.token)
+		request.Header.Set(ImpersonateHeader, tc.impersonate)
+		writer := httptest.NewRecorder()
+		handle(writer, request, nil)
+
+		if tc.success {
+			assert.Equal(t, http.StatusOK, writer.Code, "%s: request should succeed", tc.name)
+		} else {
+			assert.Equal(t, http.StatusUnauthorized, writer.Code, "%s: request should be rejected", tc.name)
+		}
+		assert.Equal(t, tc.expectedUser, calledBy, "%s: handler should be called on behalf of correct user", tc.name)
+
+		// every attempt to impersonate gets recorded into the audit log, whether it's successful or not
+		if assert.Equal(t, 1, len(reg.auditEntries), "%s: audit entry should be recorded", tc.name) {
+			entry := reg.auditEntries[0]
+			assert.Equal(t, engine.AuditActionImpersonate, entry.Action, "%s: audit entry should have correct action", tc.name)
+			assert.Equal(t, tc.impersonate, entry.Impersonated, "%s: audit entry should refer to impersonated user", tc.name)
+			assert.Equal(t, tc.success, entry.Success, "%s: audit entry should have correct status", tc.name)
+		}
+	}
+}
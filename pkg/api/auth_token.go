@@ -0,0 +1,300 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/julienschmidt/httprouter"
+)
+
+// serviceAccountNameRegex is the pattern for service account names
+var serviceAccountNameRegex = regexp.MustCompile(`^[a-z0-9]([-a-z0-9._]*[a-z0-9])?$`)
+
+// TypeServiceAccountList contains TypeInfo for the ServiceAccountList type
+var TypeServiceAccountList = &runtime.TypeInfo{
+	Kind:        "service-account-list",
+	Constructor: func() runtime.Object { return &ServiceAccountList{} },
+}
+
+// ServiceAccountList is a list of service accounts
+type ServiceAccountList struct {
+	runtime.TypeKind `yaml:",inline"`
+	Items            []*engine.ServiceAccount
+}
+
+// TypeAPITokenList contains TypeInfo for the APITokenList type
+var TypeAPITokenList = &runtime.TypeInfo{
+	Kind:        "api-token-list",
+	Constructor: func() runtime.Object { return &APITokenList{} },
+}
+
+// APITokenList is a list of API tokens
+type APITokenList struct {
+	runtime.TypeKind `yaml:",inline"`
+	Items            []*engine.APIToken
+}
+
+// TypeAPITokenRequest contains TypeInfo for the APITokenRequest type
+var TypeAPITokenRequest = &runtime.TypeInfo{
+	Kind:        "api-token-request",
+	Constructor: func() runtime.Object { return &APITokenRequest{} },
+}
+
+// APITokenRequest represents request for a new API token. If Owner is empty, token is issued for the user making the
+// request. If TTL is empty, token never expires
+type APITokenRequest struct {
+	runtime.TypeKind `yaml:",inline"`
+	Owner            string
+	Description      string
+	Scopes           []string
+	TTL              string
+}
+
+// TypeAPITokenCreated contains TypeInfo for the APITokenCreated type
+var TypeAPITokenCreated = &runtime.TypeInfo{
+	Kind:        "api-token-created",
+	Constructor: func() runtime.Object { return &APITokenCreated{} },
+}
+
+// APITokenCreated is returned when a new API token gets created. Token is only returned once and can't be retrieved
+// afterwards
+type APITokenCreated struct {
+	runtime.TypeKind `yaml:",inline"`
+	Token            string
+	APIToken         *engine.APIToken
+}
+
+func (api *coreAPI) handleServiceAccountList(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	api.checkDomainAdmin(request, "list service accounts")
+
+	serviceAccounts, err := api.registry.GetAllServiceAccounts()
+	if err != nil {
+		panic(fmt.Sprintf("error while getting service accounts: %s", err))
+	}
+
+	api.contentType.WriteOne(writer, request, &ServiceAccountList{
+		TypeKind: TypeServiceAccountList.GetTypeKind(),
+		Items:    serviceAccounts,
+	})
+}
+
+func (api *coreAPI) handleServiceAccountCreate(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	user := api.checkDomainAdmin(request, "create service accounts")
+
+	sa, ok := api.contentType.ReadOne(request).(*engine.ServiceAccount)
+	if !ok {
+		panic(fmt.Sprintf("Unexpected object received: %v", sa))
+	}
+
+	if !serviceAccountNameRegex.MatchString(sa.Name) {
+		panic(fmt.Sprintf("invalid service account name '%s', it should consist of lowercase letters, digits, '-', '.' and '_'", sa.Name))
+	}
+	if api.externalData.UserLoader.LoadUserByName(sa.Name) != nil {
+		panic(fmt.Sprintf("user or service account '%s' already exists", sa.Name))
+	}
+
+	sa = engine.NewServiceAccount(sa.Name, sa.Labels, user.Name)
+	api.auditObject(request, sa)
+	err := api.registry.SaveServiceAccount(sa)
+	if err != nil {
+		panic(fmt.Sprintf("error while creating service account: %s", err))
+	}
+
+	api.contentType.WriteOne(writer, request, sa)
+}
+
+func (api *coreAPI) handleServiceAccountDelete(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	user := api.checkDomainAdmin(request, "delete service accounts")
+
+	name := params.ByName("name")
+	sa, err := api.registry.GetServiceAccount(name)
+	if err != nil {
+		panic(fmt.Sprintf("error while getting service account: %s", err))
+	}
+	if sa == nil {
+		panic(fmt.Sprintf("service account '%s' doesn't exist", name))
+	}
+	api.auditObject(request, sa)
+
+	err = api.registry.DeleteServiceAccount(name, user.Name)
+	if err != nil {
+		panic(fmt.Sprintf("error while deleting service account: %s", err))
+	}
+
+	api.contentType.WriteOne(writer, request, sa)
+}
+
+func (api *coreAPI) handleAPITokenList(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	user := api.getUserRequired(request)
+	domainAdmin := api.isDomainAdmin(user)
+
+	tokens, err := api.registry.GetAllAPITokens()
+	if err != nil {
+		panic(fmt.Sprintf("error while getting API tokens: %s", err))
+	}
+
+	// regular users can only see their own tokens
+	result := []*engine.APIToken{}
+	for _, token := range tokens {
+		if domainAdmin || strings.EqualFold(token.Owner, user.Name) {
+			result = append(result, token)
+		}
+	}
+
+	api.contentType.WriteOne(writer, request, &APITokenList{
+		TypeKind: TypeAPITokenList.GetTypeKind(),
+		Items:    result,
+	})
+}
+
+func (api *coreAPI) handleAPITokenCreate(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	user := api.getUserRequired(request)
+
+	tokenReq, ok := api.contentType.ReadOne(request).(*APITokenRequest)
+	if !ok {
+		panic(fmt.Sprintf("Unexpected object received: %v", tokenReq))
+	}
+
+	owner := user
+	if len(tokenReq.Owner) > 0 && !strings.EqualFold(tokenReq.Owner, user.Name) {
+		if !api.isDomainAdmin(user) {
+			panic(fmt.Sprintf("user '%s' is not allowed to create API tokens for '%s'", user.Name, tokenReq.Owner))
+		}
+		owner = api.externalData.UserLoader.LoadUserByName(tokenReq.Owner)
+		if owner == nil {
+			panic(fmt.Sprintf("user or service account '%s' doesn't exist", tokenReq.Owner))
+		}
+	}
+
+	var ttl time.Duration
+	if len(tokenReq.TTL) > 0 {
+		var err error
+		ttl, err = time.ParseDuration(tokenReq.TTL)
+		if err != nil || ttl <= 0 {
+			panic(fmt.Sprintf("invalid API token TTL '%s'", tokenReq.TTL))
+		}
+	}
+
+	token, tokenString, err := engine.NewAPIToken(owner.Name, tokenReq.Description, tokenReq.Scopes, ttl, user.Name)
+	if err != nil {
+		panic(fmt.Sprintf("error while creating API token: %s", err))
+	}
+
+	api.auditObject(request, token)
+	err = api.registry.SaveAPIToken(token)
+	if err != nil {
+		panic(fmt.Sprintf("error while creating API token: %s", err))
+	}
+
+	api.contentType.WriteOne(writer, request, &APITokenCreated{
+		TypeKind: TypeAPITokenCreated.GetTypeKind(),
+		Token:    tokenString,
+		APIToken: token,
+	})
+}
+
+func (api *coreAPI) handleAPITokenRevoke(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	user := api.getUserRequired(request)
+
+	token, err := api.registry.GetAPIToken(params.ByName("id"))
+	if err != nil {
+		panic(fmt.Sprintf("error while getting API token: %s", err))
+	}
+	if token == nil {
+		panic(fmt.Sprintf("API token '%s' doesn't exist", params.ByName("id")))
+	}
+	api.auditObject(request, token)
+
+	if !strings.EqualFold(token.Owner, user.Name) && !api.isDomainAdmin(user) {
+		panic(fmt.Sprintf("user '%s' is not allowed to revoke API tokens of '%s'", user.Name, token.Owner))
+	}
+
+	if !token.Revoked {
+		err = api.registry.RevokeAPIToken(token, user.Name)
+		if err != nil {
+			panic(fmt.Sprintf("error while revoking API token: %s", err))
+		}
+	}
+
+	api.contentType.WriteOne(writer, request, token)
+}
+
+// checkAPIToken verifies API token and loads its owner
+func (api *coreAPI) checkAPIToken(id string, secret string) (*lang.User, *engine.APIToken, error) {
+	token, err := api.registry.GetAPIToken(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if token == nil {
+		return nil, nil, fmt.Errorf("invalid API token")
+	}
+
+	err = token.Verify(secret)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user := api.externalData.UserLoader.LoadUserByName(token.Owner)
+	if user == nil {
+		return nil, nil, fmt.Errorf("API token refers to non-existing user: %s", token.Owner)
+	}
+
+	return user, token, nil
+}
+
+// getAPIToken returns API token, which has been used to authenticate the request, or nil if request has been
+// authenticated in a different way
+func (api *coreAPI) getAPIToken(request *http.Request) *engine.APIToken {
+	if token, ok := request.Context().Value(ctxAPITokenKey).(*engine.APIToken); ok {
+		return token
+	}
+	return nil
+}
+
+// checkAPITokenAllowsWrite returns an error if request has been authenticated with API token, which has scopes. Such
+// tokens are only allowed to change policy and nothing else
+func (api *coreAPI) checkAPITokenAllowsWrite(request *http.Request) error {
+	token := api.getAPIToken(request)
+	if token != nil && len(token.Scopes) > 0 {
+		return fmt.Errorf("API token %s with scopes [%s] is not allowed to perform %s %s", token.ID, strings.Join(token.Scopes, ","), request.Method, request.URL.Path)
+	}
+	return nil
+}
+
+// checkAPITokenScopes panics if request has been authenticated with API token, which scopes don't allow to change any
+// of the given objects
+func (api *coreAPI) checkAPITokenScopes(request *http.Request, objects []lang.Base) {
+	token := api.getAPIToken(request)
+	if token == nil {
+		return
+	}
+	for _, obj := range objects {
+		if !token.AllowsObjectChange(obj) {
+			panic(fmt.Sprintf("API token %s with scopes [%s] is not allowed to change %s '%s' in namespace '%s'", token.ID, strings.Join(token.Scopes, ","), obj.GetKind(), obj.GetName(), obj.GetNamespace()))
+		}
+	}
+}
+
+// isDomainAdmin returns true if user is a domain admin in the latest policy
+func (api *coreAPI) isDomainAdmin(user *lang.User) bool {
+	policy, _, err := api.registry.GetPolicy(runtime.LastOrEmptyGen)
+	if err != nil {
+		panic(fmt.Sprintf("error while loading latest policy: %s", err))
+	}
+	return isDomainAdmin(user, policy)
+}
+
+// checkDomainAdmin panics if the user making the request is not a domain admin
+func (api *coreAPI) checkDomainAdmin(request *http.Request, action string) *lang.User {
+	user := api.getUserRequired(request)
+	if !api.isDomainAdmin(user) {
+		panic(fmt.Sprintf("user '%s' is not allowed to %s", user.Name, action))
+	}
+	return user
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"runtime/debug"
@@ -81,6 +82,9 @@ func (api *coreAPI) handleClaimStatusGet(writer http.ResponseWriter, request *ht
 		panic(fmt.Sprintf("can't load actual state from the registry: %s", err))
 	}
 
+	// claims are looked up on behalf of the user, so the ones user is not allowed to view are reported as not found
+	userView := policy.View(api.getUserRequired(request))
+
 	// initialize result
 	result := &ClaimsStatus{
 		TypeKind: TypeClaimsStatus.GetTypeKind(),
@@ -89,7 +93,7 @@ func (api *coreAPI) handleClaimStatusGet(writer http.ResponseWriter, request *ht
 	for _, claimID := range claimIds {
 		parts := strings.Split(claimID, "^")
 		cObj, err := policy.GetObject(lang.TypeClaim.Kind, parts[1], parts[0])
-		if cObj == nil || err != nil {
+		if cObj == nil || err != nil || userView.ViewObject(cObj.(lang.Base)) != nil {
 			claimKey := runtime.KeyFromParts(parts[0], lang.TypeClaim.Kind, parts[1])
 			result.Status[claimKey] = &ClaimStatus{
 				Found:     false,
@@ -116,7 +120,7 @@ func (api *coreAPI) handleClaimStatusGet(writer http.ResponseWriter, request *ht
 	// fetch readiness status for claims, if we were asked to do so
 	if flag == ClaimQueryDeploymentStatusAndReadiness {
 		plugins := api.pluginRegistryFactory()
-		fetchReadinessStatusForClaims(result, plugins, policy, actualState)
+		fetchReadinessStatusForClaims(request.Context(), result, plugins, policy, actualState)
 	}
 
 	// fetch endpoints for claims
@@ -130,7 +134,8 @@ func fetchDeploymentStatusForClaims(result *ClaimsStatus, actualState *resolve.P
 	// compare desired vs. actual state and see what's the claim status for every provided claim ID
 	actionPlan := diff.NewPolicyResolutionDiff(desiredState, actualState).ActionPlan
 	actionPlan.Apply(
-		action.WrapSequential(func(act action.Interface) error {
+		context.Background(),
+		action.WrapSequential(func(ctx context.Context, act action.Interface) error {
 			// if it's attach action is pending on component, let's see which particular claim it affects
 			if dAction, ok := act.(*component.AttachClaimAction); ok {
 				// reset status of this particular claim to false
@@ -179,6 +184,7 @@ func fetchDeploymentStatusForClaims(result *ClaimsStatus, actualState *resolve.P
 			}
 			return nil
 		}),
+		nil,
 		action.NewApplyResultUpdaterImpl(),
 	)
 
@@ -193,7 +199,7 @@ func fetchDeploymentStatusForClaims(result *ClaimsStatus, actualState *resolve.P
 	}
 }
 
-func fetchReadinessStatusForClaims(result *ClaimsStatus, plugins plugin.Registry, policy *lang.Policy, actualState *resolve.PolicyResolution) {
+func fetchReadinessStatusForClaims(ctx context.Context, result *ClaimsStatus, plugins plugin.Registry, policy *lang.Policy, actualState *resolve.PolicyResolution) {
 	// if claim is not deployed, it means it's not ready
 	for claimKey := range result.Status {
 		result.Status[claimKey].Ready = result.Status[claimKey].Ready && result.Status[claimKey].Deployed
@@ -245,6 +251,7 @@ func fetchReadinessStatusForClaims(result *ClaimsStatus, plugins plugin.Registry
 			}
 
 			instanceStatus, err := codePlugin.Status(
+				ctx,
 				&plugin.CodePluginInvocationParams{
 					DeployName:   instance.GetDeployName(),
 					Params:       instance.CalculatedCodeParams,
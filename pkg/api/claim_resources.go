@@ -38,8 +38,9 @@ func (api *coreAPI) handleClaimResourcesGet(writer http.ResponseWriter, request
 	if err != nil {
 		panic(fmt.Sprintf("error while getting object %s/%s/%s in policy #%s", ns, kind, name, gen))
 	}
-	if obj == nil {
+	if obj == nil || policy.View(api.getUserRequired(request)).ViewObject(obj.(lang.Base)) != nil {
 		api.contentType.WriteOneWithStatus(writer, request, nil, http.StatusNotFound)
+		return
 	}
 
 	// once claim is loaded, we need to find its state in the actual state
@@ -83,6 +84,7 @@ func (api *coreAPI) handleClaimResourcesGet(writer http.ResponseWriter, request
 				}
 
 				instanceResources, resErr := codePlugin.Resources(
+					request.Context(),
 					&plugin.CodePluginInvocationParams{
 						DeployName:   instance.GetDeployName(),
 						Params:       instance.CalculatedCodeParams,
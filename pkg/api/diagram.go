@@ -22,6 +22,7 @@ func (g *graphWrapper) GetKind() string {
 }
 
 func (api *coreAPI) handlePolicyDiagram(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	user := api.getUserRequired(request)
 	mode := params.ByName("mode")
 	gen := params.ByName("gen")
 
@@ -63,16 +64,16 @@ func (api *coreAPI) handlePolicyDiagram(writer http.ResponseWriter, request *htt
 	switch strings.ToLower(mode) {
 	case "policy":
 		// show just policy
-		graphBuilder := visualization.NewGraphBuilder(policy, nil, nil)
+		graphBuilder := visualization.NewGraphBuilder(policy, nil, nil).ForUser(user)
 		graph = graphBuilder.Policy(visualization.PolicyCfgDefault)
 	case "desired":
 		// show instances in desired state
-		graphBuilder := visualization.NewGraphBuilder(policy, desiredState, api.externalData)
+		graphBuilder := visualization.NewGraphBuilder(policy, desiredState, api.externalData).ForUser(user)
 		graph = graphBuilder.ClaimResolution(visualization.ClaimResolutionCfgDefault)
 	case "actual":
 		// TODO: actual may not work correctly in all cases (e.g. after policy delete on a cluster which is not available, desired state has less components, these components are still in actual state but will not be shown on UI)
 		// show instances in actual state
-		graphBuilder := visualization.NewGraphBuilder(policy, desiredState, api.externalData)
+		graphBuilder := visualization.NewGraphBuilder(policy, desiredState, api.externalData).ForUser(user)
 		graph = graphBuilder.ClaimResolutionWithFunc(visualization.ClaimResolutionCfgDefault, func(instance *resolve.ComponentInstance) bool {
 			_, found := actualState.ComponentInstanceMap[instance.GetKey()]
 			return found
@@ -85,6 +86,7 @@ func (api *coreAPI) handlePolicyDiagram(writer http.ResponseWriter, request *htt
 }
 
 func (api *coreAPI) handlePolicyDiagramCompare(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	user := api.getUserRequired(request)
 	mode := params.ByName("mode")
 	gen := params.ByName("gen")
 	if len(gen) == 0 {
@@ -109,8 +111,8 @@ func (api *coreAPI) handlePolicyDiagramCompare(writer http.ResponseWriter, reque
 	switch strings.ToLower(mode) {
 	case "policy":
 		// policy & policy base
-		graph = visualization.NewGraphBuilder(policy, nil, nil).Policy(visualization.PolicyCfgDefault)
-		graphBase := visualization.NewGraphBuilder(policyBase, nil, nil).Policy(visualization.PolicyCfgDefault)
+		graph = visualization.NewGraphBuilder(policy, nil, nil).ForUser(user).Policy(visualization.PolicyCfgDefault)
+		graphBase := visualization.NewGraphBuilder(policyBase, nil, nil).ForUser(user).Policy(visualization.PolicyCfgDefault)
 
 		// diff
 		graph.CalcDelta(graphBase)
@@ -127,7 +129,7 @@ func (api *coreAPI) handlePolicyDiagramCompare(writer http.ResponseWriter, reque
 				panic(fmt.Sprintf("can't load desired from revision: %s", err))
 			}
 
-			graphBuilder := visualization.NewGraphBuilder(policy, desiredState, api.externalData)
+			graphBuilder := visualization.NewGraphBuilder(policy, desiredState, api.externalData).ForUser(user)
 			graph = graphBuilder.ClaimResolution(visualization.ClaimResolutionCfgDefault)
 		}
 
@@ -144,7 +146,7 @@ func (api *coreAPI) handlePolicyDiagramCompare(writer http.ResponseWriter, reque
 				panic(fmt.Sprintf("can't load desired state from revision: %s", err))
 			}
 
-			graphBuilderBase := visualization.NewGraphBuilder(policyBase, desiredStateBase, api.externalData)
+			graphBuilderBase := visualization.NewGraphBuilder(policyBase, desiredStateBase, api.externalData).ForUser(user)
 			graphBase = graphBuilderBase.ClaimResolution(visualization.ClaimResolutionCfgDefault)
 		}
 
@@ -158,6 +160,7 @@ func (api *coreAPI) handlePolicyDiagramCompare(writer http.ResponseWriter, reque
 }
 
 func (api *coreAPI) handleObjectDiagram(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	user := api.getUserRequired(request)
 	ns := params.ByName("ns")
 	kind := params.ByName("kind")
 	name := params.ByName("name")
@@ -187,7 +190,7 @@ func (api *coreAPI) handleObjectDiagram(writer http.ResponseWriter, request *htt
 		}
 	}
 
-	graphBuilder := visualization.NewGraphBuilder(policy, desiredState, api.externalData)
+	graphBuilder := visualization.NewGraphBuilder(policy, desiredState, api.externalData).ForUser(user)
 	graph := graphBuilder.Object(obj)
 
 	api.contentType.WriteOne(writer, request, &graphWrapper{Data: graph.GetData()})
@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/api"
+	"github.com/Aptomi/aptomi/pkg/api/codec"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// rateLimitedPrefix is the prefix of paths, which are subject to rate limiting
+	rateLimitedPrefix = "/api/"
+
+	// rateLimiterIdleTTL is how long limiters of users, which don't make any requests, are kept for
+	rateLimiterIdleTTL = 10 * time.Minute
+)
+
+// KeyFunc returns the key, which identifies who has made the request (e.g. user or token)
+type KeyFunc func(request *http.Request) string
+
+// rejectedRequests counts requests rejected by the rate limiter. It's registered only once, as there is a single
+// API server per process
+var (
+	rejectedRequests     *prometheus.CounterVec
+	rejectedRequestsOnce sync.Once
+)
+
+// rateLimiters are limiters of a single user (or token)
+type rateLimiters struct {
+	all      *rate.Limiter
+	write    *rate.Limiter
+	lastSeen time.Time
+}
+
+type rateLimitHandler struct {
+	handler     http.Handler
+	cfg         config.RateLimit
+	key         KeyFunc
+	contentType *codec.ContentTypeHandler
+
+	mutex     sync.Mutex
+	limiters  map[string]*rateLimiters
+	lastSweep time.Time
+}
+
+// NewRateLimitHandler returns middleware that limits the rate of API requests made by every user (or token), as
+// identified by a given key function, and the size of request body. Rejected requests get counted in metrics
+func NewRateLimitHandler(svcName string, cfg config.RateLimit, key KeyFunc, handler http.Handler) http.Handler {
+	rejectedRequestsOnce.Do(func() {
+		rejectedRequests = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name:        "http_requests_rejected_total",
+				Help:        "Number of HTTP requests rejected by rate limiter labeled with reason, method and HTTP path.",
+				ConstLabels: prometheus.Labels{"service": svcName},
+			},
+			[]string{"reason", "method", "path"},
+		)
+		prometheus.MustRegister(rejectedRequests)
+	})
+
+	return &rateLimitHandler{
+		handler:     handler,
+		cfg:         cfg,
+		key:         key,
+		contentType: codec.NewContentTypeHandler(runtime.NewTypes().Append(api.TypeServerError)),
+		limiters:    make(map[string]*rateLimiters),
+		lastSweep:   time.Now(),
+	}
+}
+
+func (h *rateLimitHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if !strings.HasPrefix(request.URL.Path, rateLimitedPrefix) {
+		h.handler.ServeHTTP(writer, request)
+		return
+	}
+
+	if h.cfg.MaxBodySize > 0 {
+		if request.ContentLength > h.cfg.MaxBodySize {
+			h.reject(writer, request, "body_size", http.StatusRequestEntityTooLarge, fmt.Sprintf("request body is too large, max allowed size is %d bytes", h.cfg.MaxBodySize))
+			return
+		}
+		request.Body = http.MaxBytesReader(writer, request.Body, h.cfg.MaxBodySize)
+	}
+
+	if delay := h.reserve(h.key(request), request.Method != http.MethodGet); delay > 0 {
+		writer.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+		h.reject(writer, request, "rate_limit", http.StatusTooManyRequests, fmt.Sprintf("rate limit exceeded, retry in %s", delay))
+		return
+	}
+
+	h.handler.ServeHTTP(writer, request)
+}
+
+// reserve takes a token from the limiters of a given key. If request is not allowed right now, then nothing gets
+// taken and the time to wait before the request will be allowed is returned
+func (h *rateLimitHandler) reserve(key string, write bool) time.Duration {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	now := time.Now()
+	h.sweep(now)
+
+	limiters, ok := h.limiters[key]
+	if !ok {
+		limiters = &rateLimiters{}
+		if h.cfg.Rate > 0 {
+			limiters.all = rate.NewLimiter(rate.Limit(h.cfg.Rate), h.cfg.GetBurst())
+		}
+		if h.cfg.WriteRate > 0 {
+			limiters.write = rate.NewLimiter(rate.Limit(h.cfg.WriteRate), h.cfg.GetWriteBurst())
+		}
+		h.limiters[key] = limiters
+	}
+	limiters.lastSeen = now
+
+	reservations := []*rate.Reservation{}
+	if limiters.all != nil {
+		reservations = append(reservations, limiters.all.ReserveN(now, 1))
+	}
+	if write && limiters.write != nil {
+		reservations = append(reservations, limiters.write.ReserveN(now, 1))
+	}
+
+	delay := time.Duration(0)
+	for _, reservation := range reservations {
+		if reservationDelay := reservation.DelayFrom(now); reservationDelay > delay {
+			delay = reservationDelay
+		}
+	}
+	if delay > 0 {
+		for _, reservation := range reservations {
+			reservation.CancelAt(now)
+		}
+	}
+
+	return delay
+}
+
+// sweep removes limiters of the users, which haven't made any requests for a while
+func (h *rateLimitHandler) sweep(now time.Time) {
+	if now.Sub(h.lastSweep) < rateLimiterIdleTTL {
+		return
+	}
+	for key, limiters := range h.limiters {
+		if now.Sub(limiters.lastSeen) > rateLimiterIdleTTL {
+			delete(h.limiters, key)
+		}
+	}
+	h.lastSweep = now
+}
+
+func (h *rateLimitHandler) reject(writer http.ResponseWriter, request *http.Request, reason string, status int, message string) {
+	rejectedRequests.WithLabelValues(reason, request.Method, request.URL.Path).Inc()
+	h.contentType.WriteOneWithStatus(writer, request, api.NewServerError(message), status)
+}
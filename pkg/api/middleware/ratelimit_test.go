@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRateLimitHandler(cfg config.RateLimit) http.Handler {
+	return NewRateLimitHandler("aptomi-test", cfg, func(request *http.Request) string {
+		return request.Header.Get("X-User")
+	}, http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	}))
+}
+
+func doRequest(handler http.Handler, method string, path string, user string, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	request.Header.Set("X-User", user)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestRateLimitHandler(t *testing.T) {
+	handler := newTestRateLimitHandler(config.RateLimit{Rate: 0.1, Burst: 2})
+
+	assert.Equal(t, http.StatusOK, doRequest(handler, http.MethodGet, "/api/v1/policy", "alice", "").Code, "Request within burst should be allowed")
+	assert.Equal(t, http.StatusOK, doRequest(handler, http.MethodGet, "/api/v1/policy", "alice", "").Code, "Request within burst should be allowed")
+
+	rejected := doRequest(handler, http.MethodGet, "/api/v1/policy", "alice", "")
+	assert.Equal(t, http.StatusTooManyRequests, rejected.Code, "Request exceeding the limit should be rejected")
+	retryAfter, err := strconv.Atoi(rejected.Header().Get("Retry-After"))
+	assert.NoError(t, err, "Retry-After header should be set")
+	assert.True(t, retryAfter > 0 && retryAfter <= 10, "Retry-After should point to the time request will be allowed")
+
+	assert.Equal(t, http.StatusOK, doRequest(handler, http.MethodGet, "/api/v1/policy", "bob", "").Code, "Limits should be applied per user")
+	assert.Equal(t, http.StatusOK, doRequest(handler, http.MethodGet, "/metrics", "alice", "").Code, "Only API requests should be limited")
+}
+
+func TestRateLimitHandlerWrite(t *testing.T) {
+	handler := newTestRateLimitHandler(config.RateLimit{Rate: 100, WriteRate: 0.1})
+
+	assert.Equal(t, http.StatusOK, doRequest(handler, http.MethodPost, "/api/v1/policy", "alice", "").Code, "Request within write burst should be allowed")
+	assert.Equal(t, http.StatusTooManyRequests, doRequest(handler, http.MethodPost, "/api/v1/policy", "alice", "").Code, "Write request exceeding the limit should be rejected")
+	assert.Equal(t, http.StatusOK, doRequest(handler, http.MethodGet, "/api/v1/policy", "alice", "").Code, "Read requests should not be affected by write limit")
+}
+
+func TestRateLimitHandlerBodySize(t *testing.T) {
+	handler := newTestRateLimitHandler(config.RateLimit{MaxBodySize: 10})
+
+	assert.Equal(t, http.StatusOK, doRequest(handler, http.MethodPost, "/api/v1/policy", "alice", "small").Code, "Small request should be allowed")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, doRequest(handler, http.MethodPost, "/api/v1/policy", "alice", "very large request body").Code, "Large request should be rejected")
+}
@@ -12,8 +12,19 @@ var (
 	Types = runtime.AppendAllTypes([]*runtime.TypeInfo{
 		TypeClaimsStatus,
 		TypePolicyUpdateResult,
+		TypePolicyLintResult,
 		TypeAuthSuccess,
 		TypeAuthRequest,
+		TypeDeviceAuth,
+		TypeDeviceTokenRequest,
+		TypeDeviceAuthPending,
+		TypeServiceAccountList,
+		TypeAPITokenList,
+		TypeAPITokenRequest,
+		TypeAPITokenCreated,
+		TypeSecretList,
+		TypeSecretRequest,
+		TypeAuditEntryList,
 		TypeServerError,
 		version.TypeBuildInfo,
 	}, lang.PolicyTypes, engine.Types)
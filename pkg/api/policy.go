@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"sort"
 
@@ -119,18 +120,25 @@ func (rs apiObjectSorter) Less(i, j int) bool {
 }
 
 func (rs apiObjectSorter) Weight(obj lang.Base) int { // nolint: interfacer
-	// ACL rules have to come in the first place
-	if obj.GetKind() == lang.TypeACLRule.Kind {
+	// ACL roles have to come in the first place, as ACL rules may refer to them
+	if obj.GetKind() == lang.TypeACLRole.Kind {
 		return 0
 	}
 
+	// ACL rules have to come next
+	if obj.GetKind() == lang.TypeACLRule.Kind {
+		return 1
+	}
+
 	// All other objects can be added in any order
-	return 1
+	return 2
 }
 
 func (api *coreAPI) handlePolicyUpdate(writer http.ResponseWriter, request *http.Request, params httprouter.Params) { // nolint: gocyclo
 	objects := api.readLang(request)
 	user := api.getUserRequired(request)
+	api.checkAPITokenScopes(request, objects)
+	api.auditObjects(request, objects)
 
 	// Load the latest policy
 	_, policyGen, err := api.registry.GetPolicy(runtime.LastOrEmptyGen)
@@ -158,7 +166,13 @@ func (api *coreAPI) handlePolicyUpdate(writer http.ResponseWriter, request *http
 
 	// Add objects to the policy in a sorted order (e.g. make sure ACL Rules go first)
 	sort.Sort(apiObjectSorter(objects))
+	now := time.Now()
 	for _, obj := range objects {
+		// Every time a claim with TTL gets updated, its expiration time gets extended
+		if claim, ok := obj.(*lang.Claim); ok {
+			claim.UpdateExpiration(now)
+		}
+
 		errManage := policyUpdated.View(user).ManageObject(obj)
 		if errManage != nil {
 			panic(fmt.Sprintf("error while adding updated object to policy: %s", errManage))
@@ -217,6 +231,7 @@ func (api *coreAPI) handlePolicyUpdate(writer http.ResponseWriter, request *http
 
 	// If we are in noop mode, just return expected changes in a form of an action plan
 	if noop {
+		api.skipAudit(request)
 		api.contentType.WriteOne(writer, request, &PolicyUpdateResult{
 			TypeKind:         TypePolicyUpdateResult.GetTypeKind(),
 			PolicyGeneration: policyGen,              // policy generation didn't change
@@ -230,6 +245,7 @@ func (api *coreAPI) handlePolicyUpdate(writer http.ResponseWriter, request *http
 
 	// Update policy
 	changed, policyGen, revisionGen := api.changePolicy(objects, user, desiredStateUpdated, false)
+	api.auditResult(request, policyGen, revisionGen)
 
 	// Return the result back via API
 	api.contentType.WriteOne(writer, request, &PolicyUpdateResult{
@@ -251,6 +267,8 @@ func (api *coreAPI) handlePolicyUpdate(writer http.ResponseWriter, request *http
 func (api *coreAPI) handlePolicyDelete(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
 	objects := api.readLang(request)
 	user := api.getUserRequired(request)
+	api.checkAPITokenScopes(request, objects)
+	api.auditObjects(request, objects)
 
 	// Load the latest policy gen
 	_, policyGen, err := api.registry.GetPolicy(runtime.LastOrEmptyGen)
@@ -279,9 +297,9 @@ func (api *coreAPI) handlePolicyDelete(writer http.ResponseWriter, request *http
 	// Delete objects from the policy in a reversed sorted order (e.g. make sure ACL Rules go last)
 	sort.Sort(sort.Reverse(apiObjectSorter(objects)))
 	for _, obj := range objects {
-		errManage := policyUpdated.View(user).ManageObject(obj)
-		if errManage != nil {
-			panic(fmt.Sprintf("Error while removing object from policy: %s", errManage))
+		errDelete := policyUpdated.View(user).DeleteObject(obj)
+		if errDelete != nil {
+			panic(fmt.Sprintf("Error while removing object from policy: %s", errDelete))
 		}
 		policyUpdated.RemoveObject(obj)
 	}
@@ -315,6 +333,7 @@ func (api *coreAPI) handlePolicyDelete(writer http.ResponseWriter, request *http
 
 	// If we are in noop mode, just return expected changes in a form of an action plan
 	if noop {
+		api.skipAudit(request)
 		api.contentType.WriteOne(writer, request, &PolicyUpdateResult{
 			TypeKind:         TypePolicyUpdateResult.GetTypeKind(),
 			PolicyGeneration: policyGen,              // policy generation didn't change
@@ -328,6 +347,7 @@ func (api *coreAPI) handlePolicyDelete(writer http.ResponseWriter, request *http
 
 	// Update policy
 	changed, policyGen, revisionGen := api.changePolicy(objects, user, desiredStateUpdated, true)
+	api.auditResult(request, policyGen, revisionGen)
 
 	// Return the result back via API
 	api.contentType.WriteOne(writer, request, &PolicyUpdateResult{
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/julienschmidt/httprouter"
+)
+
+// TypePolicyLintResult is an informational data structure with Kind and Constructor for PolicyLintResult
+var TypePolicyLintResult = &runtime.TypeInfo{
+	Kind:        "policy-lint-result",
+	Constructor: func() runtime.Object { return &PolicyLintResult{} },
+}
+
+// PolicyLintResult represents results of the policy lint request
+type PolicyLintResult struct {
+	runtime.TypeKind `yaml:",inline"`
+	PolicyGeneration runtime.Generation
+	Warnings         []*lang.PolicyLintWarning
+}
+
+// GetDefaultColumns returns default set of columns to be displayed
+func (result *PolicyLintResult) GetDefaultColumns() []string {
+	return []string{"Policy Generation", "Warnings"}
+}
+
+// AsColumns returns PolicyLintResult representation as columns
+func (result *PolicyLintResult) AsColumns() map[string]string {
+	warnings := []string{}
+	for _, warning := range result.Warnings {
+		warnings = append(warnings, warning.String())
+	}
+	warningsStr := strings.Join(warnings, "\n")
+	if len(warningsStr) <= 0 {
+		warningsStr = "(none)"
+	}
+	return map[string]string{
+		"Policy Generation": fmt.Sprintf("%d", result.PolicyGeneration),
+		"Warnings":          warningsStr,
+	}
+}
+
+// handlePolicyLint runs lint checks on the latest policy. If objects are supplied in the request, they get added to
+// the policy first (without saving it), so the changes can be checked before they get applied
+func (api *coreAPI) handlePolicyLint(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	objects := []lang.Base{}
+	if request.Method == http.MethodPost {
+		objects = api.readLang(request)
+	}
+	user := api.getUserRequired(request)
+
+	// Load the latest policy
+	policy, policyGen, err := api.registry.GetPolicy(runtime.LastOrEmptyGen)
+	if err != nil {
+		panic(fmt.Sprintf("error while loading current policy: %s", err))
+	}
+
+	// Add objects to the policy in a sorted order (e.g. make sure ACL Rules go first)
+	sort.Sort(apiObjectSorter(objects))
+	for _, obj := range objects {
+		errManage := policy.View(user).ManageObject(obj)
+		if errManage != nil {
+			panic(fmt.Sprintf("error while adding object to policy: %s", errManage))
+		}
+		errAdd := policy.AddObject(obj)
+		if errAdd != nil {
+			panic(fmt.Sprintf("error while adding object to policy: %s", errAdd))
+		}
+	}
+
+	// Lint checks only make sense for a valid policy
+	err = policy.Validate()
+	if err != nil {
+		panic(fmt.Sprintf("policy is invalid: %s", err))
+	}
+
+	api.contentType.WriteOne(writer, request, &PolicyLintResult{
+		TypeKind:         TypePolicyLintResult.GetTypeKind(),
+		PolicyGeneration: policyGen,
+		Warnings:         policy.Lint(),
+	})
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/runtime/registry"
+	"github.com/dgrijalva/jwt-go"
+	jwtreq "github.com/dgrijalva/jwt-go/request"
+)
+
+// NewRequestKeyFunc returns function, which identifies who has made the request in order to apply rate limits to it.
+// Requests made with tokens issued by Aptomi or with client certificates are identified by user name, requests made
+// with API tokens are identified by token ID, all other requests (anonymous ones, or made with any other token) are
+// identified by source IP. Tokens which can't be verified here must not be used as keys, as otherwise a client could
+// bypass the limit (and blow up the number of tracked keys) by sending a new random token with every request. Tokens
+// issued by Aptomi are verified with a given secret, so it's cheap to call for every request. API tokens are verified
+// against the hash of their secret stored in the registry
+func NewRequestKeyFunc(secret string, registry registry.Interface) func(request *http.Request) string {
+	return func(request *http.Request) string {
+		tokenString, err := jwtreq.AuthorizationHeaderExtractor.ExtractToken(request)
+		if err != nil {
+			if hasClientCert(request) {
+				return "user:" + request.TLS.VerifiedChains[0][0].Subject.CommonName
+			}
+			return "ip:" + getSourceIP(request)
+		}
+
+		if id, tokenSecret, ok := engine.ParseAPIToken(tokenString); ok {
+			token, tokenErr := registry.GetAPIToken(id)
+			if tokenErr == nil && token != nil && token.Verify(tokenSecret) == nil {
+				return "token:" + token.ID
+			}
+			return "ip:" + getSourceIP(request)
+		}
+
+		claims := &Claims{}
+		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, jwt.ErrSignatureInvalid
+			}
+			return []byte(secret), nil
+		})
+		if err == nil && token.Valid {
+			return "user:" + claims.Name
+		}
+
+		return "ip:" + getSourceIP(request)
+	}
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestKeyFunc(t *testing.T) {
+	apiToken, apiTokenString, err := engine.NewAPIToken("alice", "ci", nil, 0, "alice")
+	if !assert.NoError(t, err, "API token should be created") {
+		t.FailNow()
+	}
+	reg := &registryMock{tokens: map[string]*engine.APIToken{apiToken.ID: apiToken}}
+
+	api := &coreAPI{secret: "secret", tokenTTL: time.Hour}
+	keyFunc := NewRequestKeyFunc(api.secret, reg)
+
+	newRequest := func(remoteAddr string, token string) *http.Request {
+		request := httptest.NewRequest(http.MethodGet, "/api/v1/policy", nil)
+		request.RemoteAddr = remoteAddr
+		if len(token) > 0 {
+			request.Header.Set("Authorization", "Bearer "+token)
+		}
+		return request
+	}
+
+	testCases := []struct {
+		request  *http.Request
+		expected string
+	}{
+		{newRequest("10.0.0.1:1234", ""), "ip:10.0.0.1"},
+		{newRequest("10.0.0.1:1234", api.newToken(&lang.User{Name: "alice"})), "user:alice"},
+		{newRequest("10.0.0.2:1234", api.newToken(&lang.User{Name: "alice"})), "user:alice"},
+		{newRequest("10.0.0.1:1234", (&coreAPI{secret: "other", tokenTTL: time.Hour}).newToken(&lang.User{Name: "alice"})), "ip:10.0.0.1"},
+		{newRequest("10.0.0.1:1234", apiTokenString), "token:" + apiToken.ID},
+		{newRequest("10.0.0.2:1234", apiTokenString), "token:" + apiToken.ID},
+		{newRequest("10.0.0.1:1234", apiTokenString+"wrong"), "ip:10.0.0.1"},
+		{newRequest("10.0.0.1:1234", "apt_0123456789abcdef_secret"), "ip:10.0.0.1"},
+		{newRequest("10.0.0.1:1234", "random1"), "ip:10.0.0.1"},
+		{newRequest("10.0.0.1:1234", "random2"), "ip:10.0.0.1"},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, keyFunc(tc.request), "Request should be identified correctly for rate limiting")
+	}
+}
@@ -4,7 +4,11 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/Aptomi/aptomi/pkg/runtime"
 	"github.com/julienschmidt/httprouter"
 )
@@ -54,3 +58,160 @@ func (api *coreAPI) handleRevisionsGetByPolicy(writer http.ResponseWriter, reque
 		api.contentType.WriteOne(writer, request, &revisionsWrapper{Data: revisions})
 	}
 }
+
+func (api *coreAPI) handleRevisionApprove(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	api.handleRevisionDecision(writer, request, params, true)
+}
+
+func (api *coreAPI) handleRevisionReject(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	api.handleRevisionDecision(writer, request, params, false)
+}
+
+// handleRevisionDecision approves or rejects revision, which is pending approval. Decision has to be made by a user
+// other than the one who made the policy change, and who is able to manage services in all affected namespaces
+func (api *coreAPI) handleRevisionDecision(writer http.ResponseWriter, request *http.Request, params httprouter.Params, approve bool) {
+	user := api.getUserRequired(request)
+
+	// Make sure to take the mutex, so no new revisions will get created in the meantime
+	api.policyAndRevisionUpdateMutex.Lock()
+	defer api.policyAndRevisionUpdateMutex.Unlock()
+
+	revision, err := api.registry.GetRevision(runtime.ParseGeneration(params.ByName("gen")))
+	if err != nil {
+		panic(fmt.Sprintf("error while getting requested revision: %s", err))
+	}
+	if revision == nil {
+		api.getAuditEntry(request).Fail("revision doesn't exist")
+		api.contentType.WriteOneWithStatus(writer, request, nil, http.StatusNotFound)
+		return
+	}
+	api.auditResult(request, revision.PolicyGen, revision.GetGeneration())
+	if revision.Status != engine.RevisionStatusPendingApproval || revision.Approval == nil {
+		panic(fmt.Sprintf("revision %d is not pending approval (status: %s)", revision.GetGeneration(), revision.Status))
+	}
+
+	// only the latest revision can be approved, otherwise older desired state would get applied on top of the newer one
+	if approve {
+		lastRevision, lastErr := api.registry.GetRevision(runtime.LastOrEmptyGen)
+		if lastErr != nil {
+			panic(fmt.Sprintf("error while getting last revision: %s", lastErr))
+		}
+		if lastRevision != nil && lastRevision.GetGeneration() != revision.GetGeneration() {
+			panic(fmt.Sprintf("revision %d can't be approved, as it has been superseded by revision %d", revision.GetGeneration(), lastRevision.GetGeneration()))
+		}
+	}
+
+	// the decision has to be made by another person than any of the people who made the policy changes
+	for _, updatedBy := range api.getPolicyUpdaters(revision) {
+		if strings.EqualFold(updatedBy, user.Name) {
+			panic(fmt.Sprintf("revision %d can't be approved or rejected by user '%s', who made the policy change", revision.GetGeneration(), user.Name))
+		}
+	}
+
+	// user should be able to approve changes of services in all affected namespaces
+	policy, _, err := api.registry.GetPolicy(revision.PolicyGen)
+	if err != nil {
+		panic(fmt.Sprintf("error while getting policy: %s", err))
+	}
+	userView := policy.View(user)
+	for _, namespace := range revision.Approval.Namespaces {
+		service := &lang.Service{TypeKind: lang.TypeService.GetTypeKind(), Metadata: lang.Metadata{Namespace: namespace}}
+		if userView.ApproveObject(service) != nil {
+			panic(fmt.Sprintf("user '%s' doesn't have ACL permissions to approve or reject changes in namespace '%s'", user.Name, namespace))
+		}
+	}
+
+	// record the decision. approved revision goes back into the queue, so it gets picked up by the enforcer
+	revision.Approval.Approved = approve
+	revision.Approval.DecidedBy = user.Name
+	revision.Approval.DecidedAt = time.Now()
+	if approve {
+		revision.Status = engine.RevisionStatusWaiting
+	} else {
+		revision.Status = engine.RevisionStatusRejected
+	}
+	err = api.registry.UpdateRevision(revision)
+	if err != nil {
+		panic(fmt.Sprintf("error while updating revision: %s", err))
+	}
+
+	api.contentType.WriteOne(writer, request, revision)
+
+	if approve {
+		// signal to the channel that revision has been approved, that will trigger the enforcement right away
+		api.runDesiredStateEnforcement <- true
+	}
+}
+
+// getPolicyUpdaters returns names of users, who made policy changes applied by a given revision. Revisions which
+// haven't been completed (e.g. rejected or cancelled ones) haven't applied their policy changes, so these changes get
+// applied by the given revision as well
+func (api *coreAPI) getPolicyUpdaters(revision *engine.Revision) []string {
+	fromPolicyGen := runtime.FirstGen
+	for gen := revision.GetGeneration(); gen > runtime.FirstGen; gen-- {
+		prevRevision, err := api.registry.GetRevision(gen - 1)
+		if err != nil {
+			panic(fmt.Sprintf("error while getting revision: %s", err))
+		}
+		if prevRevision != nil && prevRevision.Status == engine.RevisionStatusCompleted {
+			fromPolicyGen = prevRevision.PolicyGen.Next()
+			break
+		}
+	}
+
+	result := []string{}
+	for policyGen := fromPolicyGen; policyGen <= revision.PolicyGen; policyGen++ {
+		policyData, err := api.registry.GetPolicyData(policyGen)
+		if err != nil {
+			panic(fmt.Sprintf("error while getting policy data: %s", err))
+		}
+		if policyData != nil {
+			result = append(result, policyData.Metadata.UpdatedBy)
+		}
+	}
+	return result
+}
+
+// handleRevisionCancel cancels revision, which hasn't been fully applied yet. Revision can be cancelled by a domain
+// admin or by the user who made the policy change
+func (api *coreAPI) handleRevisionCancel(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	user := api.getUserRequired(request)
+	gen := runtime.ParseGeneration(params.ByName("gen"))
+
+	revision, err := api.registry.GetRevision(gen)
+	if err != nil {
+		panic(fmt.Sprintf("error while getting requested revision: %s", err))
+	}
+	if revision == nil {
+		api.getAuditEntry(request).Fail("revision doesn't exist")
+		api.contentType.WriteOneWithStatus(writer, request, nil, http.StatusNotFound)
+		return
+	}
+	api.auditResult(request, revision.PolicyGen, revision.GetGeneration())
+
+	// check that user is allowed to cancel the revision
+	policy, _, err := api.registry.GetPolicy(revision.PolicyGen)
+	if err != nil {
+		panic(fmt.Sprintf("error while getting policy: %s", err))
+	}
+	if !isDomainAdmin(user, policy) {
+		policyData, policyDataErr := api.registry.GetPolicyData(revision.PolicyGen)
+		if policyDataErr != nil {
+			panic(fmt.Sprintf("error while getting policy data: %s", policyDataErr))
+		}
+		if policyData == nil || policyData.Metadata.UpdatedBy != user.Name {
+			panic(fmt.Sprintf("user '%s' is not allowed to cancel revision %d", user.Name, revision.GetGeneration()))
+		}
+	}
+
+	revision, err = api.cancelRevision(gen, user.Name)
+	if err != nil {
+		panic(fmt.Sprintf("error while cancelling revision: %s", err))
+	}
+	if revision == nil {
+		api.contentType.WriteOneWithStatus(writer, request, nil, http.StatusNotFound)
+		return
+	}
+
+	api.contentType.WriteOne(writer, request, revision)
+}
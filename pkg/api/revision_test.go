@@ -0,0 +1,29 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPolicyUpdaters(t *testing.T) {
+	policyData := make(map[runtime.Generation]*engine.PolicyData)
+	for gen, updatedBy := range []string{"system", "alice", "bob", "carol", "dave"} {
+		policyData[runtime.Generation(gen+1)] = &engine.PolicyData{Metadata: engine.PolicyDataMetadata{Generation: runtime.Generation(gen + 1), UpdatedBy: updatedBy}}
+	}
+	revisions := map[runtime.Generation]*engine.Revision{
+		1: {Metadata: runtime.GenerationMetadata{Generation: 1}, PolicyGen: 2, Status: engine.RevisionStatusCompleted},
+		2: {Metadata: runtime.GenerationMetadata{Generation: 2}, PolicyGen: 3, Status: engine.RevisionStatusRejected},
+		3: {Metadata: runtime.GenerationMetadata{Generation: 3}, PolicyGen: 4, Status: engine.RevisionStatusCancelled},
+		4: {Metadata: runtime.GenerationMetadata{Generation: 4}, PolicyGen: 5, Status: engine.RevisionStatusPendingApproval},
+	}
+	api := &coreAPI{registry: &registryMock{revisions: revisions, policyData: policyData}}
+
+	// policy changes of rejected and cancelled revisions get applied by the pending one
+	assert.Equal(t, []string{"bob", "carol", "dave"}, api.getPolicyUpdaters(revisions[4]), "Updaters since the last completed revision should be returned")
+
+	// without completed revisions, all policy changes get applied
+	assert.Equal(t, []string{"system", "alice"}, api.getPolicyUpdaters(revisions[1]), "All updaters should be returned for the first revision")
+}
@@ -0,0 +1,324 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/external/scim"
+	jwtreq "github.com/dgrijalva/jwt-go/request"
+	"github.com/julienschmidt/httprouter"
+)
+
+const (
+	// scimBasePath is the base path of SCIM endpoint, which should be configured in identity provider
+	scimBasePath = "/api/v1/scim"
+
+	// scimUser is the name changes made via SCIM endpoint are recorded under in the audit log
+	scimUser = "system:scim"
+)
+
+// scimAuth wraps SCIM handler, so that it's only called if the request contains SCIM bearer token from the config
+func (api *coreAPI) scimAuth(handle httprouter.Handle) httprouter.Handle {
+	return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		token, err := jwtreq.AuthorizationHeaderExtractor.ExtractToken(request)
+		if err != nil || subtle.ConstantTimeCompare([]byte(token), []byte(api.scimCfg.Token)) != 1 {
+			api.writeSCIMError(writer, request, scim.NewError(http.StatusUnauthorized, "", "invalid SCIM bearer token"))
+			return
+		}
+		handle(writer, request, params)
+	}
+}
+
+func (api *coreAPI) handleSCIMServiceProviderConfig(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	api.writeSCIM(writer, http.StatusOK, scim.ServiceProviderConfig())
+}
+
+func (api *coreAPI) handleSCIMList(resourceType string) httprouter.Handle {
+	return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		query := request.URL.Query()
+
+		var filter *scim.Filter
+		if len(query.Get("filter")) > 0 {
+			var err error
+			filter, err = scim.ParseFilter(query.Get("filter"))
+			if err != nil {
+				api.writeSCIMError(writer, request, err.(*scim.Error))
+				return
+			}
+		}
+
+		startIndex, count := 1, -1
+		for name, value := range map[string]*int{"startIndex": &startIndex, "count": &count} {
+			if len(query.Get(name)) > 0 {
+				n, err := strconv.Atoi(query.Get(name))
+				if err != nil {
+					api.writeSCIMError(writer, request, scim.NewError(http.StatusBadRequest, scim.ErrorInvalidValue, "invalid %s '%s'", name, query.Get(name)))
+					return
+				}
+				*value = n
+			}
+		}
+
+		resources := []scim.Resource{}
+		for _, stored := range api.getSCIMResources(resourceType) {
+			resource := api.scimResource(request, stored)
+			if filter == nil || filter.Matches(resource) {
+				resources = append(resources, resource)
+			}
+		}
+
+		api.writeSCIM(writer, http.StatusOK, scim.NewListResponse(resources, startIndex, count))
+	}
+}
+
+func (api *coreAPI) handleSCIMGet(resourceType string) httprouter.Handle {
+	return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		stored := api.getSCIMResource(resourceType, params.ByName("id"))
+		if stored == nil {
+			api.writeSCIMError(writer, request, scim.NewError(http.StatusNotFound, "", "%s '%s' doesn't exist", resourceType, params.ByName("id")))
+			return
+		}
+
+		api.writeSCIM(writer, http.StatusOK, api.scimResource(request, stored))
+	}
+}
+
+func (api *coreAPI) handleSCIMCreate(resourceType string) httprouter.Handle {
+	return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		api.getAuditEntry(request).User = scimUser
+		api.scimMutex.Lock()
+		defer api.scimMutex.Unlock()
+
+		resource := scim.Resource{}
+		if scimErr := api.readSCIM(request, &resource); scimErr != nil {
+			api.writeSCIMError(writer, request, scimErr)
+			return
+		}
+		resource = resource.WithoutReadOnly()
+		name, scimErr := api.checkSCIMResource(resourceType, "", resource)
+		if scimErr != nil {
+			api.writeSCIMError(writer, request, scimErr)
+			return
+		}
+
+		stored, err := engine.NewSCIMResource(resourceType, name, marshalSCIMResource(resource))
+		if err != nil {
+			panic(fmt.Sprintf("error while creating SCIM %s: %s", resourceType, err))
+		}
+		api.saveSCIMResource(request, stored)
+
+		api.writeSCIM(writer, http.StatusCreated, api.scimResource(request, stored))
+	}
+}
+
+func (api *coreAPI) handleSCIMReplace(resourceType string) httprouter.Handle {
+	return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		api.getAuditEntry(request).User = scimUser
+		api.scimMutex.Lock()
+		defer api.scimMutex.Unlock()
+
+		stored := api.getSCIMResource(resourceType, params.ByName("id"))
+		if stored == nil {
+			api.writeSCIMError(writer, request, scim.NewError(http.StatusNotFound, "", "%s '%s' doesn't exist", resourceType, params.ByName("id")))
+			return
+		}
+
+		resource := scim.Resource{}
+		if scimErr := api.readSCIM(request, &resource); scimErr != nil {
+			api.writeSCIMError(writer, request, scimErr)
+			return
+		}
+		api.updateSCIMResource(writer, request, stored, resource.WithoutReadOnly())
+	}
+}
+
+func (api *coreAPI) handleSCIMPatch(resourceType string) httprouter.Handle {
+	return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		api.getAuditEntry(request).User = scimUser
+		api.scimMutex.Lock()
+		defer api.scimMutex.Unlock()
+
+		stored := api.getSCIMResource(resourceType, params.ByName("id"))
+		if stored == nil {
+			api.writeSCIMError(writer, request, scim.NewError(http.StatusNotFound, "", "%s '%s' doesn't exist", resourceType, params.ByName("id")))
+			return
+		}
+
+		patch := &scim.PatchRequest{}
+		if scimErr := api.readSCIM(request, patch); scimErr != nil {
+			api.writeSCIMError(writer, request, scimErr)
+			return
+		}
+
+		resource := unmarshalSCIMResource(stored)
+		if err := resource.Patch(patch.Operations); err != nil {
+			api.writeSCIMError(writer, request, err.(*scim.Error))
+			return
+		}
+		api.updateSCIMResource(writer, request, stored, resource.WithoutReadOnly())
+	}
+}
+
+func (api *coreAPI) handleSCIMDelete(resourceType string) httprouter.Handle {
+	return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		api.getAuditEntry(request).User = scimUser
+		api.scimMutex.Lock()
+		defer api.scimMutex.Unlock()
+
+		stored := api.getSCIMResource(resourceType, params.ByName("id"))
+		if stored == nil {
+			api.writeSCIMError(writer, request, scim.NewError(http.StatusNotFound, "", "%s '%s' doesn't exist", resourceType, params.ByName("id")))
+			return
+		}
+		api.auditObject(request, stored)
+
+		// deleted user should not remain a member of any group
+		if resourceType == scim.ResourceUser {
+			api.removeSCIMGroupMember(request, stored.ID)
+		}
+
+		err := api.registry.DeleteSCIMResource(resourceType, stored.ID)
+		if err != nil {
+			panic(fmt.Sprintf("error while deleting SCIM %s: %s", resourceType, err))
+		}
+
+		writer.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// updateSCIMResource replaces attributes of a stored SCIM resource with a given ones and writes it as a response
+func (api *coreAPI) updateSCIMResource(writer http.ResponseWriter, request *http.Request, stored *engine.SCIMResource, resource scim.Resource) {
+	name, scimErr := api.checkSCIMResource(stored.ResourceType, stored.ID, resource)
+	if scimErr != nil {
+		api.writeSCIMError(writer, request, scimErr)
+		return
+	}
+
+	stored.Name = name
+	stored.Data = marshalSCIMResource(resource)
+	stored.UpdatedAt = time.Now()
+	api.saveSCIMResource(request, stored)
+
+	api.writeSCIM(writer, http.StatusOK, api.scimResource(request, stored))
+}
+
+// removeSCIMGroupMember removes user with a given ID from all groups
+func (api *coreAPI) removeSCIMGroupMember(request *http.Request, id string) {
+	for _, group := range api.getSCIMResources(scim.ResourceGroup) {
+		resource := unmarshalSCIMResource(group)
+		for _, memberID := range resource.MemberIDs() {
+			if memberID != id {
+				continue
+			}
+			err := resource.Patch([]*scim.PatchOperation{{Op: scim.OpRemove, Path: fmt.Sprintf("members[value eq %q]", id)}})
+			if err != nil {
+				panic(fmt.Sprintf("error while removing user '%s' from SCIM group '%s': %s", id, group.ID, err))
+			}
+			group.Data = marshalSCIMResource(resource)
+			group.UpdatedAt = time.Now()
+			api.saveSCIMResource(request, group)
+			break
+		}
+	}
+}
+
+// checkSCIMResource checks that SCIM resource has a name, which is unique for the resource type, and returns it
+func (api *coreAPI) checkSCIMResource(resourceType string, id string, resource scim.Resource) (string, *scim.Error) {
+	name := resource.Name(resourceType)
+	if len(name) <= 0 {
+		attribute := "userName"
+		if resourceType == scim.ResourceGroup {
+			attribute = "displayName"
+		}
+		return "", scim.NewError(http.StatusBadRequest, scim.ErrorInvalidValue, "%s is required", attribute)
+	}
+
+	for _, other := range api.getSCIMResources(resourceType) {
+		if other.ID != id && strings.EqualFold(other.Name, name) {
+			return "", scim.NewError(http.StatusConflict, scim.ErrorUniqueness, "%s '%s' already exists", resourceType, name)
+		}
+	}
+	return name, nil
+}
+
+func (api *coreAPI) getSCIMResource(resourceType string, id string) *engine.SCIMResource {
+	stored, err := api.registry.GetSCIMResource(resourceType, id)
+	if err != nil {
+		panic(fmt.Sprintf("error while getting SCIM %s: %s", resourceType, err))
+	}
+	return stored
+}
+
+func (api *coreAPI) getSCIMResources(resourceType string) []*engine.SCIMResource {
+	stored, err := api.registry.GetSCIMResources(resourceType)
+	if err != nil {
+		panic(fmt.Sprintf("error while getting SCIM %s resources: %s", resourceType, err))
+	}
+	return stored
+}
+
+func (api *coreAPI) saveSCIMResource(request *http.Request, stored *engine.SCIMResource) {
+	api.auditObject(request, stored)
+	err := api.registry.SaveSCIMResource(stored)
+	if err != nil {
+		panic(fmt.Sprintf("error while saving SCIM %s: %s", stored.ResourceType, err))
+	}
+}
+
+// scimResource returns stored SCIM resource along with its id and meta attributes
+func (api *coreAPI) scimResource(request *http.Request, stored *engine.SCIMResource) scim.Resource {
+	scheme := "http"
+	if request.TLS != nil {
+		scheme = "https"
+	}
+	location := fmt.Sprintf("%s://%s%s/%ss/%s", scheme, request.Host, scimBasePath, stored.ResourceType, stored.ID)
+	return unmarshalSCIMResource(stored).WithMeta(stored.ID, stored.ResourceType, stored.CreatedAt, stored.UpdatedAt, location)
+}
+
+func unmarshalSCIMResource(stored *engine.SCIMResource) scim.Resource {
+	resource := scim.Resource{}
+	err := json.Unmarshal([]byte(stored.Data), &resource)
+	if err != nil {
+		panic(fmt.Sprintf("error while parsing SCIM %s '%s': %s", stored.ResourceType, stored.ID, err))
+	}
+	return resource
+}
+
+func marshalSCIMResource(resource scim.Resource) string {
+	data, err := json.Marshal(resource)
+	if err != nil {
+		panic(fmt.Sprintf("error while serializing SCIM resource: %s", err))
+	}
+	return string(data)
+}
+
+// readSCIM reads SCIM request body into a given object
+func (api *coreAPI) readSCIM(request *http.Request, obj interface{}) *scim.Error {
+	err := json.NewDecoder(request.Body).Decode(obj)
+	if err != nil {
+		return scim.NewError(http.StatusBadRequest, scim.ErrorInvalidSyntax, "invalid request body: %s", err)
+	}
+	return nil
+}
+
+// writeSCIM writes SCIM response with a given status
+func (api *coreAPI) writeSCIM(writer http.ResponseWriter, status int, obj interface{}) {
+	writer.Header().Set("Content-Type", scim.ContentType)
+	writer.WriteHeader(status)
+	err := json.NewEncoder(writer).Encode(obj)
+	if err != nil {
+		panic(fmt.Sprintf("error while writing SCIM response: %s", err))
+	}
+}
+
+// writeSCIMError writes SCIM error and records failed request into the audit log
+func (api *coreAPI) writeSCIMError(writer http.ResponseWriter, request *http.Request, scimErr *scim.Error) {
+	api.getAuditEntry(request).Fail(scimErr.Detail)
+	api.writeSCIM(writer, scimErr.StatusCode(), scimErr)
+}
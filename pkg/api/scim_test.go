@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/api/codec"
+	"github.com/Aptomi/aptomi/pkg/audit"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/external"
+	"github.com/Aptomi/aptomi/pkg/external/scim"
+	"github.com/Aptomi/aptomi/pkg/external/users"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/runtime/registry"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSCIM(t *testing.T) {
+	reg := &registryMock{scimResources: make(map[string]*engine.SCIMResource)}
+	api := &coreAPI{
+		contentType:  codec.NewContentTypeHandler(runtime.NewTypes().Append(Types...)),
+		registry:     reg,
+		externalData: external.NewData(users.NewUserLoaderMock(), nil),
+		secret:       "secret",
+		tokenTTL:     time.Hour,
+		scimCfg:      &config.SCIM{Token: "scimtoken"},
+		auditLog:     audit.NewLog(reg),
+	}
+	router := httprouter.New()
+	api.serve(router)
+
+	do := func(method, path, token, body string) (int, scim.Resource) {
+		request := httptest.NewRequest(method, scimBasePath+path, strings.NewReader(body))
+		if len(token) > 0 {
+			request.Header.Set("Authorization", "Bearer "+token)
+		}
+		writer := httptest.NewRecorder()
+		router.ServeHTTP(writer, request)
+
+		resource := scim.Resource{}
+		if writer.Body.Len() > 0 {
+			assert.NoError(t, json.Unmarshal(writer.Body.Bytes(), &resource), "Response should be valid JSON: %s %s", method, path)
+		}
+		return writer.Code, resource
+	}
+
+	// SCIM endpoint is only available with SCIM token
+	for _, token := range []string{"", "wrong", api.newToken(&lang.User{Name: "admin", DomainAdmin: true})} {
+		code, _ := do(http.MethodGet, "/Users", token, "")
+		assert.Equal(t, http.StatusUnauthorized, code, "Request without SCIM token should be rejected")
+	}
+	code, _ := do(http.MethodGet, "/Users", "scimtoken", "")
+	assert.Equal(t, http.StatusOK, code, "Request with SCIM token should be allowed")
+
+	// users get created, and their names should be unique
+	code, alice := do(http.MethodPost, "/Users", "scimtoken", `{"userName": "alice@example.com"}`)
+	assert.Equal(t, http.StatusCreated, code, "User should be created")
+	code, bob := do(http.MethodPost, "/Users", "scimtoken", `{"userName": "bob@example.com"}`)
+	assert.Equal(t, http.StatusCreated, code, "User should be created")
+	code, _ = do(http.MethodPost, "/Users", "scimtoken", `{"userName": "Alice@example.com"}`)
+	assert.Equal(t, http.StatusConflict, code, "User with the same name should not be created")
+	code, _ = do(http.MethodPut, "/Users/"+bob.GetString("id"), "scimtoken", `{"userName": "alice@example.com"}`)
+	assert.Equal(t, http.StatusConflict, code, "User should not be renamed to the name of another user")
+
+	// users get added to groups
+	code, group := do(http.MethodPost, "/Groups", "scimtoken", `{"displayName": "developers", "members": [{"value": "`+alice.GetString("id")+`"}, {"value": "`+bob.GetString("id")+`"}]}`)
+	assert.Equal(t, http.StatusCreated, code, "Group should be created")
+	loader := registry.NewSCIMUserLoader(reg, config.SCIM{}, nil)
+	if assert.NotNil(t, loader.LoadUserByName("bob@example.com"), "User should be loaded") {
+		assert.Equal(t, []string{"developers"}, loader.LoadUserByName("bob@example.com").Groups, "User should be a member of the group")
+	}
+
+	// deactivated user should not be loaded
+	code, _ = do(http.MethodPatch, "/Users/"+bob.GetString("id"), "scimtoken", `{"Operations": [{"op": "replace", "path": "active", "value": false}]}`)
+	assert.Equal(t, http.StatusOK, code, "User should be deactivated")
+	loader = registry.NewSCIMUserLoader(reg, config.SCIM{}, nil)
+	assert.Nil(t, loader.LoadUserByName("bob@example.com"), "Deactivated user should not be loaded")
+	assert.True(t, loader.IsDeactivated("bob@example.com"), "User should be reported as deactivated")
+	assert.NotNil(t, loader.LoadUserByName("alice@example.com"), "Active user should be loaded")
+
+	// deleted user should be removed from groups
+	code, _ = do(http.MethodDelete, "/Users/"+alice.GetString("id"), "scimtoken", "")
+	assert.Equal(t, http.StatusNoContent, code, "User should be deleted")
+	code, _ = do(http.MethodGet, "/Users/"+alice.GetString("id"), "scimtoken", "")
+	assert.Equal(t, http.StatusNotFound, code, "Deleted user should not exist")
+	code, group = do(http.MethodGet, "/Groups/"+group.GetString("id"), "scimtoken", "")
+	assert.Equal(t, http.StatusOK, code, "Group should be retrieved")
+	assert.Equal(t, []string{bob.GetString("id")}, group.MemberIDs(), "Deleted user should be removed from the group")
+	loader = registry.NewSCIMUserLoader(reg, config.SCIM{}, nil)
+	assert.Nil(t, loader.LoadUserByName("alice@example.com"), "Deleted user should not be loaded")
+
+	// every change should be recorded into the audit log on behalf of SCIM
+	assert.True(t, len(reg.auditEntries) > 0, "Changes should be recorded into the audit log")
+	for _, entry := range reg.auditEntries {
+		assert.Equal(t, scimUser, entry.User, "Changes should be recorded on behalf of SCIM")
+	}
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/julienschmidt/httprouter"
+)
+
+// secretNameRegex is the pattern for secret names
+var secretNameRegex = regexp.MustCompile(`^[a-zA-Z0-9]([-a-zA-Z0-9._]*[a-zA-Z0-9])?$`)
+
+// TypeSecretList contains TypeInfo for the SecretList type
+var TypeSecretList = &runtime.TypeInfo{
+	Kind:        "secret-list",
+	Constructor: func() runtime.Object { return &SecretList{} },
+}
+
+// SecretList is a list of secrets (without their values)
+type SecretList struct {
+	runtime.TypeKind `yaml:",inline"`
+	Items            []*engine.Secret
+}
+
+// TypeSecretRequest contains TypeInfo for the SecretRequest type
+var TypeSecretRequest = &runtime.TypeInfo{
+	Kind:        "secret-request",
+	Constructor: func() runtime.Object { return &SecretRequest{} },
+}
+
+// SecretRequest represents request to set the value of a secret. If User is empty, secret is set for the user making
+// the request
+type SecretRequest struct {
+	runtime.TypeKind `yaml:",inline"`
+	User             string
+	Name             string
+	Value            string
+}
+
+func (api *coreAPI) handleSecretList(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	user := api.getUserRequired(request)
+
+	var secretList []*engine.Secret
+	var err error
+	if api.isDomainAdmin(user) {
+		secretList, err = api.registry.GetAllSecrets()
+	} else {
+		// regular users can only see their own secrets
+		secretList, err = api.registry.GetSecrets(user.Name)
+	}
+	if err != nil {
+		panic(fmt.Sprintf("error while getting secrets: %s", err))
+	}
+
+	result := []*engine.Secret{}
+	for _, secret := range secretList {
+		result = append(result, secret.WithoutValue())
+	}
+
+	api.contentType.WriteOne(writer, request, &SecretList{
+		TypeKind: TypeSecretList.GetTypeKind(),
+		Items:    result,
+	})
+}
+
+func (api *coreAPI) handleSecretSet(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	user := api.getUserRequired(request)
+	api.checkSecretStore()
+
+	secretReq, ok := api.contentType.ReadOne(request).(*SecretRequest)
+	if !ok {
+		panic(fmt.Sprintf("Unexpected object received: %v", secretReq))
+	}
+
+	if !secretNameRegex.MatchString(secretReq.Name) {
+		panic(fmt.Sprintf("invalid secret name '%s', it should consist of letters, digits, '-', '.' and '_'", secretReq.Name))
+	}
+
+	owner := user
+	if len(secretReq.User) > 0 && !strings.EqualFold(secretReq.User, user.Name) {
+		owner = api.externalData.UserLoader.LoadUserByName(secretReq.User)
+		if owner == nil {
+			panic(fmt.Sprintf("user or service account '%s' doesn't exist", secretReq.User))
+		}
+	}
+	api.checkSecretAccess(user, owner.Name)
+
+	sealed, err := api.secretEnvelope.Seal(secretReq.Value, engine.SecretName(owner.Name, secretReq.Name))
+	if err != nil {
+		panic(fmt.Sprintf("error while encrypting secret: %s", err))
+	}
+
+	secret := engine.NewSecret(owner.Name, secretReq.Name, sealed, user.Name)
+	api.auditObject(request, secret)
+	err = api.registry.SaveSecret(secret)
+	if err != nil {
+		panic(fmt.Sprintf("error while saving secret: %s", err))
+	}
+
+	api.contentType.WriteOne(writer, request, secret.WithoutValue())
+}
+
+func (api *coreAPI) handleSecretDelete(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	user := api.getUserRequired(request)
+
+	// secret of the user making the request is deleted, unless another user is given in the query
+	owner, name := request.URL.Query().Get("user"), params.ByName("name")
+	if len(owner) <= 0 {
+		owner = user.Name
+	}
+	api.checkSecretAccess(user, owner)
+
+	secret, err := api.registry.GetSecret(owner, name)
+	if err != nil {
+		panic(fmt.Sprintf("error while getting secret: %s", err))
+	}
+	if secret == nil {
+		panic(fmt.Sprintf("secret '%s' of user '%s' doesn't exist", name, owner))
+	}
+	api.auditObject(request, secret)
+
+	err = api.registry.DeleteSecret(owner, name)
+	if err != nil {
+		panic(fmt.Sprintf("error while deleting secret: %s", err))
+	}
+
+	api.contentType.WriteOne(writer, request, secret.WithoutValue())
+}
+
+// checkSecretStore panics if secret store isn't configured, so secrets can't be encrypted
+func (api *coreAPI) checkSecretStore() {
+	if api.secretEnvelope == nil {
+		panic("secret store isn't configured, master key should be set in server config")
+	}
+}
+
+// checkSecretAccess panics if user isn't allowed to manage secrets of a given owner. Users can manage their own
+// secrets, domain admins can manage secrets of anyone
+func (api *coreAPI) checkSecretAccess(user *lang.User, owner string) {
+	if !strings.EqualFold(user.Name, owner) && !api.isDomainAdmin(user) {
+		panic(fmt.Sprintf("user '%s' is not allowed to manage secrets of '%s'", user.Name, owner))
+	}
+}
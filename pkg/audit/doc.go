@@ -0,0 +1,3 @@
+// Package audit implements append-only audit log, which gets persisted into the object registry and can optionally be
+// streamed to external sinks (File, Syslog).
+package audit
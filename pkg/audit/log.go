@@ -0,0 +1,64 @@
+package audit
+
+import (
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/runtime/registry"
+	log "github.com/sirupsen/logrus"
+)
+
+// Sink is an external destination, which audit entries get streamed to in addition to the registry
+type Sink interface {
+	// Write writes audit entry into the sink
+	Write(entry *engine.AuditEntry) error
+
+	// Close closes the sink
+	Close() error
+}
+
+// Log is the audit log. Every entry gets saved into the registry and written into all configured sinks
+type Log struct {
+	registry registry.AuditRegistry
+	sinks    []Sink
+}
+
+// NewLog creates a new audit log, which saves entries into a given registry and writes them into given sinks
+func NewLog(registry registry.AuditRegistry, sinks ...Sink) *Log {
+	return &Log{
+		registry: registry,
+		sinks:    sinks,
+	}
+}
+
+// Record records a given entry into the audit log. Failure to record an entry doesn't prevent action from being
+// performed, so errors only get logged
+func (auditLog *Log) Record(entry *engine.AuditEntry) {
+	if auditLog == nil {
+		return
+	}
+
+	err := auditLog.registry.SaveAuditEntry(entry)
+	if err != nil {
+		log.Errorf("error while saving audit entry %s (action: %s, user: %s): %s", entry.ID, entry.Action, entry.User, err)
+	}
+
+	for _, sink := range auditLog.sinks {
+		err = sink.Write(entry)
+		if err != nil {
+			log.Errorf("error while writing audit entry %s (action: %s, user: %s) into sink: %s", entry.ID, entry.Action, entry.User, err)
+		}
+	}
+}
+
+// Close closes all sinks of the audit log
+func (auditLog *Log) Close() {
+	if auditLog == nil {
+		return
+	}
+
+	for _, sink := range auditLog.sinks {
+		err := sink.Close()
+		if err != nil {
+			log.Errorf("error while closing audit sink: %s", err)
+		}
+	}
+}
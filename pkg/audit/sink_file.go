@@ -0,0 +1,48 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/Aptomi/aptomi/pkg/engine"
+)
+
+// FileSink appends audit entries to a file, one JSON object per line
+type FileSink struct {
+	file *os.File
+	mu   sync.Mutex
+}
+
+// NewFileSink opens a given file for appending audit entries. File gets created if it doesn't exist
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("error while opening audit log file '%s': %s", path, err)
+	}
+
+	return &FileSink{file: file}, nil
+}
+
+// Write appends audit entry to the file
+func (sink *FileSink) Write(entry *engine.AuditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("error while marshaling audit entry: %s", err)
+	}
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+
+	_, err = sink.file.Write(append(data, '\n'))
+	return err
+}
+
+// Close closes the file
+func (sink *FileSink) Close() error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+
+	return sink.file.Close()
+}
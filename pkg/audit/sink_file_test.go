@@ -0,0 +1,43 @@
+package audit
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aptomi-audit")
+	if !assert.NoError(t, err, "Temp dir should be created") {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	path := filepath.Join(dir, "audit.log")
+	for i := 0; i < 2; i++ {
+		sink, errSink := NewFileSink(path)
+		if !assert.NoError(t, errSink, "File sink should be created") {
+			t.FailNow()
+		}
+		assert.NoError(t, sink.Write(engine.NewAuditEntry(engine.AuditActionLogin, "Alice")), "Audit entry should be written")
+		assert.NoError(t, sink.Close(), "File sink should be closed")
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if !assert.NoError(t, err, "Audit log file should be read") {
+		t.FailNow()
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Len(t, lines, 2, "Audit entries should be appended to the file")
+	for _, line := range lines {
+		entry := &engine.AuditEntry{}
+		assert.NoError(t, json.Unmarshal([]byte(line), entry), "Audit entry should be valid JSON")
+		assert.Equal(t, "Alice", entry.User, "Audit entry should contain user")
+	}
+}
@@ -0,0 +1,44 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/syslog"
+
+	"github.com/Aptomi/aptomi/pkg/engine"
+)
+
+// SyslogSink sends audit entries to syslog as JSON objects. Failed actions are sent with warning severity, the rest
+// are sent with info severity
+type SyslogSink struct {
+	writer *syslog.Writer
+}
+
+// NewSyslogSink connects to syslog daemon at a given address. If network is empty, it connects to the local syslog
+// server. If tag is empty, the program name is used
+func NewSyslogSink(network string, address string, tag string) (*SyslogSink, error) {
+	writer, err := syslog.Dial(network, address, syslog.LOG_INFO|syslog.LOG_AUTH, tag)
+	if err != nil {
+		return nil, fmt.Errorf("error while connecting to syslog: %s", err)
+	}
+
+	return &SyslogSink{writer: writer}, nil
+}
+
+// Write sends audit entry to syslog
+func (sink *SyslogSink) Write(entry *engine.AuditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("error while marshaling audit entry: %s", err)
+	}
+
+	if !entry.Success {
+		return sink.writer.Warning(string(data))
+	}
+	return sink.writer.Info(string(data))
+}
+
+// Close closes connection to syslog
+func (sink *SyslogSink) Close() error {
+	return sink.writer.Close()
+}
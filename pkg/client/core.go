@@ -16,6 +16,10 @@ type Core interface {
 	Revision() Revision
 	State() State
 	User() User
+	ServiceAccount() ServiceAccount
+	APIToken() APIToken
+	Secret() Secret
+	Audit() Audit
 	Version() Version
 }
 
@@ -31,9 +35,12 @@ type Claim interface {
 	Status([]*lang.Claim, api.ClaimQueryFlag) (*api.ClaimsStatus, error)
 }
 
-// Revision is the interface for getting Revisions
+// Revision is the interface for getting, approving, rejecting and cancelling Revisions
 type Revision interface {
 	Show(gen runtime.Generation) (*engine.Revision, error)
+	Approve(gen runtime.Generation) (*engine.Revision, error)
+	Reject(gen runtime.Generation) (*engine.Revision, error)
+	Cancel(gen runtime.Generation) (*engine.Revision, error)
 }
 
 // State is the interface for resetting Actual State
@@ -41,9 +48,38 @@ type State interface {
 	Reset(bool) (*api.PolicyUpdateResult, error)
 }
 
-// User is the interface for auth and user management
+// User is the interface for auth and user management. Besides username/password login, it supports logging in via
+// OpenID Connect provider using device flow, where token is polled for until user approves the device
 type User interface {
 	Login(username, password string) (*api.AuthSuccess, error)
+	LoginDevice() (*api.DeviceAuth, error)
+	LoginDeviceToken(deviceCode string) (*api.AuthSuccess, *api.DeviceAuthPending, error)
+}
+
+// ServiceAccount is the interface for managing service accounts
+type ServiceAccount interface {
+	List() (*api.ServiceAccountList, error)
+	Create(name string, labels map[string]string) (*engine.ServiceAccount, error)
+	Delete(name string) (*engine.ServiceAccount, error)
+}
+
+// APIToken is the interface for managing API tokens
+type APIToken interface {
+	List() (*api.APITokenList, error)
+	Create(owner string, description string, scopes []string, ttl string) (*api.APITokenCreated, error)
+	Revoke(id string) (*engine.APIToken, error)
+}
+
+// Secret is the interface for managing secrets. If user is empty, secrets of the current user are managed
+type Secret interface {
+	List() (*api.SecretList, error)
+	Set(user string, name string, value string) (*engine.Secret, error)
+	Delete(user string, name string) (*engine.Secret, error)
+}
+
+// Audit is the interface for retrieving audit log
+type Audit interface {
+	List(filter *engine.AuditFilter) (*api.AuditEntryList, error)
 }
 
 // Version is the interface for getting current server version
@@ -0,0 +1,47 @@
+package rest
+
+import (
+	"github.com/Aptomi/aptomi/pkg/api"
+	"github.com/Aptomi/aptomi/pkg/client/rest/http"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/engine"
+)
+
+type apiTokenClient struct {
+	cfg        *config.Client
+	httpClient http.Client
+}
+
+func (client *apiTokenClient) List() (*api.APITokenList, error) {
+	response, err := client.httpClient.GET("/token", api.TypeAPITokenList)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.(*api.APITokenList), nil
+}
+
+func (client *apiTokenClient) Create(owner string, description string, scopes []string, ttl string) (*api.APITokenCreated, error) {
+	tokenReq := &api.APITokenRequest{
+		TypeKind:    api.TypeAPITokenRequest.GetTypeKind(),
+		Owner:       owner,
+		Description: description,
+		Scopes:      scopes,
+		TTL:         ttl,
+	}
+	response, err := client.httpClient.POST("/token", api.TypeAPITokenCreated, tokenReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.(*api.APITokenCreated), nil
+}
+
+func (client *apiTokenClient) Revoke(id string) (*engine.APIToken, error) {
+	response, err := client.httpClient.DELETE("/token/"+id, engine.TypeAPIToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.(*engine.APIToken), nil
+}
@@ -0,0 +1,48 @@
+package rest
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/api"
+	"github.com/Aptomi/aptomi/pkg/client/rest/http"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/engine"
+)
+
+type auditClient struct {
+	cfg        *config.Client
+	httpClient http.Client
+}
+
+func (client *auditClient) List(filter *engine.AuditFilter) (*api.AuditEntryList, error) {
+	query := url.Values{}
+	if len(filter.User) > 0 {
+		query.Set("user", filter.User)
+	}
+	if len(filter.Action) > 0 {
+		query.Set("action", filter.Action)
+	}
+	if !filter.Since.IsZero() {
+		query.Set("since", filter.Since.Format(time.RFC3339))
+	}
+	if !filter.Until.IsZero() {
+		query.Set("until", filter.Until.Format(time.RFC3339))
+	}
+	if filter.Limit > 0 {
+		query.Set("limit", strconv.Itoa(filter.Limit))
+	}
+
+	path := "/audit"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	response, err := client.httpClient.GET(path, api.TypeAuditEntryList)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.(*api.AuditEntryList), nil
+}
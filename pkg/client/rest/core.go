@@ -36,6 +36,22 @@ func (client *coreClient) User() client.User {
 	return &userClient{cfg: client.cfg, httpClient: client.httpClient}
 }
 
+func (client *coreClient) ServiceAccount() client.ServiceAccount {
+	return &serviceAccountClient{cfg: client.cfg, httpClient: client.httpClient}
+}
+
+func (client *coreClient) APIToken() client.APIToken {
+	return &apiTokenClient{cfg: client.cfg, httpClient: client.httpClient}
+}
+
+func (client *coreClient) Secret() client.Secret {
+	return &secretClient{cfg: client.cfg, httpClient: client.httpClient}
+}
+
+func (client *coreClient) Audit() client.Audit {
+	return &auditClient{cfg: client.cfg, httpClient: client.httpClient}
+}
+
 func (client *coreClient) Version() client.Version {
 	return &versionClient{cfg: client.cfg, httpClient: client.httpClient}
 }
@@ -26,6 +26,7 @@ type httpClient struct {
 	contentType *codec.ContentTypeHandler
 	http        *http.Client
 	cfg         *config.Client
+	err         error
 }
 
 // NewClient returns implementation of
@@ -35,7 +36,16 @@ func NewClient(cfg *config.Client) Client {
 	}
 	contentTypeHandler := codec.NewContentTypeHandler(runtime.NewTypes().Append(api.Types...))
 
-	return &httpClient{contentType: contentTypeHandler, http: client, cfg: cfg}
+	// if TLS can't be configured, then every request will fail with the error
+	tlsConfig, err := newTLSConfig(cfg.HTTP)
+	if err == nil && tlsConfig != nil {
+		client.Transport = &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		}
+	}
+
+	return &httpClient{contentType: contentTypeHandler, http: client, cfg: cfg, err: err}
 }
 
 func (client *httpClient) GET(path string, expected *runtime.TypeInfo) (runtime.Object, error) {
@@ -89,6 +99,10 @@ func (client *httpClient) DELETESlice(path string, expected *runtime.TypeInfo, b
 }
 
 func (client *httpClient) request(method string, path string, expected *runtime.TypeInfo, body io.Reader) (runtime.Object, error) {
+	if client.err != nil {
+		return nil, client.err
+	}
+
 	req, err := http.NewRequest(method, client.cfg.API.URL()+path, body)
 	if err != nil {
 		return nil, err
@@ -97,6 +111,9 @@ func (client *httpClient) request(method string, path string, expected *runtime.
 	if len(client.cfg.Auth.Token) > 0 {
 		req.Header.Set("Authorization", "Bearer "+client.cfg.Auth.Token)
 	}
+	if len(client.cfg.Auth.Impersonate) > 0 {
+		req.Header.Set(api.ImpersonateHeader, client.cfg.Auth.Impersonate)
+	}
 	req.Header.Set("Content-Type", codec.Default)
 	req.Header.Set("User-Agent", "aptomictl")
 
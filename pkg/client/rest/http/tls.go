@@ -0,0 +1,42 @@
+package http
+
+import (
+	"crypto/tls"
+	"fmt"
+
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/util/certs"
+)
+
+// newTLSConfig returns TLS config for connecting to the server. If no TLS options are set, then nil is returned and
+// default settings get used
+func newTLSConfig(cfg config.HTTP) (*tls.Config, error) {
+	if len(cfg.CACert) == 0 && len(cfg.ClientCert) == 0 && len(cfg.ClientKey) == 0 && !cfg.InsecureSkipVerify {
+		return nil, nil
+	}
+
+	result := &tls.Config{
+		InsecureSkipVerify: cfg.InsecureSkipVerify, // nolint: gas
+	}
+
+	if len(cfg.CACert) > 0 {
+		pool, err := certs.LoadCertPool(cfg.CACert)
+		if err != nil {
+			return nil, err
+		}
+		result.RootCAs = pool
+	}
+
+	if len(cfg.ClientCert) > 0 || len(cfg.ClientKey) > 0 {
+		if len(cfg.ClientCert) == 0 || len(cfg.ClientKey) == 0 {
+			return nil, fmt.Errorf("both client certificate and key should be specified")
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCert, cfg.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("error while loading client certificate %s and key %s: %s", cfg.ClientCert, cfg.ClientKey, err)
+		}
+		result.Certificates = []tls.Certificate{cert}
+	}
+
+	return result, nil
+}
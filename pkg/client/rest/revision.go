@@ -28,3 +28,28 @@ func (client *revisionClient) Show(gen runtime.Generation) (*engine.Revision, er
 
 	return response.(*engine.Revision), nil
 }
+
+func (client *revisionClient) Approve(gen runtime.Generation) (*engine.Revision, error) {
+	return client.post(gen, "approve")
+}
+
+func (client *revisionClient) Reject(gen runtime.Generation) (*engine.Revision, error) {
+	return client.post(gen, "reject")
+}
+
+func (client *revisionClient) Cancel(gen runtime.Generation) (*engine.Revision, error) {
+	return client.post(gen, "cancel")
+}
+
+func (client *revisionClient) post(gen runtime.Generation, operation string) (*engine.Revision, error) {
+	response, err := client.httpClient.POST(fmt.Sprintf("/revision/gen/%d/%s", gen, operation), engine.TypeRevision, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if serverError, ok := response.(*api.ServerError); ok {
+		return nil, fmt.Errorf("server error: %s", serverError.Error)
+	}
+
+	return response.(*engine.Revision), nil
+}
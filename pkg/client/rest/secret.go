@@ -0,0 +1,53 @@
+package rest
+
+import (
+	"net/url"
+
+	"github.com/Aptomi/aptomi/pkg/api"
+	"github.com/Aptomi/aptomi/pkg/client/rest/http"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/engine"
+)
+
+type secretClient struct {
+	cfg        *config.Client
+	httpClient http.Client
+}
+
+func (client *secretClient) List() (*api.SecretList, error) {
+	response, err := client.httpClient.GET("/secret", api.TypeSecretList)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.(*api.SecretList), nil
+}
+
+func (client *secretClient) Set(user string, name string, value string) (*engine.Secret, error) {
+	secretReq := &api.SecretRequest{
+		TypeKind: api.TypeSecretRequest.GetTypeKind(),
+		User:     user,
+		Name:     name,
+		Value:    value,
+	}
+	response, err := client.httpClient.POST("/secret", engine.TypeSecret, secretReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.(*engine.Secret), nil
+}
+
+func (client *secretClient) Delete(user string, name string) (*engine.Secret, error) {
+	path := "/secret/" + url.PathEscape(name)
+	if len(user) > 0 {
+		path += "?" + url.Values{"user": []string{user}}.Encode()
+	}
+
+	response, err := client.httpClient.DELETE(path, engine.TypeSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.(*engine.Secret), nil
+}
@@ -0,0 +1,45 @@
+package rest
+
+import (
+	"github.com/Aptomi/aptomi/pkg/api"
+	"github.com/Aptomi/aptomi/pkg/client/rest/http"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/engine"
+)
+
+type serviceAccountClient struct {
+	cfg        *config.Client
+	httpClient http.Client
+}
+
+func (client *serviceAccountClient) List() (*api.ServiceAccountList, error) {
+	response, err := client.httpClient.GET("/serviceaccount", api.TypeServiceAccountList)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.(*api.ServiceAccountList), nil
+}
+
+func (client *serviceAccountClient) Create(name string, labels map[string]string) (*engine.ServiceAccount, error) {
+	sa := &engine.ServiceAccount{
+		TypeKind: engine.TypeServiceAccount.GetTypeKind(),
+		Name:     name,
+		Labels:   labels,
+	}
+	response, err := client.httpClient.POST("/serviceaccount", engine.TypeServiceAccount, sa)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.(*engine.ServiceAccount), nil
+}
+
+func (client *serviceAccountClient) Delete(name string) (*engine.ServiceAccount, error) {
+	response, err := client.httpClient.DELETE("/serviceaccount/"+name, engine.TypeServiceAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.(*engine.ServiceAccount), nil
+}
@@ -1,6 +1,8 @@
 package rest
 
 import (
+	"fmt"
+
 	"github.com/Aptomi/aptomi/pkg/api"
 	"github.com/Aptomi/aptomi/pkg/client/rest/http"
 	"github.com/Aptomi/aptomi/pkg/config"
@@ -24,3 +26,31 @@ func (client *userClient) Login(username, password string) (*api.AuthSuccess, er
 
 	return authSuccess.(*api.AuthSuccess), nil
 }
+
+func (client *userClient) LoginDevice() (*api.DeviceAuth, error) {
+	deviceAuth, err := client.httpClient.POST("/user/login/device", api.TypeDeviceAuth, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return deviceAuth.(*api.DeviceAuth), nil
+}
+
+func (client *userClient) LoginDeviceToken(deviceCode string) (*api.AuthSuccess, *api.DeviceAuthPending, error) {
+	tokenReq := &api.DeviceTokenRequest{
+		TypeKind:   api.TypeDeviceTokenRequest.GetTypeKind(),
+		DeviceCode: deviceCode,
+	}
+	result, err := client.httpClient.POST("/user/login/device/token", nil, tokenReq)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch obj := result.(type) {
+	case *api.AuthSuccess:
+		return obj, nil, nil
+	case *api.DeviceAuthPending:
+		return nil, obj, nil
+	}
+	return nil, nil, fmt.Errorf("received unexpected object kind: %s", result.GetKind())
+}
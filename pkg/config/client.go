@@ -18,6 +18,17 @@ type Client struct {
 // HTTP is the config for low level HTTP client
 type HTTP struct {
 	Timeout time.Duration `yaml:",omitempty" `
+
+	// CACert is the CA bundle in PEM format to verify server certificate against (system CAs are used by default)
+	CACert string `yaml:",omitempty" validate:"omitempty,file"`
+
+	// ClientCert and ClientKey are the client certificate and key in PEM format, which are used to authenticate with
+	// the server (certificate CN should match user name)
+	ClientCert string `yaml:",omitempty" validate:"omitempty,file"`
+	ClientKey  string `yaml:",omitempty" validate:"omitempty,file"`
+
+	// InsecureSkipVerify disables verification of server certificate
+	InsecureSkipVerify bool `yaml:",omitempty"`
 }
 
 // IsDebug returns true if debug mode enabled
@@ -36,4 +47,7 @@ func (c *Client) GetLogLevel() logrus.Level {
 // ClientAuth represents client auth configs
 type ClientAuth struct {
 	Token string `yaml:",omitempty" validate:"-"`
+
+	// Impersonate is the name of the user, on behalf of which read-only requests are made (domain admins only)
+	Impersonate string `yaml:",omitempty" validate:"-"`
 }
@@ -2,8 +2,15 @@ package config
 
 import (
 	"sort"
+	"time"
 )
 
+// DefaultLDAPGroupsAttribute is the LDAP attribute, which lists groups a user (or a group) is a member of
+const DefaultLDAPGroupsAttribute = "memberOf"
+
+// DefaultLDAPGroupsCacheTTL is how long group membership retrieved from LDAP is cached for
+const DefaultLDAPGroupsCacheTTL = 5 * time.Minute
+
 // LDAP contains configuration for LDAP sync service (host, port, DN, filter query and mapping of LDAP properties to Aptomi attributes)
 type LDAP struct {
 	Host   string `validate:"required,hostname|ip"`
@@ -17,6 +24,47 @@ type LDAP struct {
 	FilterByName string `validate:"required"`
 
 	LabelToAttributes map[string]string `validate:"required"`
+
+	// Groups defines how user group membership is retrieved from LDAP
+	Groups LDAPGroups
+}
+
+// LDAPGroups contains configuration for resolving groups users are members of
+type LDAPGroups struct {
+	// Disabled turns off group resolution, so users will have no groups
+	Disabled bool `validate:"-"`
+
+	// Attribute is LDAP attribute, which lists DNs of the groups a user (or a group) is a member of (memberOf by default)
+	Attribute string `validate:"-"`
+
+	// Nested enables resolution of nested groups, i.e. if a user is a member of a group, which is a member of
+	// another group, then the user will be treated as a member of both groups
+	Nested bool `validate:"-"`
+
+	// CacheTTL is how long group membership of each group is cached for, when resolving nested groups
+	CacheTTL time.Duration `validate:"-"`
+
+	// NameBaseDN is DN of the entry, which contains groups (e.g. ou=groups,o=aptomiOrg). Groups, which are its direct
+	// children, are named by the value of their RDN (e.g. developers for cn=developers,ou=groups,o=aptomiOrg), as
+	// their RDNs are unique. All other groups are named by their full DN, so that groups with the same RDN in
+	// different parts of the directory never collide. If it's not set, all groups are named by their full DN
+	NameBaseDN string `validate:"-"`
+}
+
+// GetAttribute returns LDAP attribute, which lists groups a user (or a group) is a member of
+func (cfg *LDAPGroups) GetAttribute() string {
+	if len(cfg.Attribute) > 0 {
+		return cfg.Attribute
+	}
+	return DefaultLDAPGroupsAttribute
+}
+
+// GetCacheTTL returns how long group membership retrieved from LDAP should be cached for
+func (cfg *LDAPGroups) GetCacheTTL() time.Duration {
+	if cfg.CacheTTL > 0 {
+		return cfg.CacheTTL
+	}
+	return DefaultLDAPGroupsCacheTTL
 }
 
 // GetAttributes returns the list of attributes to be retrieved from LDAP
@@ -0,0 +1,51 @@
+package config
+
+// OIDC contains configuration for logging users in via OpenID Connect provider (issuer, client credentials and mapping
+// of ID token claims to Aptomi attributes)
+type OIDC struct {
+	// Issuer is the URL of OpenID Connect provider. Provider configuration gets discovered from it
+	Issuer string `validate:"required,url"`
+
+	ClientID     string `validate:"required"`
+	ClientSecret string `validate:"-"`
+
+	// RedirectURL is the URL of Aptomi callback endpoint registered with the provider, it's required for web UI login
+	// (authorization code flow)
+	RedirectURL string `validate:"omitempty,url"`
+
+	// UIRedirectURL is where browser gets redirected to after successful web UI login, with Aptomi token appended to
+	// it as '#token=...'. If it's not set, token gets returned in the response body
+	UIRedirectURL string `validate:"omitempty,url"`
+
+	// Scopes are requested from the provider in addition to 'openid'
+	Scopes []string `validate:"-"`
+
+	// UsernameClaim is the ID token claim, which is used as a user name ('email' by default, which should be verified)
+	UsernameClaim string `validate:"-"`
+
+	// LabelToClaims defines which ID token claims get mapped into which user labels
+	LabelToClaims map[string]string `validate:"-"`
+}
+
+// GetScopes returns the list of scopes to be requested from the provider
+func (cfg *OIDC) GetScopes() []string {
+	result := []string{"openid"}
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"profile", "email"}
+	}
+	for _, scope := range scopes {
+		if scope != "openid" {
+			result = append(result, scope)
+		}
+	}
+	return result
+}
+
+// GetUsernameClaim returns the ID token claim, which is used as a user name
+func (cfg *OIDC) GetUsernameClaim() string {
+	if len(cfg.UsernameClaim) == 0 {
+		return "email"
+	}
+	return cfg.UsernameClaim
+}
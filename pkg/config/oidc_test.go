@@ -0,0 +1,24 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+func TestConfigOIDC(t *testing.T) {
+	config := &OIDC{}
+	assert.Equal(t, []string{"openid", "profile", "email"}, config.GetScopes(), "Default scopes must be requested")
+	assert.Equal(t, "email", config.GetUsernameClaim(), "Default username claim must be email")
+
+	config = &OIDC{Scopes: []string{"openid", "groups"}, UsernameClaim: "preferred_username"}
+	assert.Equal(t, []string{"openid", "groups"}, config.GetScopes(), "Scope 'openid' must be requested exactly once")
+	assert.Equal(t, "preferred_username", config.GetUsernameClaim(), "Username claim must be taken from config")
+
+	val := validator.New()
+	assert.NoError(t, val.Struct(ServerAuth{}), "OIDC should be optional")
+	assert.NoError(t, val.Struct(ServerAuth{OIDC: &OIDC{Issuer: "https://accounts.example.com", ClientID: "aptomi"}}), "OIDC config should be valid")
+	assert.Error(t, val.Struct(ServerAuth{OIDC: &OIDC{Issuer: "https://accounts.example.com"}}), "OIDC config without client id should be invalid")
+	assert.Error(t, val.Struct(ServerAuth{OIDC: &OIDC{Issuer: "accounts", ClientID: "aptomi"}}), "OIDC config with invalid issuer should be invalid")
+}
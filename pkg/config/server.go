@@ -1,6 +1,7 @@
 package config
 
 import (
+	"math"
 	"time"
 
 	"github.com/Aptomi/aptomi/pkg/runtime/store/etcd"
@@ -11,15 +12,21 @@ import (
 type Server struct {
 	Debug                bool                 `validate:"-"`
 	API                  API                  `validate:"required"`
+	TLS                  ServerTLS            `validate:"-"`
 	UI                   UI                   `validate:"omitempty"` // if UI is not defined, then UI will not be started
 	DB                   DB                   `validate:"required"`
 	Plugins              Plugins              `validate:"required"`
 	Users                UserSources          `validate:"required"`
-	SecretsDir           string               `validate:"omitempty,dir"` // secrets is not a first-class citizen yet, so it's not required
+	SecretsDir           string               `validate:"omitempty,dir"` // deprecated, secrets should be stored in the secret store
+	Secrets              Secrets              `validate:"-"`
 	Enforcer             DesiredStateEnforcer `validate:"required"`
 	Updater              ActualStateUpdater   `validate:"required"`
+	ClaimExpirer         ClaimExpirer         `validate:"required"`
+	Approval             Approval             `validate:"-"`
 	DomainAdminOverrides map[string]bool      `validate:"-"`
-	Auth                 ServerAuth           `validate:"-"`
+	Auth                 ServerAuth           `validate:"required"`
+	Audit                Audit                `validate:"-"`
+	RateLimit            RateLimit            `validate:"-"`
 	Profile              Profile              `validate:"-"`
 }
 
@@ -36,10 +43,83 @@ func (s *Server) GetLogLevel() logrus.Level {
 	return logrus.InfoLevel
 }
 
-// UserSources represents configs for the user loaders that could be file and LDAP loaders
+// ServerTLS represents configs for serving API over TLS. Optionally, clients can authenticate with certificates
+type ServerTLS struct {
+	// CertFile and KeyFile are the server certificate and key in PEM format. If set, API is served over HTTPS. Files
+	// are re-read once they change, so certificate can be rotated without restarting the server
+	CertFile string
+	KeyFile  string
+
+	// ClientCAFile is the CA bundle in PEM format to verify client certificates against. If set, clients can
+	// authenticate with certificates and certificate CN is treated as user name
+	ClientCAFile string
+
+	// RequireClientCert makes client certificate mandatory for all connections
+	RequireClientCert bool
+}
+
+// Enabled returns true if API should be served over TLS
+func (t ServerTLS) Enabled() bool {
+	return len(t.CertFile) > 0 || len(t.KeyFile) > 0
+}
+
+// Secrets represents configs for the secret store. Secrets are stored in the registry encrypted with envelope
+// encryption, using a given master key. If master key is not set, secret store is disabled
+type Secrets struct {
+	// Key is the base64 encoded 32 byte master key (e.g. generated with 'openssl rand -base64 32')
+	Key string
+
+	// KeyFile is the file with base64 encoded master key. It's used if Key is not set
+	KeyFile string `validate:"omitempty,file"`
+}
+
+// Enabled returns true if secret store is enabled
+func (s Secrets) Enabled() bool {
+	return len(s.Key) > 0 || len(s.KeyFile) > 0
+}
+
+// RateLimit represents configs for limiting API requests made by every user, API token (or source IP). Requests, which
+// exceed the limit, get rejected with 429 Too Many Requests
+type RateLimit struct {
+	// Rate is the number of API requests per second allowed for each user, API token (or source IP). Zero means no limit
+	Rate float64
+
+	// Burst is the maximum number of API requests allowed at once (Rate rounded up by default)
+	Burst int
+
+	// WriteRate and WriteBurst additionally limit API requests changing state (e.g. policy updates), as they are
+	// much more expensive to process. Zero means no additional limit
+	WriteRate  float64
+	WriteBurst int
+
+	// MaxBodySize is the maximum size of API request body in bytes. Zero means no limit
+	MaxBodySize int64
+}
+
+// GetBurst returns the maximum number of API requests allowed at once
+func (r RateLimit) GetBurst() int {
+	return burst(r.Rate, r.Burst)
+}
+
+// GetWriteBurst returns the maximum number of API requests changing state allowed at once
+func (r RateLimit) GetWriteBurst() int {
+	return burst(r.WriteRate, r.WriteBurst)
+}
+
+func burst(rate float64, burst int) int {
+	if burst > 0 {
+		return burst
+	}
+	return int(math.Ceil(rate))
+}
+
+// UserSources represents configs for the user loaders that could be file, LDAP and webhook loaders, as well as SCIM
+// endpoint, which allows identity providers to push users into Aptomi
 type UserSources struct {
-	LDAP []LDAP   `validate:"dive"`
-	File []string `validate:"dive,file"`
+	LDAP    []LDAP        `validate:"dive"`
+	File    []string      `validate:"dive,file"`
+	Webhook []UserWebhook `validate:"dive"`
+	SCIM    *SCIM         `validate:"omitempty"`
 }
 
 // DB represents configs for DB
@@ -54,6 +134,65 @@ type DesiredStateEnforcer struct {
 	Noop                 bool          `validate:"-"`
 	NoopSleep            time.Duration `validate:"-"`
 	MaxConcurrentActions int           `validate:"-"`
+	Retry                []ActionRetry `validate:"dive"`
+	ActionTimeout        time.Duration `validate:"-"`
+	CodeTimeouts         []CodeTimeout `validate:"dive"`
+	Scheduling           Scheduling    `validate:"required"`
+}
+
+// Scheduling represents config for scheduling of actions across clusters and namespaces. Zero MaxPerCluster or
+// MaxPerNamespace means no limit. Order can be either deletes-first, creates-first or empty. Priority of actions is
+// taken from a given label of component instances (which can be set via change-labels in services)
+type Scheduling struct {
+	MaxPerCluster   int    `validate:"min=0"`
+	MaxPerNamespace int    `validate:"min=0"`
+	Order           string `validate:"eq=|eq=deletes-first|eq=creates-first"`
+	PriorityLabel   string `validate:"-"`
+}
+
+// IsEnabled returns true if any scheduling options are set. Otherwise actions are applied in the order they become ready
+func (scheduling Scheduling) IsEnabled() bool {
+	return scheduling.MaxPerCluster > 0 || scheduling.MaxPerNamespace > 0 || len(scheduling.Order) > 0 || len(scheduling.PriorityLabel) > 0
+}
+
+// CodeTimeout represents config for the deadline of actions on components with a given code type (e.g. helm). It
+// overrides the default action timeout
+type CodeTimeout struct {
+	CodeType string        `validate:"required"`
+	Timeout  time.Duration `validate:"-"`
+}
+
+// GetTimeout returns the deadline for actions on components with a given code type. Empty code type corresponds to
+// actions on non-code components. Zero means that actions have no deadline
+func (enforcer DesiredStateEnforcer) GetTimeout(codeType string) time.Duration {
+	for _, codeTimeout := range enforcer.CodeTimeouts {
+		if len(codeType) > 0 && codeTimeout.CodeType == codeType {
+			return codeTimeout.Timeout
+		}
+	}
+	return enforcer.ActionTimeout
+}
+
+// ActionRetry represents config for retrying failed actions of a given kind (e.g. action-component-create) in clusters
+// of a given type (e.g. kubernetes). Empty kind or cluster type means any. Delay between attempts starts with the
+// given interval (1 second by default) and doubles after every attempt, until it reaches max interval
+type ActionRetry struct {
+	ActionKind  string        `validate:"-"`
+	ClusterType string        `validate:"-"`
+	MaxAttempts int           `validate:"min=1"`
+	Interval    time.Duration `validate:"-"`
+	MaxInterval time.Duration `validate:"-"`
+}
+
+// GetRetry returns the first retry config matching a given action kind and cluster type or nil if there is none
+func (enforcer DesiredStateEnforcer) GetRetry(actionKind string, clusterType string) *ActionRetry {
+	for i := range enforcer.Retry {
+		retry := &enforcer.Retry[i]
+		if (len(retry.ActionKind) <= 0 || retry.ActionKind == actionKind) && (len(retry.ClusterType) <= 0 || retry.ClusterType == clusterType) {
+			return retry
+		}
+	}
+	return nil
 }
 
 // ActualStateUpdater represents config for actual state updater background process that periodically refreshes actual state
@@ -66,9 +205,87 @@ type ActualStateUpdater struct {
 	MaxConcurrentActions int           `validate:"-"`
 }
 
-// ServerAuth represents server auth config
+// ClaimExpirer represents config for claim expirer background process that periodically deletes expired claims
+// (e.g. claims with TTL) from the policy
+type ClaimExpirer struct {
+	Disabled bool          `validate:"-"`
+	Interval time.Duration `validate:"-"`
+}
+
+// Approval represents config for manual approval of revisions. If an action plan affects component instances in
+// namespaces or clusters matching any of the rules, it will not be applied until another user approves it
+type Approval struct {
+	Rules []ApprovalRule `validate:"-"`
+}
+
+// ApprovalRule defines a set of namespaces and clusters, changes to which require manual approval. Empty list
+// means any namespace or cluster. Clusters can be specified as 'name' or 'namespace/name'
+type ApprovalRule struct {
+	Namespaces []string
+	Clusters   []string
+}
+
+// Matches returns true if a component instance in a given namespace and cluster requires manual approval
+func (rule ApprovalRule) Matches(namespace string, clusterNamespace string, clusterName string) bool {
+	return matchesAny(rule.Namespaces, namespace) && matchesAny(rule.Clusters, clusterName, clusterNamespace+"/"+clusterName)
+}
+
+// IsRequired returns true if a component instance in a given namespace and cluster matches any of the approval rules
+func (approval Approval) IsRequired(namespace string, clusterNamespace string, clusterName string) bool {
+	for _, rule := range approval.Rules {
+		if rule.Matches(namespace, clusterNamespace, clusterName) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesAny returns true if the list is empty, contains a wildcard or any of the given values
+func matchesAny(list []string, values ...string) bool {
+	if len(list) <= 0 {
+		return true
+	}
+	for _, item := range list {
+		if item == "*" {
+			return true
+		}
+		for _, value := range values {
+			if item == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ServerAuth represents server auth config. Secret is used to sign tokens issued by Aptomi, which are valid for
+// TokenTTL (30 days by default). If OIDC is set, users can also log in via OpenID Connect provider
 type ServerAuth struct {
-	Secret string `validate:"-"`
+	Secret   string        `validate:"-"`
+	TokenTTL time.Duration `validate:"min=0"`
+	OIDC     *OIDC         `validate:"omitempty"`
+}
+
+// GetTokenTTL returns for how long tokens issued by Aptomi are valid
+func (auth ServerAuth) GetTokenTTL() time.Duration {
+	if auth.TokenTTL <= 0 {
+		return 30 * 24 * time.Hour
+	}
+	return auth.TokenTTL
+}
+
+// Audit represents config for the audit log. Audit entries are always saved into the registry. If File is set, they
+// are also appended to a given file. If Syslog is set, they are also sent to syslog
+type Audit struct {
+	File   string
+	Syslog *AuditSyslog
+}
+
+// AuditSyslog represents config for sending audit entries to syslog. If Network is empty, local syslog server is used
+type AuditSyslog struct {
+	Network string
+	Address string
+	Tag     string
 }
 
 // Profile represents profiler config
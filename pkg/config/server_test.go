@@ -2,11 +2,87 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/go-playground/validator.v9"
 )
 
 func TestConfigServer(t *testing.T) {
 	config := &Server{}
 	assert.Equal(t, false, config.IsDebug(), "IsDebug() must be false for default server config")
 }
+
+func TestConfigServerApproval(t *testing.T) {
+	approval := Approval{
+		Rules: []ApprovalRule{
+			{Namespaces: []string{"prod"}},
+			{Namespaces: []string{"main", "social"}, Clusters: []string{"system/cluster-us-east"}},
+			{Clusters: []string{"cluster-eu"}},
+		},
+	}
+	assert.True(t, approval.IsRequired("prod", "system", "any-cluster"), "Changes in prod namespace should require approval")
+	assert.True(t, approval.IsRequired("social", "system", "cluster-us-east"), "Changes in social namespace in cluster-us-east should require approval")
+	assert.False(t, approval.IsRequired("social", "system", "cluster-us-west"), "Changes in social namespace in cluster-us-west should not require approval")
+	assert.True(t, approval.IsRequired("dev", "system", "cluster-eu"), "Changes in cluster-eu should require approval")
+	assert.False(t, approval.IsRequired("dev", "system", "cluster-us-east"), "Changes in dev namespace should not require approval")
+
+	assert.False(t, Approval{}.IsRequired("prod", "system", "cluster"), "Approval should not be required if there are no rules")
+	assert.True(t, Approval{Rules: []ApprovalRule{{Namespaces: []string{"*"}}}}.IsRequired("prod", "system", "cluster"), "Wildcard should match any namespace")
+}
+
+func TestConfigServerEnforcerRetry(t *testing.T) {
+	enforcer := DesiredStateEnforcer{
+		Retry: []ActionRetry{
+			{ActionKind: "action-component-create", ClusterType: "kubernetes", MaxAttempts: 5},
+			{ActionKind: "action-component-create", MaxAttempts: 3},
+			{ClusterType: "kubernetes", MaxAttempts: 2},
+		},
+	}
+	assert.Equal(t, 5, enforcer.GetRetry("action-component-create", "kubernetes").MaxAttempts, "Retry config should match both action kind and cluster type")
+	assert.Equal(t, 3, enforcer.GetRetry("action-component-create", "other").MaxAttempts, "Retry config should match action kind for any cluster type")
+	assert.Equal(t, 2, enforcer.GetRetry("action-component-update", "kubernetes").MaxAttempts, "Retry config should match cluster type for any action kind")
+	assert.Nil(t, enforcer.GetRetry("action-component-update", "other"), "Retry config should not match")
+}
+
+func TestConfigServerEnforcerTimeout(t *testing.T) {
+	enforcer := DesiredStateEnforcer{
+		ActionTimeout: 5 * time.Minute,
+		CodeTimeouts: []CodeTimeout{
+			{CodeType: "helm", Timeout: 10 * time.Minute},
+			{CodeType: "raw", Timeout: time.Minute},
+		},
+	}
+	assert.Equal(t, 10*time.Minute, enforcer.GetTimeout("helm"), "Timeout should match code type")
+	assert.Equal(t, time.Minute, enforcer.GetTimeout("raw"), "Timeout should match code type")
+	assert.Equal(t, 5*time.Minute, enforcer.GetTimeout("other"), "Default timeout should be used for unknown code type")
+	assert.Equal(t, 5*time.Minute, enforcer.GetTimeout(""), "Default timeout should be used for non-code components")
+	assert.Equal(t, time.Duration(0), DesiredStateEnforcer{}.GetTimeout("helm"), "There should be no timeout by default")
+}
+
+func TestConfigServerEnforcerScheduling(t *testing.T) {
+	assert.False(t, Scheduling{}.IsEnabled(), "Scheduling should be disabled by default")
+	assert.True(t, Scheduling{MaxPerCluster: 5}.IsEnabled(), "Scheduling should be enabled when per-cluster limit is set")
+	assert.True(t, Scheduling{Order: "deletes-first"}.IsEnabled(), "Scheduling should be enabled when order is set")
+	assert.True(t, Scheduling{PriorityLabel: "priority"}.IsEnabled(), "Scheduling should be enabled when priority label is set")
+
+	val := validator.New()
+	assert.NoError(t, val.Struct(Scheduling{}), "Empty order should be valid")
+	assert.NoError(t, val.Struct(Scheduling{Order: "deletes-first"}), "Deletes-first order should be valid")
+	assert.NoError(t, val.Struct(Scheduling{Order: "creates-first"}), "Creates-first order should be valid")
+	assert.Error(t, val.Struct(Scheduling{Order: "random"}), "Unknown order should be invalid")
+	assert.Error(t, val.Struct(Scheduling{MaxPerCluster: -1}), "Negative per-cluster limit should be invalid")
+}
+
+func TestConfigServerTLS(t *testing.T) {
+	assert.False(t, ServerTLS{}.Enabled(), "TLS should be disabled by default")
+	assert.True(t, ServerTLS{CertFile: "cert.pem", KeyFile: "key.pem"}.Enabled(), "TLS should be enabled when certificate is set")
+	assert.False(t, ServerTLS{ClientCAFile: "ca.pem"}.Enabled(), "TLS should not be enabled by client CA alone")
+}
+
+func TestConfigServerRateLimit(t *testing.T) {
+	assert.Equal(t, 0, RateLimit{}.GetBurst(), "There should be no burst by default")
+	assert.Equal(t, 3, RateLimit{Rate: 2.5}.GetBurst(), "Burst should be rate rounded up by default")
+	assert.Equal(t, 10, RateLimit{Rate: 2.5, Burst: 10}.GetBurst(), "Burst should be taken from config")
+	assert.Equal(t, 1, RateLimit{Rate: 5, WriteRate: 0.2}.GetWriteBurst(), "Write burst should be write rate rounded up by default")
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"time"
+)
+
+// DefaultUserWebhookTimeout is the time limit for requests to user webhook
+const DefaultUserWebhookTimeout = 10 * time.Second
+
+// DefaultUserWebhookCacheTTL is how long users retrieved from webhook are cached for
+const DefaultUserWebhookCacheTTL = time.Minute
+
+// UserWebhook contains configuration for loading users from an HTTP endpoint (e.g. in-house user directory)
+type UserWebhook struct {
+	// URL is the endpoint, which returns the list of all users in JSON
+	URL string `validate:"required,url"`
+
+	// AuthURL is the endpoint, which authenticates users by name and password. If it's not set, users loaded from
+	// the webhook can't log in with password
+	AuthURL string `validate:"omitempty,url"`
+
+	// Token is sent to the webhook as bearer token, if set
+	Token string `validate:"-"`
+
+	// Timeout is the time limit for requests to the webhook
+	Timeout time.Duration `validate:"-"`
+
+	// CacheTTL is how long users retrieved from the webhook are cached for
+	CacheTTL time.Duration `validate:"-"`
+
+	// AllowDomainAdmin allows the webhook to make users domain admins via domainAdmin field. By default, the field is
+	// ignored, and only domain admin overrides from the server config are applied
+	AllowDomainAdmin bool `validate:"-"`
+}
+
+// GetTimeout returns the time limit for requests to the webhook
+func (cfg *UserWebhook) GetTimeout() time.Duration {
+	if cfg.Timeout > 0 {
+		return cfg.Timeout
+	}
+	return DefaultUserWebhookTimeout
+}
+
+// GetCacheTTL returns how long users retrieved from the webhook should be cached for
+func (cfg *UserWebhook) GetCacheTTL() time.Duration {
+	if cfg.CacheTTL > 0 {
+		return cfg.CacheTTL
+	}
+	return DefaultUserWebhookCacheTTL
+}
+
+// SCIM contains configuration for SCIM 2.0 endpoint, which allows identity providers to push users and groups into
+// Aptomi
+type SCIM struct {
+	// Token is the bearer token identity provider has to use when calling SCIM endpoint
+	Token string `validate:"required"`
+
+	// LabelToAttributes is the mapping of user labels to SCIM attributes (e.g. email: emails or
+	// team: department). Name of the user is always taken from userName attribute
+	LabelToAttributes map[string]string `validate:"-"`
+}
@@ -0,0 +1,25 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+func TestConfigUserSources(t *testing.T) {
+	webhook := &UserWebhook{URL: "https://directory.example.com/users"}
+	assert.Equal(t, DefaultUserWebhookTimeout, webhook.GetTimeout(), "Default webhook timeout must be used")
+	assert.Equal(t, DefaultUserWebhookCacheTTL, webhook.GetCacheTTL(), "Default webhook cache TTL must be used")
+
+	webhook = &UserWebhook{URL: "https://directory.example.com/users", Timeout: time.Second, CacheTTL: time.Hour}
+	assert.Equal(t, time.Second, webhook.GetTimeout(), "Webhook timeout must be taken from config")
+	assert.Equal(t, time.Hour, webhook.GetCacheTTL(), "Webhook cache TTL must be taken from config")
+
+	val := validator.New()
+	assert.NoError(t, val.Struct(UserSources{}), "Webhook and SCIM should be optional")
+	assert.NoError(t, val.Struct(UserSources{Webhook: []UserWebhook{*webhook}, SCIM: &SCIM{Token: "token"}}), "Webhook and SCIM config should be valid")
+	assert.Error(t, val.Struct(UserSources{Webhook: []UserWebhook{{URL: "directory"}}}), "Webhook config with invalid URL should be invalid")
+	assert.Error(t, val.Struct(UserSources{SCIM: &SCIM{}}), "SCIM config without token should be invalid")
+}
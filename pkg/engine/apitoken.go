@@ -0,0 +1,230 @@
+package engine
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+)
+
+const (
+	// APITokenPrefix is the prefix of all API tokens, which allows to tell them apart from session tokens
+	APITokenPrefix = "apt_"
+
+	// APITokenScopeReadOnly is the scope, which only allows to read data via API
+	APITokenScopeReadOnly = "read-only"
+
+	// APITokenScopeClaimsOnly is the scope, which allows to read data and to change claims, but not other policy objects
+	APITokenScopeClaimsOnly = "claims-only"
+
+	// APITokenScopeNamespacePrefix is the prefix of the scope, which allows to read data and to change policy objects
+	// in a single namespace only (e.g. "namespace:dev")
+	APITokenScopeNamespacePrefix = "namespace:"
+)
+
+// TypeAPIToken is TypeInfo for APIToken
+var TypeAPIToken = &runtime.TypeInfo{
+	Kind:        "api-token",
+	Storable:    true,
+	Versioned:   false,
+	Constructor: func() runtime.Object { return &APIToken{} },
+}
+
+// APIToken is a long-lived token, which allows to call API on behalf of its owner (user or service account). Only the
+// hash of the token secret is stored. If token has scopes, it is restricted by all of them. Token without scopes has
+// the same permissions as its owner
+type APIToken struct {
+	runtime.TypeKind `yaml:",inline"`
+
+	// ID is a unique ID of the token, which is a part of the token itself
+	ID string
+
+	// Owner is the name of the user or service account the token belongs to
+	Owner string
+
+	// Description is a human-readable description of the token
+	Description string
+
+	// Hash is SHA-256 hash of the token secret
+	Hash string
+
+	// Scopes restrict what can be done with the token
+	Scopes []string
+
+	// ExpiresAt is when the token expires. Zero value means token never expires
+	ExpiresAt time.Time
+
+	// CreatedBy is the name of the user who created the token
+	CreatedBy string
+
+	// CreatedAt is when the token was created
+	CreatedAt time.Time
+
+	// Revoked is true if token has been revoked
+	Revoked bool
+
+	// RevokedBy is the name of the user who revoked the token
+	RevokedBy string
+
+	// RevokedAt is when the token was revoked
+	RevokedAt time.Time
+}
+
+// NewAPIToken creates a new API token for a given owner and returns it along with the token string, which should be
+// handed over to the caller. Token string can't be recovered later, as only its hash is kept
+func NewAPIToken(owner string, description string, scopes []string, ttl time.Duration, createdBy string) (*APIToken, string, error) {
+	err := ValidateAPITokenScopes(scopes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	id, err := randomHex(8)
+	if err != nil {
+		return nil, "", err
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, "", err
+	}
+
+	token := &APIToken{
+		TypeKind:    TypeAPIToken.GetTypeKind(),
+		ID:          id,
+		Owner:       owner,
+		Description: description,
+		Hash:        hashAPITokenSecret(secret),
+		Scopes:      scopes,
+		CreatedBy:   createdBy,
+		CreatedAt:   time.Now(),
+	}
+	if ttl > 0 {
+		token.ExpiresAt = token.CreatedAt.Add(ttl)
+	}
+
+	return token, APITokenPrefix + id + "_" + secret, nil
+}
+
+// ParseAPIToken splits token string into token ID and secret. It returns false if the string is not an API token
+func ParseAPIToken(tokenString string) (id string, secret string, ok bool) {
+	if !strings.HasPrefix(tokenString, APITokenPrefix) {
+		return "", "", false
+	}
+	parts := strings.SplitN(strings.TrimPrefix(tokenString, APITokenPrefix), "_", 2)
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// ValidateAPITokenScopes returns an error if any of the scopes is unknown
+func ValidateAPITokenScopes(scopes []string) error {
+	for _, scope := range scopes {
+		if scope == APITokenScopeReadOnly || scope == APITokenScopeClaimsOnly {
+			continue
+		}
+		if strings.HasPrefix(scope, APITokenScopeNamespacePrefix) && len(strings.TrimPrefix(scope, APITokenScopeNamespacePrefix)) > 0 {
+			continue
+		}
+		return fmt.Errorf("unknown API token scope '%s', expected one of: %s, %s, %s<namespace>", scope, APITokenScopeReadOnly, APITokenScopeClaimsOnly, APITokenScopeNamespacePrefix)
+	}
+	return nil
+}
+
+// GetName returns APIToken name
+func (token *APIToken) GetName() string {
+	return token.ID
+}
+
+// GetNamespace returns APIToken namespace
+func (token *APIToken) GetNamespace() string {
+	return runtime.SystemNS
+}
+
+// Verify checks that the secret matches the token and that the token is neither revoked nor expired
+func (token *APIToken) Verify(secret string) error {
+	if subtle.ConstantTimeCompare([]byte(token.Hash), []byte(hashAPITokenSecret(secret))) != 1 {
+		return fmt.Errorf("invalid API token")
+	}
+	if token.Revoked {
+		return fmt.Errorf("API token %s has been revoked", token.ID)
+	}
+	if token.IsExpired() {
+		return fmt.Errorf("API token %s has expired", token.ID)
+	}
+	return nil
+}
+
+// IsExpired returns true if token has expired
+func (token *APIToken) IsExpired() bool {
+	return !token.ExpiresAt.IsZero() && time.Now().After(token.ExpiresAt)
+}
+
+// AllowsObjectChange returns true if token scopes allow to change a given policy object
+func (token *APIToken) AllowsObjectChange(obj lang.Base) bool {
+	for _, scope := range token.Scopes {
+		switch {
+		case scope == APITokenScopeReadOnly:
+			return false
+		case scope == APITokenScopeClaimsOnly:
+			if obj.GetKind() != lang.TypeClaim.Kind {
+				return false
+			}
+		case strings.HasPrefix(scope, APITokenScopeNamespacePrefix):
+			if obj.GetNamespace() != strings.TrimPrefix(scope, APITokenScopeNamespacePrefix) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// GetDefaultColumns returns default set of columns to be displayed
+func (token *APIToken) GetDefaultColumns() []string {
+	return []string{"ID", "Owner", "Description", "Scopes", "Expires At", "Status"}
+}
+
+// AsColumns returns APIToken representation as columns
+func (token *APIToken) AsColumns() map[string]string {
+	expiresAt := "never"
+	if !token.ExpiresAt.IsZero() {
+		expiresAt = token.ExpiresAt.Format(time.RFC3339)
+	}
+
+	status := "active"
+	if token.Revoked {
+		status = "revoked"
+	} else if token.IsExpired() {
+		status = "expired"
+	}
+
+	return map[string]string{
+		"ID":          token.ID,
+		"Owner":       token.Owner,
+		"Description": token.Description,
+		"Scopes":      strings.Join(token.Scopes, ","),
+		"Expires At":  expiresAt,
+		"Status":      status,
+		"Created By":  token.CreatedBy,
+		"Created At":  token.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func hashAPITokenSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
+
+func randomHex(size int) (string, error) {
+	data := make([]byte, size)
+	_, err := rand.Read(data)
+	if err != nil {
+		return "", fmt.Errorf("error while generating random data: %s", err)
+	}
+	return hex.EncodeToString(data), nil
+}
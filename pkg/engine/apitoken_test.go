@@ -0,0 +1,71 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPITokenVerify(t *testing.T) {
+	token, tokenString, err := NewAPIToken("ci", "pipeline", nil, time.Hour, "admin")
+	if !assert.NoError(t, err, "API token should be created") {
+		t.FailNow()
+	}
+	assert.NotContains(t, token.Hash, tokenString, "API token secret should not be stored as is")
+
+	id, secret, ok := ParseAPIToken(tokenString)
+	if !assert.True(t, ok, "API token should be parsed") {
+		t.FailNow()
+	}
+	assert.Equal(t, token.ID, id, "API token ID should be parsed")
+	assert.NoError(t, token.Verify(secret), "API token should be valid")
+	assert.Error(t, token.Verify(secret+"x"), "API token with wrong secret should be invalid")
+
+	// expired token
+	token.ExpiresAt = time.Now().Add(-time.Minute)
+	assert.Error(t, token.Verify(secret), "Expired API token should be invalid")
+
+	// revoked token
+	token.ExpiresAt = time.Time{}
+	token.Revoked = true
+	assert.Error(t, token.Verify(secret), "Revoked API token should be invalid")
+
+	// not an API token
+	for _, str := range []string{"eyJhbGciOiJIUzI1NiJ9.e30.abc", "apt_", "apt_id", "apt__secret"} {
+		_, _, ok = ParseAPIToken(str)
+		assert.False(t, ok, "String should not be parsed as API token: %s", str)
+	}
+}
+
+func TestAPITokenScopes(t *testing.T) {
+	_, _, err := NewAPIToken("ci", "", []string{"admin"}, 0, "admin")
+	assert.Error(t, err, "API token with unknown scope should not be created")
+	_, _, err = NewAPIToken("ci", "", []string{APITokenScopeNamespacePrefix}, 0, "admin")
+	assert.Error(t, err, "API token with empty namespace scope should not be created")
+
+	claimDev := &lang.Claim{TypeKind: lang.TypeClaim.GetTypeKind(), Metadata: lang.Metadata{Namespace: "dev", Name: "claim"}}
+	claimProd := &lang.Claim{TypeKind: lang.TypeClaim.GetTypeKind(), Metadata: lang.Metadata{Namespace: "prod", Name: "claim"}}
+	serviceDev := &lang.Service{TypeKind: lang.TypeService.GetTypeKind(), Metadata: lang.Metadata{Namespace: "dev", Name: "service"}}
+
+	cases := []struct {
+		scopes  []string
+		allowed []bool
+	}{
+		{nil, []bool{true, true, true}},
+		{[]string{APITokenScopeReadOnly}, []bool{false, false, false}},
+		{[]string{APITokenScopeClaimsOnly}, []bool{true, true, false}},
+		{[]string{APITokenScopeNamespacePrefix + "dev"}, []bool{true, false, true}},
+		{[]string{APITokenScopeClaimsOnly, APITokenScopeNamespacePrefix + "dev"}, []bool{true, false, false}},
+	}
+	for _, c := range cases {
+		token, _, errNew := NewAPIToken("ci", "", c.scopes, 0, "admin")
+		if !assert.NoError(t, errNew, "API token should be created with scopes %v", c.scopes) {
+			continue
+		}
+		for idx, obj := range []lang.Base{claimDev, claimProd, serviceDev} {
+			assert.Equal(t, c.allowed[idx], token.AllowsObjectChange(obj), "API token with scopes %v: change of %s '%s' in '%s'", c.scopes, obj.GetKind(), obj.GetName(), obj.GetNamespace())
+		}
+	}
+}
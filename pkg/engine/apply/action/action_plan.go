@@ -1,6 +1,7 @@
 package action
 
 import (
+	"context"
 	"fmt"
 	"runtime/debug"
 	"sync"
@@ -11,6 +12,9 @@ type Plan struct {
 	// NodeMap is a map from key to a graph of actions, which must to be executed in order to get from actual state to
 	// desired state. Key in the map corresponds to the key of the GraphNode
 	NodeMap map[string]*GraphNode
+
+	// Rollouts is a list of progressive rollouts, which are a part of this plan
+	Rollouts []*Rollout
 }
 
 // NewPlan creates a new Plan
@@ -30,41 +34,68 @@ func (plan *Plan) GetActionGraphNode(key string) *GraphNode {
 	return result
 }
 
-// Apply applies the action plan. It may call fn in multiple go routines, executing the plan in parallel
-func (plan *Plan) Apply(fn ApplyFunction, resultUpdater ApplyResultUpdater) *ApplyResult {
+// Apply applies the action plan. It may call fn in multiple go routines, executing the plan in parallel. Failed
+// actions get retried according to the retry policy in given options, and every action attempt gets a deadline
+// according to the timeout function. Actions get held (not applied) according to the hold function or if they are
+// blocked, and all actions depending on them will be marked as skipped. Nodes which are ready to be applied get picked
+// according to the scheduling policy. Nil options mean that defaults are used (see ApplyOptions). If ctx gets
+// cancelled, no new actions will be started and all remaining actions will be marked as skipped
+func (plan *Plan) Apply(ctx context.Context, fn ApplyFunction, opts *ApplyOptions, resultUpdater ApplyResultUpdater) *ApplyResult {
 	// make sure we are converting panics into errors
-	fnModified := func(act Interface) (errResult error) {
+	fnModified := func(ctx context.Context, act Interface) (errResult error) {
 		defer func() {
 			if err := recover(); err != nil {
 				errResult = fmt.Errorf("panic: %s\n%s", err, string(debug.Stack()))
 			}
 		}()
-		return fn(act)
+		return fn(ctx, act)
 	}
 
 	// update total number of actions and start the revision
 	resultUpdater.SetTotal(plan.NumberOfActions())
 
-	// apply the plan and calculate result (success/failed/skipped actions)
-	plan.applyInternal(fnModified, resultUpdater)
+	// initialize progress of all rollouts
+	for _, rollout := range plan.Rollouts {
+		total := 0
+		for _, batch := range rollout.Batches {
+			total += len(batch)
+		}
+		strategy, batches := rollout.Strategy, len(rollout.Batches)
+		resultUpdater.UpdateRollout(rollout.Name, func(result *RolloutResult) {
+			result.Strategy = strategy
+			result.Batches = batches
+			result.Total = total
+		})
+	}
+
+	// apply the plan and calculate result (success/failed/skipped actions), blocked actions always get held
+	opts = getApplyOptions(opts)
+	opts.block = true
+	plan.applyInternal(ctx, fnModified, opts, resultUpdater)
+
+	// record that apply has been cancelled
+	if ctx.Err() != nil {
+		resultUpdater.SetCancelled()
+	}
 
 	// tell results updater that we are done and return the results
 	return resultUpdater.Done()
 }
 
 // Apply applies the action plan. It may call fn in multiple go routines, executing the plan in parallel
-func (plan *Plan) applyInternal(fn ApplyFunction, resultUpdater ApplyResultUpdater) {
+func (plan *Plan) applyInternal(ctx context.Context, fn ApplyFunction, opts *ApplyOptions, resultUpdater ApplyResultUpdater) {
 	deg := make(map[string]int)
 	wasError := make(map[string]error)
-	queue := make(chan string, len(plan.NodeMap))
+	sched := newScheduler(opts.Scheduling)
+	slots := newActionSlots(opts.MaxConcurrentActions)
 	mutex := &sync.RWMutex{}
 
-	// Initialize all degrees, put 0-degree leaf nodes into the queue
+	// Initialize all degrees, put 0-degree leaf nodes into the scheduler
 	var wg sync.WaitGroup
 	for key := range plan.NodeMap {
 		deg[key] = len(plan.NodeMap[key].Before)
 		if deg[key] <= 0 {
-			queue <- key
+			sched.add(key)
 		}
 		wg.Add(1)
 	}
@@ -73,12 +104,17 @@ func (plan *Plan) applyInternal(fn ApplyFunction, resultUpdater ApplyResultUpdat
 	var done sync.WaitGroup
 	done.Add(1)
 	go func() {
-		// This will keep running until the queue if not closed
-		for key := range queue {
-			// Take element off the queue, apply the block of actions and put into queue 0-degree nodes which are waiting on us
+		// This will keep running until the scheduler is not closed
+		for {
+			key, ok := sched.next()
+			if !ok {
+				break
+			}
+
+			// Take the next node from the scheduler, apply the block of actions and put into scheduler 0-degree nodes which are waiting on us
 			go func(key string) {
 				defer wg.Done()
-				plan.applyActions(key, fn, queue, deg, wasError, mutex, resultUpdater)
+				plan.applyActions(ctx, key, fn, opts, slots, sched, deg, wasError, mutex, resultUpdater)
 			}(key)
 		}
 		done.Done()
@@ -87,15 +123,15 @@ func (plan *Plan) applyInternal(fn ApplyFunction, resultUpdater ApplyResultUpdat
 	// Wait for all actions to finish
 	wg.Wait()
 
-	// Close the channel to ensure that the go routine launched above will exit
-	close(queue)
+	// Close the scheduler to ensure that the go routine launched above will exit
+	sched.close()
 
 	// Wait for the go routine to finish
 	done.Wait()
 }
 
 // This function applies a block of actions and updates nodes which are waiting on this node
-func (plan *Plan) applyActions(key string, fn ApplyFunction, queue chan string, deg map[string]int, wasError map[string]error, mutex *sync.RWMutex, resultUpdater ApplyResultUpdater) {
+func (plan *Plan) applyActions(ctx context.Context, key string, fn ApplyFunction, opts *ApplyOptions, slots actionSlots, sched *scheduler, deg map[string]int, wasError map[string]error, mutex *sync.RWMutex, resultUpdater ApplyResultUpdater) {
 	// locate the node
 	node := plan.NodeMap[key]
 
@@ -104,14 +140,37 @@ func (plan *Plan) applyActions(key string, fn ApplyFunction, queue chan string,
 	mutex.RLock()
 	foundErr := wasError[key]
 	mutex.RUnlock()
+	skipped := foundErr != nil
+	holdReason := ""
+	blocked := false
 	for _, action := range node.Actions {
-		// if an error happened before, all subsequent actions are getting marked as skipped
-		if foundErr != nil {
+		// once an action is held, all subsequent actions in the node are getting held for the same reason
+		if foundErr == nil && ctx.Err() == nil && len(holdReason) <= 0 {
+			holdReason, blocked = getHoldReason(key, action, opts)
+		}
+
+		// if an error happened before or apply has been cancelled, all subsequent actions are getting marked as skipped
+		if foundErr != nil || ctx.Err() != nil {
 			resultUpdater.AddSkipped()
+			if foundErr == nil {
+				skipped = true
+			}
+		} else if len(holdReason) > 0 && blocked {
+			resultUpdater.AddBlocked(action.GetName(), holdReason)
+		} else if len(holdReason) > 0 {
+			resultUpdater.AddHeld(action.GetName(), holdReason)
 		} else {
-			// Otherwise, let's run the action and see if it failed or not
-			err := fn(action)
-			if err != nil {
+			// Otherwise, let's run the action (retrying it, if needed) and see if it failed or not
+			err := applyWithRetry(ctx, key, action, fn, opts.RetryPolicy, opts.Timeout, slots, resultUpdater)
+			if err == errNotStarted {
+				// apply has been cancelled while action was waiting for its turn
+				resultUpdater.AddSkipped()
+				skipped = true
+				foundErr = err
+			} else if IsTimeout(err) {
+				resultUpdater.AddTimedOut()
+				foundErr = err
+			} else if err != nil {
 				resultUpdater.AddFailed()
 				foundErr = err
 			} else {
@@ -120,6 +179,12 @@ func (plan *Plan) applyActions(key string, fn ApplyFunction, queue chan string,
 		}
 	}
 
+	// mark our node as held, so that nodes waiting on us don't get applied
+	held := foundErr == nil && len(holdReason) > 0
+	if held {
+		foundErr = &HoldError{Reason: holdReason}
+	}
+
 	// mark our node as failed, if we encountered an error
 	if foundErr != nil {
 		mutex.Lock()
@@ -127,25 +192,29 @@ func (plan *Plan) applyActions(key string, fn ApplyFunction, queue chan string,
 		mutex.Unlock()
 	}
 
+	// record rollout progress, if our node is a part of the rollout
+	plan.updateRolloutProgress(key, resultUpdater, skipped || held, foundErr != nil)
+
 	// decrement degrees of nodes which are waiting on us
 	for _, prevNode := range plan.NodeMap[node.Key].BeforeRev {
 		mutex.Lock()
+		if foundErr != nil {
+			// Mark prev nodes failed too
+			wasError[prevNode.Key] = foundErr
+		}
 		deg[prevNode.Key]--
 		if deg[prevNode.Key] < 0 {
 			panic("negative node degree while applying actions in parallel")
 		}
 		if deg[prevNode.Key] == 0 {
-			queue <- prevNode.Key
+			sched.add(prevNode.Key)
 		}
 		mutex.Unlock()
-		if foundErr != nil {
-			// Mark prev nodes failed too
-			mutex.Lock()
-			wasError[prevNode.Key] = foundErr
-			mutex.Unlock()
-		}
 	}
 
+	// let scheduler know that our node is processed, so it can apply other nodes in the same cluster and namespace.
+	// it's done after nodes waiting on us have been added, so they can compete for the freed slot
+	sched.done(key)
 }
 
 // NumberOfActions returns the total number of actions that is expected to be executed in the whole action graph
@@ -153,7 +222,7 @@ func (plan *Plan) NumberOfActions() uint32 {
 	resultUpdater := NewApplyResultUpdaterImpl()
 
 	// apply the plan and calculate result (success/failed/skipped actions)
-	plan.applyInternal(Noop(), resultUpdater)
+	plan.applyInternal(context.Background(), Noop(), getApplyOptions(nil), resultUpdater)
 
 	// return the number of success actions (all of them will be success due to Noop() action)
 	return resultUpdater.Result.Success
@@ -164,10 +233,10 @@ func (plan *Plan) AsText() *PlanAsText {
 	result := NewPlanAsText()
 
 	// apply the plan and capture actions as text
-	plan.applyInternal(WrapSequential(func(act Interface) error {
+	plan.applyInternal(context.Background(), WrapSequential(func(ctx context.Context, act Interface) error {
 		result.Actions = append(result.Actions, act.DescribeChanges())
 		return nil
-	}), NewApplyResultUpdaterImpl())
+	}), getApplyOptions(nil), NewApplyResultUpdaterImpl())
 
 	return result
 }
@@ -1,34 +1,74 @@
 package action
 
 import (
+	"context"
+	"errors"
 	"sync"
 )
 
-// ApplyFunction is a function which applies an action
-type ApplyFunction func(Interface) error
+// ApplyFunction is a function which applies an action. Provided context gets cancelled when action has to be aborted
+// (e.g. its deadline has been reached or apply has been cancelled)
+type ApplyFunction func(context.Context, Interface) error
 
 // WrapSequential wraps apply function to be sequential
 func WrapSequential(fn ApplyFunction) ApplyFunction {
 	mutex := sync.Mutex{}
-	return func(act Interface) error {
+	return func(ctx context.Context, act Interface) error {
 		mutex.Lock()
 		defer mutex.Unlock()
-		return fn(act)
+		return fn(ctx, act)
 	}
 }
 
 // WrapParallelWithLimit allows to run the provided function in parallel, but in no more than maxConcurrentGoRoutines
-// concurrent go routines
+// concurrent go routines. If ctx gets cancelled while waiting for a free go routine, the function doesn't get called
 func WrapParallelWithLimit(maxConcurrentGoRoutines int, fn ApplyFunction) ApplyFunction {
-	var semaphore = make(chan int, maxConcurrentGoRoutines)
-	return func(act Interface) error {
-		semaphore <- 1
-		defer func() { <-semaphore }()
-		return fn(act)
+	slots := newActionSlots(maxConcurrentGoRoutines)
+	return func(ctx context.Context, act Interface) error {
+		err := slots.acquire(ctx)
+		if err != nil {
+			return err
+		}
+		defer slots.release()
+		return fn(ctx, act)
+	}
+}
+
+// errNotStarted is returned when ctx gets cancelled while action is waiting for its turn to be applied
+var errNotStarted = errors.New("action aborted before it has been started")
+
+// actionSlots limits the number of actions being applied at the same time. Nil actionSlots means no limit
+type actionSlots chan struct{}
+
+// newActionSlots returns actionSlots for a given number of concurrent actions. If it's zero, there is no limit
+func newActionSlots(maxConcurrentActions int) actionSlots {
+	if maxConcurrentActions <= 0 {
+		return nil
+	}
+	return make(actionSlots, maxConcurrentActions)
+}
+
+// acquire waits for a free slot. If ctx gets cancelled while waiting, errNotStarted is returned
+func (slots actionSlots) acquire(ctx context.Context) error {
+	if slots == nil {
+		return nil
+	}
+	select {
+	case slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return errNotStarted
+	}
+}
+
+// release frees a slot, acquired before
+func (slots actionSlots) release() {
+	if slots != nil {
+		<-slots
 	}
 }
 
 // Noop returns a function that does nothing and returns nil
 func Noop() ApplyFunction {
-	return func(Interface) error { return nil }
+	return func(context.Context, Interface) error { return nil }
 }
@@ -2,6 +2,7 @@ package action
 
 import (
 	"fmt"
+	"sync"
 	"sync/atomic"
 )
 
@@ -11,6 +12,93 @@ type ApplyResult struct {
 	Failed  uint32
 	Skipped uint32
 	Total   uint32
+
+	// TimedOut is the number of actions which didn't complete within their deadline. They are not counted as failed
+	TimedOut uint32
+
+	// Held is the number of actions which have been held and not applied (e.g. due to maintenance windows). They are
+	// not counted as skipped
+	Held uint32
+
+	// Blocked is the number of actions which must never be applied (e.g. deletion of a component instance, which is
+	// protected from deletion). Unlike held actions, they don't need to be retried later
+	Blocked uint32
+
+	// Retried is the number of actions which have been retried at least once
+	Retried uint32
+
+	// Exhausted is the number of actions which kept failing after all retry attempts had been exhausted
+	Exhausted uint32
+
+	// Cancelled is true if apply has been cancelled before all actions were processed
+	Cancelled bool `yaml:",omitempty"`
+
+	// Attempts is the number of attempts made for every retried action, keyed by action name
+	Attempts map[string]int `yaml:",omitempty"`
+
+	// HeldActions is the reason why every held action has been held, keyed by action name
+	HeldActions map[string]string `yaml:",omitempty"`
+
+	// BlockedActions is the reason why every blocked action has been blocked, keyed by action name
+	BlockedActions map[string]string `yaml:",omitempty"`
+
+	// Rollouts is a progress of all rollouts in the action plan, keyed by rollout name
+	Rollouts map[string]*RolloutResult `yaml:",omitempty"`
+}
+
+// Processed returns the number of actions which have been processed so far (succeeded, failed, timed out, held,
+// blocked or skipped)
+func (result *ApplyResult) Processed() uint32 {
+	return result.Success + result.Failed + result.TimedOut + result.Held + result.Blocked + result.Skipped
+}
+
+// AddHeld records that an action has been held for a given reason. It's not thread-safe and should be called
+// by ApplyResultUpdater implementations under a lock
+func (result *ApplyResult) AddHeld(name string, reason string) {
+	if result.HeldActions == nil {
+		result.HeldActions = make(map[string]string)
+	}
+	result.HeldActions[name] = reason
+	result.Held++
+}
+
+// AddBlocked records that an action has been blocked for a given reason. It's not thread-safe and should be called
+// by ApplyResultUpdater implementations under a lock
+func (result *ApplyResult) AddBlocked(name string, reason string) {
+	if result.BlockedActions == nil {
+		result.BlockedActions = make(map[string]string)
+	}
+	result.BlockedActions[name] = reason
+	result.Blocked++
+}
+
+// AddAttempts records the number of attempts made for a retried action. It's not thread-safe and should be called
+// by ApplyResultUpdater implementations under a lock
+func (result *ApplyResult) AddAttempts(name string, attempts int, exhausted bool) {
+	if result.Attempts == nil {
+		result.Attempts = make(map[string]int)
+	}
+	result.Attempts[name] = attempts
+	if attempts > 1 {
+		result.Retried++
+	}
+	if exhausted {
+		result.Exhausted++
+	}
+}
+
+// UpdateRollout applies an update to the progress of a rollout with a given name, creating it if it doesn't exist.
+// It's not thread-safe and should be called by ApplyResultUpdater implementations under a lock
+func (result *ApplyResult) UpdateRollout(name string, update func(*RolloutResult)) {
+	if result.Rollouts == nil {
+		result.Rollouts = make(map[string]*RolloutResult)
+	}
+	rollout, ok := result.Rollouts[name]
+	if !ok {
+		rollout = &RolloutResult{}
+		result.Rollouts[name] = rollout
+	}
+	update(rollout)
 }
 
 // ApplyResultUpdater is an interface for handling revision progress stats (# of processed actions) when applying action plan
@@ -19,12 +107,19 @@ type ApplyResultUpdater interface {
 	AddSuccess()
 	AddFailed()
 	AddSkipped()
+	AddTimedOut()
+	AddHeld(name string, reason string)
+	AddBlocked(name string, reason string)
+	SetCancelled()
+	AddAttempts(name string, attempts int, exhausted bool)
+	UpdateRollout(name string, update func(*RolloutResult))
 	Done() *ApplyResult
 }
 
 // ApplyResultUpdaterImpl is a default thread-safe implementation of ApplyResultUpdater
 type ApplyResultUpdaterImpl struct {
 	Result *ApplyResult
+	mutex  sync.Mutex
 }
 
 // NewApplyResultUpdaterImpl creates a new default thread-safe implementation ApplyResultUpdaterImpl of ApplyResultUpdater
@@ -54,10 +149,50 @@ func (updater *ApplyResultUpdaterImpl) AddSkipped() {
 	atomic.AddUint32(&updater.Result.Skipped, 1)
 }
 
+// AddTimedOut safely increments the number of timed out actions
+func (updater *ApplyResultUpdaterImpl) AddTimedOut() {
+	atomic.AddUint32(&updater.Result.TimedOut, 1)
+}
+
+// AddHeld safely records that an action has been held for a given reason
+func (updater *ApplyResultUpdaterImpl) AddHeld(name string, reason string) {
+	updater.mutex.Lock()
+	defer updater.mutex.Unlock()
+	updater.Result.AddHeld(name, reason)
+}
+
+// AddBlocked safely records that an action has been blocked for a given reason
+func (updater *ApplyResultUpdaterImpl) AddBlocked(name string, reason string) {
+	updater.mutex.Lock()
+	defer updater.mutex.Unlock()
+	updater.Result.AddBlocked(name, reason)
+}
+
+// SetCancelled safely marks apply as cancelled
+func (updater *ApplyResultUpdaterImpl) SetCancelled() {
+	updater.mutex.Lock()
+	defer updater.mutex.Unlock()
+	updater.Result.Cancelled = true
+}
+
+// AddAttempts safely records the number of attempts made for a retried action
+func (updater *ApplyResultUpdaterImpl) AddAttempts(name string, attempts int, exhausted bool) {
+	updater.mutex.Lock()
+	defer updater.mutex.Unlock()
+	updater.Result.AddAttempts(name, attempts, exhausted)
+}
+
+// UpdateRollout safely updates progress of a rollout with a given name
+func (updater *ApplyResultUpdaterImpl) UpdateRollout(name string, update func(*RolloutResult)) {
+	updater.mutex.Lock()
+	defer updater.mutex.Unlock()
+	updater.Result.UpdateRollout(name, update)
+}
+
 // Done does nothing except doing an integrity check for default implementation
 func (updater *ApplyResultUpdaterImpl) Done() *ApplyResult {
-	if updater.Result.Success+updater.Result.Failed+updater.Result.Skipped != updater.Result.Total {
-		panic(fmt.Sprintf("error while applying actions: %d (success) + %d (failed) + %d (skipped) != %d (total)", updater.Result.Success, updater.Result.Failed, updater.Result.Skipped, updater.Result.Total))
+	if updater.Result.Processed() != updater.Result.Total {
+		panic(fmt.Sprintf("error while applying actions: %d (success) + %d (failed) + %d (timed out) + %d (held) + %d (blocked) + %d (skipped) != %d (total)", updater.Result.Success, updater.Result.Failed, updater.Result.TimedOut, updater.Result.Held, updater.Result.Blocked, updater.Result.Skipped, updater.Result.Total))
 	}
 	return updater.Result
 }
@@ -0,0 +1,47 @@
+package action
+
+import (
+	"fmt"
+)
+
+// HoldFunc returns the reason why a given action, which belongs to an action graph node with a given key (i.e.
+// component instance key), must be held and not applied right now (e.g. its cluster is outside of the maintenance
+// window). If it returns empty string, then action will be applied
+type HoldFunc func(key string, act Interface) string
+
+// NoHold is a hold function which never holds actions
+func NoHold() HoldFunc {
+	return func(string, Interface) string { return "" }
+}
+
+// Blocked is implemented by actions, which must never be applied (e.g. deletion of a component instance, which is
+// protected from deletion). Such actions always get held with a given reason, but they are counted as blocked rather
+// than held, as there is no point in retrying them
+type Blocked interface {
+	GetBlockedReason() string
+}
+
+// HoldError is an error which gets recorded for an action graph node, when its actions have been held. It makes sure
+// that actions depending on held actions don't get applied either
+type HoldError struct {
+	// Reason is the reason why actions have been held
+	Reason string
+}
+
+// Error returns an error message for HoldError
+func (err *HoldError) Error() string {
+	return fmt.Sprintf("action held: %s", err.Reason)
+}
+
+// getHoldReason returns the reason why a given action must be held according to the hold function in given options,
+// or empty string if it can be applied. Blocked actions are always held when options say so, in which case it also
+// returns true
+func getHoldReason(key string, act Interface, opts *ApplyOptions) (string, bool) {
+	if blocked, ok := act.(Blocked); ok && opts.block {
+		return blocked.GetBlockedReason(), true
+	}
+	if opts.Hold == nil {
+		return "", false
+	}
+	return opts.Hold(key, act), false
+}
@@ -0,0 +1,36 @@
+package action
+
+// ApplyOptions defines how action plan gets applied. Nil options, as well as any nil field, mean that the default
+// behavior is used
+type ApplyOptions struct {
+	// RetryPolicy defines how failed actions get retried. By default, failed actions are not retried
+	RetryPolicy RetryPolicyFunc
+
+	// Timeout defines deadlines for actions. By default, actions don't have deadlines
+	Timeout TimeoutFunc
+
+	// Hold defines which actions must be held and not applied right now. By default, only blocked actions get held
+	Hold HoldFunc
+
+	// MaxConcurrentActions is the maximum number of actions applied at the same time. Deadlines of actions only start
+	// once they get their turn to be applied. By default, the number of concurrent actions is not limited
+	MaxConcurrentActions int
+
+	// Scheduling defines which of the nodes ready to be applied get picked first. By default, nodes are applied in
+	// the order they become ready
+	Scheduling *SchedulingPolicy
+
+	// block is true if blocked actions must be held. It's set when the plan gets applied, but not when it gets
+	// rendered as text, so that blocked actions are shown
+	block bool
+}
+
+// getApplyOptions returns a copy of given apply options, so they could be modified. If nil is given, default options
+// are returned
+func getApplyOptions(opts *ApplyOptions) *ApplyOptions {
+	if opts == nil {
+		return &ApplyOptions{}
+	}
+	result := *opts
+	return &result
+}
@@ -0,0 +1,52 @@
+package action
+
+import (
+	"context"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/util/retry"
+)
+
+// RetryPolicy defines how a failed action gets retried while applying action plan
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts to apply an action (including the first one)
+	MaxAttempts int
+
+	// Interval is the delay before the first retry. It gets doubled after every attempt
+	Interval time.Duration
+
+	// MaxInterval is the maximum delay between retries
+	MaxInterval time.Duration
+}
+
+// RetryPolicyFunc returns retry policy for a given action, which belongs to an action graph node with a given key
+// (i.e. component instance key). If it returns nil, then action will not be retried
+type RetryPolicyFunc func(key string, act Interface) *RetryPolicy
+
+// NoRetry is a retry policy function which never retries failed actions
+func NoRetry() RetryPolicyFunc {
+	return func(string, Interface) *RetryPolicy { return nil }
+}
+
+// applyWithRetry applies an action, retrying it according to the retry policy. If action has been retried, the
+// number of attempts gets recorded via result updater. Every attempt waits for a free slot and gets its own deadline
+// according to the timeout function. Retries stop once ctx gets cancelled
+func applyWithRetry(ctx context.Context, key string, act Interface, fn ApplyFunction, retryPolicy RetryPolicyFunc, timeout TimeoutFunc, slots actionSlots, resultUpdater ApplyResultUpdater) error {
+	var policy *RetryPolicy
+	if retryPolicy != nil {
+		policy = retryPolicy(key, act)
+	}
+	if policy == nil || policy.MaxAttempts <= 1 {
+		return applyWithTimeout(ctx, key, act, fn, timeout, slots)
+	}
+
+	var err error
+	attempts, _ := retry.Backoff(ctx, policy.MaxAttempts, policy.Interval, policy.MaxInterval, func() bool {
+		err = applyWithTimeout(ctx, key, act, fn, timeout, slots)
+		return err == nil
+	})
+	if attempts > 1 || err != nil {
+		resultUpdater.AddAttempts(act.GetName(), attempts, err != nil)
+	}
+	return err
+}
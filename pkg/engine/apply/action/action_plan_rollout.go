@@ -0,0 +1,117 @@
+package action
+
+// Rollout is a group of action graph nodes, which get updated progressively in batches. Every node in a batch waits
+// for all nodes from the previous batch to complete. If any of them fails, the rest of the rollout will be halted
+type Rollout struct {
+	// Name is a unique name of the rollout (typically namespace/service/bundle/component)
+	Name string
+
+	// Strategy is a rollout strategy (e.g. canary or batch)
+	Strategy string
+
+	// Batches is an ordered list of batches, each containing keys of action graph nodes
+	Batches [][]string
+}
+
+// RolloutResult is a progress of a single rollout, when applying action plan
+type RolloutResult struct {
+	// Strategy is a rollout strategy (e.g. canary or batch)
+	Strategy string
+
+	// Batches is the total number of batches in the rollout
+	Batches int
+
+	// CurrentBatch is the number of the latest batch (starting from 1), which has been processed
+	CurrentBatch int
+
+	// Total is the total number of component instances in the rollout
+	Total int
+
+	// Updated is the number of successfully updated component instances
+	Updated int
+
+	// Failed is the number of component instances, which failed to update
+	Failed int
+
+	// Skipped is the number of component instances, which were not updated because the rollout has been halted
+	Skipped int
+
+	// Halted is true if the rollout has been halted due to a failure
+	Halted bool
+}
+
+// AddRollout registers a new rollout in the plan. Nodes in every batch will be executed only after all nodes in the
+// previous batch have been executed. Dependencies which would introduce a cycle into the action graph are not added
+func (plan *Plan) AddRollout(rollout *Rollout) {
+	for i := 1; i < len(rollout.Batches); i++ {
+		for _, key := range rollout.Batches[i] {
+			node := plan.GetActionGraphNode(key)
+			for _, prevKey := range rollout.Batches[i-1] {
+				prevNode := plan.GetActionGraphNode(prevKey)
+				if !plan.isReachable(node, prevNode) {
+					node.AddBefore(prevNode)
+				}
+			}
+		}
+	}
+	plan.Rollouts = append(plan.Rollouts, rollout)
+}
+
+// isReachable returns true if the 'to' node has to be executed after the 'from' node (i.e. 'to' node can be reached
+// from 'from' node following BeforeRev links)
+func (plan *Plan) isReachable(from *GraphNode, to *GraphNode) bool {
+	visited := make(map[string]bool)
+	queue := []*GraphNode{from}
+	visited[from.Key] = true
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		if node.Key == to.Key {
+			return true
+		}
+		for _, next := range node.BeforeRev {
+			if !visited[next.Key] {
+				visited[next.Key] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return false
+}
+
+// findRollout returns a rollout and a batch number (starting from 1) for a given action graph node key
+func (plan *Plan) findRollout(key string) (*Rollout, int) {
+	for _, rollout := range plan.Rollouts {
+		for i, batch := range rollout.Batches {
+			for _, batchKey := range batch {
+				if batchKey == key {
+					return rollout, i + 1
+				}
+			}
+		}
+	}
+	return nil, 0
+}
+
+// updateRolloutProgress records the outcome of processing a given action graph node, if it's a part of any rollout
+func (plan *Plan) updateRolloutProgress(key string, resultUpdater ApplyResultUpdater, skipped bool, failed bool) {
+	rollout, batch := plan.findRollout(key)
+	if rollout == nil {
+		return
+	}
+	resultUpdater.UpdateRollout(rollout.Name, func(result *RolloutResult) {
+		if batch > result.CurrentBatch {
+			result.CurrentBatch = batch
+		}
+		switch {
+		case skipped:
+			result.Skipped++
+			result.Halted = true
+		case failed:
+			result.Failed++
+			result.Halted = true
+		default:
+			result.Updated++
+		}
+	})
+}
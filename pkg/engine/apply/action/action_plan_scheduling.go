@@ -0,0 +1,217 @@
+package action
+
+import (
+	"sync"
+)
+
+const (
+	// SchedulingOrderDeletesFirst means that nodes which delete component instances get applied before the rest
+	SchedulingOrderDeletesFirst = "deletes-first"
+
+	// SchedulingOrderCreatesFirst means that nodes which create component instances get applied before the rest
+	SchedulingOrderCreatesFirst = "creates-first"
+)
+
+// NodeInfo describes an action graph node for scheduling purposes
+type NodeInfo struct {
+	// Cluster is the cluster where component instance, which corresponds to the node, resides
+	Cluster string
+
+	// Namespace is the namespace of the service, which component instance belongs to
+	Namespace string
+
+	// Priority is the priority of the node. Nodes with higher priority get applied first
+	Priority int
+
+	// Deletes is true if the node deletes component instance
+	Deletes bool
+
+	// Creates is true if the node creates component instance
+	Creates bool
+}
+
+// SchedulingPolicy defines in which order action graph nodes, which are ready to be applied (i.e. all nodes they
+// depend on have been processed), get applied and how many of them can be applied concurrently within a single cluster
+// or a single namespace. Dependencies between nodes are always respected, regardless of the scheduling policy.
+//
+// Ready nodes get ordered by deletes-first or creates-first order (if set), then by priority (higher first), then by
+// the number of nodes being applied and already applied in the same namespace (fewer first, so that a large namespace
+// doesn't starve the others), and then by key
+type SchedulingPolicy struct {
+	// MaxPerCluster is the maximum number of nodes which can be applied concurrently in a single cluster. If it's
+	// zero, then there is no limit
+	MaxPerCluster int
+
+	// MaxPerNamespace is the maximum number of nodes which can be applied concurrently in a single namespace. If it's
+	// zero, then there is no limit
+	MaxPerNamespace int
+
+	// Order is either deletes-first, creates-first or empty (no ordering by operation)
+	Order string
+
+	// NodeInfo returns information about the action graph node with a given key. If it's nil, all nodes are
+	// considered to be equal
+	NodeInfo func(key string) *NodeInfo
+}
+
+// scheduler keeps track of action graph nodes which are ready to be applied and decides which one goes next,
+// according to the scheduling policy
+type scheduler struct {
+	policy *SchedulingPolicy
+	mutex  sync.Mutex
+	cond   *sync.Cond
+	ready  []string
+	info   map[string]*NodeInfo
+	closed bool
+
+	// number of nodes being applied per cluster and per namespace
+	runningPerCluster   map[string]int
+	runningPerNamespace map[string]int
+
+	// number of nodes which have been started per namespace
+	startedPerNamespace map[string]int
+}
+
+// newScheduler creates a new scheduler for a given scheduling policy. If policy is nil, nodes are applied in the order
+// they become ready
+func newScheduler(policy *SchedulingPolicy) *scheduler {
+	if policy == nil {
+		policy = &SchedulingPolicy{}
+	}
+	result := &scheduler{
+		policy:              policy,
+		info:                make(map[string]*NodeInfo),
+		runningPerCluster:   make(map[string]int),
+		runningPerNamespace: make(map[string]int),
+		startedPerNamespace: make(map[string]int),
+	}
+	result.cond = sync.NewCond(&result.mutex)
+	return result
+}
+
+// add marks node with a given key as ready to be applied
+func (s *scheduler) add(key string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.ready = append(s.ready, key)
+	if s.policy.NodeInfo != nil {
+		s.info[key] = s.policy.NodeInfo(key)
+	}
+	if s.info[key] == nil {
+		s.info[key] = &NodeInfo{}
+	}
+	s.cond.Broadcast()
+}
+
+// next blocks until there is a node which can be applied according to the scheduling policy and returns its key. It
+// returns false once scheduler has been closed
+func (s *scheduler) next() (string, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for {
+		if s.closed {
+			return "", false
+		}
+
+		idx := s.pick()
+		if idx >= 0 {
+			key := s.ready[idx]
+			s.ready = append(s.ready[:idx], s.ready[idx+1:]...)
+			info := s.info[key]
+			s.runningPerCluster[info.Cluster]++
+			s.runningPerNamespace[info.Namespace]++
+			s.startedPerNamespace[info.Namespace]++
+			return key, true
+		}
+
+		s.cond.Wait()
+	}
+}
+
+// done marks node with a given key as processed, so that the next node in the same cluster or namespace can be applied
+func (s *scheduler) done(key string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	info := s.info[key]
+	s.runningPerCluster[info.Cluster]--
+	s.runningPerNamespace[info.Namespace]--
+	s.cond.Broadcast()
+}
+
+// close makes scheduler stop handing out nodes
+func (s *scheduler) close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.closed = true
+	s.cond.Broadcast()
+}
+
+// pick returns index of the ready node, which should be applied next, or -1 if none of the ready nodes can be applied
+// right now due to concurrency limits. It should be called under a lock
+func (s *scheduler) pick() int {
+	result := -1
+	for idx, key := range s.ready {
+		info := s.info[key]
+		if s.policy.MaxPerCluster > 0 && len(info.Cluster) > 0 && s.runningPerCluster[info.Cluster] >= s.policy.MaxPerCluster {
+			continue
+		}
+		if s.policy.MaxPerNamespace > 0 && len(info.Namespace) > 0 && s.runningPerNamespace[info.Namespace] >= s.policy.MaxPerNamespace {
+			continue
+		}
+		if result < 0 || s.before(key, s.ready[result]) {
+			result = idx
+		}
+	}
+	return result
+}
+
+// before returns true if node with key a should be applied before node with key b. It should be called under a lock
+func (s *scheduler) before(a string, b string) bool {
+	infoA, infoB := s.info[a], s.info[b]
+
+	// order by operation
+	rankA, rankB := s.rank(infoA), s.rank(infoB)
+	if rankA != rankB {
+		return rankA < rankB
+	}
+
+	// order by priority
+	if infoA.Priority != infoB.Priority {
+		return infoA.Priority > infoB.Priority
+	}
+
+	// order by the number of nodes being applied in the same namespace
+	runningA, runningB := s.runningPerNamespace[infoA.Namespace], s.runningPerNamespace[infoB.Namespace]
+	if runningA != runningB {
+		return runningA < runningB
+	}
+
+	// order by the number of nodes already started in the same namespace
+	startedA, startedB := s.startedPerNamespace[infoA.Namespace], s.startedPerNamespace[infoB.Namespace]
+	if startedA != startedB {
+		return startedA < startedB
+	}
+
+	// if there is no scheduling policy defined, keep the order in which nodes became ready
+	if s.policy.NodeInfo == nil {
+		return false
+	}
+	return a < b
+}
+
+// rank returns 0 if node has to be applied first according to the deletes-first/creates-first order, and 1 otherwise
+func (s *scheduler) rank(info *NodeInfo) int {
+	switch s.policy.Order {
+	case SchedulingOrderDeletesFirst:
+		if info.Deletes {
+			return 0
+		}
+		return 1
+	case SchedulingOrderCreatesFirst:
+		if info.Creates {
+			return 0
+		}
+		return 1
+	}
+	return 0
+}
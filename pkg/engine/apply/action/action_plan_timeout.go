@@ -0,0 +1,74 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/plugin"
+)
+
+// TimeoutFunc returns the maximum amount of time a given action, which belongs to an action graph node with a given
+// key (i.e. component instance key), is allowed to take. If it returns zero, then action will not have a deadline
+type TimeoutFunc func(key string, act Interface) time.Duration
+
+// NoTimeout is a timeout function which never sets deadlines for actions
+func NoTimeout() TimeoutFunc {
+	return func(string, Interface) time.Duration { return 0 }
+}
+
+// TimeoutError is an error which gets returned when action doesn't complete within its deadline
+type TimeoutError struct {
+	// Timeout is the amount of time action was allowed to take
+	Timeout time.Duration
+
+	// Err is the error returned by action after its deadline had been reached
+	Err error
+
+	// InProgress means that plugin operation has been abandoned after the deadline, but it's still running in
+	// background (the next operation on the same object will wait for it to complete)
+	InProgress bool
+}
+
+// Error returns an error message for TimeoutError
+func (err *TimeoutError) Error() string {
+	if err.InProgress {
+		return fmt.Sprintf("action timed out after %s, but plugin operation is still in progress: %s", err.Timeout, err.Err)
+	}
+	return fmt.Sprintf("action timed out after %s: %s", err.Timeout, err.Err)
+}
+
+// IsTimeout returns true if a given error is TimeoutError
+func IsTimeout(err error) bool {
+	_, ok := err.(*TimeoutError)
+	return ok
+}
+
+// applyWithTimeout applies an action with a deadline set according to the timeout function. The deadline starts once
+// a free slot for the action is acquired, so the time spent waiting for other actions doesn't count. If action fails
+// after its deadline has been reached, TimeoutError is returned. If plugin operation is still in progress after the
+// deadline, it gets reported as timed out as well, but marked as in progress
+func applyWithTimeout(ctx context.Context, key string, act Interface, fn ApplyFunction, timeout TimeoutFunc, slots actionSlots) error {
+	err := slots.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer slots.release()
+
+	var duration time.Duration
+	if timeout != nil {
+		duration = timeout(key, act)
+	}
+	if duration <= 0 {
+		return fn(ctx, act)
+	}
+
+	actionCtx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	err = fn(actionCtx, act)
+	if err != nil && actionCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+		return &TimeoutError{Timeout: duration, Err: err, InProgress: plugin.IsInProgress(err)}
+	}
+	return err
+}
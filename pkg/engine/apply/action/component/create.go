@@ -100,12 +100,28 @@ func (a *CreateAction) processDeployment(context *action.Context) (*resolve.Comp
 		return nil, err
 	}
 
-	return instance, p.Create(
+	params, err := resolveSecrets(context, instance, instance.CalculatedCodeParams)
+	if err != nil {
+		return nil, err
+	}
+
+	err = runHook(context, instance, cluster, lang.HookPreCreate)
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.Create(
+		context.Ctx,
 		&plugin.CodePluginInvocationParams{
 			DeployName:   instance.GetDeployName(),
-			Params:       instance.CalculatedCodeParams,
+			Params:       params,
 			PluginParams: map[string]string{plugin.ParamTargetSuffix: instance.Metadata.Key.TargetSuffix},
 			EventLog:     context.EventLog,
 		},
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	return instance, runHook(context, instance, cluster, lang.HookPostCreate)
 }
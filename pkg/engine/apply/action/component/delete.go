@@ -99,10 +99,23 @@ func (a *DeleteAction) processDeployment(context *action.Context) (*resolve.Comp
 		return nil, err
 	}
 
+	// secrets may have been already removed by the time component gets deleted, so don't block the deletion
+	params, err := resolveSecrets(context, instance, instance.CalculatedCodeParams)
+	if err != nil {
+		context.EventLog.NewEntry().Warnf("Unable to resolve secrets for component instance %s, deleting it without them: %s", instance.GetKey(), err)
+		params = instance.CalculatedCodeParams
+	}
+
+	err = runHook(context, instance, cluster, lang.HookPreDelete)
+	if err != nil {
+		return nil, err
+	}
+
 	return instance, p.Destroy(
+		context.Ctx,
 		&plugin.CodePluginInvocationParams{
 			DeployName:   instance.GetDeployName(),
-			Params:       instance.CalculatedCodeParams,
+			Params:       params,
 			PluginParams: map[string]string{plugin.ParamTargetSuffix: instance.Metadata.Key.TargetSuffix},
 			EventLog:     context.EventLog,
 		},
@@ -0,0 +1,44 @@
+package component
+
+import (
+	"fmt"
+
+	"github.com/Aptomi/aptomi/pkg/engine/apply/action"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/util"
+)
+
+// BlockedDeleteAction is a action which gets produced instead of DeleteAction when an existing component is no longer
+// needed, but it's protected from deletion. It never gets applied and always gets held, so that the blocked deletion
+// is visible in the action plan and in the revision until the protection is removed
+type BlockedDeleteAction struct {
+	*action.Metadata
+	ComponentKey string
+}
+
+// NewBlockedDeleteAction creates new BlockedDeleteAction
+func NewBlockedDeleteAction(componentKey string) *BlockedDeleteAction {
+	return &BlockedDeleteAction{
+		Metadata:     action.NewMetadata("action-component-delete-blocked", componentKey),
+		ComponentKey: componentKey,
+	}
+}
+
+// Apply applies the action. It always fails, as protected component instances can't be deleted
+func (a *BlockedDeleteAction) Apply(context *action.Context) error {
+	return fmt.Errorf("unable to delete component instance '%s': %s", a.ComponentKey, a.GetBlockedReason())
+}
+
+// GetBlockedReason returns the reason why the action is blocked
+func (a *BlockedDeleteAction) GetBlockedReason() string {
+	return fmt.Sprintf("component instance is protected from deletion (deletion-policy: %s)", lang.DeletionPolicyProtect)
+}
+
+// DescribeChanges returns text-based description of changes that will be applied
+func (a *BlockedDeleteAction) DescribeChanges() util.NestedParameterMap {
+	return util.NestedParameterMap{
+		"kind":   a.Kind,
+		"key":    a.ComponentKey,
+		"pretty": fmt.Sprintf("[!] %s (protected from deletion)", a.ComponentKey),
+	}
+}
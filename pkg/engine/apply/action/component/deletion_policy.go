@@ -0,0 +1,57 @@
+package component
+
+import (
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/engine/apply/action"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
+	"github.com/Aptomi/aptomi/pkg/util"
+)
+
+// SetDeletionPolicyAction is a action which gets called when deletion policy of an existing component changes. It
+// records the new deletion policy in the actual state, so that it's still honored once the component gets removed
+// from the policy
+type SetDeletionPolicyAction struct {
+	*action.Metadata
+	ComponentKey   string
+	DeletionPolicy string
+}
+
+// NewSetDeletionPolicyAction creates new SetDeletionPolicyAction
+func NewSetDeletionPolicyAction(componentKey string, deletionPolicy string) *SetDeletionPolicyAction {
+	return &SetDeletionPolicyAction{
+		Metadata:       action.NewMetadata("action-component-deletion-policy", componentKey),
+		ComponentKey:   componentKey,
+		DeletionPolicy: deletionPolicy,
+	}
+}
+
+// Apply applies the action
+func (a *SetDeletionPolicyAction) Apply(context *action.Context) (errResult error) {
+	start := time.Now()
+	defer func() {
+		if err := recover(); err != nil {
+			errResult = fmt.Errorf("panic: %s\n%s", err, string(debug.Stack()))
+		}
+
+		action.CollectMetricsFor(a, start, errResult)
+	}()
+
+	context.EventLog.NewEntry().Debugf("Setting deletion policy of component instance '%s' to '%s'", a.ComponentKey, a.DeletionPolicy)
+
+	return context.ActualStateUpdater.UpdateComponentInstance(a.ComponentKey, func(obj *resolve.ComponentInstance) {
+		obj.DeletionPolicy = a.DeletionPolicy
+	})
+}
+
+// DescribeChanges returns text-based description of changes that will be applied
+func (a *SetDeletionPolicyAction) DescribeChanges() util.NestedParameterMap {
+	return util.NestedParameterMap{
+		"kind":   a.Kind,
+		"key":    a.ComponentKey,
+		"policy": a.DeletionPolicy,
+		"pretty": fmt.Sprintf("[#] %s (deletion-policy: %s)", a.ComponentKey, a.DeletionPolicy),
+	}
+}
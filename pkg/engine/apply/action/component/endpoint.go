@@ -97,10 +97,16 @@ func (a *EndpointsAction) processEndpoints(context *action.Context) (*resolve.Co
 		return nil, nil, err
 	}
 
+	params, err := resolveSecrets(context, instance, instance.CalculatedCodeParams)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	endpoints, err := p.Endpoints(
+		context.Ctx,
 		&plugin.CodePluginInvocationParams{
 			DeployName:   instance.GetDeployName(),
-			Params:       instance.CalculatedCodeParams,
+			Params:       params,
 			PluginParams: map[string]string{plugin.ParamTargetSuffix: instance.Metadata.Key.TargetSuffix},
 			EventLog:     context.EventLog,
 		},
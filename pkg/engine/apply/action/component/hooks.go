@@ -0,0 +1,73 @@
+package component
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/engine/apply/action"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/plugin"
+)
+
+// hookCleanupTimeout is the maximum amount of time hook cleanup can take
+const hookCleanupTimeout = time.Minute
+
+// runHook runs a hook of a given phase for component instance, if it's defined. Hook gets deployed via code plugin
+// under its own deploy name, then it's polled until it completes (i.e. plugin reports it as ready), and then it gets
+// destroyed regardless of the result
+func runHook(context *action.Context, instance *resolve.ComponentInstance, cluster *lang.Cluster, phase string) error {
+	hook := instance.CalculatedHooks[phase]
+	if hook == nil {
+		return nil
+	}
+
+	err := runHookCode(context, instance, cluster, phase, hook)
+	if err != nil {
+		return fmt.Errorf("%s hook failed: %s", phase, err)
+	}
+	return nil
+}
+
+func runHookCode(context *action.Context, instance *resolve.ComponentInstance, cluster *lang.Cluster, phase string, hook *lang.Hook) error {
+	p, err := context.Plugins.ForCodeType(cluster, hook.Type)
+	if err != nil {
+		return err
+	}
+
+	params, err := resolveSecrets(context, instance, hook.Params)
+	if err != nil {
+		return err
+	}
+
+	invocationParams := &plugin.CodePluginInvocationParams{
+		DeployName:   instance.GetDeployName() + "-" + phase,
+		Params:       params,
+		PluginParams: map[string]string{plugin.ParamTargetSuffix: instance.Metadata.Key.TargetSuffix},
+		EventLog:     context.EventLog,
+	}
+
+	context.EventLog.NewEntry().Infof("Running %s hook for component instance: %s", phase, instance.GetKey())
+	defer func() {
+		errDestroy := destroyHook(p, invocationParams)
+		if errDestroy != nil {
+			context.EventLog.NewEntry().Warnf("Unable to clean up %s hook for component instance %s: %s", phase, instance.GetKey(), errDestroy)
+		}
+	}()
+
+	err = p.Create(context.Ctx, invocationParams)
+	if err != nil {
+		return err
+	}
+
+	return waitForReady(context, p, invocationParams, instance.GetKey()+" "+phase+" hook", hook.GetTimeout(), lang.DefaultHookInterval)
+}
+
+// destroyHook destroys a hook with its own bounded context rather than the action one, so that the hook gets cleaned up
+// even if the action has been cancelled or its deadline has been exceeded
+func destroyHook(p plugin.CodePlugin, invocationParams *plugin.CodePluginInvocationParams) error {
+	ctx, cancel := context.WithTimeout(context.Background(), hookCleanupTimeout)
+	defer cancel()
+	return p.Destroy(ctx, invocationParams)
+}
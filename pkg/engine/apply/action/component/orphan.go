@@ -0,0 +1,51 @@
+package component
+
+import (
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/engine/apply/action"
+	"github.com/Aptomi/aptomi/pkg/util"
+)
+
+// OrphanAction is a action which gets called when an existing component is no longer needed, but has to keep running
+// in the cloud (i.e. it gets forgotten from the actual state, while the instance of code is left intact)
+type OrphanAction struct {
+	*action.Metadata
+	ComponentKey string
+}
+
+// NewOrphanAction creates new OrphanAction
+func NewOrphanAction(componentKey string) *OrphanAction {
+	return &OrphanAction{
+		Metadata:     action.NewMetadata("action-component-orphan", componentKey),
+		ComponentKey: componentKey,
+	}
+}
+
+// Apply applies the action
+func (a *OrphanAction) Apply(context *action.Context) (errResult error) {
+	start := time.Now()
+	defer func() {
+		if err := recover(); err != nil {
+			errResult = fmt.Errorf("panic: %s\n%s", err, string(debug.Stack()))
+		}
+
+		action.CollectMetricsFor(a, start, errResult)
+	}()
+
+	context.EventLog.NewEntry().Infof("Orphaning component instance, it will be left running in the cloud: %s", a.ComponentKey)
+
+	// delete from the actual state only
+	return context.ActualStateUpdater.DeleteComponentInstance(a.ComponentKey)
+}
+
+// DescribeChanges returns text-based description of changes that will be applied
+func (a *OrphanAction) DescribeChanges() util.NestedParameterMap {
+	return util.NestedParameterMap{
+		"kind":   a.Kind,
+		"key":    a.ComponentKey,
+		"pretty": fmt.Sprintf("[~] %s", a.ComponentKey),
+	}
+}
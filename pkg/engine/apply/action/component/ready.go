@@ -0,0 +1,118 @@
+package component
+
+import (
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/engine/apply/action"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/plugin"
+	"github.com/Aptomi/aptomi/pkg/util"
+)
+
+// WaitReadyAction is a action which gets called when a component instance replaces another instance and has to become
+// ready before the replaced instance can be deleted (i.e. code instance needs to be up and running in the cloud)
+type WaitReadyAction struct {
+	*action.Metadata
+	ComponentKey string
+	ReplacedKeys []string
+}
+
+// NewWaitReadyAction creates new WaitReadyAction
+func NewWaitReadyAction(componentKey string, replacedKeys []string) *WaitReadyAction {
+	return &WaitReadyAction{
+		Metadata:     action.NewMetadata("action-component-wait-ready", componentKey),
+		ComponentKey: componentKey,
+		ReplacedKeys: replacedKeys,
+	}
+}
+
+// Apply applies the action
+func (a *WaitReadyAction) Apply(context *action.Context) (errResult error) {
+	start := time.Now()
+	defer func() {
+		if err := recover(); err != nil {
+			errResult = fmt.Errorf("panic: %s\n%s", err, string(debug.Stack()))
+		}
+
+		action.CollectMetricsFor(a, start, errResult)
+	}()
+
+	context.EventLog.NewEntry().Debugf("Checking readiness of component instance: %s", a.ComponentKey)
+
+	err := a.processDeployment(context)
+	if err != nil {
+		return fmt.Errorf("component instance '%s' is not ready to replace %s: %s", a.ComponentKey, a.ReplacedKeys, err)
+	}
+
+	return nil
+}
+
+// DescribeChanges returns text-based description of changes that will be applied
+func (a *WaitReadyAction) DescribeChanges() util.NestedParameterMap {
+	return util.NestedParameterMap{
+		"kind":     a.Kind,
+		"key":      a.ComponentKey,
+		"replaces": a.ReplacedKeys,
+		"pretty":   fmt.Sprintf("[?] %s", a.ComponentKey),
+	}
+}
+
+func (a *WaitReadyAction) processDeployment(context *action.Context) error {
+	instance := context.DesiredState.ComponentInstanceMap[a.ComponentKey]
+	if instance == nil {
+		panic(fmt.Sprintf("component instance not found in desired state: %s", a.ComponentKey))
+	}
+
+	bundleObj, err := context.DesiredPolicy.GetObject(lang.TypeBundle.Kind, instance.Metadata.Key.BundleName, instance.Metadata.Key.Namespace)
+	if err != nil {
+		return err
+	}
+	component := bundleObj.(*lang.Bundle).GetComponentsMap()[instance.Metadata.Key.ComponentName] // nolint: errcheck
+
+	if component == nil || component.Code == nil {
+		// If this is a bundle instance or not a code component, it's always ready
+		return nil
+	}
+
+	clusterObj, err := context.DesiredPolicy.GetObject(lang.TypeCluster.Kind, instance.Metadata.Key.ClusterName, instance.Metadata.Key.ClusterNameSpace)
+	if err != nil {
+		return err
+	}
+	if clusterObj == nil {
+		return fmt.Errorf("cluster '%s/%s' in not present in policy", instance.Metadata.Key.ClusterNameSpace, instance.Metadata.Key.ClusterName)
+	}
+	cluster := clusterObj.(*lang.Cluster) // nolint: errcheck
+
+	p, err := context.Plugins.ForCodeType(cluster, component.Code.Type)
+	if err != nil {
+		return err
+	}
+
+	params, err := resolveSecrets(context, instance, instance.CalculatedCodeParams)
+	if err != nil {
+		return err
+	}
+
+	// readiness check is done with the same timeouts as rollouts
+	rollout := instance.Rollout
+	if rollout == nil {
+		rollout = &lang.Rollout{}
+	}
+
+	context.EventLog.NewEntry().Infof("Waiting for component instance to become ready before replacing %s: %s", a.ReplacedKeys, instance.GetKey())
+	return waitForReady(
+		context,
+		p,
+		&plugin.CodePluginInvocationParams{
+			DeployName:   instance.GetDeployName(),
+			Params:       params,
+			PluginParams: map[string]string{plugin.ParamTargetSuffix: instance.Metadata.Key.TargetSuffix},
+			EventLog:     context.EventLog,
+		},
+		instance.GetKey(),
+		rollout.GetReadyTimeout(),
+		rollout.GetReadyInterval(),
+	)
+}
@@ -0,0 +1,22 @@
+package component
+
+import (
+	"github.com/Aptomi/aptomi/pkg/engine/apply/action"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
+	"github.com/Aptomi/aptomi/pkg/external/secrets"
+	"github.com/Aptomi/aptomi/pkg/util"
+)
+
+// resolveSecrets replaces placeholders of secrets referenced by component instance in code params with actual secret
+// values, right before they get passed to the code plugin. All substituted values get masked in the event log
+func resolveSecrets(context *action.Context, instance *resolve.ComponentInstance, params util.NestedParameterMap) (util.NestedParameterMap, error) {
+	if context.ExternalData == nil || context.ExternalData.SecretLoader == nil || len(instance.SecretRefs) <= 0 {
+		return params, nil
+	}
+	result, values, err := secrets.ResolveReferences(params, instance.SecretRefs, context.ExternalData.SecretLoader)
+	if err != nil {
+		return nil, err
+	}
+	context.EventLog.Mask(values...)
+	return result, nil
+}
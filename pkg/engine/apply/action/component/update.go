@@ -54,6 +54,8 @@ func (a *UpdateAction) Apply(context *action.Context) (errResult error) {
 		return context.ActualStateUpdater.UpdateComponentInstance(instance.GetKey(), func(obj *resolve.ComponentInstance) {
 			obj.EndpointsUpToDate = false // invalidate endpoints, so we retrieve them again later
 			obj.CalculatedCodeParams = instance.CalculatedCodeParams
+			obj.CalculatedHooks = instance.CalculatedHooks
+			obj.SecretRefs = instance.SecretRefs
 		})
 	}
 
@@ -110,12 +112,59 @@ func (a *UpdateAction) processDeployment(context *action.Context) (*resolve.Comp
 		return nil, err
 	}
 
-	return instance, p.Update(
-		&plugin.CodePluginInvocationParams{
-			DeployName:   instance.GetDeployName(),
-			Params:       instance.CalculatedCodeParams,
-			PluginParams: map[string]string{plugin.ParamTargetSuffix: instance.Metadata.Key.TargetSuffix},
-			EventLog:     context.EventLog,
-		},
-	)
+	params, err := resolveSecrets(context, instance, instance.CalculatedCodeParams)
+	if err != nil {
+		return nil, err
+	}
+
+	invocationParams := &plugin.CodePluginInvocationParams{
+		DeployName:   instance.GetDeployName(),
+		Params:       params,
+		PluginParams: map[string]string{plugin.ParamTargetSuffix: instance.Metadata.Key.TargetSuffix},
+		EventLog:     context.EventLog,
+	}
+
+	err = runHook(context, instance, cluster, lang.HookPreUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.Update(context.Ctx, invocationParams)
+	if err != nil {
+		return nil, err
+	}
+
+	// if component is being rolled out progressively, the update is only considered successful once it's ready
+	if instance.Rollout != nil {
+		context.EventLog.NewEntry().Infof("Waiting for component instance to become ready (%s rollout): %s", instance.Rollout.Strategy, instance.GetKey())
+		err = waitForReady(context, p, invocationParams, instance.GetKey(), instance.Rollout.GetReadyTimeout(), instance.Rollout.GetReadyInterval())
+		if err != nil {
+			return nil, fmt.Errorf("%s, halting %s rollout", err, instance.Rollout.Strategy)
+		}
+	}
+
+	return instance, runHook(context, instance, cluster, lang.HookPostUpdate)
+}
+
+// waitForReady polls plugin for the component instance status until it becomes ready or timeout expires
+func waitForReady(context *action.Context, p plugin.CodePlugin, invocationParams *plugin.CodePluginInvocationParams, key string, readyTimeout time.Duration, readyInterval time.Duration) error {
+	timeout := time.After(readyTimeout)
+	for {
+		ready, err := p.Status(context.Ctx, invocationParams)
+		if err != nil {
+			return fmt.Errorf("error while checking readiness: %s", err)
+		}
+		if ready {
+			context.EventLog.NewEntry().Infof("Component instance is ready: %s", key)
+			return nil
+		}
+
+		select {
+		case <-timeout:
+			return fmt.Errorf("component instance is not ready after %s", readyTimeout)
+		case <-context.Ctx.Done():
+			return fmt.Errorf("cancelled while waiting for component instance to become ready")
+		case <-time.After(readyInterval):
+		}
+	}
 }
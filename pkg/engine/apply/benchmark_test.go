@@ -1,6 +1,7 @@
 package apply
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
@@ -107,7 +108,7 @@ func RunEngine(b *testing.B, testName string, desiredPolicy *lang.Policy, extern
 
 func applyAndCheckBenchmark(b *testing.B, apply *EngineApply, expectedResult action.ApplyResult) *resolve.PolicyResolution {
 	b.Helper()
-	actualState, result := apply.Apply(50)
+	actualState, result := apply.Apply(context.Background(), &action.ApplyOptions{MaxConcurrentActions: 50})
 
 	t := &testing.T{}
 	ok := assert.Equal(t, expectedResult.Success, result.Success, "Number of successfully executed actions")
@@ -1,6 +1,8 @@
 package apply
 
 import (
+	"context"
+
 	"github.com/Aptomi/aptomi/pkg/engine/actual"
 	"github.com/Aptomi/aptomi/pkg/engine/apply/action"
 	"github.com/Aptomi/aptomi/pkg/engine/resolve"
@@ -51,10 +53,19 @@ func NewEngineApply(desiredPolicy *lang.Policy, desiredState *resolve.PolicyReso
 //
 // As actions get executed, they will instantiate/update/delete components according to the resolved
 // policy, as well as configure the underlying cloud components appropriately. In case of errors (e.g. cloud is not
-// available), actual state may not be equal to desired state after performing all the actions.
-func (apply *EngineApply) Apply(maxConcurrentActions int) (*resolve.PolicyResolution, *action.ApplyResult) {
+// available), actual state may not be equal to desired state after performing all the actions. Failed actions
+// will be retried according to the retry policy in given options. Every action gets a deadline according to the
+// timeout function, and actions which didn't complete in time are reported as timed out. Actions get held according
+// to the hold function (e.g. when cluster is outside of its maintenance window), and actions depending on them get
+// skipped. Actions get scheduled according to the scheduling policy, and no more than the maximum number of concurrent
+// actions get applied at the same time. Nil options mean that defaults are used (see action.ApplyOptions).
+//
+// If ctx gets cancelled, no new actions will be started and the remaining actions will be marked as skipped. Actions
+// which are in progress will see the cancellation via action context, which gets passed into all plugin calls.
+func (apply *EngineApply) Apply(ctx context.Context, opts *action.ApplyOptions) (*resolve.PolicyResolution, *action.ApplyResult) {
 	// process all actions
-	context := action.NewContext(
+	actionContext := action.NewContext(
+		ctx,
 		apply.desiredPolicy,
 		apply.desiredState,
 		apply.actualStateUpdater,
@@ -64,13 +75,13 @@ func (apply *EngineApply) Apply(maxConcurrentActions int) (*resolve.PolicyResolu
 	)
 
 	// Note that the action plan will call function in different go routines by apply
-	result := apply.actionPlan.Apply(action.WrapParallelWithLimit(maxConcurrentActions, func(act action.Interface) error {
-		err := act.Apply(context)
+	result := apply.actionPlan.Apply(ctx, func(actionCtx context.Context, act action.Interface) error {
+		err := act.Apply(actionContext.WithContext(actionCtx))
 		if err != nil {
-			context.EventLog.NewEntry().Errorf("error while applying action '%s': %s", act, err)
+			actionContext.EventLog.NewEntry().Errorf("error while applying action '%s': %s", act, err)
 		}
 		return err
-	}), apply.updater)
+	}, opts, apply.updater)
 
 	// No errors occurred
 	return apply.actualStateUpdater.GetUpdatedActualState(), result
@@ -1,12 +1,15 @@
 package apply
 
 import (
+	"context"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/Aptomi/aptomi/pkg/config"
 	"github.com/Aptomi/aptomi/pkg/engine/actual"
 	"github.com/Aptomi/aptomi/pkg/engine/apply/action"
+	"github.com/Aptomi/aptomi/pkg/engine/apply/action/component"
 	"github.com/Aptomi/aptomi/pkg/engine/diff"
 	"github.com/Aptomi/aptomi/pkg/engine/resolve"
 	"github.com/Aptomi/aptomi/pkg/event"
@@ -87,6 +90,232 @@ func checkApplyComponentCreateFail(t *testing.T, failAsPanic bool) {
 	assert.Equal(t, 0, len(actualState.ComponentInstanceMap), "Actual state should not be touched by apply()")
 }
 
+func TestApplyComponentCreateFailureWithRetry(t *testing.T) {
+	// resolve empty policy
+	empty := newTestData(t, builder.NewPolicyBuilder())
+	actualState := empty.resolution()
+
+	// resolve full policy
+	desired := newTestData(t, makePolicyBuilder())
+
+	// process all actions (and make component fail deployment)
+	applier := NewEngineApply(
+		desired.policy(),
+		desired.resolution(),
+		actual.NewNoOpActionStateUpdater(actualState),
+		desired.external(),
+		mockRegistry(false, false),
+		diff.NewPolicyResolutionDiff(desired.resolution(), actualState).ActionPlan,
+		event.NewLog(logrus.DebugLevel, "test-apply"),
+		action.NewApplyResultUpdaterImpl(),
+	)
+
+	// retry only create actions
+	retryPolicy := func(key string, act action.Interface) *action.RetryPolicy {
+		if _, ok := act.(*component.CreateAction); !ok {
+			return nil
+		}
+		return &action.RetryPolicy{MaxAttempts: 3, Interval: 100 * time.Millisecond, MaxInterval: 100 * time.Millisecond}
+	}
+	_, result := applier.Apply(context.Background(), &action.ApplyOptions{MaxConcurrentActions: 50, RetryPolicy: retryPolicy})
+
+	// action should fail after exhausting all attempts, while dependent actions should be skipped
+	assert.Equal(t, uint32(0), result.Success, "Number of successfully executed actions")
+	assert.Equal(t, uint32(1), result.Failed, "Number of failed actions")
+	assert.Equal(t, uint32(3), result.Skipped, "Number of skipped actions")
+	assert.Equal(t, uint32(1), result.Retried, "Number of retried actions")
+	assert.Equal(t, uint32(1), result.Exhausted, "Number of actions which exhausted all retries")
+	for name, attempts := range result.Attempts {
+		assert.Equal(t, 3, attempts, "Number of attempts for action %s", name)
+	}
+	assert.Equal(t, 1, len(result.Attempts), "Attempts should be recorded for a retried action")
+}
+
+func TestApplyCancelled(t *testing.T) {
+	// resolve empty policy
+	empty := newTestData(t, builder.NewPolicyBuilder())
+	actualState := empty.resolution()
+
+	// resolve full policy
+	desired := newTestData(t, makePolicyBuilder())
+
+	// apply with a context, which has been cancelled already
+	applier := NewEngineApply(
+		desired.policy(),
+		desired.resolution(),
+		actual.NewNoOpActionStateUpdater(actualState),
+		desired.external(),
+		mockRegistry(true, false),
+		diff.NewPolicyResolutionDiff(desired.resolution(), actualState).ActionPlan,
+		event.NewLog(logrus.DebugLevel, "test-apply"),
+		action.NewApplyResultUpdaterImpl(),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, result := applier.Apply(ctx, &action.ApplyOptions{MaxConcurrentActions: 50})
+
+	// no actions should be executed, all of them should be skipped
+	assert.True(t, result.Cancelled, "Apply should be marked as cancelled")
+	assert.Equal(t, uint32(0), result.Success, "Number of successfully executed actions")
+	assert.Equal(t, uint32(0), result.Failed, "Number of failed actions")
+	assert.Equal(t, result.Total, result.Skipped, "All actions should be skipped")
+}
+
+func TestApplyComponentCreateTimeout(t *testing.T) {
+	// resolve empty policy
+	empty := newTestData(t, builder.NewPolicyBuilder())
+	actualState := empty.resolution()
+
+	// resolve full policy
+	desired := newTestData(t, makePolicyBuilder())
+
+	// make code plugin hang on every call
+	clusterTypes := map[string]plugin.ClusterPluginConstructor{
+		"kubernetes": func(cluster *lang.Cluster, cfg config.Plugins) (plugin.ClusterPlugin, error) {
+			return fake.NewNoOpClusterPlugin(0), nil
+		},
+	}
+	codeTypes := map[string]map[string]plugin.CodePluginConstructor{
+		"kubernetes": {
+			"helm": func(cluster plugin.ClusterPlugin, cfg config.Plugins) (plugin.CodePlugin, error) {
+				return fake.NewNoOpCodePlugin(time.Hour), nil
+			},
+		},
+	}
+
+	// process all actions with a short deadline
+	applier := NewEngineApply(
+		desired.policy(),
+		desired.resolution(),
+		actual.NewNoOpActionStateUpdater(actualState),
+		desired.external(),
+		plugin.NewRegistry(config.Plugins{}, clusterTypes, codeTypes),
+		diff.NewPolicyResolutionDiff(desired.resolution(), actualState).ActionPlan,
+		event.NewLog(logrus.DebugLevel, "test-apply"),
+		action.NewApplyResultUpdaterImpl(),
+	)
+	timeout := func(key string, act action.Interface) time.Duration {
+		return 100 * time.Millisecond
+	}
+	_, result := applier.Apply(context.Background(), &action.ApplyOptions{MaxConcurrentActions: 50, Timeout: timeout})
+
+	// code component creation should time out, while dependent actions should be skipped
+	assert.Equal(t, uint32(1), result.TimedOut, "Number of timed out actions")
+	assert.Equal(t, uint32(0), result.Failed, "Timed out actions should not be counted as failed")
+	assert.Equal(t, uint32(3), result.Skipped, "Number of skipped actions")
+	assert.Equal(t, result.Total, result.Processed(), "All actions should be processed")
+}
+
+func TestApplyTimeoutInProgress(t *testing.T) {
+	b := makeSchedulingPolicyBuilder(
+		schedulingService{"ns1", "1"},
+		schedulingService{"ns2", "1"},
+	)
+	desiredState := resolvePolicy(t, b)
+	actualState := resolvePolicy(t, builder.NewPolicyBuilder())
+
+	// every action calls plugin operation, which doesn't pay attention to ctx (e.g. helm install)
+	release := make(chan struct{})
+	defer close(release)
+	fn := func(ctx context.Context, act action.Interface) error {
+		return plugin.RunWithContext(ctx, act.GetName(), func() error {
+			<-release
+			return nil
+		})
+	}
+	timeout := func(key string, act action.Interface) time.Duration {
+		return 20 * time.Millisecond
+	}
+
+	// abandoned plugin operations should be reported as timed out rather than failed
+	plan := diff.NewPolicyResolutionDiff(desiredState, actualState).ActionPlan
+	result := plan.Apply(context.Background(), fn, &action.ApplyOptions{Timeout: timeout}, action.NewApplyResultUpdaterImpl())
+	assert.True(t, result.TimedOut > 0, "Abandoned plugin operations should be reported as timed out")
+	assert.Equal(t, uint32(0), result.Failed, "Abandoned plugin operations should not be counted as failed")
+	assert.Equal(t, result.Total, result.Processed(), "All actions should be processed")
+}
+
+func TestApplyTimeoutStartsAfterWaiting(t *testing.T) {
+	b := makeSchedulingPolicyBuilder(
+		schedulingService{"ns1", "1"},
+		schedulingService{"ns2", "1"},
+		schedulingService{"ns3", "1"},
+		schedulingService{"ns4", "1"},
+	)
+	desiredState := resolvePolicy(t, b)
+	actualState := resolvePolicy(t, builder.NewPolicyBuilder())
+
+	// every action takes a while, so waiting for the turn takes longer than the timeout
+	fn := func(ctx context.Context, act action.Interface) error {
+		select {
+		case <-time.After(30 * time.Millisecond):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	timeout := func(key string, act action.Interface) time.Duration {
+		return 100 * time.Millisecond
+	}
+
+	// only the time action is being applied should count towards its deadline
+	plan := diff.NewPolicyResolutionDiff(desiredState, actualState).ActionPlan
+	result := plan.Apply(context.Background(), fn, &action.ApplyOptions{Timeout: timeout, MaxConcurrentActions: 1}, action.NewApplyResultUpdaterImpl())
+	assert.Equal(t, uint32(0), result.TimedOut, "Actions waiting for their turn should not time out")
+	assert.Equal(t, result.Total, result.Success, "All actions should be applied successfully")
+
+	// once apply gets cancelled, actions waiting for their turn should be skipped rather than failed
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	plan = diff.NewPolicyResolutionDiff(desiredState, actualState).ActionPlan
+	result = plan.Apply(ctx, fn, &action.ApplyOptions{Timeout: timeout, MaxConcurrentActions: 1}, action.NewApplyResultUpdaterImpl())
+	assert.Equal(t, uint32(0), result.TimedOut, "Actions should not time out")
+	assert.True(t, result.Skipped > 0, "Actions waiting for their turn should be skipped")
+	assert.Equal(t, result.Total, result.Processed(), "All actions should be processed")
+}
+
+func TestApplyComponentCreateHeld(t *testing.T) {
+	// resolve empty policy
+	empty := newTestData(t, builder.NewPolicyBuilder())
+	actualState := empty.resolution()
+
+	// resolve full policy
+	desired := newTestData(t, makePolicyBuilder())
+
+	// process all actions
+	applier := NewEngineApply(
+		desired.policy(),
+		desired.resolution(),
+		actual.NewNoOpActionStateUpdater(actualState),
+		desired.external(),
+		mockRegistry(true, false),
+		diff.NewPolicyResolutionDiff(desired.resolution(), actualState).ActionPlan,
+		event.NewLog(logrus.DebugLevel, "test-apply"),
+		action.NewApplyResultUpdaterImpl(),
+	)
+
+	// hold only create actions
+	hold := func(key string, act action.Interface) string {
+		if _, ok := act.(*component.CreateAction); !ok {
+			return ""
+		}
+		return "outside of maintenance window"
+	}
+	_, result := applier.Apply(context.Background(), &action.ApplyOptions{MaxConcurrentActions: 50, Hold: hold})
+
+	// component creation and the rest of actions for the same component instance should be held, while dependent
+	// actions should be skipped
+	assert.Equal(t, uint32(0), result.Success, "Number of successfully executed actions")
+	assert.Equal(t, uint32(0), result.Failed, "Held actions should not be counted as failed")
+	assert.Equal(t, uint32(2), result.Held, "Number of held actions")
+	assert.Equal(t, uint32(2), result.Skipped, "Number of skipped actions")
+	assert.Equal(t, result.Total, result.Processed(), "All actions should be processed")
+	for name, reason := range result.HeldActions {
+		assert.Equal(t, "outside of maintenance window", reason, "Reason for holding action %s", name)
+	}
+	assert.Equal(t, 2, len(result.HeldActions), "Reason should be recorded for every held action")
+}
+
 func TestDiffHasUpdatedComponentsAndCheckTimes(t *testing.T) {
 	/*
 		Step 1: actual = empty, desired = test policy, check = claim update/create times
@@ -259,6 +488,180 @@ func TestDeletePolicyObjectsWhileComponentInstancesAreStillRunningFails(t *testi
 	assert.Equal(t, 2, len(actualState.ComponentInstanceMap), "Actual state should still have component instances after actions failing")
 }
 
+func TestApplyComponentHooks(t *testing.T) {
+	// resolve empty policy
+	empty := newTestData(t, builder.NewPolicyBuilder())
+	actualState := empty.resolution()
+
+	// resolve policy with hooks
+	desired := newTestData(t, makeHooksPolicyBuilder())
+
+	// failing pre-create hook should fail component creation
+	applier := NewEngineApply(
+		desired.policy(),
+		desired.resolution(),
+		actual.NewNoOpActionStateUpdater(actualState),
+		desired.external(),
+		mockHooksRegistry(false),
+		diff.NewPolicyResolutionDiff(desired.resolution(), actualState).ActionPlan,
+		event.NewLog(logrus.DebugLevel, "test-apply"),
+		action.NewApplyResultUpdaterImpl(),
+	)
+	actualState = applyAndCheck(t, applier, action.ApplyResult{Success: 0, Failed: 1, Skipped: 3})
+	assert.Equal(t, 0, len(actualState.ComponentInstanceMap), "Actual state should not be touched if hook failed")
+
+	// successful hooks should let component get created
+	applier = NewEngineApply(
+		desired.policy(),
+		desired.resolution(),
+		actual.NewNoOpActionStateUpdater(actualState),
+		desired.external(),
+		mockHooksRegistry(true),
+		diff.NewPolicyResolutionDiff(desired.resolution(), actualState).ActionPlan,
+		event.NewLog(logrus.DebugLevel, "test-apply"),
+		action.NewApplyResultUpdaterImpl(),
+	)
+	actualState = applyAndCheck(t, applier, action.ApplyResult{Success: 4, Failed: 0, Skipped: 0})
+	assert.Equal(t, 2, len(actualState.ComponentInstanceMap), "Actual state should be updated after apply()")
+
+	// failing pre-delete hook (recorded in actual state) should fail component deletion
+	empty = newTestData(t, builder.NewPolicyBuilder())
+	applier = NewEngineApply(
+		desired.policy(),
+		empty.resolution(),
+		actual.NewNoOpActionStateUpdater(actualState),
+		empty.external(),
+		mockHooksRegistry(false),
+		diff.NewPolicyResolutionDiff(empty.resolution(), actualState).ActionPlan,
+		event.NewLog(logrus.DebugLevel, "test-apply"),
+		action.NewApplyResultUpdaterImpl(),
+	)
+	actualState = applyAndCheck(t, applier, action.ApplyResult{Success: 3, Failed: 1, Skipped: 0})
+	assert.Equal(t, 1, len(actualState.ComponentInstanceMap), "Code component instance should stay in actual state if hook failed")
+}
+
+// cleanupRecordingPlugin is a code plugin, which records whether its context has already been done when it's destroyed
+type cleanupRecordingPlugin struct {
+	plugin.CodePlugin
+	mutex       sync.Mutex
+	destroyErrs []error
+}
+
+func (p *cleanupRecordingPlugin) Destroy(ctx context.Context, invocation *plugin.CodePluginInvocationParams) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.destroyErrs = append(p.destroyErrs, ctx.Err())
+	return nil
+}
+
+func TestApplyComponentHookCleanupAfterTimeout(t *testing.T) {
+	actualState := newTestData(t, builder.NewPolicyBuilder()).resolution()
+	desired := newTestData(t, makeHooksPolicyBuilder())
+
+	// hook never completes, so the action deadline gets exceeded while it's running
+	hookPlugin := &cleanupRecordingPlugin{CodePlugin: fake.NewNoOpCodePlugin(time.Hour)}
+	clusterTypes := map[string]plugin.ClusterPluginConstructor{
+		"kubernetes": func(cluster *lang.Cluster, cfg config.Plugins) (plugin.ClusterPlugin, error) {
+			return fake.NewNoOpClusterPlugin(0), nil
+		},
+	}
+	codeTypes := map[string]map[string]plugin.CodePluginConstructor{
+		"kubernetes": {
+			"helm": func(cluster plugin.ClusterPlugin, cfg config.Plugins) (plugin.CodePlugin, error) {
+				return fake.NewNoOpCodePlugin(0), nil
+			},
+			"raw": func(cluster plugin.ClusterPlugin, cfg config.Plugins) (plugin.CodePlugin, error) {
+				return hookPlugin, nil
+			},
+		},
+	}
+
+	applier := NewEngineApply(
+		desired.policy(),
+		desired.resolution(),
+		actual.NewNoOpActionStateUpdater(actualState),
+		desired.external(),
+		plugin.NewRegistry(config.Plugins{}, clusterTypes, codeTypes),
+		diff.NewPolicyResolutionDiff(desired.resolution(), actualState).ActionPlan,
+		event.NewLog(logrus.DebugLevel, "test-apply"),
+		action.NewApplyResultUpdaterImpl(),
+	)
+	timeout := func(key string, act action.Interface) time.Duration {
+		return 50 * time.Millisecond
+	}
+	_, result := applier.Apply(context.Background(), &action.ApplyOptions{MaxConcurrentActions: 50, Timeout: timeout})
+	assert.Equal(t, uint32(1), result.TimedOut, "Action running the hook should time out")
+
+	// hook should still get cleaned up with a context, which is not done yet
+	hookPlugin.mutex.Lock()
+	defer hookPlugin.mutex.Unlock()
+	if assert.Equal(t, 1, len(hookPlugin.destroyErrs), "Hook should be cleaned up") {
+		assert.NoError(t, hookPlugin.destroyErrs[0], "Hook should be cleaned up with a fresh context")
+	}
+}
+
+func TestApplySchedulingOrderAndPriority(t *testing.T) {
+	// actual state has a service, which is going to be deleted
+	actualState := newTestData(t, makeSchedulingPolicyBuilder(schedulingService{"old", "0"})).resolution()
+
+	// desired state has two new services with different priorities
+	desired := newTestData(t, makeSchedulingPolicyBuilder(schedulingService{"main", "1"}, schedulingService{"main", "10"}))
+
+	testCases := []struct {
+		order    string
+		expected []string
+	}{
+		{action.SchedulingOrderDeletesFirst, []string{"delete", "delete", "10", "10", "1", "1"}},
+		{action.SchedulingOrderCreatesFirst, []string{"10", "10", "1", "1", "delete", "delete"}},
+	}
+	for _, tc := range testCases {
+		// only one node at a time is allowed in a cluster, so the order is fully determined by the scheduling policy
+		rec := applyWithScheduling(t, desired.resolution(), actualState, &action.SchedulingPolicy{MaxPerCluster: 1, Order: tc.order})
+
+		var order []string
+		for _, key := range rec.order {
+			if instance, ok := desired.resolution().ComponentInstanceMap[key]; ok {
+				order = append(order, instance.CalculatedLabels.Labels["priority"])
+			} else {
+				order = append(order, "delete")
+			}
+		}
+		assert.Equal(t, tc.expected, order, "Nodes should be applied in %s order, then by priority", tc.order)
+		assert.Equal(t, 1, rec.maxRunning["cluster"], "Number of nodes applied concurrently in a cluster")
+	}
+}
+
+func TestApplySchedulingConcurrencyLimits(t *testing.T) {
+	// actual state is empty
+	actualState := newTestData(t, builder.NewPolicyBuilder()).resolution()
+
+	// desired state has a large namespace and a small one
+	var services []schedulingService
+	for i := 0; i < 6; i++ {
+		services = append(services, schedulingService{"big", "0"})
+	}
+	services = append(services, schedulingService{"small", "0"}, schedulingService{"small", "0"})
+	desired := newTestData(t, makeSchedulingPolicyBuilder(services...))
+
+	rec := applyWithScheduling(t, desired.resolution(), actualState, &action.SchedulingPolicy{MaxPerCluster: 2, MaxPerNamespace: 1})
+
+	// concurrency limits should be respected
+	assert.Equal(t, 2, rec.maxRunning["cluster"], "Number of nodes applied concurrently in a cluster")
+	assert.Equal(t, 1, rec.maxRunning["big"], "Number of nodes applied concurrently in a large namespace")
+	assert.Equal(t, 1, rec.maxRunning["small"], "Number of nodes applied concurrently in a small namespace")
+
+	// small namespace should not be starved by the large one
+	lastSmall, lastBig := 0, 0
+	for idx, key := range rec.order {
+		if desired.resolution().ComponentInstanceMap[key].Metadata.Key.Namespace == "small" {
+			lastSmall = idx
+		} else {
+			lastBig = idx
+		}
+	}
+	assert.True(t, lastSmall < lastBig, "Small namespace should be done before the large one")
+}
+
 /*
 	Helpers
 */
@@ -336,7 +739,7 @@ func resolvePolicy(t *testing.T, b *builder.PolicyBuilder) *resolve.PolicyResolu
 
 func applyAndCheck(t *testing.T, apply *EngineApply, expectedResult action.ApplyResult) *resolve.PolicyResolution {
 	t.Helper()
-	actualState, result := apply.Apply(50)
+	actualState, result := apply.Apply(context.Background(), &action.ApplyOptions{MaxConcurrentActions: 50})
 
 	ok := assert.Equal(t, expectedResult.Success, result.Success, "Number of successfully executed actions")
 	ok = ok && assert.Equal(t, expectedResult.Failed, result.Failed, "Number of failed actions")
@@ -393,3 +796,127 @@ func mockRegistry(applySuccess, failAsPanic bool) plugin.Registry {
 
 	return plugin.NewRegistry(config.Plugins{}, clusterTypes, codeTypes)
 }
+
+// makeHooksPolicyBuilder creates a policy with a component, which has pre-create and pre-delete hooks of "raw" type
+func makeHooksPolicyBuilder() *builder.PolicyBuilder {
+	b := makePolicyBuilder()
+	for _, bundle := range b.Policy().GetObjectsByKind(lang.TypeBundle.Kind) {
+		for _, component := range bundle.(*lang.Bundle).Components {
+			component.Hooks = &lang.Hooks{
+				PreCreate: &lang.Hook{Type: "raw", Params: util.NestedParameterMap{"check": "{{ .Labels.param }}"}},
+				PreDelete: &lang.Hook{Type: "raw", Params: util.NestedParameterMap{"backup": "true"}},
+			}
+		}
+	}
+	return b
+}
+
+// mockHooksRegistry returns plugin registry, where component code ("helm") always succeeds, while hooks ("raw")
+// either succeed or fail
+func mockHooksRegistry(hookSuccess bool) plugin.Registry {
+	clusterTypes := make(map[string]plugin.ClusterPluginConstructor)
+	codeTypes := make(map[string]map[string]plugin.CodePluginConstructor)
+
+	clusterTypes["kubernetes"] = func(cluster *lang.Cluster, cfg config.Plugins) (plugin.ClusterPlugin, error) {
+		return fake.NewNoOpClusterPlugin(0), nil
+	}
+
+	codeTypes["kubernetes"] = make(map[string]plugin.CodePluginConstructor)
+	codeTypes["kubernetes"]["helm"] = func(cluster plugin.ClusterPlugin, cfg config.Plugins) (plugin.CodePlugin, error) {
+		return fake.NewNoOpCodePlugin(0), nil
+	}
+	codeTypes["kubernetes"]["raw"] = func(cluster plugin.ClusterPlugin, cfg config.Plugins) (plugin.CodePlugin, error) {
+		if hookSuccess {
+			return fake.NewNoOpCodePlugin(0), nil
+		}
+		return fake.NewFailCodePlugin(false), nil
+	}
+
+	return plugin.NewRegistry(config.Plugins{}, clusterTypes, codeTypes)
+}
+
+type schedulingService struct {
+	namespace string
+	priority  string
+}
+
+// makeSchedulingPolicyBuilder creates a policy with a service in every given namespace, all running in the same cluster.
+// Every service gets consumed with a claim, which has a given priority label
+func makeSchedulingPolicyBuilder(services ...schedulingService) *builder.PolicyBuilder {
+	b := builder.NewPolicyBuilder()
+	clusterObj := b.AddCluster()
+	for _, s := range services {
+		b.SwitchNamespace(s.namespace)
+		bundle := b.AddBundle()
+		b.AddBundleComponent(bundle, b.CodeComponent(util.NestedParameterMap{"param": "value"}, nil))
+		service := b.AddService(bundle, b.CriteriaTrue())
+		b.AddRule(b.CriteriaTrue(), b.RuleActions(lang.NewLabelOperationsSetSingleLabel(lang.LabelTarget, clusterObj.Name)))
+		claim := b.AddClaim(b.AddUser(), service)
+		claim.Labels["priority"] = s.priority
+	}
+	return b
+}
+
+// schedulingRecorder records the order in which action graph nodes get applied and how many of them run concurrently
+type schedulingRecorder struct {
+	mutex      sync.Mutex
+	seq        int
+	order      []string
+	started    map[string]int
+	finished   map[string]int
+	running    map[string]int
+	maxRunning map[string]int
+}
+
+// applyWithScheduling applies the plan between desired and actual state with a given scheduling policy, checks that
+// all dependencies between nodes have been respected and returns the recorded order
+func applyWithScheduling(t *testing.T, desiredState *resolve.PolicyResolution, actualState *resolve.PolicyResolution, scheduling *action.SchedulingPolicy) *schedulingRecorder {
+	t.Helper()
+	plan := diff.NewPolicyResolutionDiff(desiredState, actualState).ActionPlan
+	scheduling.NodeInfo = GetSchedulingNodeInfo(plan, desiredState, actualState, "priority")
+
+	rec := &schedulingRecorder{
+		started:    make(map[string]int),
+		finished:   make(map[string]int),
+		running:    make(map[string]int),
+		maxRunning: make(map[string]int),
+	}
+	update := func(key string, delta int) {
+		info := scheduling.NodeInfo(key)
+		rec.mutex.Lock()
+		defer rec.mutex.Unlock()
+		rec.seq++
+		if delta > 0 {
+			if _, ok := rec.started[key]; !ok {
+				rec.started[key] = rec.seq
+				rec.order = append(rec.order, key)
+			}
+		} else {
+			rec.finished[key] = rec.seq
+		}
+		for _, group := range []string{"cluster", info.Namespace} {
+			rec.running[group] += delta
+			if rec.running[group] > rec.maxRunning[group] {
+				rec.maxRunning[group] = rec.running[group]
+			}
+		}
+	}
+
+	result := plan.Apply(context.Background(), func(ctx context.Context, act action.Interface) error {
+		key := act.DescribeChanges()["key"].(string)
+		update(key, 1)
+		time.Sleep(5 * time.Millisecond)
+		update(key, -1)
+		return nil
+	}, &action.ApplyOptions{Scheduling: scheduling}, action.NewApplyResultUpdaterImpl())
+
+	assert.Equal(t, result.Total, result.Success, "All actions should be applied successfully")
+	assert.Equal(t, len(plan.NodeMap), len(rec.order), "All nodes should be applied")
+	for key, node := range plan.NodeMap {
+		for _, before := range node.Before {
+			assert.True(t, rec.finished[before.Key] < rec.started[key], "Node %s should be applied after %s", key, before.Key)
+		}
+	}
+
+	return rec
+}
@@ -0,0 +1,51 @@
+package apply
+
+import (
+	"strconv"
+
+	"github.com/Aptomi/aptomi/pkg/engine/apply/action"
+	"github.com/Aptomi/aptomi/pkg/engine/apply/action/component"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+)
+
+// GetSchedulingNodeInfo returns a function, which describes action graph nodes of a given plan for the scheduler.
+// Cluster and namespace are taken from the corresponding component instance (from desired state or, if it's being
+// deleted, from actual state). Priority is taken from a given calculated label of the component instance (e.g. set
+// via change-labels in service), it's 0 if label is not set or isn't a number
+func GetSchedulingNodeInfo(plan *action.Plan, desiredState *resolve.PolicyResolution, actualState *resolve.PolicyResolution, priorityLabel string) func(key string) *action.NodeInfo {
+	// describe all nodes upfront, as states may get modified while the plan is being applied
+	result := make(map[string]*action.NodeInfo)
+	for key, node := range plan.NodeMap {
+		info := &action.NodeInfo{}
+
+		instance := desiredState.ComponentInstanceMap[key]
+		if instance == nil {
+			instance = actualState.ComponentInstanceMap[key]
+		}
+		if instance != nil {
+			info.Cluster = instance.Metadata.Key.ClusterNameSpace + runtime.KeySeparator + instance.Metadata.Key.ClusterName
+			info.Namespace = instance.Metadata.Key.Namespace
+			if len(priorityLabel) > 0 && instance.CalculatedLabels != nil {
+				if priority, err := strconv.Atoi(instance.CalculatedLabels.Labels[priorityLabel]); err == nil {
+					info.Priority = priority
+				}
+			}
+		}
+
+		for _, act := range node.Actions {
+			switch act.(type) {
+			case *component.CreateAction:
+				info.Creates = true
+			case *component.DeleteAction, *component.OrphanAction:
+				info.Deletes = true
+			}
+		}
+
+		result[key] = info
+	}
+
+	return func(key string) *action.NodeInfo {
+		return result[key]
+	}
+}
@@ -0,0 +1,183 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/runtime"
+)
+
+// Audit actions, which get recorded into the audit log
+const (
+	AuditActionLogin                = "login"
+	AuditActionPolicyUpdate         = "policy-update"
+	AuditActionPolicyDelete         = "policy-delete"
+	AuditActionStateReset           = "state-reset"
+	AuditActionRevisionApprove      = "revision-approve"
+	AuditActionRevisionReject       = "revision-reject"
+	AuditActionRevisionCancel       = "revision-cancel"
+	AuditActionServiceAccountCreate = "serviceaccount-create"
+	AuditActionServiceAccountDelete = "serviceaccount-delete"
+	AuditActionAPITokenCreate       = "token-create"
+	AuditActionAPITokenRevoke       = "token-revoke"
+	AuditActionSecretSet            = "secret-set"
+	AuditActionSecretDelete         = "secret-delete"
+	AuditActionSCIMUpdate           = "scim-update"
+	AuditActionSCIMDelete           = "scim-delete"
+	AuditActionImpersonate          = "impersonate"
+	AuditActionEnforce              = "enforce"
+	AuditActionClaimExpire          = "claim-expire"
+)
+
+// TypeAuditEntry is TypeInfo for AuditEntry
+var TypeAuditEntry = &runtime.TypeInfo{
+	Kind:        "audit-entry",
+	Storable:    true,
+	Versioned:   false,
+	Constructor: func() runtime.Object { return &AuditEntry{} },
+}
+
+// AuditEntry is a single record of the audit log. Audit log is append-only, every API call changing the state of Aptomi
+// and every enforcement action performed by Aptomi server gets recorded into it
+type AuditEntry struct {
+	runtime.TypeKind `yaml:",inline"`
+
+	// ID is a unique ID of the entry. IDs are ordered by the time entries were created at
+	ID string
+
+	// Time is when the action was performed
+	Time time.Time
+
+	// Action is what has been done (e.g. policy-update)
+	Action string
+
+	// User is the name of the user, on behalf of which the action has been performed
+	User string
+
+	// Impersonated is the name of the user, which has been impersonated by the domain admin making the request
+	Impersonated string `yaml:",omitempty"`
+
+	// SourceIP is the address the request came from. It's empty for actions performed by Aptomi server itself
+	SourceIP string `yaml:",omitempty"`
+
+	// Request is the API request (method and path), which triggered the action
+	Request string `yaml:",omitempty"`
+
+	// Objects is the list of objects affected by the action (e.g. policy objects in form of namespace/kind/name)
+	Objects []string `yaml:",omitempty"`
+
+	// PolicyGen is the policy generation resulting from the action
+	PolicyGen runtime.Generation `yaml:",omitempty"`
+
+	// RevisionGen is the revision generation resulting from the action
+	RevisionGen runtime.Generation `yaml:",omitempty"`
+
+	// Success is true if the action has been performed successfully
+	Success bool
+
+	// Error is the error message, if action failed
+	Error string `yaml:",omitempty"`
+}
+
+// NewAuditEntry creates a new successful audit entry for a given action performed by a given user
+func NewAuditEntry(action string, user string) *AuditEntry {
+	now := time.Now()
+	suffix, err := randomHex(4)
+	if err != nil {
+		panic(err)
+	}
+
+	return &AuditEntry{
+		TypeKind: TypeAuditEntry.GetTypeKind(),
+		ID:       AuditEntryIDPrefix(now) + "-" + suffix,
+		Time:     now,
+		Action:   action,
+		User:     user,
+		Success:  true,
+	}
+}
+
+// GetName returns AuditEntry name
+func (entry *AuditEntry) GetName() string {
+	return entry.ID
+}
+
+// GetNamespace returns AuditEntry namespace
+func (entry *AuditEntry) GetNamespace() string {
+	return runtime.SystemNS
+}
+
+// Fail marks audit entry as failed with a given error
+func (entry *AuditEntry) Fail(err interface{}) {
+	entry.Success = false
+	entry.Error = fmt.Sprintf("%v", err)
+}
+
+// GetDefaultColumns returns default set of columns to be displayed
+func (entry *AuditEntry) GetDefaultColumns() []string {
+	return []string{"Time", "User", "Source IP", "Action", "Policy", "Revision", "Result"}
+}
+
+// AsColumns returns AuditEntry representation as columns
+func (entry *AuditEntry) AsColumns() map[string]string {
+	result := "success"
+	if !entry.Success {
+		result = "failed: " + entry.Error
+	}
+
+	gen := func(gen runtime.Generation) string {
+		if gen == 0 || gen == runtime.MaxGeneration {
+			return ""
+		}
+		return gen.String()
+	}
+
+	return map[string]string{
+		"ID":           entry.ID,
+		"Time":         entry.Time.Format(time.RFC3339),
+		"User":         entry.User,
+		"Impersonated": entry.Impersonated,
+		"Source IP":    entry.SourceIP,
+		"Action":       entry.Action,
+		"Request":      entry.Request,
+		"Objects":      strings.Join(entry.Objects, ","),
+		"Policy":       gen(entry.PolicyGen),
+		"Revision":     gen(entry.RevisionGen),
+		"Result":       result,
+	}
+}
+
+// AuditEntryIDPrefix returns the prefix of IDs of audit entries created at a given time. As IDs are ordered by time,
+// it allows to look up entries within a given time range without loading the whole audit log
+func AuditEntryIDPrefix(t time.Time) string {
+	return fmt.Sprintf("%019d", t.UnixNano())
+}
+
+// AuditFilter defines which audit entries should be returned. Empty fields match any entry. If Limit is set, only the
+// given number of the most recent matching entries is returned. User matches both the user who performed the action
+// and the user who has been impersonated
+type AuditFilter struct {
+	User   string
+	Action string
+	Since  time.Time
+	Until  time.Time
+	Limit  int
+}
+
+// Matches returns true if a given audit entry matches the filter
+func (filter *AuditFilter) Matches(entry *AuditEntry) bool {
+	if len(filter.User) > 0 && !strings.EqualFold(filter.User, entry.User) && !strings.EqualFold(filter.User, entry.Impersonated) {
+		return false
+	}
+	if len(filter.Action) > 0 && filter.Action != entry.Action {
+		return false
+	}
+	if !filter.Since.IsZero() && entry.Time.Before(filter.Since) {
+		return false
+	}
+	if !filter.Until.IsZero() && entry.Time.After(filter.Until) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,41 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditFilter(t *testing.T) {
+	entry := NewAuditEntry(AuditActionPolicyUpdate, "Alice")
+	assert.True(t, entry.Success, "New audit entry should be successful")
+	assert.True(t, NewAuditEntry(AuditActionLogin, "Bob").ID > entry.ID, "Audit entry IDs should be ordered by time")
+
+	testCases := []struct {
+		filter   *AuditFilter
+		expected bool
+	}{
+		{&AuditFilter{}, true},
+		{&AuditFilter{User: "alice"}, true},
+		{&AuditFilter{User: "bob"}, false},
+		{&AuditFilter{Action: AuditActionPolicyUpdate}, true},
+		{&AuditFilter{Action: AuditActionPolicyDelete}, false},
+		{&AuditFilter{Since: entry.Time.Add(-time.Minute), Until: entry.Time.Add(time.Minute)}, true},
+		{&AuditFilter{Since: entry.Time.Add(time.Minute)}, false},
+		{&AuditFilter{Until: entry.Time.Add(-time.Minute)}, false},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, tc.filter.Matches(entry), "Audit filter %+v should work correctly", tc.filter)
+	}
+
+	impersonated := NewAuditEntry(AuditActionImpersonate, "Alice")
+	impersonated.Impersonated = "Bob"
+	assert.True(t, (&AuditFilter{User: "alice"}).Matches(impersonated), "Audit filter should match user, who impersonated another one")
+	assert.True(t, (&AuditFilter{User: "bob"}).Matches(impersonated), "Audit filter should match impersonated user")
+	assert.False(t, (&AuditFilter{User: "carol"}).Matches(impersonated), "Audit filter should not match other users")
+
+	entry.Fail("error")
+	assert.False(t, entry.Success, "Failed audit entry should not be successful")
+	assert.Equal(t, "error", entry.Error, "Failed audit entry should have error message")
+}
@@ -1,9 +1,14 @@
 package diff
 
 import (
+	"sort"
+	"strings"
+
 	"github.com/Aptomi/aptomi/pkg/engine/apply/action"
 	"github.com/Aptomi/aptomi/pkg/engine/apply/action/component"
 	"github.com/Aptomi/aptomi/pkg/engine/resolve"
+	"github.com/Aptomi/aptomi/pkg/external/secrets"
+	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/Aptomi/aptomi/pkg/util"
 )
 
@@ -17,6 +22,10 @@ type PolicyResolutionDiff struct {
 
 	// Plan is a plan of actions to transform Prev to Next
 	ActionPlan *action.Plan
+
+	// replacements is a map from component instance key to the list of keys of component instances it replaces,
+	// which have to be destroyed only after it has been created and became ready
+	replacements map[string][]string
 }
 
 // NewPolicyResolutionDiff calculates difference between prev and next policy resolution structs (actual and desired states).
@@ -46,6 +55,9 @@ func (diff *PolicyResolutionDiff) compareAndProduceActions() {
 		allCompInstances[keyNext] = true
 	}
 
+	// Find component instances which replace other instances and have to be created before those get destroyed
+	diff.replacements = diff.findReplacements()
+
 	// Build a flat list of actions for every component instance
 	for key := range allCompInstances {
 		diff.buildActions(key)
@@ -64,6 +76,111 @@ func (diff *PolicyResolutionDiff) compareAndProduceActions() {
 			diff.ActionPlan.GetActionGraphNode(key).AddBefore(diff.ActionPlan.GetActionGraphNode(keyOut))
 		}
 	}
+
+	// Make sure replaced component instances get destroyed only after their replacements are ready
+	for keyNext, keysPrev := range diff.replacements {
+		for _, keyPrev := range keysPrev {
+			diff.ActionPlan.GetActionGraphNode(keyPrev).AddBefore(diff.ActionPlan.GetActionGraphNode(keyNext))
+		}
+	}
+
+	// Split updates of components with rollout strategies into batches
+	diff.buildRollouts()
+}
+
+// Finds component instances with create-before-destroy behavior, which replace component instances getting destroyed.
+// Instance is considered a replacement if it only differs from the destroyed one by allocation keys (i.e. it's the
+// same component of the same bundle in the same cluster, allocated within the same service context)
+func (diff *PolicyResolutionDiff) findReplacements() map[string][]string {
+	// group destroyed component instances by their key without allocation keys
+	destroyed := make(map[string][]string)
+	for key, prevInstance := range diff.Prev.ComponentInstanceMap {
+		nextInstance := diff.Next.ComponentInstanceMap[key]
+		if len(prevInstance.ClaimKeys) > 0 && (nextInstance == nil || len(nextInstance.ClaimKeys) <= 0) {
+			id := getReplacementID(prevInstance.Metadata.Key)
+			destroyed[id] = append(destroyed[id], key)
+		}
+	}
+
+	// find component instances, which are going to replace them
+	candidates := make(map[string][]string)
+	for key, nextInstance := range diff.Next.ComponentInstanceMap {
+		if !nextInstance.CreateBeforeDestroy || len(nextInstance.ClaimKeys) <= 0 {
+			continue
+		}
+		id := getReplacementID(nextInstance.Metadata.Key)
+		if len(destroyed[id]) > 0 {
+			candidates[id] = append(candidates[id], key)
+		}
+	}
+
+	// replacement is only unambiguous when there is a single instance to replace destroyed ones with
+	result := make(map[string][]string)
+	for id, keys := range candidates {
+		if len(keys) != 1 {
+			continue
+		}
+		keysPrev := destroyed[id]
+		sort.Strings(keysPrev)
+		result[keys[0]] = keysPrev
+	}
+	return result
+}
+
+// Returns component instance key without allocation keys
+func getReplacementID(cik *resolve.ComponentInstanceKey) string {
+	return strings.Join([]string{cik.ClusterNameSpace, cik.ClusterName, cik.TargetSuffix, cik.Namespace, cik.ServiceName, cik.ContextName, cik.BundleName, cik.ComponentName}, "#")
+}
+
+// Groups updated instances of the same code component (e.g. running in different clusters) and arranges their
+// updates into batches according to the rollout strategy
+func (diff *PolicyResolutionDiff) buildRollouts() {
+	groups := make(map[string][]string)
+	for key, node := range diff.ActionPlan.NodeMap {
+		nextInstance := diff.Next.ComponentInstanceMap[key]
+		if nextInstance == nil || !nextInstance.IsCode || nextInstance.Rollout == nil || !hasUpdateAction(node) {
+			continue
+		}
+		cik := nextInstance.Metadata.Key
+		name := strings.Join([]string{cik.Namespace, cik.ServiceName, cik.BundleName, cik.ComponentName}, "/")
+		groups[name] = append(groups[name], key)
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		keys := groups[name]
+		sort.Strings(keys)
+
+		// all instances in the group have the same rollout strategy, since they belong to the same service and bundle
+		rollout := diff.Next.ComponentInstanceMap[keys[0]].Rollout
+		batches := [][]string{}
+		idx := 0
+		for _, size := range rollout.GetBatchSizes(len(keys)) {
+			batches = append(batches, keys[idx:idx+size])
+			idx += size
+		}
+
+		diff.ActionPlan.AddRollout(&action.Rollout{
+			Name:     name,
+			Strategy: rollout.Strategy,
+			Batches:  batches,
+		})
+	}
+}
+
+// Returns true if a given action graph node contains an update action
+func hasUpdateAction(node *action.GraphNode) bool {
+	for _, act := range node.Actions {
+		if _, ok := act.(*component.UpdateAction); ok {
+			return true
+		}
+	}
+	return false
 }
 
 // Traverse a graph for a given component instance
@@ -89,6 +206,22 @@ func (diff *PolicyResolutionDiff) buildActions(key string) { // nolint: gocyclo
 		First of all, let's see if a component needs to be destructed. If so, destruct it and don't proceed to any further actions.
 	*/
 
+	// See if a component needs to be destructed, but it's protected from deletion. If so, leave it as is (with all claims
+	// attached), so its deletion gets blocked until the protection is removed
+	destruct := len(claimKeysPrev) > 0 && len(claimKeysNext) <= 0
+	deletionPolicy := lang.DeletionPolicyDelete
+	if destruct {
+		deletionPolicy = diff.Next.GetDeletionPolicy(prevInstance)
+	}
+	if destruct && deletionPolicy == lang.DeletionPolicyProtect {
+		// protection gets recorded in the actual state, so it's still honored once the bundle gets removed as well
+		if prevInstance.DeletionPolicy != deletionPolicy {
+			node.AddAction(component.NewSetDeletionPolicyAction(key, deletionPolicy), diff.Prev, true)
+		}
+		node.AddAction(component.NewBlockedDeleteAction(key), diff.Prev, true)
+		return // exit right away
+	}
+
 	// See if a claim needs to be detached from a component
 	for claimKey := range claimKeysPrev {
 		if _, found := claimKeysNext[claimKey]; !found {
@@ -96,9 +229,13 @@ func (diff *PolicyResolutionDiff) buildActions(key string) { // nolint: gocyclo
 		}
 	}
 
-	// See if a component needs to be destructed
-	if len(claimKeysPrev) > 0 && len(claimKeysNext) <= 0 {
-		node.AddAction(component.NewDeleteAction(key, prevInstance.CalculatedCodeParams), diff.Prev, true)
+	// See if a component needs to be destructed or orphaned (i.e. left running in the cloud)
+	if destruct {
+		if deletionPolicy == lang.DeletionPolicyOrphan {
+			node.AddAction(component.NewOrphanAction(key), diff.Prev, true)
+		} else {
+			node.AddAction(component.NewDeleteAction(key, prevInstance.CalculatedCodeParams), diff.Prev, true)
+		}
 		return // exit right away
 	}
 
@@ -109,6 +246,12 @@ func (diff *PolicyResolutionDiff) buildActions(key string) { // nolint: gocyclo
 	// See if it's a bundle or component
 	isCodeComponent := (prevInstance != nil && prevInstance.IsCode) || (nextInstance != nil && nextInstance.IsCode)
 
+	// See if deletion policy of a component has changed, so it gets recorded in the actual state. Otherwise it won't be
+	// honored once the component gets removed from the policy together with its claims
+	if len(claimKeysPrev) > 0 && len(claimKeysNext) > 0 && prevInstance.DeletionPolicy != nextInstance.DeletionPolicy {
+		node.AddAction(component.NewSetDeletionPolicyAction(key, nextInstance.DeletionPolicy), diff.Prev, true)
+	}
+
 	// See if a component needs to be instantiated
 	if len(claimKeysPrev) <= 0 && len(claimKeysNext) > 0 {
 		node.AddAction(component.NewCreateAction(key, nextInstance.CalculatedCodeParams), diff.Prev, true)
@@ -117,7 +260,8 @@ func (diff *PolicyResolutionDiff) buildActions(key string) { // nolint: gocyclo
 	// See if a component needs to be updated
 	if isCodeComponent && len(claimKeysPrev) > 0 && len(claimKeysNext) > 0 {
 		sameParams := prevInstance.CalculatedCodeParams.DeepEqual(nextInstance.CalculatedCodeParams)
-		if !sameParams {
+		sameSecrets := secrets.SameReferences(prevInstance.SecretRefs, nextInstance.SecretRefs)
+		if !sameParams || !sameSecrets {
 			node.AddAction(component.NewUpdateAction(key, prevInstance.CalculatedCodeParams, nextInstance.CalculatedCodeParams), diff.Prev, true)
 
 			// indicate that a parent bundle component instance gets updated as well
@@ -129,6 +273,11 @@ func (diff *PolicyResolutionDiff) buildActions(key string) { // nolint: gocyclo
 		}
 	}
 
+	// See if a component replaces other instances, so it has to become ready before they get destroyed
+	if keysPrev, replaces := diff.replacements[key]; replaces && isCodeComponent {
+		node.AddAction(component.NewWaitReadyAction(key, keysPrev), diff.Prev, true)
+	}
+
 	// See if a claim needs to be attached to a component
 	for claimKey, depth := range claimKeysNext {
 		if _, found := claimKeysPrev[claimKey]; !found {
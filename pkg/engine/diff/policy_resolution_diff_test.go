@@ -2,9 +2,11 @@
 package diff
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
+	"github.com/Aptomi/aptomi/pkg/engine/actual"
 	"github.com/Aptomi/aptomi/pkg/engine/apply/action"
 	"github.com/Aptomi/aptomi/pkg/engine/apply/action/component"
 	"github.com/Aptomi/aptomi/pkg/engine/resolve"
@@ -102,6 +104,205 @@ func TestDiffComponentWithBundleSharing(t *testing.T) {
 	verifyDiff(t, diff, 7, 0, 0, 9, 0)
 }
 
+func TestDiffComponentUpdateWithRollout(t *testing.T) {
+	b := makePolicyBuilder()
+	service := b.Policy().GetObjectsByKind(lang.TypeService.Kind)[0].(*lang.Service)
+
+	// one component instance per claim, all of them rolled out using canary strategy
+	service.Contexts[0].Allocation.Keys = []string{"{{ .Claim.ID }}"}
+	service.Rollout = &lang.Rollout{Strategy: lang.RolloutStrategyCanary, BatchPercent: 50}
+	claims := []*lang.Claim{}
+	for i := 0; i < 4; i++ {
+		claim := b.AddClaim(b.AddUser(), service)
+		claim.Labels["param"] = "value1"
+		claims = append(claims, claim)
+	}
+	resolvedPrev := resolvePolicy(t, b)
+
+	// update all claims, so all component instances get updated
+	for _, claim := range claims {
+		claim.Labels["param"] = "value2"
+	}
+	resolvedNext := resolvePolicy(t, b)
+
+	// all code component updates should be arranged into batches: canary (1), then 50% (2), then the rest (1)
+	diff := NewPolicyResolutionDiff(resolvedNext, resolvedPrev)
+	if !assert.Equal(t, 1, len(diff.ActionPlan.Rollouts), "Diff should contain a rollout") {
+		return
+	}
+	rollout := diff.ActionPlan.Rollouts[0]
+	assert.Equal(t, lang.RolloutStrategyCanary, rollout.Strategy, "Rollout strategy should be correct")
+	batchSizes := []int{}
+	for _, batch := range rollout.Batches {
+		batchSizes = append(batchSizes, len(batch))
+	}
+	assert.Equal(t, []int{1, 2, 1}, batchSizes, "Rollout batches should be correct")
+
+	// updates should be applied in batch order
+	batchOf := make(map[string]int)
+	for i, batch := range rollout.Batches {
+		for _, key := range batch {
+			batchOf[key] = i
+		}
+	}
+	lastBatch := 0
+	result := diff.ActionPlan.Apply(context.Background(), action.WrapSequential(func(ctx context.Context, act action.Interface) error {
+		if update, ok := act.(*component.UpdateAction); ok {
+			if batch, found := batchOf[update.ComponentKey]; found {
+				assert.True(t, batch >= lastBatch, "Component instance %s from batch %d updated after batch %d", update.ComponentKey, batch, lastBatch)
+				lastBatch = batch
+			}
+		}
+		return nil
+	}), nil, action.NewApplyResultUpdaterImpl())
+	assert.Equal(t, &action.RolloutResult{Strategy: lang.RolloutStrategyCanary, Batches: 3, CurrentBatch: 3, Total: 4, Updated: 4}, result.Rollouts[rollout.Name], "Rollout should be completed")
+
+	// if canary fails, the rollout should be halted and the rest of instances should not be updated
+	canaryKey := rollout.Batches[0][0]
+	result = diff.ActionPlan.Apply(context.Background(), action.WrapSequential(func(ctx context.Context, act action.Interface) error {
+		if update, ok := act.(*component.UpdateAction); ok && update.ComponentKey == canaryKey {
+			return fmt.Errorf("canary failed")
+		}
+		return nil
+	}), nil, action.NewApplyResultUpdaterImpl())
+	assert.Equal(t, &action.RolloutResult{Strategy: lang.RolloutStrategyCanary, Batches: 3, CurrentBatch: 3, Total: 4, Failed: 1, Skipped: 3, Halted: true}, result.Rollouts[rollout.Name], "Rollout should be halted")
+}
+
+func TestDiffComponentReplaceCreateBeforeDestroy(t *testing.T) {
+	b := makePolicyBuilder()
+	service := b.Policy().GetObjectsByKind(lang.TypeService.Kind)[0].(*lang.Service)
+
+	// allocate component instances by a claim label, replacing them with create-before-destroy behavior
+	service.Contexts[0].Allocation.Keys = []string{"{{ .Labels.param }}"}
+	service.Contexts[0].Allocation.CreateBeforeDestroy = true
+	claim := b.AddClaim(b.AddUser(), service)
+	claim.Labels["param"] = "value1"
+	resolvedPrev := resolvePolicy(t, b)
+
+	// change allocation key, so all component instances get replaced
+	claim.Labels["param"] = "value2"
+	resolvedNext := resolvePolicy(t, b)
+
+	// new instances should be created and the code one verified ready, while the old ones should be destroyed
+	diff := NewPolicyResolutionDiff(resolvedNext, resolvedPrev)
+	assert.Equal(t, 2, len(diff.replacements), "Both bundle and code component instances should be replaced")
+	order := make(map[string]int)
+	ready := 0
+	_ = diff.ActionPlan.Apply(context.Background(), action.WrapSequential(func(ctx context.Context, act action.Interface) error {
+		if waitReady, ok := act.(*component.WaitReadyAction); ok {
+			ready++
+			assert.Equal(t, 1, len(waitReady.ReplacedKeys), "Code component instance should replace a single instance")
+		}
+		order[act.GetName()] = len(order)
+		return nil
+	}), nil, action.NewApplyResultUpdaterImpl())
+	assert.Equal(t, 1, ready, "Readiness of the new code component instance should be checked once")
+	assert.Equal(t, 8, len(order)-ready, "Number of create, attach, detach and delete actions")
+
+	// old instances should be destroyed only after new ones have been created, became ready and got claims attached
+	for keyNext, keysPrev := range diff.replacements {
+		for _, keyPrev := range keysPrev {
+			for _, actNext := range diff.ActionPlan.NodeMap[keyNext].Actions {
+				for _, actPrev := range diff.ActionPlan.NodeMap[keyPrev].Actions {
+					assert.True(t, order[actNext.GetName()] < order[actPrev.GetName()], "Action %s should be executed before %s", actNext.GetName(), actPrev.GetName())
+				}
+			}
+		}
+	}
+}
+
+func TestDiffComponentDeletionPolicy(t *testing.T) {
+	resolvedPrev := resolvePolicy(t, makePolicyBuilderWithDeletionPolicy(lang.DeletionPolicyProtect, true))
+
+	testCases := []struct {
+		next    *builder.PolicyBuilder
+		blocked int
+		orphan  int
+		delete  int
+	}{
+		// claim removed, protected code component instance should not be deleted, while bundle instance should be
+		{makePolicyBuilderWithDeletionPolicy(lang.DeletionPolicyProtect, false), 1, 0, 1},
+		// claim and bundle removed, protection recorded in component instance should still be honored
+		{builder.NewPolicyBuilder(), 1, 0, 1},
+		// protection removed from the component, so it should be deleted
+		{makePolicyBuilderWithDeletionPolicy("", false), 0, 0, 2},
+		// component instance should be orphaned
+		{makePolicyBuilderWithDeletionPolicy(lang.DeletionPolicyOrphan, false), 0, 1, 1},
+	}
+	for _, tc := range testCases {
+		diff := NewPolicyResolutionDiff(resolvePolicy(t, tc.next), resolvedPrev)
+
+		// blocked deletions should be visible in the plan
+		blocked, orphan, deleted := 0, 0, 0
+		for _, act := range diff.ActionPlan.AsText().Actions {
+			switch act["kind"] {
+			case "action-component-delete-blocked":
+				blocked++
+			case "action-component-orphan":
+				orphan++
+			case "action-component-delete":
+				deleted++
+			}
+		}
+		assert.Equal(t, tc.blocked, blocked, "Number of blocked deletions")
+		assert.Equal(t, tc.orphan, orphan, "Number of orphaned component instances")
+		assert.Equal(t, tc.delete, deleted, "Number of deleted component instances")
+
+		// and blocked deletions should be counted as blocked rather than held when the plan is applied, so they don't
+		// get retried
+		result := diff.ActionPlan.Apply(context.Background(), action.WrapSequential(func(ctx context.Context, act action.Interface) error {
+			assert.NotEqual(t, "action-component-delete-blocked", act.GetKind(), "Blocked deletion should never be applied")
+			return nil
+		}), nil, action.NewApplyResultUpdaterImpl())
+		assert.Equal(t, uint32(tc.blocked), result.Blocked, "Number of blocked actions")
+		assert.Equal(t, uint32(0), result.Held, "Blocked actions should not be counted as held")
+		assert.Equal(t, tc.blocked, len(result.BlockedActions), "Reason should be recorded for every blocked action")
+		assert.Equal(t, result.Total, result.Processed(), "All actions should be processed")
+	}
+}
+
+func TestDiffComponentDeletionPolicyAddedAfterCreate(t *testing.T) {
+	// component instance gets created without protection
+	actualState := resolvePolicy(t, makePolicyBuilderWithDeletionPolicy("", true))
+
+	// protection gets added later, it should be recorded in the actual state
+	resolvedNext := resolvePolicy(t, makePolicyBuilderWithDeletionPolicy(lang.DeletionPolicyProtect, true))
+	diff := NewPolicyResolutionDiff(resolvedNext, actualState)
+	updater := actual.NewNoOpActionStateUpdater(actualState)
+	applied := 0
+	diff.ActionPlan.Apply(context.Background(), action.WrapSequential(func(ctx context.Context, act action.Interface) error {
+		assert.Equal(t, "action-component-deletion-policy", act.GetKind(), "Only deletion policy should be changed")
+		applied++
+		return act.Apply(action.NewContext(ctx, nil, resolvedNext, updater, nil, nil, event.NewLog(logrus.WarnLevel, "test")))
+	}), nil, action.NewApplyResultUpdaterImpl())
+	assert.Equal(t, 1, applied, "Deletion policy should be changed for code component instance")
+
+	// then bundle and claim get removed together, protection recorded in the actual state should be honored
+	diff = NewPolicyResolutionDiff(resolvePolicy(t, builder.NewPolicyBuilder()), updater.GetUpdatedActualState())
+	blocked, deleted := 0, 0
+	for _, act := range diff.ActionPlan.AsText().Actions {
+		switch act["kind"] {
+		case "action-component-delete-blocked":
+			blocked++
+		case "action-component-delete":
+			deleted++
+		}
+	}
+	assert.Equal(t, 1, blocked, "Protected component instance should not be deleted")
+	assert.Equal(t, 1, deleted, "Bundle instance should be deleted")
+
+	// once protection is removed from the bundle, it should be recorded in the actual state as well
+	diff = NewPolicyResolutionDiff(resolvePolicy(t, makePolicyBuilderWithDeletionPolicy(lang.DeletionPolicyDelete, true)), updater.GetUpdatedActualState())
+	found := false
+	for _, act := range diff.ActionPlan.AsText().Actions {
+		if act["kind"] == "action-component-deletion-policy" {
+			assert.Equal(t, lang.DeletionPolicyDelete, act["policy"], "Deletion policy should be changed to delete")
+			found = true
+		}
+	}
+	assert.True(t, found, "Removed protection should be recorded in the actual state")
+}
+
 /*
 	Helpers
 */
@@ -126,6 +327,22 @@ func makePolicyBuilder() *builder.PolicyBuilder {
 	return b
 }
 
+func makePolicyBuilderWithDeletionPolicy(deletionPolicy string, withClaim bool) *builder.PolicyBuilder {
+	b := makePolicyBuilder()
+
+	// set deletion policy on the code component
+	bundle := b.Policy().GetObjectsByKind(lang.TypeBundle.Kind)[0].(*lang.Bundle)
+	bundle.Components[0].DeletionPolicy = deletionPolicy
+
+	// add claim
+	if withClaim {
+		claim := b.AddClaim(b.AddUser(), b.Policy().GetObjectsByKind(lang.TypeService.Kind)[0].(*lang.Service))
+		claim.Labels["param"] = "value1"
+	}
+
+	return b
+}
+
 func makePolicyBuilderWithBundleSharing() *builder.PolicyBuilder {
 	b := builder.NewPolicyBuilder()
 
@@ -180,7 +397,7 @@ func verifyDiff(t *testing.T, diff *PolicyResolutionDiff, componentInstantiate i
 	}{}
 
 	s := []string{}
-	fn := func(act action.Interface) error {
+	fn := func(ctx context.Context, act action.Interface) error {
 		switch act.(type) {
 		case *component.CreateAction:
 			cnt.create++
@@ -201,7 +418,7 @@ func verifyDiff(t *testing.T, diff *PolicyResolutionDiff, componentInstantiate i
 		return nil
 	}
 
-	_ = diff.ActionPlan.Apply(action.WrapSequential(fn), action.NewApplyResultUpdaterImpl())
+	_ = diff.ActionPlan.Apply(context.Background(), action.WrapSequential(fn), nil, action.NewApplyResultUpdaterImpl())
 
 	ok := assert.Equal(t, componentInstantiate, cnt.create, "Diff: component instantiations")
 	ok = ok && assert.Equal(t, componentDestruct, cnt.delete, "Diff: component destructions")
@@ -12,5 +12,11 @@ var (
 		TypeRevision,
 		TypeDesiredState,
 		resolve.TypeComponentInstance,
+		TypeServiceAccount,
+		TypeAPIToken,
+		TypeAuditEntry,
+		TypeSecret,
+		TypeSCIMResource,
+		TypeOIDCUser,
 	})
 )
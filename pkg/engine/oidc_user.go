@@ -0,0 +1,65 @@
+package engine
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+)
+
+// TypeOIDCUser is TypeInfo for OIDCUser
+var TypeOIDCUser = &runtime.TypeInfo{
+	Kind:        "oidc-user",
+	Storable:    true,
+	Versioned:   false,
+	Constructor: func() runtime.Object { return &OIDCUser{} },
+}
+
+// OIDCUser is a user, who has logged in via OpenID Connect provider. User and its labels are mapped from ID token
+// claims on every login and stored, so that claims of the user and tokens issued to the user survive restarts
+type OIDCUser struct {
+	runtime.TypeKind `yaml:",inline"`
+
+	// Name is the name of the user, mapped from the username claim
+	Name string
+
+	// Labels is a set of labels, mapped from ID token claims on the last login
+	Labels map[string]string
+
+	// LastLoginAt is when the user has logged in for the last time
+	LastLoginAt time.Time
+}
+
+// NewOIDCUser creates a new OIDCUser from a user, who has just logged in
+func NewOIDCUser(user *lang.User) *OIDCUser {
+	return &OIDCUser{
+		TypeKind:    TypeOIDCUser.GetTypeKind(),
+		Name:        user.Name,
+		Labels:      user.Labels,
+		LastLoginAt: time.Now(),
+	}
+}
+
+// GetName returns OIDCUser name. User names are case-insensitive, so it's always in lower case
+func (user *OIDCUser) GetName() string {
+	return strings.ToLower(user.Name)
+}
+
+// GetNamespace returns OIDCUser namespace
+func (user *OIDCUser) GetNamespace() string {
+	return runtime.SystemNS
+}
+
+// User returns OIDCUser as a user
+func (user *OIDCUser) User() *lang.User {
+	labels := make(map[string]string)
+	for name, value := range user.Labels {
+		labels[name] = value
+	}
+
+	return &lang.User{
+		Name:   user.Name,
+		Labels: labels,
+	}
+}
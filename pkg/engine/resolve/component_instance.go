@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/Aptomi/aptomi/pkg/errors"
+	"github.com/Aptomi/aptomi/pkg/external/secrets"
 	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/Aptomi/aptomi/pkg/runtime"
 	"github.com/Aptomi/aptomi/pkg/util"
@@ -67,9 +68,29 @@ type ComponentInstance struct {
 	// CalculatedCodeParams is a set of calculated code parameters for the component (non-conflicting over all uses of this component)
 	CalculatedCodeParams util.NestedParameterMap
 
+	// CalculatedHooks is a set of hooks for the component with calculated parameters: phase -> hook. They get recorded
+	// into actual state, so hooks can run for the instance even if the component has been removed from the policy
+	CalculatedHooks map[string]*lang.Hook `yaml:",omitempty"`
+
+	// SecretRefs are references to secrets of claim users, placeholders for which are present in code and hook
+	// params (placeholder -> reference). Only these placeholders get replaced with secret values when code is being
+	// deployed. They get recorded into actual state, so secrets are available when the instance gets deleted
+	SecretRefs map[string]*secrets.Reference `yaml:",omitempty"`
+
 	// DataForPlugins is an additional data recorded for use in plugins
 	DataForPlugins map[string]string
 
+	// Rollout is a strategy of rolling out updates of the component (taken from the service or the bundle)
+	Rollout *lang.Rollout `yaml:",omitempty"`
+
+	// CreateBeforeDestroy means that when this instance replaces another instance of the same component (e.g. when
+	// allocation keys change), it has to be created and become ready before the other instance gets deleted
+	CreateBeforeDestroy bool `yaml:",omitempty"`
+
+	// DeletionPolicy defines what happens with the instance once it's no longer needed (delete, orphan or protect).
+	// It gets recorded into actual state, so it's known even if the component has been removed from the policy
+	DeletionPolicy string `yaml:",omitempty"`
+
 	/*
 		These fields only make sense for the desired state. They will NOT be present in actual state
 	*/
@@ -160,6 +181,40 @@ func (instance *ComponentInstance) addCodeParams(codeParams util.NestedParameter
 	return nil
 }
 
+func (instance *ComponentInstance) addHook(phase string, hook *lang.Hook) error {
+	if instance.CalculatedHooks == nil {
+		instance.CalculatedHooks = make(map[string]*lang.Hook)
+	}
+	existing, ok := instance.CalculatedHooks[phase]
+	if !ok {
+		// Record hook
+		instance.CalculatedHooks[phase] = hook
+	} else if existing.Type != hook.Type || existing.Timeout != hook.Timeout || !existing.Params.DeepEqual(hook.Params) {
+		// Same component instance, different hook parameters
+		return errors.NewErrorWithDetails(
+			fmt.Sprintf("conflicting %s hook parameters for component instance: %s", phase, instance.GetKey()),
+			errors.Details{
+				"hook_params_existing": existing.Params,
+				"hook_params_new":      hook.Params,
+				"diff":                 existing.Params.Diff(hook.Params),
+			},
+		)
+	}
+	return nil
+}
+
+func (instance *ComponentInstance) addSecretRefs(refs map[string]*secrets.Reference) {
+	if len(refs) <= 0 {
+		return
+	}
+	if instance.SecretRefs == nil {
+		instance.SecretRefs = make(map[string]*secrets.Reference)
+	}
+	for placeholder, ref := range refs {
+		instance.SecretRefs[placeholder] = ref
+	}
+}
+
 func (instance *ComponentInstance) addDiscoveryParams(discoveryParams util.NestedParameterMap) error {
 	if len(instance.CalculatedDiscovery) == 0 {
 		// Record discovery parameters
@@ -222,6 +277,15 @@ func (instance *ComponentInstance) appendData(ops *ComponentInstance) {
 		return
 	}
 
+	// Combine hooks
+	for phase, hook := range ops.CalculatedHooks {
+		err = instance.addHook(phase, hook)
+		if err != nil {
+			instance.Error = err
+			return
+		}
+	}
+
 	// Combine code params
 	err = instance.addCodeParams(ops.CalculatedCodeParams)
 	if err != nil {
@@ -229,6 +293,9 @@ func (instance *ComponentInstance) appendData(ops *ComponentInstance) {
 		return
 	}
 
+	// Combine secret references
+	instance.addSecretRefs(ops.SecretRefs)
+
 	// Outgoing graph edges (instance: key -> true) as we are traversing the graph
 	for keyDst := range ops.EdgesOut {
 		instance.addEdgeOut(keyDst)
@@ -238,4 +305,17 @@ func (instance *ComponentInstance) appendData(ops *ComponentInstance) {
 	for k, v := range ops.DataForPlugins {
 		instance.DataForPlugins[k] = v
 	}
+
+	// Rollout strategy
+	if ops.Rollout != nil {
+		instance.Rollout = ops.Rollout
+	}
+
+	// Replacement behavior
+	instance.CreateBeforeDestroy = instance.CreateBeforeDestroy || ops.CreateBeforeDestroy
+
+	// Deletion policy
+	if len(ops.DeletionPolicy) > 0 {
+		instance.DeletionPolicy = ops.DeletionPolicy
+	}
 }
@@ -2,7 +2,9 @@ package resolve
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/Aptomi/aptomi/pkg/external/secrets"
 	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/Aptomi/aptomi/pkg/runtime"
 	"github.com/Aptomi/aptomi/pkg/util"
@@ -14,6 +16,10 @@ import (
 type PolicyResolution struct {
 	// Resolved component instances: componentKey -> componentInstance
 	ComponentInstanceMap map[string]*ComponentInstance
+
+	// Deletion policies of all code components in the policy: 'namespace/bundle/component' -> deletionPolicy. They
+	// determine what happens with component instances, which are no longer needed. Only makes sense for desired state
+	DeletionPolicies map[string]string `yaml:",omitempty"`
 }
 
 // NewPolicyResolution creates new empty PolicyResolution, given a flag indicating whether it's a
@@ -21,6 +27,7 @@ type PolicyResolution struct {
 func NewPolicyResolution() *PolicyResolution {
 	return &PolicyResolution{
 		ComponentInstanceMap: make(map[string]*ComponentInstance),
+		DeletionPolicies:     make(map[string]string),
 	}
 }
 
@@ -47,6 +54,64 @@ func (resolution *PolicyResolution) RecordCodeParams(cik *ComponentInstanceKey,
 	return instance.addCodeParams(codeParams)
 }
 
+// RecordHook stores a hook with calculated params for component instance
+func (resolution *PolicyResolution) RecordHook(cik *ComponentInstanceKey, phase string, hook *lang.Hook) error {
+	return resolution.GetComponentInstanceEntry(cik).addHook(phase, hook)
+}
+
+// RecordSecretRefs stores references to secrets, placeholders for which are present in code and hook params of
+// component instance
+func (resolution *PolicyResolution) RecordSecretRefs(cik *ComponentInstanceKey, refs map[string]*secrets.Reference) {
+	resolution.GetComponentInstanceEntry(cik).addSecretRefs(refs)
+}
+
+// RecordRollout stores rollout strategy for component instance
+func (resolution *PolicyResolution) RecordRollout(cik *ComponentInstanceKey, rollout *lang.Rollout) {
+	resolution.GetComponentInstanceEntry(cik).Rollout = rollout
+}
+
+// RecordCreateBeforeDestroy marks component instance as the one which has to be created before the instance it
+// replaces gets destroyed
+func (resolution *PolicyResolution) RecordCreateBeforeDestroy(cik *ComponentInstanceKey) {
+	resolution.GetComponentInstanceEntry(cik).CreateBeforeDestroy = true
+}
+
+// RecordDeletionPolicy stores deletion policy for component instance
+func (resolution *PolicyResolution) RecordDeletionPolicy(cik *ComponentInstanceKey, deletionPolicy string) {
+	resolution.GetComponentInstanceEntry(cik).DeletionPolicy = deletionPolicy
+}
+
+// RecordDeletionPolicies stores deletion policies of all code components of all bundles in a given policy
+func (resolution *PolicyResolution) RecordDeletionPolicies(policy *lang.Policy) {
+	for _, obj := range policy.GetObjectsByKind(lang.TypeBundle.Kind) {
+		bundle := obj.(*lang.Bundle) // nolint: errcheck
+		for _, component := range bundle.Components {
+			if component.Code != nil {
+				resolution.DeletionPolicies[getDeletionPolicyKey(bundle.Namespace, bundle.Name, component.Name)] = component.GetDeletionPolicy()
+			}
+		}
+	}
+}
+
+// GetDeletionPolicy returns deletion policy for a given component instance, which is no longer needed. It's taken
+// from the component in the resolved policy. If the component is no longer present in the policy, deletion policy
+// recorded in the instance itself is used
+func (resolution *PolicyResolution) GetDeletionPolicy(instance *ComponentInstance) string {
+	cik := instance.Metadata.Key
+	if deletionPolicy, ok := resolution.DeletionPolicies[getDeletionPolicyKey(cik.Namespace, cik.BundleName, cik.ComponentName)]; ok {
+		return deletionPolicy
+	}
+	if len(instance.DeletionPolicy) > 0 {
+		return instance.DeletionPolicy
+	}
+	return lang.DeletionPolicyDelete
+}
+
+// Returns key for the deletion policy of a given bundle component
+func getDeletionPolicyKey(namespace string, bundleName string, componentName string) string {
+	return strings.Join([]string{namespace, bundleName, componentName}, "/")
+}
+
 // RecordDiscoveryParams stores calculated discovery params for component instance
 func (resolution *PolicyResolution) RecordDiscoveryParams(cik *ComponentInstanceKey, discoveryParams util.NestedParameterMap) error {
 	return resolution.GetComponentInstanceEntry(cik).addDiscoveryParams(discoveryParams)
@@ -69,6 +134,9 @@ func (resolution *PolicyResolution) StoreEdge(src *ComponentInstanceKey, dst *Co
 // If there is a conflict (e.g. components have different code parameters), then the corresponding component instances
 // will be marked with errors.
 func (resolution *PolicyResolution) AppendData(ops *PolicyResolution) {
+	for key, deletionPolicy := range ops.DeletionPolicies {
+		resolution.DeletionPolicies[key] = deletionPolicy
+	}
 	for key, instance := range ops.ComponentInstanceMap {
 		// if component doesn't exist, copy it over
 		if _, ok := resolution.ComponentInstanceMap[key]; !ok {
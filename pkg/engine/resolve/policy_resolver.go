@@ -103,6 +103,9 @@ func (resolver *PolicyResolver) ResolveAllClaims() *PolicyResolution {
 	// Wait for all go routines to end
 	wg.Wait()
 
+	// Record deletion policies of all components, so it's known what to do with instances which are no longer needed
+	resolver.resolution.RecordDeletionPolicies(resolver.policy)
+
 	// Once all components are resolved, print information about them into event log
 	for _, instance := range resolver.resolution.ComponentInstanceMap {
 		if instance.Metadata.Key.IsComponent() {
@@ -316,11 +319,17 @@ func (resolver *PolicyResolver) resolveNode(node *resolutionNode) (resolveErr er
 		// Record usage of a given component instance
 		node.logInstanceSuccessfullyResolved(node.componentKey)
 		node.resolution.RecordResolved(node.componentKey, node.claim, node.depth, ruleResult)
+		if node.context.Allocation.CreateBeforeDestroy {
+			node.resolution.RecordCreateBeforeDestroy(node.componentKey)
+		}
 	}
 
 	// Mark note as resolved and record usage of a given bundle instance
 	node.logInstanceSuccessfullyResolved(node.bundleKey)
 	node.resolution.RecordResolved(node.bundleKey, node.claim, node.depth, ruleResult)
+	if node.context.Allocation.CreateBeforeDestroy {
+		node.resolution.RecordCreateBeforeDestroy(node.bundleKey)
+	}
 
 	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/Aptomi/aptomi/pkg/event"
+	"github.com/Aptomi/aptomi/pkg/external/secrets"
 	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/Aptomi/aptomi/pkg/plugin/k8s"
 	"github.com/Aptomi/aptomi/pkg/runtime"
@@ -352,6 +353,39 @@ func (node *resolutionNode) calculateAndStoreCodeParams() error {
 		return node.errorWhenProcessingCodeParams(err)
 	}
 
+	// rollout strategy defined on the service takes precedence over the one defined on the bundle
+	rollout := node.service.Rollout
+	if rollout == nil {
+		rollout = node.bundle.Rollout
+	}
+	if rollout != nil {
+		node.resolution.RecordRollout(node.componentKey, rollout)
+	}
+
+	if len(node.component.DeletionPolicy) > 0 {
+		node.resolution.RecordDeletionPolicy(node.componentKey, node.component.DeletionPolicy)
+	}
+
+	// hook params follow the same syntax as code params
+	allParams := []util.NestedParameterMap{componentCodeParams}
+	for phase, hook := range node.component.Hooks.GetHooksMap() {
+		hookParams, err := util.ProcessParameterTree(hook.Params, node.getContextualDataForCodeDiscoveryTemplate(), node.resolver.templateCache, util.ModeEvaluate)
+		if err != nil {
+			return node.errorWhenProcessingHookParams(phase, err)
+		}
+
+		err = node.resolution.RecordHook(node.componentKey, phase, &lang.Hook{Type: hook.Type, Params: hookParams, Timeout: hook.Timeout})
+		if err != nil {
+			return node.errorWhenProcessingHookParams(phase, err)
+		}
+		allParams = append(allParams, hookParams)
+	}
+
+	// only secrets of the claim user can be referred to from code and hook params
+	externalData := node.resolver.externalData
+	userSecrets := externalData.SecretLoader.LoadSecretsByUserName(node.user.Name)
+	node.resolution.RecordSecretRefs(node.componentKey, secrets.FindReferences(node.user.Name, userSecrets, externalData.SecretFingerprintKey, allParams...))
+
 	return nil
 }
 
@@ -1,6 +1,7 @@
 package resolve
 
 import (
+	"github.com/Aptomi/aptomi/pkg/external/secrets"
 	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/Aptomi/aptomi/pkg/lang/expression"
 	"github.com/Aptomi/aptomi/pkg/lang/template"
@@ -17,7 +18,9 @@ import (
 func (node *resolutionNode) getContextualDataForContextExpression() *expression.Parameters {
 	return expression.NewParams(
 		node.labels.Labels,
-		map[string]interface{}{},
+		map[string]interface{}{
+			lang.UserGroupsParam: node.proxyUserGroups(node.user),
+		},
 	)
 }
 
@@ -26,7 +29,9 @@ func (node *resolutionNode) getContextualDataForContextExpression() *expression.
 func (node *resolutionNode) getContextualDataForComponentCriteria() *expression.Parameters {
 	return expression.NewParams(
 		node.labels.Labels,
-		map[string]interface{}{},
+		map[string]interface{}{
+			lang.UserGroupsParam: node.proxyUserGroups(node.user),
+		},
 	)
 }
 
@@ -40,8 +45,9 @@ func (node *resolutionNode) getContextualDataForRuleExpression() *expression.Par
 	return expression.NewParams(
 		node.labels.Labels,
 		map[string]interface{}{
-			"Bundle": node.proxyBundle(node.bundle),
-			"Claim":  node.proxyClaim(node.claim),
+			"Bundle":             node.proxyBundle(node.bundle),
+			"Claim":              node.proxyClaim(node.claim),
+			lang.UserGroupsParam: node.proxyUserGroups(node.user),
 		},
 	)
 }
@@ -99,17 +105,32 @@ func (node *resolutionNode) proxyBundle(bundle *lang.Bundle) interface{} {
 	}
 }
 
-// How user is visible from the policy language
+// How user is visible from the policy language. Secret values are never exposed directly, only placeholders for
+// them, which get replaced with actual values right before the code gets deployed
 func (node *resolutionNode) proxyUser(user *lang.User) interface{} {
+	secretRefs := make(map[string]string)
+	for name := range node.resolver.externalData.SecretLoader.LoadSecretsByUserName(user.Name) {
+		secretRefs[name] = secrets.Placeholder(user.Name, name)
+	}
 	return struct {
 		Name    interface{}
 		Labels  interface{}
+		Groups  interface{}
 		Secrets interface{}
 	}{
 		Name:    user.Name,
 		Labels:  user.Labels,
-		Secrets: node.resolver.externalData.SecretLoader.LoadSecretsByUserName(user.Name),
+		Groups:  user.Groups,
+		Secrets: secretRefs,
+	}
+}
+
+// How user groups are visible from the policy language (e.g. inGroup(groups, 'developers'))
+func (node *resolutionNode) proxyUserGroups(user *lang.User) interface{} {
+	if user == nil {
+		return []interface{}{}
 	}
+	return user.GroupsParam()
 }
 
 // How claim is visible from the policy language
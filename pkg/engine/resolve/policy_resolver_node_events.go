@@ -63,6 +63,10 @@ func (node *resolutionNode) errorWhenProcessingCodeParams(cause error) error {
 	return fmt.Errorf("error when processing code params for bundle '%s', service '%s', context '%s', component '%s': %s", node.bundle.Name, node.service.Name, node.context.Name, node.component.Name, printCauseDetailsOnDebug(cause, node.eventLog))
 }
 
+func (node *resolutionNode) errorWhenProcessingHookParams(phase string, cause error) error {
+	return fmt.Errorf("error when processing %s hook params for bundle '%s', service '%s', context '%s', component '%s': %s", phase, node.bundle.Name, node.service.Name, node.context.Name, node.component.Name, printCauseDetailsOnDebug(cause, node.eventLog))
+}
+
 func (node *resolutionNode) errorWhenProcessingDiscoveryParams(cause error) error {
 	return fmt.Errorf("error when processing discovery params for bundle '%s', service '%s', context '%s', component '%s': %s", node.bundle.Name, node.service.Name, node.context.Name, node.component.Name, printCauseDetailsOnDebug(cause, node.eventLog))
 }
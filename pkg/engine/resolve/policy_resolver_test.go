@@ -4,8 +4,10 @@ package resolve
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/Aptomi/aptomi/pkg/event"
+	"github.com/Aptomi/aptomi/pkg/external/secrets"
 	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/Aptomi/aptomi/pkg/lang/builder"
 	"github.com/Aptomi/aptomi/pkg/util"
@@ -269,6 +271,108 @@ func TestPolicyResolverCodeAndDiscoveryParams(t *testing.T) {
 	assert.Equal(t, 5, instance2.CalculatedCodeParams.GetNestedMap("nested").GetNestedMap("param")["nameInt"], "Code parameter should be calculated correctly (int)")
 }
 
+func TestPolicyResolverHookParams(t *testing.T) {
+	b := builder.NewPolicyBuilder()
+
+	// create a bundle with a component, which has hooks with templated parameters
+	bundle := b.AddBundle()
+	component := b.CodeComponent(util.NestedParameterMap{"debug": "{{ .Labels.target }}"}, nil)
+	component.Hooks = &lang.Hooks{
+		PreUpdate: &lang.Hook{
+			Type:    "raw",
+			Params:  util.NestedParameterMap{"migrate": "{{ .Labels.version }}", "target": "{{ .Labels.target }}"},
+			Timeout: time.Minute,
+		},
+		PreDelete: &lang.Hook{
+			Type:   "raw",
+			Params: util.NestedParameterMap{"backup": "true"},
+		},
+	}
+	b.AddBundleComponent(bundle, component)
+
+	service := b.AddService(bundle, b.CriteriaTrue())
+	cluster := b.AddCluster()
+	b.AddRule(b.CriteriaTrue(), b.RuleActions(lang.NewLabelOperationsSetSingleLabel(lang.LabelTarget, cluster.Name)))
+
+	claim := b.AddClaim(b.AddUser(), service)
+	claim.Labels["version"] = "42"
+
+	// policy should be resolved successfully
+	resolution := resolvePolicy(t, b, []verifyClaim{
+		{claim: claim, resolved: true},
+	})
+
+	// check that hook parameters got calculated
+	instance := getInstanceByParams(t, cluster, "k8ns", service, service.Contexts[0], nil, bundle, component, resolution)
+	assert.Equal(t, 2, len(instance.CalculatedHooks), "Only defined hooks should be calculated")
+	assert.Equal(t, "raw", instance.CalculatedHooks[lang.HookPreUpdate].Type, "Hook type should be recorded")
+	assert.Equal(t, time.Minute, instance.CalculatedHooks[lang.HookPreUpdate].Timeout, "Hook timeout should be recorded")
+	assert.Equal(t, "42", instance.CalculatedHooks[lang.HookPreUpdate].Params["migrate"], "Hook parameter should be calculated correctly")
+	assert.Equal(t, cluster.Name, instance.CalculatedHooks[lang.HookPreUpdate].Params["target"], "Hook parameter should be calculated correctly")
+	assert.Equal(t, "true", instance.CalculatedHooks[lang.HookPreDelete].Params["backup"], "Hook parameter should be calculated correctly")
+	assert.Equal(t, "{{ .Labels.version }}", component.Hooks.PreUpdate.Params["migrate"], "Hook parameters in policy should stay intact")
+}
+
+func TestPolicyResolverSecretRefs(t *testing.T) {
+	b := builder.NewPolicyBuilder()
+
+	// create a bundle with a component, which refers to a secret of the claim user and to a claim label
+	bundle := b.AddBundle()
+	component := b.CodeComponent(util.NestedParameterMap{"token": "{{ .User.Secrets.token }}", "debug": "{{ .Labels.debug }}"}, nil)
+	b.AddBundleComponent(bundle, component)
+
+	service := b.AddService(bundle, b.CriteriaTrue())
+	cluster := b.AddCluster()
+	b.AddRule(b.CriteriaTrue(), b.RuleActions(lang.NewLabelOperationsSetSingleLabel(lang.LabelTarget, cluster.Name)))
+
+	// claim label tries to get secret of another user into code params
+	admin := b.AddUser()
+	b.AddSecret(admin, "password", "adminpassword")
+	user := b.AddUser()
+	b.AddSecret(user, "token", "usertoken")
+	claim := b.AddClaim(user, service)
+	claim.Labels["debug"] = secrets.Placeholder(admin.Name, "password")
+
+	// policy should be resolved successfully
+	resolution := resolvePolicy(t, b, []verifyClaim{
+		{claim: claim, resolved: true},
+	})
+
+	// only secret of the claim user should be referenced
+	instance := getInstanceByParams(t, cluster, "k8ns", service, service.Contexts[0], nil, bundle, component, resolution)
+	assert.Equal(t, secrets.Placeholder(user.Name, "token"), instance.CalculatedCodeParams["token"], "Secret placeholder should be exposed to templates")
+	assert.Equal(t, map[string]*secrets.Reference{
+		secrets.Placeholder(user.Name, "token"): {User: user.Name, Name: "token"},
+	}, instance.SecretRefs, "Only secrets of the claim user should be referenced")
+}
+
+func TestPolicyResolverConflictingHookParams(t *testing.T) {
+	b := builder.NewPolicyBuilder()
+
+	// create a bundle which uses label in its hook parameters
+	bundle := b.AddBundle()
+	component := b.CodeComponent(nil, nil)
+	component.Hooks = &lang.Hooks{
+		PreCreate: &lang.Hook{Type: "raw", Params: util.NestedParameterMap{"address": "{{ .Labels.deplabel }}"}},
+	}
+	b.AddBundleComponent(bundle, component)
+	service := b.AddService(bundle, b.CriteriaTrue())
+	cluster := b.AddCluster()
+	b.AddRule(b.CriteriaTrue(), b.RuleActions(lang.NewLabelOperationsSetSingleLabel(lang.LabelTarget, cluster.Name)))
+
+	// add claims which feed conflicting labels into a given component
+	c1 := b.AddClaim(b.AddUser(), service)
+	c1.Labels["deplabel"] = "1"
+	c2 := b.AddClaim(b.AddUser(), service)
+	c2.Labels["deplabel"] = "2"
+
+	// policy resolution with conflicting hook parameters should result in an error
+	resolvePolicy(t, b, []verifyClaim{
+		{claim: c1, resolved: false, logMessage: "conflicting pre-create hook parameters"},
+		{claim: c2, resolved: false, logMessage: "conflicting pre-create hook parameters"},
+	})
+}
+
 func TestPolicyResolverClaimWithNonExistingUser(t *testing.T) {
 	b := builder.NewPolicyBuilder()
 	bundle := b.AddBundle()
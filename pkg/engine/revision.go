@@ -17,6 +17,16 @@ const (
 	RevisionStatusCompleted = "completed"
 	// RevisionStatusError represents Revision status when a critical error happened (we should rarely see those)
 	RevisionStatusError = "error"
+	// RevisionStatusFailed represents Revision status with apply finished, when some actions kept failing after all
+	// retry attempts had been exhausted. Such revision will not be retried automatically
+	RevisionStatusFailed = "failed"
+	// RevisionStatusCancelled represents Revision status when it has been cancelled by user before or while being applied.
+	// Such revision will not be retried automatically
+	RevisionStatusCancelled = "cancelled"
+	// RevisionStatusPendingApproval represents Revision status when its action plan has to be manually approved before apply
+	RevisionStatusPendingApproval = "pendingapproval"
+	// RevisionStatusRejected represents Revision status when its action plan has been manually rejected and will not be applied
+	RevisionStatusRejected = "rejected"
 )
 
 // RevisionKey is the default key for the Revision object (there is only one Revision exists but with multiple generations)
@@ -55,6 +65,63 @@ type Revision struct {
 
 	// TODO: do not store apply log in revision
 	ApplyLog []*event.APIEvent
+
+	// Approval contains information about manual approval of the revision, if it's required
+	Approval *RevisionApproval `yaml:",omitempty"`
+
+	// CancelledBy is the name of the user who cancelled the revision
+	CancelledBy string `yaml:",omitempty"`
+
+	// CancelledAt is when the revision was cancelled
+	CancelledAt time.Time `yaml:",omitempty"`
+}
+
+// IsCancellable returns true if revision can be cancelled (i.e. it hasn't been fully processed yet or it will be
+// retried)
+func (revision *Revision) IsCancellable() bool {
+	switch revision.Status {
+	case RevisionStatusWaiting, RevisionStatusInProgress, RevisionStatusPendingApproval:
+		return true
+	}
+	return revision.NeedsRetry()
+}
+
+// NeedsRetry returns true if revision has to be applied again. It's the case when a critical error happened, or when
+// revision completed, but some actions failed, timed out or have been held. Revisions in failed status (some actions
+// kept failing after all retries) are not retried, neither are blocked actions (e.g. deletions of protected component
+// instances), as they will never be applied
+func (revision *Revision) NeedsRetry() bool {
+	if revision.Status == RevisionStatusError {
+		return true
+	}
+	return revision.Status == RevisionStatusCompleted && revision.Result != nil && (revision.Result.Failed > 0 || revision.Result.TimedOut > 0 || revision.Result.Held > 0)
+}
+
+// RevisionApproval represents a manual approval of the revision action plan. It gets required when the action plan
+// affects component instances in namespaces/clusters, which are protected by approval rules
+type RevisionApproval struct {
+	// Namespaces is a list of protected namespaces affected by the revision
+	Namespaces []string
+
+	// Clusters is a list of protected clusters affected by the revision
+	Clusters []string
+
+	// RequestedAt is when the approval was requested
+	RequestedAt time.Time
+
+	// Approved is true if revision has been approved
+	Approved bool
+
+	// DecidedBy is the name of the user who approved or rejected the revision
+	DecidedBy string
+
+	// DecidedAt is when the revision was approved or rejected
+	DecidedAt time.Time
+}
+
+// IsApproved returns true if revision has been manually approved
+func (revision *Revision) IsApproved() bool {
+	return revision.Approval != nil && revision.Approval.Approved
 }
 
 // NewRevision creates a new revision
@@ -0,0 +1,22 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/Aptomi/aptomi/pkg/engine/apply/action"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevisionIsCancellable(t *testing.T) {
+	assert.True(t, (&Revision{Status: RevisionStatusWaiting}).IsCancellable(), "Waiting revision should be cancellable")
+	assert.True(t, (&Revision{Status: RevisionStatusPendingApproval}).IsCancellable(), "Revision pending approval should be cancellable")
+	assert.False(t, (&Revision{Status: RevisionStatusCancelled}).IsCancellable(), "Cancelled revision should not be cancellable")
+	assert.False(t, (&Revision{Status: RevisionStatusFailed, Result: &action.ApplyResult{Failed: 1}}).IsCancellable(), "Failed revision should not be cancellable")
+
+	// completed revisions can be cancelled only if they are going to be retried
+	assert.False(t, (&Revision{Status: RevisionStatusCompleted, Result: &action.ApplyResult{Success: 1}}).IsCancellable(), "Successfully completed revision should not be cancellable")
+	assert.False(t, (&Revision{Status: RevisionStatusCompleted, Result: &action.ApplyResult{Blocked: 1}}).IsCancellable(), "Completed revision with blocked actions should not be cancellable")
+	assert.True(t, (&Revision{Status: RevisionStatusCompleted, Result: &action.ApplyResult{Failed: 1}}).IsCancellable(), "Completed revision with failed actions should be cancellable")
+	assert.True(t, (&Revision{Status: RevisionStatusCompleted, Result: &action.ApplyResult{TimedOut: 1}}).IsCancellable(), "Completed revision with timed out actions should be cancellable")
+	assert.True(t, (&Revision{Status: RevisionStatusCompleted, Result: &action.ApplyResult{Held: 1}}).IsCancellable(), "Completed revision with held actions should be cancellable")
+}
@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/runtime"
+)
+
+// TypeSCIMResource is TypeInfo for SCIMResource
+var TypeSCIMResource = &runtime.TypeInfo{
+	Kind:        "scim-resource",
+	Storable:    true,
+	Versioned:   false,
+	Constructor: func() runtime.Object { return &SCIMResource{} },
+}
+
+// SCIMResource is a user or a group pushed into Aptomi by identity provider via SCIM endpoint. Resource attributes
+// are stored as JSON exactly as they have been received, and get mapped into users when users are loaded
+type SCIMResource struct {
+	runtime.TypeKind `yaml:",inline"`
+
+	// ID is a unique ID of the resource assigned by Aptomi
+	ID string
+
+	// ResourceType is either User or Group
+	ResourceType string
+
+	// Name is userName of the user or displayName of the group, it's unique for the resource type
+	Name string
+
+	// Data is JSON with resource attributes
+	Data string
+
+	// CreatedAt is when the resource was created
+	CreatedAt time.Time
+
+	// UpdatedAt is when the resource was last changed
+	UpdatedAt time.Time
+}
+
+// NewSCIMResource creates a new SCIM resource with a random ID
+func NewSCIMResource(resourceType string, name string, data string) (*SCIMResource, error) {
+	id, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return &SCIMResource{
+		TypeKind:     TypeSCIMResource.GetTypeKind(),
+		ID:           id,
+		ResourceType: resourceType,
+		Name:         name,
+		Data:         data,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}, nil
+}
+
+// GetName returns SCIMResource name, which consists of the resource type and ID
+func (resource *SCIMResource) GetName() string {
+	return SCIMResourceName(resource.ResourceType, resource.ID)
+}
+
+// GetNamespace returns SCIMResource namespace
+func (resource *SCIMResource) GetNamespace() string {
+	return runtime.SystemNS
+}
+
+// SCIMResourceName returns name of the SCIM resource object for a given resource type and ID
+func SCIMResourceName(resourceType string, id string) string {
+	return resourceType + runtime.KeySeparator + id
+}
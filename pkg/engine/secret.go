@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/external/secrets"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+)
+
+// TypeSecret is TypeInfo for Secret
+var TypeSecret = &runtime.TypeInfo{
+	Kind:        "secret",
+	Storable:    true,
+	Versioned:   false,
+	Constructor: func() runtime.Object { return &Secret{} },
+}
+
+// Secret is a named secret value of a user, which can be referred to from code params via templates (e.g.
+// {{ .User.Secrets.token }}). Value is stored encrypted and it never leaves Aptomi server, only references to it do.
+// The actual value gets substituted only when code is being deployed
+type Secret struct {
+	runtime.TypeKind `yaml:",inline"`
+
+	// User is the name of the user the secret belongs to
+	User string
+
+	// Name is the name of the secret, unique for the user
+	Name string
+
+	// Sealed is the encrypted secret value
+	Sealed *secrets.Sealed `yaml:",omitempty"`
+
+	// UpdatedBy is the name of the user who has set the secret value
+	UpdatedBy string
+
+	// UpdatedAt is when the secret value was set
+	UpdatedAt time.Time
+}
+
+// NewSecret creates a new secret with a given encrypted value
+func NewSecret(user string, name string, sealed *secrets.Sealed, updatedBy string) *Secret {
+	return &Secret{
+		TypeKind:  TypeSecret.GetTypeKind(),
+		User:      strings.ToLower(user),
+		Name:      name,
+		Sealed:    sealed,
+		UpdatedBy: updatedBy,
+		UpdatedAt: time.Now(),
+	}
+}
+
+// GetName returns Secret name, which consists of the user name and the secret name
+func (secret *Secret) GetName() string {
+	return SecretName(secret.User, secret.Name)
+}
+
+// GetNamespace returns Secret namespace
+func (secret *Secret) GetNamespace() string {
+	return runtime.SystemNS
+}
+
+// WithoutValue returns a copy of the secret without its value, so it can be returned from API
+func (secret *Secret) WithoutValue() *Secret {
+	result := *secret
+	result.Sealed = nil
+	return &result
+}
+
+// SecretName returns name of the secret object for a given user and secret name
+func SecretName(user string, name string) string {
+	return strings.ToLower(user) + runtime.KeySeparator + name
+}
+
+// GetDefaultColumns returns default set of columns to be displayed
+func (secret *Secret) GetDefaultColumns() []string {
+	return []string{"User", "Name", "Updated By", "Updated At"}
+}
+
+// AsColumns returns Secret representation as columns
+func (secret *Secret) AsColumns() map[string]string {
+	return map[string]string{
+		"User":       secret.User,
+		"Name":       secret.Name,
+		"Updated By": secret.UpdatedBy,
+		"Updated At": secret.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+)
+
+// ServiceAccountLabel is the label, which is set to "true" for all service accounts. It allows ACL rules to tell
+// service accounts apart from people
+const ServiceAccountLabel = "serviceaccount"
+
+// TypeServiceAccount is TypeInfo for ServiceAccount
+var TypeServiceAccount = &runtime.TypeInfo{
+	Kind:        "service-account",
+	Storable:    true,
+	Versioned:   false,
+	Constructor: func() runtime.Object { return &ServiceAccount{} },
+}
+
+// ServiceAccount is a non-human identity (e.g. CI pipeline), which calls API using API tokens. Service account acts as
+// a user with a given set of labels, so it can be matched by ACL rule criteria and it can own claims
+type ServiceAccount struct {
+	runtime.TypeKind `yaml:",inline"`
+
+	// Name is a unique name of the service account. It can't be the same as a name of any user
+	Name string
+
+	// Labels is a set of labels, attached to the service account
+	Labels map[string]string
+
+	// CreatedBy is the name of the user who created the service account
+	CreatedBy string
+
+	// CreatedAt is when the service account was created
+	CreatedAt time.Time
+}
+
+// NewServiceAccount creates a new service account
+func NewServiceAccount(name string, labels map[string]string, createdBy string) *ServiceAccount {
+	return &ServiceAccount{
+		TypeKind:  TypeServiceAccount.GetTypeKind(),
+		Name:      name,
+		Labels:    labels,
+		CreatedBy: createdBy,
+		CreatedAt: time.Now(),
+	}
+}
+
+// GetName returns ServiceAccount name
+func (sa *ServiceAccount) GetName() string {
+	return sa.Name
+}
+
+// GetNamespace returns ServiceAccount namespace
+func (sa *ServiceAccount) GetNamespace() string {
+	return runtime.SystemNS
+}
+
+// User returns service account as a user, with ServiceAccountLabel set in addition to service account labels
+func (sa *ServiceAccount) User() *lang.User {
+	labels := make(map[string]string)
+	for name, value := range sa.Labels {
+		labels[name] = value
+	}
+	labels[ServiceAccountLabel] = "true"
+
+	return &lang.User{
+		Name:   sa.Name,
+		Labels: labels,
+	}
+}
+
+// GetDefaultColumns returns default set of columns to be displayed
+func (sa *ServiceAccount) GetDefaultColumns() []string {
+	return []string{"Name", "Labels", "Created By", "Created At"}
+}
+
+// AsColumns returns ServiceAccount representation as columns
+func (sa *ServiceAccount) AsColumns() map[string]string {
+	labels := []string{}
+	for name, value := range sa.Labels {
+		labels = append(labels, name+"="+value)
+	}
+	sort.Strings(labels)
+
+	return map[string]string{
+		"Name":       sa.Name,
+		"Labels":     strings.Join(labels, ","),
+		"Created By": sa.CreatedBy,
+		"Created At": sa.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -13,6 +13,7 @@ type Fields map[string]interface{}
 // It stores all log entries in memory first, then allows them to be processed and stored
 type Log struct {
 	logger      *logrus.Logger
+	hookMask    *HookMask
 	hookMemory  *HookMemory
 	level       logrus.Level
 	scope       string
@@ -31,11 +32,16 @@ func NewLog(level logrus.Level, scope string) *Log {
 		Level:     level,
 	}
 
+	// masking hook goes first, so sensitive values never get into memory or console
+	hookMask := &HookMask{}
+	logger.Hooks.Add(hookMask)
+
 	hookMemory := &HookMemory{}
 	logger.Hooks.Add(hookMemory)
 
 	return &Log{
 		logger:     logger,
+		hookMask:   hookMask,
 		hookMemory: hookMemory,
 		level:      level,
 		scope:      scope,
@@ -56,6 +62,11 @@ func (eventLog *Log) AddConsoleHook(level logrus.Level) *Log {
 	return eventLog.AddHook(NewHookConsole(level))
 }
 
+// Mask registers sensitive values (e.g. secrets), which will be replaced with asterisks in all subsequent entries
+func (eventLog *Log) Mask(values ...string) {
+	eventLog.hookMask.Add(values...)
+}
+
 // GetLevel returns log level for the event log
 func (eventLog *Log) GetLevel() logrus.Level {
 	return eventLog.level
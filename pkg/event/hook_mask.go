@@ -0,0 +1,70 @@
+package event
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maskedValue is what sensitive values get replaced with in event log entries
+const maskedValue = "******"
+
+// HookMask implements event log hook, which replaces sensitive values (e.g. secrets) in event log entries
+type HookMask struct {
+	mutex  sync.RWMutex
+	values map[string]bool
+}
+
+// Add registers values which should be masked in all subsequent entries
+func (hook *HookMask) Add(values ...string) {
+	hook.mutex.Lock()
+	defer hook.mutex.Unlock()
+	if hook.values == nil {
+		hook.values = make(map[string]bool)
+	}
+	for _, value := range values {
+		if len(value) > 0 {
+			hook.values[value] = true
+		}
+	}
+}
+
+// Levels defines on which log levels this hook should be fired
+func (hook *HookMask) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire processes a single log entry
+func (hook *HookMask) Fire(e *logrus.Entry) error {
+	hook.mutex.RLock()
+	defer hook.mutex.RUnlock()
+	if len(hook.values) <= 0 {
+		return nil
+	}
+
+	e.Message = hook.mask(e.Message)
+	data := logrus.Fields{}
+	for key, value := range e.Data {
+		switch v := value.(type) {
+		case string:
+			data[key] = hook.mask(v)
+		case error:
+			data[key] = hook.mask(v.Error())
+		case fmt.Stringer:
+			data[key] = hook.mask(v.String())
+		default:
+			data[key] = value
+		}
+	}
+	e.Data = data
+	return nil
+}
+
+func (hook *HookMask) mask(str string) string {
+	for value := range hook.values {
+		str = strings.Replace(str, value, maskedValue, -1)
+	}
+	return str
+}
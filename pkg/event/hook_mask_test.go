@@ -0,0 +1,24 @@
+package event
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventLogMask(t *testing.T) {
+	eventLog := NewLog(logrus.DebugLevel, "test")
+	eventLog.NewEntry().Infof("before: %s", "secretvalue")
+	eventLog.Mask("secretvalue", "")
+	eventLog.NewEntry().Infof("after: %s", "secretvalue")
+	eventLog.NewEntry().WithField("error", fmt.Errorf("failed with secretvalue")).Warnf("no secrets here")
+
+	entries := eventLog.hookMemory.entries
+	assert.Equal(t, 3, len(entries))
+	assert.Equal(t, "before: secretvalue", entries[0].Message)
+	assert.Equal(t, "after: ******", entries[1].Message)
+	assert.Equal(t, "no secrets here", entries[2].Message)
+	assert.Equal(t, "failed with ******", entries[2].Data["error"])
+}
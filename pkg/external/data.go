@@ -9,6 +9,10 @@ import (
 type Data struct {
 	UserLoader   users.UserLoader
 	SecretLoader secrets.SecretLoader
+
+	// SecretFingerprintKey is the server-side key for fingerprints of secret values, which let components get updated
+	// when secrets they refer to change. If it's not set, changing secrets doesn't update components
+	SecretFingerprintKey []byte
 }
 
 // NewData creates a new instance of external Data
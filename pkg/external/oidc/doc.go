@@ -0,0 +1,3 @@
+// Package oidc implements support for logging users in via OpenID Connect provider (authorization code and device
+// flows), validation of ID tokens signed with provider keys (RS256, JWKS) and mapping of ID token claims into Users.
+package oidc
@@ -0,0 +1,264 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// FakeIssuer is an in-process OpenID Connect provider, which can be used in tests. It supports discovery, JWKS,
+// authorization code flow (user gets logged in right away, without any consent page) and device flow (device has to
+// be approved via ApproveDevice). All ID tokens are issued for a single user with given claims
+type FakeIssuer struct {
+	server       *httptest.Server
+	clientID     string
+	clientSecret string
+
+	mutex       sync.Mutex
+	key         *rsa.PrivateKey
+	kid         string
+	claims      map[string]interface{}
+	codes       map[string]bool
+	devices     map[string]*fakeDevice
+	keyRequests int
+}
+
+type fakeDevice struct {
+	userCode string
+	approved bool
+}
+
+// NewFakeIssuer starts a new fake OpenID Connect provider for a given client, which issues ID tokens for a user with
+// given claims. It should be closed after use
+func NewFakeIssuer(clientID, clientSecret string, claims map[string]interface{}) *FakeIssuer {
+	issuer := &FakeIssuer{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		claims:       claims,
+		codes:        make(map[string]bool),
+		devices:      make(map[string]*fakeDevice),
+	}
+	issuer.RotateKey()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", issuer.handleDiscovery)
+	mux.HandleFunc("/keys", issuer.handleKeys)
+	mux.HandleFunc("/authorize", issuer.handleAuthorize)
+	mux.HandleFunc("/device/code", issuer.handleDeviceCode)
+	mux.HandleFunc("/token", issuer.handleToken)
+	issuer.server = httptest.NewServer(mux)
+
+	return issuer
+}
+
+// URL returns issuer URL
+func (issuer *FakeIssuer) URL() string {
+	return issuer.server.URL
+}
+
+// Close shuts down the issuer
+func (issuer *FakeIssuer) Close() {
+	issuer.server.Close()
+}
+
+// RotateKey replaces the key, which is used to sign ID tokens, with a new one
+func (issuer *FakeIssuer) RotateKey() {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(fmt.Sprintf("error while generating key: %s", err))
+	}
+
+	issuer.mutex.Lock()
+	defer issuer.mutex.Unlock()
+	issuer.key = key
+	issuer.kid = randomCode()
+}
+
+// KeyRequests returns how many times issuer keys have been retrieved from JWKS
+func (issuer *FakeIssuer) KeyRequests() int {
+	issuer.mutex.Lock()
+	defer issuer.mutex.Unlock()
+	return issuer.keyRequests
+}
+
+// ApproveDevice approves device authorization with a given user code, as if the user has entered it on the
+// verification page of the provider
+func (issuer *FakeIssuer) ApproveDevice(userCode string) {
+	issuer.mutex.Lock()
+	defer issuer.mutex.Unlock()
+	for _, device := range issuer.devices {
+		if device.userCode == userCode {
+			device.approved = true
+		}
+	}
+}
+
+// IDToken returns ID token, signed by the issuer, with given claims added on top of the standard ones (issuer,
+// audience, issue and expiration time)
+func (issuer *FakeIssuer) IDToken(claims map[string]interface{}) string {
+	issuer.mutex.Lock()
+	defer issuer.mutex.Unlock()
+
+	tokenClaims := jwt.MapClaims{
+		"iss": issuer.URL(),
+		"aud": issuer.clientID,
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	for name, value := range claims {
+		tokenClaims[name] = value
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, tokenClaims)
+	token.Header["kid"] = issuer.kid
+	result, err := token.SignedString(issuer.key)
+	if err != nil {
+		panic(fmt.Sprintf("error while signing token: %s", err))
+	}
+	return result
+}
+
+func (issuer *FakeIssuer) handleDiscovery(writer http.ResponseWriter, request *http.Request) {
+	writeJSON(writer, http.StatusOK, &discovery{
+		Issuer:                      issuer.URL(),
+		AuthorizationEndpoint:       issuer.URL() + "/authorize",
+		TokenEndpoint:               issuer.URL() + "/token",
+		DeviceAuthorizationEndpoint: issuer.URL() + "/device/code",
+		JWKSURI:                     issuer.URL() + "/keys",
+	})
+}
+
+func (issuer *FakeIssuer) handleKeys(writer http.ResponseWriter, request *http.Request) {
+	issuer.mutex.Lock()
+	defer issuer.mutex.Unlock()
+	issuer.keyRequests++
+	writeJSON(writer, http.StatusOK, map[string]interface{}{
+		"keys": []jsonWebKey{{
+			Kty: "RSA",
+			Kid: issuer.kid,
+			N:   jwt.EncodeSegment(issuer.key.PublicKey.N.Bytes()),
+			E:   jwt.EncodeSegment(big.NewInt(int64(issuer.key.PublicKey.E)).Bytes()),
+		}},
+	})
+}
+
+func (issuer *FakeIssuer) handleAuthorize(writer http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	if query.Get("client_id") != issuer.clientID || query.Get("response_type") != "code" {
+		writeError(writer, "invalid_request", "unknown client or unsupported response type")
+		return
+	}
+
+	code := randomCode()
+	issuer.mutex.Lock()
+	issuer.codes[code] = true
+	issuer.mutex.Unlock()
+
+	redirect := withQuery(query.Get("redirect_uri"), url.Values{"code": {code}, "state": {query.Get("state")}})
+	http.Redirect(writer, request, redirect, http.StatusFound)
+}
+
+func (issuer *FakeIssuer) handleDeviceCode(writer http.ResponseWriter, request *http.Request) {
+	if !issuer.checkClient(writer, request) {
+		return
+	}
+
+	deviceCode, userCode := randomCode(), randomCode()[:8]
+	issuer.mutex.Lock()
+	issuer.devices[deviceCode] = &fakeDevice{userCode: userCode}
+	issuer.mutex.Unlock()
+
+	writeJSON(writer, http.StatusOK, &DeviceAuthorization{
+		DeviceCode:              deviceCode,
+		UserCode:                userCode,
+		VerificationURI:         issuer.URL() + "/device",
+		VerificationURIComplete: issuer.URL() + "/device?user_code=" + userCode,
+		ExpiresIn:               600,
+		Interval:                1,
+	})
+}
+
+func (issuer *FakeIssuer) handleToken(writer http.ResponseWriter, request *http.Request) {
+	if !issuer.checkClient(writer, request) {
+		return
+	}
+
+	issuer.mutex.Lock()
+	switch request.PostForm.Get("grant_type") {
+	case "authorization_code":
+		code := request.PostForm.Get("code")
+		if !issuer.codes[code] {
+			issuer.mutex.Unlock()
+			writeError(writer, "invalid_grant", "unknown authorization code")
+			return
+		}
+		delete(issuer.codes, code)
+	case "urn:ietf:params:oauth:grant-type:device_code":
+		device := issuer.devices[request.PostForm.Get("device_code")]
+		if device == nil {
+			issuer.mutex.Unlock()
+			writeError(writer, "invalid_grant", "unknown device code")
+			return
+		}
+		if !device.approved {
+			issuer.mutex.Unlock()
+			writeError(writer, "authorization_pending", "device hasn't been approved yet")
+			return
+		}
+		delete(issuer.devices, request.PostForm.Get("device_code"))
+	default:
+		issuer.mutex.Unlock()
+		writeError(writer, "unsupported_grant_type", "")
+		return
+	}
+	issuer.mutex.Unlock()
+
+	writeJSON(writer, http.StatusOK, &Tokens{
+		IDToken:     issuer.IDToken(issuer.claims),
+		AccessToken: randomCode(),
+		TokenType:   "Bearer",
+		ExpiresIn:   3600,
+	})
+}
+
+func (issuer *FakeIssuer) checkClient(writer http.ResponseWriter, request *http.Request) bool {
+	err := request.ParseForm()
+	if err != nil || request.PostForm.Get("client_id") != issuer.clientID || request.PostForm.Get("client_secret") != issuer.clientSecret {
+		writeError(writer, "invalid_client", "unknown client or invalid client secret")
+		return false
+	}
+	return true
+}
+
+func writeError(writer http.ResponseWriter, code string, description string) {
+	writeJSON(writer, http.StatusBadRequest, map[string]string{"error": code, "error_description": description})
+}
+
+func writeJSON(writer http.ResponseWriter, status int, value interface{}) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		panic(fmt.Sprintf("error while marshalling response: %s", err))
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	writer.Write(data) // nolint: errcheck
+}
+
+func randomCode() string {
+	data := make([]byte, 16)
+	_, err := rand.Read(data)
+	if err != nil {
+		panic(fmt.Sprintf("error while generating random code: %s", err))
+	}
+	return hex.EncodeToString(data)
+}
@@ -0,0 +1,441 @@
+package oidc
+
+import (
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/dgrijalva/jwt-go"
+)
+
+var (
+	// ErrAuthorizationPending is returned when device authorization hasn't been approved by the user yet
+	ErrAuthorizationPending = errors.New("authorization pending")
+
+	// ErrSlowDown is returned when device token is being polled too often
+	ErrSlowDown = errors.New("slow down")
+)
+
+// Tokens represents tokens issued by the provider
+type Tokens struct {
+	IDToken     string `json:"id_token"`
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// DeviceAuthorization represents device authorization issued by the provider. User has to open VerificationURI and
+// enter UserCode there, while the client polls for tokens using DeviceCode
+type DeviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// discovery is the provider configuration, which gets retrieved from '.well-known/openid-configuration'
+type discovery struct {
+	Issuer                      string `json:"issuer"`
+	AuthorizationEndpoint       string `json:"authorization_endpoint"`
+	TokenEndpoint               string `json:"token_endpoint"`
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+	JWKSURI                     string `json:"jwks_uri"`
+}
+
+// keysRefreshInterval is the minimal interval between reloads of provider keys from JWKS, so tokens signed with
+// unknown keys can't make Aptomi hammer the provider
+const keysRefreshInterval = time.Minute
+
+// Provider is a client of OpenID Connect provider. Provider configuration is discovered on first use, while provider
+// keys are retrieved from JWKS and refreshed when ID token is signed with an unknown key (at most once per
+// keysRefreshInterval)
+type Provider struct {
+	cfg          *config.OIDC
+	httpClient   *http.Client
+	mutex        sync.Mutex
+	discovery    *discovery
+	keys         map[string]*rsa.PublicKey
+	keysLoadedAt time.Time
+
+	// refreshMutex makes sure only one JWKS refresh is in flight, without blocking lookups of known keys
+	refreshMutex        sync.Mutex
+	keysRefreshInterval time.Duration
+}
+
+// NewProvider returns new Provider for a given OIDC config
+func NewProvider(cfg *config.OIDC) *Provider {
+	return &Provider{
+		cfg:                 cfg,
+		httpClient:          &http.Client{Timeout: 30 * time.Second},
+		keys:                make(map[string]*rsa.PublicKey),
+		keysRefreshInterval: keysRefreshInterval,
+	}
+}
+
+// AuthCodeURL returns the URL of provider login page for authorization code flow, which redirects back to the
+// configured redirect URL with a given state
+func (p *Provider) AuthCodeURL(state string) (string, error) {
+	if len(p.cfg.RedirectURL) == 0 {
+		return "", fmt.Errorf("redirect URL is not configured for OpenID Connect provider")
+	}
+
+	d, err := p.getDiscovery()
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{
+		"response_type": {"code"},
+		"client_id":     {p.cfg.ClientID},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"scope":         {strings.Join(p.cfg.GetScopes(), " ")},
+		"state":         {state},
+	}
+	return withQuery(d.AuthorizationEndpoint, params), nil
+}
+
+// Exchange exchanges authorization code for tokens
+func (p *Provider) Exchange(code string) (*Tokens, error) {
+	d, err := p.getDiscovery()
+	if err != nil {
+		return nil, err
+	}
+
+	return p.tokenRequest(d.TokenEndpoint, url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {p.cfg.RedirectURL},
+	})
+}
+
+// AuthorizeDevice starts device flow
+func (p *Provider) AuthorizeDevice() (*DeviceAuthorization, error) {
+	d, err := p.getDiscovery()
+	if err != nil {
+		return nil, err
+	}
+	if len(d.DeviceAuthorizationEndpoint) == 0 {
+		return nil, fmt.Errorf("OpenID Connect provider doesn't support device flow")
+	}
+
+	result := &DeviceAuthorization{}
+	err = p.postForm(d.DeviceAuthorizationEndpoint, url.Values{"scope": {strings.Join(p.cfg.GetScopes(), " ")}}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeviceToken retrieves tokens for a given device code. It returns ErrAuthorizationPending if user hasn't approved
+// the device yet, and ErrSlowDown if it's being called too often
+func (p *Provider) DeviceToken(deviceCode string) (*Tokens, error) {
+	d, err := p.getDiscovery()
+	if err != nil {
+		return nil, err
+	}
+
+	return p.tokenRequest(d.TokenEndpoint, url.Values{
+		"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+		"device_code": {deviceCode},
+	})
+}
+
+// Verify verifies signature of a given ID token using provider keys (RS256), as well as its issuer, audience and
+// expiration. It returns claims of the token
+func (p *Provider) Verify(rawToken string) (jwt.MapClaims, error) {
+	d, err := p.getDiscovery()
+	if err != nil {
+		return nil, err
+	}
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(rawToken, claims, func(token *jwt.Token) (interface{}, error) {
+		if token.Method != jwt.SigningMethodRS256 {
+			return nil, fmt.Errorf("unexpected token signing method: %s", token.Header["alg"])
+		}
+		kid, _ := token.Header["kid"].(string)
+		return p.getKey(d, kid)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !claims.VerifyIssuer(d.Issuer, true) {
+		return nil, fmt.Errorf("token is issued by unexpected issuer: %v", claims["iss"])
+	}
+	if !verifyAudience(claims, p.cfg.ClientID) {
+		return nil, fmt.Errorf("token is issued for unexpected audience: %v", claims["aud"])
+	}
+	if _, ok := claims["exp"]; !ok {
+		return nil, fmt.Errorf("token should have expiration time")
+	}
+
+	return claims, nil
+}
+
+// User maps claims of ID token into a user. User name is taken from the configured username claim, while user labels
+// are taken from the claims configured in label to claims mapping. List claims (e.g. groups) get joined with commas.
+// When user name is taken from email claim, the email should be verified by the provider
+func (p *Provider) User(claims jwt.MapClaims) (*lang.User, error) {
+	name, _ := claims[p.cfg.GetUsernameClaim()].(string)
+	if len(name) == 0 {
+		return nil, fmt.Errorf("token should contain non-empty '%s' claim", p.cfg.GetUsernameClaim())
+	}
+	if p.cfg.GetUsernameClaim() == "email" {
+		if verified, _ := claims["email_verified"].(bool); !verified {
+			return nil, fmt.Errorf("email '%s' is not verified by OpenID Connect provider", name)
+		}
+	}
+
+	user := &lang.User{
+		Name:   name,
+		Labels: make(map[string]string),
+	}
+	for label, claim := range p.cfg.LabelToClaims {
+		if value, ok := claimToString(claims[claim]); ok {
+			user.Labels[label] = value
+		}
+	}
+	return user, nil
+}
+
+func (p *Provider) getDiscovery() (*discovery, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	result := &discovery{}
+	err := p.get(strings.TrimSuffix(p.cfg.Issuer, "/")+"/.well-known/openid-configuration", result)
+	if err != nil {
+		return nil, fmt.Errorf("error while discovering OpenID Connect provider configuration: %s", err)
+	}
+	if strings.TrimSuffix(result.Issuer, "/") != strings.TrimSuffix(p.cfg.Issuer, "/") {
+		return nil, fmt.Errorf("OpenID Connect provider issuer '%s' doesn't match configured '%s'", result.Issuer, p.cfg.Issuer)
+	}
+
+	p.discovery = result
+	return result, nil
+}
+
+// jsonWebKey is a public key of the provider in JWK format (only RSA keys are supported)
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// getKey returns provider key with a given id. If the key is unknown, keys get reloaded from JWKS (e.g. provider
+// might have rotated its keys), unless they have been reloaded recently. If key id is empty, the only provider key is
+// used
+func (p *Provider) getKey(d *discovery, kid string) (*rsa.PublicKey, error) {
+	if key, _ := p.lookupKey(kid); key != nil {
+		return key, nil
+	}
+
+	p.refreshMutex.Lock()
+	defer p.refreshMutex.Unlock()
+
+	// keys might have been reloaded while we were waiting for another refresh
+	key, loadedAt := p.lookupKey(kid)
+	if key != nil {
+		return key, nil
+	}
+	if time.Since(loadedAt) < p.keysRefreshInterval {
+		return nil, fmt.Errorf("token is signed with unknown key: %s", kid)
+	}
+
+	keys, err := p.loadKeys(d)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.keysLoadedAt = time.Now()
+	if err != nil {
+		return nil, err
+	}
+	p.keys = keys
+
+	if key := p.findKey(kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("token is signed with unknown key: %s", kid)
+}
+
+// lookupKey returns known provider key with a given id (or nil if it's unknown) and the time keys were loaded at
+func (p *Provider) lookupKey(kid string) (*rsa.PublicKey, time.Time) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.findKey(kid), p.keysLoadedAt
+}
+
+// loadKeys retrieves provider keys from JWKS
+func (p *Provider) loadKeys(d *discovery) (map[string]*rsa.PublicKey, error) {
+	jwks := struct {
+		Keys []jsonWebKey `json:"keys"`
+	}{}
+	err := p.get(d.JWKSURI, &jwks)
+	if err != nil {
+		return nil, fmt.Errorf("error while retrieving OpenID Connect provider keys: %s", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, jwk := range jwks.Keys {
+		if jwk.Kty != "RSA" {
+			continue
+		}
+		key, keyErr := jwk.publicKey()
+		if keyErr != nil {
+			return nil, fmt.Errorf("error while parsing OpenID Connect provider key '%s': %s", jwk.Kid, keyErr)
+		}
+		keys[jwk.Kid] = key
+	}
+	return keys, nil
+}
+
+// findKey should be called under a lock
+func (p *Provider) findKey(kid string) *rsa.PublicKey {
+	if len(kid) == 0 && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key
+		}
+	}
+	return p.keys[kid]
+}
+
+func (jwk jsonWebKey) publicKey() (*rsa.PublicKey, error) {
+	n, err := jwt.DecodeSegment(jwk.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := jwt.DecodeSegment(jwk.E)
+	if err != nil {
+		return nil, err
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
+
+func (p *Provider) tokenRequest(endpoint string, form url.Values) (*Tokens, error) {
+	result := &Tokens{}
+	err := p.postForm(endpoint, form, result)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.IDToken) == 0 {
+		return nil, fmt.Errorf("OpenID Connect provider didn't return ID token")
+	}
+	return result, nil
+}
+
+func (p *Provider) get(endpoint string, result interface{}) error {
+	resp, err := p.httpClient.Get(endpoint)
+	if err != nil {
+		return err
+	}
+	return readResponse(resp, result)
+}
+
+func (p *Provider) postForm(endpoint string, form url.Values, result interface{}) error {
+	form.Set("client_id", p.cfg.ClientID)
+	if len(p.cfg.ClientSecret) > 0 {
+		form.Set("client_secret", p.cfg.ClientSecret)
+	}
+	resp, err := p.httpClient.PostForm(endpoint, form)
+	if err != nil {
+		return err
+	}
+	return readResponse(resp, result)
+}
+
+func readResponse(resp *http.Response, result interface{}) error {
+	defer resp.Body.Close() // nolint: errcheck
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error while reading response: %s", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		errResp := struct {
+			Error            string `json:"error"`
+			ErrorDescription string `json:"error_description"`
+		}{}
+		if json.Unmarshal(data, &errResp) == nil && len(errResp.Error) > 0 {
+			switch errResp.Error {
+			case "authorization_pending":
+				return ErrAuthorizationPending
+			case "slow_down":
+				return ErrSlowDown
+			}
+			return fmt.Errorf("%s: %s", errResp.Error, errResp.ErrorDescription)
+		}
+		return fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+
+	err = json.Unmarshal(data, result)
+	if err != nil {
+		return fmt.Errorf("error while unmarshalling response: %s", err)
+	}
+	return nil
+}
+
+func withQuery(endpoint string, params url.Values) string {
+	if strings.Contains(endpoint, "?") {
+		return endpoint + "&" + params.Encode()
+	}
+	return endpoint + "?" + params.Encode()
+}
+
+// verifyAudience checks that token is issued for a given client. Audience can be either a string or a list of strings
+func verifyAudience(claims jwt.MapClaims, clientID string) bool {
+	switch aud := claims["aud"].(type) {
+	case string:
+		return aud == clientID
+	case []interface{}:
+		for _, item := range aud {
+			if item == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// claimToString converts value of a claim into a label value
+func claimToString(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case bool:
+		return strconv.FormatBool(v), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case []interface{}:
+		items := []string{}
+		for _, item := range v {
+			if s, ok := claimToString(item); ok {
+				items = append(items, s)
+			}
+		}
+		sort.Strings(items)
+		return strings.Join(items, ","), true
+	}
+	return "", false
+}
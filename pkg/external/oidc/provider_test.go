@@ -0,0 +1,214 @@
+package oidc
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testClientID     = "aptomi"
+	testClientSecret = "secret"
+	testRedirectURL  = "http://aptomi.example.com/api/v1/user/login/oidc/callback"
+)
+
+func makeIssuerAndProvider() (*FakeIssuer, *Provider) {
+	issuer := NewFakeIssuer(testClientID, testClientSecret, map[string]interface{}{
+		"email":          "alice@example.com",
+		"email_verified": true,
+		"name":           "Alice",
+		"groups":         []string{"dev", "admins"},
+		"level":          5,
+	})
+	provider := NewProvider(&config.OIDC{
+		Issuer:       issuer.URL(),
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		RedirectURL:  testRedirectURL,
+		LabelToClaims: map[string]string{
+			"full_name": "name",
+			"groups":    "groups",
+			"level":     "level",
+			"missing":   "missing",
+		},
+	})
+	return issuer, provider
+}
+
+func TestProviderAuthCodeFlow(t *testing.T) {
+	issuer, provider := makeIssuerAndProvider()
+	defer issuer.Close()
+
+	authURL, err := provider.AuthCodeURL("state123")
+	if !assert.NoError(t, err, "Auth code URL should be generated") {
+		t.FailNow()
+	}
+
+	// follow provider login page, which redirects back with authorization code right away
+	client := &http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL)
+	if !assert.NoError(t, err, "Provider login page should be available") {
+		t.FailNow()
+	}
+	resp.Body.Close() // nolint: errcheck
+	redirect, err := url.Parse(resp.Header.Get("Location"))
+	if !assert.NoError(t, err, "Provider should redirect back") {
+		t.FailNow()
+	}
+	assert.Equal(t, "aptomi.example.com", redirect.Host, "Provider should redirect back to the redirect URL")
+	assert.Equal(t, "state123", redirect.Query().Get("state"), "Provider should preserve state")
+
+	// exchange code for tokens
+	tokens, err := provider.Exchange(redirect.Query().Get("code"))
+	if !assert.NoError(t, err, "Authorization code should be exchanged for tokens") {
+		t.FailNow()
+	}
+	_, err = provider.Exchange(redirect.Query().Get("code"))
+	assert.Error(t, err, "Authorization code should only be exchanged once")
+
+	// verify ID token and map it into the user
+	claims, err := provider.Verify(tokens.IDToken)
+	if !assert.NoError(t, err, "ID token should be valid") {
+		t.FailNow()
+	}
+	user, err := provider.User(claims)
+	if !assert.NoError(t, err, "User should be mapped from claims") {
+		t.FailNow()
+	}
+	assert.Equal(t, "alice@example.com", user.Name, "User name should be taken from email claim")
+	assert.Equal(t, map[string]string{"full_name": "Alice", "groups": "admins,dev", "level": "5"}, user.Labels, "User labels should be mapped from claims")
+}
+
+func TestProviderDeviceFlow(t *testing.T) {
+	issuer, provider := makeIssuerAndProvider()
+	defer issuer.Close()
+
+	device, err := provider.AuthorizeDevice()
+	if !assert.NoError(t, err, "Device should be authorized") {
+		t.FailNow()
+	}
+	assert.NotEmpty(t, device.UserCode, "User code should be issued")
+	assert.NotEmpty(t, device.VerificationURI, "Verification URI should be returned")
+
+	_, err = provider.DeviceToken(device.DeviceCode)
+	assert.Equal(t, ErrAuthorizationPending, err, "Authorization should be pending until user approves the device")
+
+	issuer.ApproveDevice(device.UserCode)
+	tokens, err := provider.DeviceToken(device.DeviceCode)
+	if !assert.NoError(t, err, "Tokens should be issued after user approves the device") {
+		t.FailNow()
+	}
+	claims, err := provider.Verify(tokens.IDToken)
+	if assert.NoError(t, err, "ID token should be valid") {
+		assert.Equal(t, "alice@example.com", claims["email"], "ID token should have user claims")
+	}
+
+	_, err = provider.DeviceToken("unknown")
+	assert.Error(t, err, "Unknown device code should result in an error")
+	assert.NotEqual(t, ErrAuthorizationPending, err, "Unknown device code should not be reported as pending")
+}
+
+func TestProviderVerify(t *testing.T) {
+	issuer, provider := makeIssuerAndProvider()
+	defer issuer.Close()
+
+	// valid token
+	_, err := provider.Verify(issuer.IDToken(map[string]interface{}{"email": "bob@example.com"}))
+	assert.NoError(t, err, "Token should be valid")
+
+	// token signed with the rotated key gets verified after provider keys get reloaded
+	provider.keysRefreshInterval = 0
+	issuer.RotateKey()
+	_, err = provider.Verify(issuer.IDToken(map[string]interface{}{"email": "bob@example.com"}))
+	assert.NoError(t, err, "Token signed with rotated key should be valid")
+
+	// invalid tokens
+	invalid := map[string]map[string]interface{}{
+		"expired":        {"exp": time.Now().Add(-time.Minute).Unix()},
+		"wrong audience": {"aud": "other"},
+		"wrong issuer":   {"iss": "http://other.example.com"},
+	}
+	for name, claims := range invalid {
+		_, err = provider.Verify(issuer.IDToken(claims))
+		assert.Error(t, err, "Token should be invalid: %s", name)
+	}
+
+	// audience can be a list
+	_, err = provider.Verify(issuer.IDToken(map[string]interface{}{"aud": []string{"other", testClientID}}))
+	assert.NoError(t, err, "Token with audience list containing client should be valid")
+
+	// token signed by another issuer
+	other := NewFakeIssuer(testClientID, testClientSecret, nil)
+	defer other.Close()
+	_, err = provider.Verify(other.IDToken(map[string]interface{}{"iss": issuer.URL()}))
+	assert.Error(t, err, "Token signed with unknown key should be invalid")
+
+	// user can't be mapped without username claim
+	claims, err := provider.Verify(issuer.IDToken(map[string]interface{}{"name": "Bob"}))
+	if assert.NoError(t, err, "Token should be valid") {
+		_, err = provider.User(claims)
+		assert.Error(t, err, "User should not be mapped without username claim")
+	}
+
+	// user can't be mapped from unverified email
+	for _, verified := range []interface{}{false, "true", nil} {
+		claims, err = provider.Verify(issuer.IDToken(map[string]interface{}{"email": "bob@example.com", "email_verified": verified}))
+		if assert.NoError(t, err, "Token should be valid") {
+			_, err = provider.User(claims)
+			assert.Error(t, err, "User should not be mapped from unverified email: %v", verified)
+		}
+	}
+	claims, err = provider.Verify(issuer.IDToken(map[string]interface{}{"email": "bob@example.com", "email_verified": true}))
+	if assert.NoError(t, err, "Token should be valid") {
+		_, err = provider.User(claims)
+		assert.NoError(t, err, "User should be mapped from verified email")
+	}
+}
+
+func TestProviderKeysRefreshThrottling(t *testing.T) {
+	issuer, provider := makeIssuerAndProvider()
+	defer issuer.Close()
+
+	_, err := provider.Verify(issuer.IDToken(map[string]interface{}{"email": "bob@example.com"}))
+	assert.NoError(t, err, "Token should be valid")
+	assert.Equal(t, 1, issuer.KeyRequests(), "Keys should be retrieved once")
+
+	// tokens signed with unknown keys don't trigger JWKS refresh, as keys have just been loaded
+	other := NewFakeIssuer(testClientID, testClientSecret, nil)
+	defer other.Close()
+	for i := 0; i < 10; i++ {
+		_, err = provider.Verify(other.IDToken(map[string]interface{}{"iss": issuer.URL()}))
+		assert.Error(t, err, "Token signed with unknown key should be invalid")
+	}
+	assert.Equal(t, 1, issuer.KeyRequests(), "Keys should not be reloaded within refresh interval")
+
+	// known keys keep working
+	_, err = provider.Verify(issuer.IDToken(map[string]interface{}{"email": "bob@example.com"}))
+	assert.NoError(t, err, "Token should be valid")
+
+	// once refresh interval passes, keys get reloaded
+	provider.keysLoadedAt = time.Now().Add(-provider.keysRefreshInterval)
+	issuer.RotateKey()
+	_, err = provider.Verify(issuer.IDToken(map[string]interface{}{"email": "bob@example.com"}))
+	assert.NoError(t, err, "Token signed with rotated key should be valid after refresh interval")
+	assert.Equal(t, 2, issuer.KeyRequests(), "Keys should be reloaded after refresh interval")
+}
+
+func TestProviderDiscoveryFailure(t *testing.T) {
+	issuer, _ := makeIssuerAndProvider()
+	defer issuer.Close()
+
+	provider := NewProvider(&config.OIDC{Issuer: issuer.URL() + "/other", ClientID: testClientID})
+	_, err := provider.AuthorizeDevice()
+	assert.Error(t, err, "Discovery should fail for unknown issuer")
+
+	provider = NewProvider(&config.OIDC{Issuer: issuer.URL(), ClientID: testClientID})
+	_, err = provider.AuthCodeURL("state")
+	assert.Error(t, err, "Auth code flow should fail without redirect URL")
+}
@@ -0,0 +1,3 @@
+// Package scim implements the parts of SCIM 2.0 protocol (RFC 7643, RFC 7644) needed to let identity providers push
+// users and groups into Aptomi: resource representation, filtering by attribute value, PATCH operations and errors.
+package scim
@@ -0,0 +1,38 @@
+package scim
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// filterRegex is the pattern for supported filters, which compare a single attribute with a string value, e.g.
+// userName eq "alice@example.com". It's what identity providers use to look up existing users and groups
+var filterRegex = regexp.MustCompile(`^\s*([A-Za-z][\w$.:-]*)\s+(?i:eq)\s+("(?:[^"\\]|\\.)*")\s*$`)
+
+// Filter selects resources (or values of multi-valued attributes), which have a given attribute equal to a given
+// value. Comparison is case insensitive
+type Filter struct {
+	Attribute string
+	Value     string
+}
+
+// ParseFilter parses filter expression. Only 'eq' operator is supported
+func ParseFilter(filter string) (*Filter, error) {
+	match := filterRegex.FindStringSubmatch(filter)
+	if match == nil {
+		return nil, NewError(http.StatusBadRequest, ErrorInvalidFilter, "unsupported filter '%s', only 'attribute eq \"value\"' is supported", filter)
+	}
+	value, err := strconv.Unquote(match[2])
+	if err != nil {
+		return nil, NewError(http.StatusBadRequest, ErrorInvalidFilter, "invalid value in filter '%s': %s", filter, err)
+	}
+	return &Filter{Attribute: match[1], Value: value}, nil
+}
+
+// Matches returns true if a given resource (or value of multi-valued attribute) matches the filter
+func (filter *Filter) Matches(attrs map[string]interface{}) bool {
+	value, ok := Resource(attrs).Attributes()[strings.ToLower(filter.Attribute)]
+	return ok && strings.EqualFold(value, filter.Value)
+}
@@ -0,0 +1,299 @@
+package scim
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Patch operations
+const (
+	OpAdd     = "add"
+	OpReplace = "replace"
+	OpRemove  = "remove"
+)
+
+// pathRegex is the pattern for attribute paths, e.g. active, name.givenName, emails[type eq "work"].value or
+// members[value eq "2819c223"]
+var pathRegex = regexp.MustCompile(`^([A-Za-z][\w$-]*)(?:\[(.+)\])?(?:\.([A-Za-z][\w$-]*))?$`)
+
+// PatchRequest is SCIM PATCH request
+type PatchRequest struct {
+	Schemas    []string          `json:"schemas"`
+	Operations []*PatchOperation `json:"Operations"`
+}
+
+// PatchOperation is a single operation of SCIM PATCH request
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// path is a parsed attribute path
+type path struct {
+	attribute    string
+	filter       *Filter
+	subAttribute string
+}
+
+// Patch applies given PATCH operations to the resource. Operation names are case insensitive, as some identity
+// providers send them capitalized
+func (resource Resource) Patch(operations []*PatchOperation) error {
+	for _, operation := range operations {
+		op := strings.ToLower(operation.Op)
+		switch op {
+		case OpAdd, OpReplace:
+			if len(operation.Path) > 0 {
+				err := applyPath(resource, op, operation.Path, operation.Value)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			// without path, value contains attributes (or paths) to be added or replaced
+			attrs, ok := operation.Value.(map[string]interface{})
+			if !ok {
+				return NewError(http.StatusBadRequest, ErrorInvalidValue, "value of '%s' operation without path should be an object", operation.Op)
+			}
+			for name, value := range attrs {
+				err := applyPath(resource, op, name, value)
+				if err != nil {
+					return err
+				}
+			}
+		case OpRemove:
+			if len(operation.Path) <= 0 {
+				return NewError(http.StatusBadRequest, ErrorNoTarget, "path is required for '%s' operation", operation.Op)
+			}
+			err := applyPath(resource, op, operation.Path, operation.Value)
+			if err != nil {
+				return err
+			}
+		default:
+			return NewError(http.StatusBadRequest, ErrorInvalidSyntax, "unsupported patch operation '%s'", operation.Op)
+		}
+	}
+	return nil
+}
+
+// applyPath applies operation to the attribute at a given path. Attributes from schema extensions are referred to
+// by schema followed by attribute name (e.g. urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department)
+func applyPath(attrs map[string]interface{}, op string, pathStr string, value interface{}) error {
+	if strings.HasPrefix(strings.ToLower(pathStr), "urn:") {
+		idx := strings.LastIndex(pathStr, ":")
+		schema, rest := pathStr[:idx], pathStr[idx+1:]
+		if schemaAttrs := getOrCreateMap(attrs, schema, op != OpRemove); schemaAttrs != nil {
+			return applyPath(schemaAttrs, op, rest, value)
+		}
+		return nil
+	}
+
+	p, err := parsePath(pathStr)
+	if err != nil {
+		return err
+	}
+
+	if p.filter != nil {
+		return applyFiltered(attrs, op, p, value)
+	}
+
+	if len(p.subAttribute) > 0 {
+		if subAttrs := getOrCreateMap(attrs, p.attribute, op != OpRemove); subAttrs != nil {
+			return applyAttribute(subAttrs, op, p.subAttribute, value)
+		}
+		return nil
+	}
+
+	return applyAttribute(attrs, op, p.attribute, value)
+}
+
+// applyAttribute adds, replaces or removes a single attribute
+func applyAttribute(attrs map[string]interface{}, op string, name string, value interface{}) error {
+	key := findKey(attrs, name)
+	if len(key) <= 0 {
+		key = name
+	}
+	existing, exists := attrs[key]
+
+	if op == OpRemove {
+		// if values are given, only those get removed from multi-valued attribute
+		existingValues, isMultiValued := existing.([]interface{})
+		removeValues, hasValues := value.([]interface{})
+		if exists && isMultiValued && hasValues {
+			attrs[key] = removeMatching(existingValues, func(v interface{}) bool {
+				for _, remove := range removeValues {
+					if sameValue(v, remove) {
+						return true
+					}
+				}
+				return false
+			})
+			return nil
+		}
+		delete(attrs, key)
+		return nil
+	}
+
+	switch existingValue := existing.(type) {
+	case []interface{}:
+		// add appends to multi-valued attribute, while replace overwrites it
+		if newValues, ok := value.([]interface{}); ok && op == OpAdd {
+			for _, newValue := range newValues {
+				if !containsValue(existingValue, newValue) {
+					existingValue = append(existingValue, newValue)
+				}
+			}
+			attrs[key] = existingValue
+			return nil
+		}
+	case map[string]interface{}:
+		// complex attributes get merged with the given sub-attributes
+		if newAttrs, ok := value.(map[string]interface{}); ok {
+			for subName, subValue := range newAttrs {
+				err := applyAttribute(existingValue, op, subName, subValue)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
+	attrs[key] = value
+	return nil
+}
+
+// applyFiltered applies operation to values of multi-valued attribute, which match the filter of a given path. If
+// nothing matches when adding or replacing, then a new value gets created
+func applyFiltered(attrs map[string]interface{}, op string, p *path, value interface{}) error {
+	key := findKey(attrs, p.attribute)
+	if len(key) <= 0 {
+		key = p.attribute
+	}
+	values, _ := attrs[key].([]interface{})
+
+	if op == OpRemove {
+		if values == nil {
+			return nil
+		}
+		if len(p.subAttribute) <= 0 {
+			attrs[key] = removeMatching(values, func(v interface{}) bool {
+				m, ok := v.(map[string]interface{})
+				return ok && p.filter.Matches(m)
+			})
+			return nil
+		}
+		for _, v := range values {
+			if m, ok := v.(map[string]interface{}); ok && p.filter.Matches(m) {
+				delete(m, findKey(m, p.subAttribute))
+			}
+		}
+		return nil
+	}
+
+	matched := false
+	for _, v := range values {
+		m, ok := v.(map[string]interface{})
+		if !ok || !p.filter.Matches(m) {
+			continue
+		}
+		matched = true
+		if len(p.subAttribute) > 0 {
+			err := applyAttribute(m, op, p.subAttribute, value)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		newAttrs, ok := value.(map[string]interface{})
+		if !ok {
+			return NewError(http.StatusBadRequest, ErrorInvalidValue, "value for path '%s' should be an object", p.attribute)
+		}
+		for name, v := range newAttrs {
+			err := applyAttribute(m, op, name, v)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if !matched {
+		created := map[string]interface{}{p.filter.Attribute: p.filter.Value}
+		if len(p.subAttribute) > 0 {
+			created[p.subAttribute] = value
+		} else if newAttrs, ok := value.(map[string]interface{}); ok {
+			for name, v := range newAttrs {
+				created[name] = v
+			}
+		} else {
+			return NewError(http.StatusBadRequest, ErrorInvalidValue, "value for path '%s' should be an object", p.attribute)
+		}
+		attrs[key] = append(values, created)
+	}
+	return nil
+}
+
+func parsePath(pathStr string) (*path, error) {
+	match := pathRegex.FindStringSubmatch(pathStr)
+	if match == nil {
+		return nil, NewError(http.StatusBadRequest, ErrorInvalidPath, "invalid path '%s'", pathStr)
+	}
+	result := &path{attribute: match[1], subAttribute: match[3]}
+	if len(match[2]) > 0 {
+		filter, err := ParseFilter(match[2])
+		if err != nil {
+			return nil, NewError(http.StatusBadRequest, ErrorInvalidPath, "invalid filter in path '%s': %s", pathStr, err)
+		}
+		result.filter = filter
+	}
+	return result, nil
+}
+
+// getOrCreateMap returns complex attribute with a given name, creating it if needed. If it doesn't exist and it
+// shouldn't be created, then nil is returned
+func getOrCreateMap(attrs map[string]interface{}, name string, create bool) map[string]interface{} {
+	key := findKey(attrs, name)
+	if m, ok := attrs[key].(map[string]interface{}); ok {
+		return m
+	}
+	if !create {
+		return nil
+	}
+	m := make(map[string]interface{})
+	attrs[name] = m
+	return m
+}
+
+func removeMatching(values []interface{}, matches func(v interface{}) bool) []interface{} {
+	result := []interface{}{}
+	for _, v := range values {
+		if !matches(v) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if sameValue(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameValue returns true if two values of multi-valued attribute are the same. Complex values (e.g. group members)
+// are compared by their value sub-attribute
+func sameValue(a interface{}, b interface{}) bool {
+	aMap, aIsMap := a.(map[string]interface{})
+	bMap, bIsMap := b.(map[string]interface{})
+	if aIsMap && bIsMap {
+		aValue, bValue := aMap[findKey(aMap, "value")], bMap[findKey(bMap, "value")]
+		return aValue != nil && reflect.DeepEqual(aValue, bValue)
+	}
+	return reflect.DeepEqual(a, b)
+}
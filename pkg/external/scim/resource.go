@@ -0,0 +1,275 @@
+package scim
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SCIM schemas and message types
+const (
+	SchemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SchemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	SchemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+// Supported resource types
+const (
+	ResourceUser  = "User"
+	ResourceGroup = "Group"
+)
+
+// ContentType is the content type of SCIM requests and responses
+const ContentType = "application/scim+json"
+
+// Error types, which are returned along with 400 Bad Request and 409 Conflict
+const (
+	ErrorInvalidFilter = "invalidFilter"
+	ErrorInvalidPath   = "invalidPath"
+	ErrorInvalidSyntax = "invalidSyntax"
+	ErrorInvalidValue  = "invalidValue"
+	ErrorNoTarget      = "noTarget"
+	ErrorUniqueness    = "uniqueness"
+)
+
+// Resource is a SCIM resource (user or group) represented as a map of its attributes, exactly as it has been
+// received from identity provider. Attribute names are case insensitive
+type Resource map[string]interface{}
+
+// Error is SCIM error response
+type Error struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail"`
+}
+
+// NewError returns SCIM error with a given HTTP status and error type (which can be empty)
+func NewError(status int, scimType string, format string, args ...interface{}) *Error {
+	return &Error{
+		Schemas:  []string{SchemaError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   fmt.Sprintf(format, args...),
+	}
+}
+
+// Error returns error details
+func (err *Error) Error() string {
+	return err.Detail
+}
+
+// StatusCode returns HTTP status of the error
+func (err *Error) StatusCode() int {
+	status, convErr := strconv.Atoi(err.Status)
+	if convErr != nil {
+		return http.StatusInternalServerError
+	}
+	return status
+}
+
+// ListResponse is SCIM response with a page of resources
+type ListResponse struct {
+	Schemas      []string   `json:"schemas"`
+	TotalResults int        `json:"totalResults"`
+	StartIndex   int        `json:"startIndex"`
+	ItemsPerPage int        `json:"itemsPerPage"`
+	Resources    []Resource `json:"Resources"`
+}
+
+// NewListResponse returns a page of given resources, which starts at a given 1-based index and contains at most count
+// resources. Negative count means no limit
+func NewListResponse(resources []Resource, startIndex int, count int) *ListResponse {
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	page := []Resource{}
+	if startIndex <= len(resources) {
+		page = resources[startIndex-1:]
+	}
+	if count >= 0 && len(page) > count {
+		page = page[:count]
+	}
+	return &ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: len(resources),
+		StartIndex:   startIndex,
+		ItemsPerPage: len(page),
+		Resources:    page,
+	}
+}
+
+// ServiceProviderConfig returns SCIM service provider configuration, which tells identity providers what's supported
+func ServiceProviderConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"schemas":        []string{SchemaServiceProviderConfig},
+		"patch":          map[string]interface{}{"supported": true},
+		"bulk":           map[string]interface{}{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]interface{}{"supported": true, "maxResults": 0},
+		"changePassword": map[string]interface{}{"supported": false},
+		"sort":           map[string]interface{}{"supported": false},
+		"etag":           map[string]interface{}{"supported": false},
+		"authenticationSchemes": []map[string]interface{}{
+			{
+				"type":        "oauthbearertoken",
+				"name":        "OAuth Bearer Token",
+				"description": "Authentication with the bearer token from Aptomi server config",
+			},
+		},
+	}
+}
+
+// Name returns the unique name of the resource, which is userName for users and displayName for groups
+func (resource Resource) Name(resourceType string) string {
+	if resourceType == ResourceGroup {
+		return resource.GetString("displayName")
+	}
+	return resource.GetString("userName")
+}
+
+// GetString returns the value of a given top-level string attribute or empty string if it's not set
+func (resource Resource) GetString(name string) string {
+	if value, ok := resource[findKey(resource, name)].(string); ok {
+		return value
+	}
+	return ""
+}
+
+// Active returns false if the user has been deactivated by identity provider. Some providers send it as a string
+func (resource Resource) Active() bool {
+	switch value := resource[findKey(resource, "active")].(type) {
+	case bool:
+		return value
+	case string:
+		return !strings.EqualFold(value, "false")
+	}
+	return true
+}
+
+// MemberIDs returns IDs of group members
+func (resource Resource) MemberIDs() []string {
+	result := []string{}
+	members, _ := resource[findKey(resource, "members")].([]interface{})
+	for _, member := range members {
+		if m, ok := member.(map[string]interface{}); ok {
+			if id, ok := m[findKey(m, "value")].(string); ok {
+				result = append(result, id)
+			}
+		}
+	}
+	return result
+}
+
+// Attributes returns all attributes of the resource flattened into a map of lowercase attribute names to their string
+// values. Sub-attributes are named as attribute.subattribute (e.g. name.givenname), attributes from schema extensions
+// (e.g. enterprise user) are named without schema (e.g. department). Multi-valued attributes (e.g. emails) get the
+// primary (or first) value, and values of a certain type are available as attribute.type (e.g. emails.work)
+func (resource Resource) Attributes() map[string]string {
+	result := make(map[string]string)
+	flatten(result, "", resource)
+	return result
+}
+
+func flatten(result map[string]string, prefix string, attrs map[string]interface{}) {
+	for name, value := range attrs {
+		key := prefix + strings.ToLower(name)
+		if strings.HasPrefix(key, "urn:") {
+			key = ""
+		}
+
+		switch v := value.(type) {
+		case nil:
+			continue
+		case map[string]interface{}:
+			if len(key) > 0 {
+				key += "."
+			}
+			flatten(result, key, v)
+		case []interface{}:
+			flattenMultiValued(result, key, v)
+		default:
+			result[key] = fmt.Sprint(v)
+		}
+	}
+}
+
+func flattenMultiValued(result map[string]string, key string, values []interface{}) {
+	primary := -1
+	for i, value := range values {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			if primary < 0 {
+				result[key] = fmt.Sprint(value)
+				primary = i
+			}
+			continue
+		}
+		v := m[findKey(m, "value")]
+		if v == nil {
+			continue
+		}
+		if typ, ok := m[findKey(m, "type")].(string); ok {
+			result[key+"."+strings.ToLower(typ)] = fmt.Sprint(v)
+		}
+		if isPrimary, _ := m[findKey(m, "primary")].(bool); primary < 0 || isPrimary {
+			result[key] = fmt.Sprint(v)
+			primary = i
+		}
+	}
+}
+
+// WithoutReadOnly returns a copy of the resource without attributes, which are assigned by the service provider and
+// can't be changed by identity provider (id, meta and groups of a user)
+func (resource Resource) WithoutReadOnly() Resource {
+	result := Resource{}
+	for name, value := range resource {
+		switch strings.ToLower(name) {
+		case "id", "meta", "groups":
+			continue
+		}
+		result[name] = value
+	}
+	return result
+}
+
+// WithMeta returns a copy of the resource with a given id, schema (if not set) and meta attributes
+func (resource Resource) WithMeta(id string, resourceType string, created time.Time, lastModified time.Time, location string) Resource {
+	result := Resource{}
+	for name, value := range resource {
+		result[name] = value
+	}
+	result["id"] = id
+	if len(findKey(result, "schemas")) <= 0 {
+		schema := SchemaUser
+		if resourceType == ResourceGroup {
+			schema = SchemaGroup
+		}
+		result["schemas"] = []string{schema}
+	}
+	result["meta"] = map[string]interface{}{
+		"resourceType": resourceType,
+		"created":      created.UTC().Format(time.RFC3339),
+		"lastModified": lastModified.UTC().Format(time.RFC3339),
+		"location":     location,
+	}
+	return result
+}
+
+// findKey returns the key of a given attribute in a map, as attribute names are case insensitive. If attribute
+// doesn't exist, then empty string is returned
+func findKey(attrs map[string]interface{}, name string) string {
+	if _, ok := attrs[name]; ok {
+		return name
+	}
+	for key := range attrs {
+		if strings.EqualFold(key, name) {
+			return key
+		}
+	}
+	return ""
+}
@@ -0,0 +1,187 @@
+package scim
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func parseResource(t *testing.T, data string) Resource {
+	t.Helper()
+	resource := Resource{}
+	if !assert.NoError(t, json.Unmarshal([]byte(data), &resource), "Resource should be valid JSON") {
+		t.FailNow()
+	}
+	return resource
+}
+
+func parsePatch(t *testing.T, data string) []*PatchOperation {
+	t.Helper()
+	patch := &PatchRequest{}
+	if !assert.NoError(t, json.Unmarshal([]byte(data), patch), "Patch should be valid JSON") {
+		t.FailNow()
+	}
+	return patch.Operations
+}
+
+func TestResourceAttributes(t *testing.T) {
+	user := parseResource(t, `{
+		"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+		"userName": "alice@example.com",
+		"name": {"givenName": "Alice", "familyName": "Smith"},
+		"emails": [
+			{"value": "alice@home.com", "type": "home"},
+			{"value": "alice@example.com", "type": "work", "primary": true}
+		],
+		"active": "False",
+		"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User": {"department": "dev"}
+	}`)
+
+	attrs := user.Attributes()
+	assert.Equal(t, "alice@example.com", attrs["username"], "Attribute names should be lowercase")
+	assert.Equal(t, "Alice", attrs["name.givenname"], "Sub-attributes should be flattened")
+	assert.Equal(t, "alice@example.com", attrs["emails"], "Primary value of multi-valued attribute should be used")
+	assert.Equal(t, "alice@home.com", attrs["emails.home"], "Values of multi-valued attribute should be available by type")
+	assert.Equal(t, "dev", attrs["department"], "Extension attributes should be flattened without schema")
+
+	assert.Equal(t, "alice@example.com", user.Name(ResourceUser), "User name should be taken from userName")
+	assert.False(t, user.Active(), "User should be inactive")
+	assert.True(t, Resource{}.Active(), "User should be active by default")
+
+	group := parseResource(t, `{"displayName": "developers", "members": [{"value": "1"}, {"value": "2"}]}`)
+	assert.Equal(t, "developers", group.Name(ResourceGroup), "Group name should be taken from displayName")
+	assert.Equal(t, []string{"1", "2"}, group.MemberIDs(), "Group members should be retrieved")
+
+	withMeta := group.WithMeta("42", ResourceGroup, time.Time{}, time.Time{}, "/Groups/42")
+	assert.Equal(t, "42", withMeta["id"], "Resource ID should be set")
+	assert.Equal(t, []string{SchemaGroup}, withMeta["schemas"], "Resource schema should be set")
+	assert.NotContains(t, withMeta.WithoutReadOnly(), "id", "Read-only attributes should be removed")
+	assert.NotContains(t, withMeta.WithoutReadOnly(), "meta", "Read-only attributes should be removed")
+}
+
+func TestFilter(t *testing.T) {
+	filter, err := ParseFilter(`userName eq "Alice@example.com"`)
+	if assert.NoError(t, err, "Filter should be parsed") {
+		assert.True(t, filter.Matches(Resource{"userName": "alice@example.com"}), "Filter should be case insensitive")
+		assert.False(t, filter.Matches(Resource{"userName": "bob@example.com"}), "Filter should not match different value")
+		assert.False(t, filter.Matches(Resource{}), "Filter should not match missing attribute")
+	}
+
+	filter, err = ParseFilter(`externalId EQ "a \"quoted\" id"`)
+	if assert.NoError(t, err, "Filter with escaped quotes should be parsed") {
+		assert.Equal(t, `a "quoted" id`, filter.Value, "Filter value should be unquoted")
+	}
+
+	for _, invalid := range []string{`userName sw "alice"`, `userName eq alice`, `userName eq "a" and active eq "true"`} {
+		_, err = ParseFilter(invalid)
+		if assert.Error(t, err, "Unsupported filter should not be parsed: %s", invalid) {
+			assert.Equal(t, http.StatusBadRequest, err.(*Error).StatusCode(), "Invalid filter should result in bad request")
+			assert.Equal(t, ErrorInvalidFilter, err.(*Error).ScimType, "Invalid filter error type should be returned")
+		}
+	}
+}
+
+func TestPatchUser(t *testing.T) {
+	user := parseResource(t, `{
+		"userName": "alice@example.com",
+		"active": true,
+		"name": {"givenName": "Alice"},
+		"emails": [{"value": "alice@example.com", "type": "work"}]
+	}`)
+
+	// azure style operations with paths and capitalized names
+	err := user.Patch(parsePatch(t, `{"Operations": [
+		{"op": "Replace", "path": "active", "value": "False"},
+		{"op": "Replace", "path": "name.familyName", "value": "Smith"},
+		{"op": "Replace", "path": "emails[type eq \"work\"].value", "value": "asmith@example.com"},
+		{"op": "Add", "path": "emails[type eq \"home\"].value", "value": "alice@home.com"},
+		{"op": "Add", "path": "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department", "value": "dev"}
+	]}`))
+	assert.NoError(t, err, "Patch should be applied")
+
+	attrs := user.Attributes()
+	assert.False(t, user.Active(), "User should be deactivated")
+	assert.Equal(t, "Alice", attrs["name.givenname"], "Complex attribute should be merged")
+	assert.Equal(t, "Smith", attrs["name.familyname"], "Sub-attribute should be replaced")
+	assert.Equal(t, "asmith@example.com", attrs["emails.work"], "Filtered value should be replaced")
+	assert.Equal(t, "alice@home.com", attrs["emails.home"], "Filtered value should be added")
+	assert.Equal(t, "dev", attrs["department"], "Extension attribute should be added")
+
+	// okta style operations without path
+	err = user.Patch(parsePatch(t, `{"Operations": [{"op": "replace", "value": {"active": true, "name.givenName": "Alicia"}}]}`))
+	assert.NoError(t, err, "Patch should be applied")
+	assert.True(t, user.Active(), "User should be activated")
+	assert.Equal(t, "Alicia", user.Attributes()["name.givenname"], "Sub-attribute should be replaced")
+
+	err = user.Patch(parsePatch(t, `{"Operations": [
+		{"op": "remove", "path": "emails[type eq \"home\"]"},
+		{"op": "remove", "path": "name.givenName"}
+	]}`))
+	assert.NoError(t, err, "Patch should be applied")
+	attrs = user.Attributes()
+	assert.Empty(t, attrs["emails.home"], "Filtered value should be removed")
+	assert.Empty(t, attrs["name.givenname"], "Sub-attribute should be removed")
+	assert.Equal(t, "asmith@example.com", attrs["emails.work"], "Other values should be kept")
+
+	for _, invalid := range []string{
+		`{"Operations": [{"op": "move", "path": "active"}]}`,
+		`{"Operations": [{"op": "remove"}]}`,
+		`{"Operations": [{"op": "add", "value": "x"}]}`,
+		`{"Operations": [{"op": "add", "path": "emails[type sw \"w\"].value", "value": "x"}]}`,
+	} {
+		err = user.Patch(parsePatch(t, invalid))
+		if assert.Error(t, err, "Invalid patch should not be applied: %s", invalid) {
+			assert.Equal(t, http.StatusBadRequest, err.(*Error).StatusCode(), "Invalid patch should result in bad request")
+		}
+	}
+}
+
+func TestPatchGroupMembers(t *testing.T) {
+	group := parseResource(t, `{"displayName": "developers", "members": [{"value": "1"}]}`)
+
+	err := group.Patch(parsePatch(t, `{"Operations": [
+		{"op": "add", "path": "members", "value": [{"value": "1"}, {"value": "2"}, {"value": "3"}]},
+		{"op": "replace", "path": "displayName", "value": "devs"}
+	]}`))
+	assert.NoError(t, err, "Patch should be applied")
+	assert.Equal(t, []string{"1", "2", "3"}, group.MemberIDs(), "Members should be added once")
+	assert.Equal(t, "devs", group.Name(ResourceGroup), "Group should be renamed")
+
+	err = group.Patch(parsePatch(t, `{"Operations": [
+		{"op": "remove", "path": "members[value eq \"1\"]"},
+		{"op": "Remove", "path": "members", "value": [{"value": "3"}]}
+	]}`))
+	assert.NoError(t, err, "Patch should be applied")
+	assert.Equal(t, []string{"2"}, group.MemberIDs(), "Members should be removed")
+
+	err = group.Patch(parsePatch(t, `{"Operations": [{"op": "replace", "path": "members", "value": [{"value": "4"}]}]}`))
+	assert.NoError(t, err, "Patch should be applied")
+	assert.Equal(t, []string{"4"}, group.MemberIDs(), "Members should be replaced")
+
+	err = group.Patch(parsePatch(t, `{"Operations": [{"op": "remove", "path": "members"}]}`))
+	assert.NoError(t, err, "Patch should be applied")
+	assert.Empty(t, group.MemberIDs(), "All members should be removed")
+}
+
+func TestListResponse(t *testing.T) {
+	resources := []Resource{{"id": "1"}, {"id": "2"}, {"id": "3"}}
+
+	page := NewListResponse(resources, 2, 1)
+	assert.Equal(t, 3, page.TotalResults, "Total number of resources should be returned")
+	assert.Equal(t, 1, page.ItemsPerPage, "Page should be limited by count")
+	assert.Equal(t, "2", page.Resources[0]["id"], "Page should start at a given index")
+
+	page = NewListResponse(resources, 0, -1)
+	assert.Equal(t, 3, page.ItemsPerPage, "All resources should be returned if count is not limited")
+	assert.Equal(t, 1, page.StartIndex, "Start index should be 1-based")
+
+	page = NewListResponse(resources, 1, 0)
+	assert.Equal(t, 3, page.TotalResults, "Total number of resources should be returned")
+	assert.Empty(t, page.Resources, "No resources should be returned for zero count")
+
+	page = NewListResponse(resources, 10, -1)
+	assert.Empty(t, page.Resources, "Page beyond the end should be empty")
+}
@@ -0,0 +1,142 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// keySize is the size of master and data keys in bytes (AES-256)
+const keySize = 32
+
+// Sealed is a secret value encrypted with envelope encryption. Value is encrypted with its own random data key, which
+// in turn is encrypted with the master key. Both are stored base64 encoded, with the nonce prepended
+type Sealed struct {
+	// KeyID identifies master key the data key has been encrypted with
+	KeyID string
+
+	// DataKey is the data key encrypted with the master key
+	DataKey string
+
+	// Value is the secret value encrypted with the data key
+	Value string
+}
+
+// Envelope encrypts and decrypts secret values using envelope encryption (AES-256-GCM) with a given master key
+type Envelope struct {
+	keyID  string
+	master cipher.AEAD
+}
+
+// ParseKey decodes base64 encoded master key, which can be generated with 'openssl rand -base64 32'
+func ParseKey(key string) ([]byte, error) {
+	result, err := base64.StdEncoding.DecodeString(strings.TrimSpace(key))
+	if err != nil {
+		return nil, fmt.Errorf("master key should be base64 encoded: %s", err)
+	}
+	return result, nil
+}
+
+// NewEnvelope returns Envelope for a given master key, which should be 32 bytes long
+func NewEnvelope(masterKey []byte) (*Envelope, error) {
+	if len(masterKey) != keySize {
+		return nil, fmt.Errorf("master key should be %d bytes long, but it's %d bytes long", keySize, len(masterKey))
+	}
+	master, err := newAEAD(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(masterKey)
+	return &Envelope{
+		keyID:  hex.EncodeToString(hash[:])[:16],
+		master: master,
+	}, nil
+}
+
+// Seal encrypts a given secret value with a new random data key. Both get bound to a given additional data (e.g. name of
+// the secret), so sealed value can only be opened with the same additional data and can't be swapped with another one
+func (envelope *Envelope) Seal(value string, additionalData string) (*Sealed, error) {
+	dataKey := make([]byte, keySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, fmt.Errorf("error while generating data key: %s", err)
+	}
+	data, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedKey, err := seal(envelope.master, dataKey, []byte(additionalData))
+	if err != nil {
+		return nil, err
+	}
+	encryptedValue, err := seal(data, []byte(value), []byte(additionalData))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Sealed{
+		KeyID:   envelope.keyID,
+		DataKey: encryptedKey,
+		Value:   encryptedValue,
+	}, nil
+}
+
+// Open decrypts a given sealed secret value, which has been sealed with a given additional data
+func (envelope *Envelope) Open(sealed *Sealed, additionalData string) (string, error) {
+	if sealed == nil {
+		return "", fmt.Errorf("secret value is missing")
+	}
+	if sealed.KeyID != envelope.keyID {
+		return "", fmt.Errorf("secret value has been encrypted with a different master key %s", sealed.KeyID)
+	}
+
+	dataKey, err := open(envelope.master, sealed.DataKey, []byte(additionalData))
+	if err != nil {
+		return "", fmt.Errorf("error while decrypting data key: %s", err)
+	}
+	data, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	value, err := open(data, sealed.Value, []byte(additionalData))
+	if err != nil {
+		return "", fmt.Errorf("error while decrypting secret value: %s", err)
+	}
+
+	return string(value), nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating cipher: %s", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext bound to additional data and returns base64 encoded nonce followed by ciphertext
+func seal(aead cipher.AEAD, plaintext []byte, additionalData []byte) (string, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("error while generating nonce: %s", err)
+	}
+	return base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, plaintext, additionalData)), nil
+}
+
+// open decrypts base64 encoded nonce followed by ciphertext, which is bound to additional data
+func open(aead cipher.AEAD, sealed string, additionalData []byte) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf("encrypted data is too short")
+	}
+	return aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], additionalData)
+}
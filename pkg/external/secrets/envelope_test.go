@@ -0,0 +1,54 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvelope(t *testing.T) {
+	key, err := ParseKey(base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32)))
+	assert.NoError(t, err, "Master key should be parsed")
+	envelope, err := NewEnvelope(key)
+	if !assert.NoError(t, err, "Envelope should be created") {
+		t.FailNow()
+	}
+
+	sealed, err := envelope.Seal("alicepassword", "alice/password")
+	assert.NoError(t, err, "Value should be encrypted")
+	assert.NotContains(t, sealed.Value, "alicepassword", "Value should be encrypted")
+
+	value, err := envelope.Open(sealed, "alice/password")
+	assert.NoError(t, err, "Value should be decrypted")
+	assert.Equal(t, "alicepassword", value, "Value should be decrypted")
+
+	// value is bound to the secret name, so it can't be opened as another secret
+	_, err = envelope.Open(sealed, "bob/password")
+	assert.Error(t, err, "Value should not be decrypted with different additional data")
+
+	// every value gets its own data key
+	sealedAgain, err := envelope.Seal("alicepassword", "alice/password")
+	assert.NoError(t, err, "Value should be encrypted")
+	assert.NotEqual(t, sealed.DataKey, sealedAgain.DataKey, "Every value should get its own data key")
+	assert.NotEqual(t, sealed.Value, sealedAgain.Value, "Same value should be encrypted differently")
+
+	// tampered value can't be decrypted
+	tampered := *sealed
+	tampered.Value = sealedAgain.Value
+	_, err = envelope.Open(&tampered, "alice/password")
+	assert.Error(t, err, "Value encrypted with a different data key should not be decrypted")
+
+	// value can't be decrypted with a different master key
+	otherEnvelope, err := NewEnvelope(bytes.Repeat([]byte{2}, 32))
+	assert.NoError(t, err, "Envelope should be created")
+	_, err = otherEnvelope.Open(sealed, "alice/password")
+	assert.Error(t, err, "Value should not be decrypted with a different master key")
+
+	// invalid keys
+	_, err = NewEnvelope([]byte("short"))
+	assert.Error(t, err, "Short master key should not be accepted")
+	_, err = ParseKey("not base64!")
+	assert.Error(t, err, "Master key which isn't base64 encoded should not be accepted")
+}
@@ -0,0 +1,195 @@
+package secrets
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/Aptomi/aptomi/pkg/util"
+)
+
+// Reference is a reference to a secret of a certain user. Templates only get placeholders for secrets, and policy
+// resolver records references for placeholders found in code params of a component instance. Only recorded
+// references get replaced with actual secret values when code is being deployed, so a placeholder which ended up in
+// code params in any other way (e.g. via claim labels) is never resolved
+type Reference struct {
+	// User is the name of the user, who owns the secret. It's always the user of the claim being resolved
+	User string
+
+	// Name is the name of the secret
+	Name string
+
+	// Fingerprint changes when secret value changes, so that component gets updated. It's HMAC of the value keyed
+	// with a server-side key, so it can't be used to test guesses of the secret. It's empty if there is no key
+	Fingerprint string `yaml:",omitempty"`
+}
+
+// Placeholder returns a placeholder for user's secret, which gets exposed to templates instead of the actual value
+func Placeholder(userName string, secretName string) string {
+	return fmt.Sprintf("[[secret:%s/%s]]", userName, secretName)
+}
+
+// NewFingerprintKey derives the key for secret fingerprints from a given server-side secret
+func NewFingerprintKey(secret string) []byte {
+	if len(secret) <= 0 {
+		return nil
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("secret-fingerprint")) // nolint: errcheck
+	return mac.Sum(nil)
+}
+
+// FindReferences returns references to secrets of a given user, for which placeholders are present in any of given
+// parameters (placeholder -> reference). Fingerprints get calculated with a given key
+func FindReferences(userName string, userSecrets map[string]string, key []byte, params ...util.NestedParameterMap) map[string]*Reference {
+	result := make(map[string]*Reference)
+	for name, value := range userSecrets {
+		placeholder := Placeholder(userName, name)
+		found := false
+		for _, p := range params {
+			found = found || containsString(p, placeholder)
+		}
+		if !found {
+			continue
+		}
+		ref := &Reference{User: userName, Name: name}
+		if len(key) > 0 {
+			mac := hmac.New(sha256.New, key)
+			mac.Write([]byte(userName + "/" + name + "/" + value)) // nolint: errcheck
+			ref.Fingerprint = hex.EncodeToString(mac.Sum(nil))
+		}
+		result[placeholder] = ref
+	}
+	return result
+}
+
+// SameReferences returns true if two sets of references refer to the same secrets with the same values
+func SameReferences(refs map[string]*Reference, other map[string]*Reference) bool {
+	if len(refs) != len(other) {
+		return false
+	}
+	for placeholder, ref := range refs {
+		otherRef, ok := other[placeholder]
+		if !ok || *ref != *otherRef {
+			return false
+		}
+	}
+	return true
+}
+
+// ResolveReferences returns a copy of given parameters with placeholders of given references replaced by actual
+// secret values, which get loaded via given secret loader. It also returns the list of all substituted values, so
+// they can be masked
+func ResolveReferences(params util.NestedParameterMap, refs map[string]*Reference, loader SecretLoader) (util.NestedParameterMap, []string, error) {
+	if params == nil {
+		return nil, nil, nil
+	}
+	r := &referenceResolver{
+		refs:    refs,
+		loader:  loader,
+		secrets: make(map[string]map[string]string),
+	}
+	result, err := r.resolve(params)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result.(util.NestedParameterMap), r.values, nil
+}
+
+type referenceResolver struct {
+	refs    map[string]*Reference
+	loader  SecretLoader
+	secrets map[string]map[string]string
+	values  []string
+}
+
+func (r *referenceResolver) resolve(node interface{}) (interface{}, error) {
+	switch value := node.(type) {
+	case string:
+		return r.resolveString(value)
+	case util.NestedParameterMap:
+		result := util.NestedParameterMap{}
+		for k, v := range value {
+			resolved, err := r.resolve(v)
+			if err != nil {
+				return nil, err
+			}
+			result[k] = resolved
+		}
+		return result, nil
+	case map[string]interface{}:
+		result := make(map[string]interface{})
+		for k, v := range value {
+			resolved, err := r.resolve(v)
+			if err != nil {
+				return nil, err
+			}
+			result[k] = resolved
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(value))
+		for i, v := range value {
+			resolved, err := r.resolve(v)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = resolved
+		}
+		return result, nil
+	}
+	return node, nil
+}
+
+func (r *referenceResolver) resolveString(str string) (string, error) {
+	for placeholder, ref := range r.refs {
+		if !strings.Contains(str, placeholder) {
+			continue
+		}
+
+		userSecrets, ok := r.secrets[ref.User]
+		if !ok {
+			userSecrets = r.loader.LoadSecretsByUserName(ref.User)
+			r.secrets[ref.User] = userSecrets
+		}
+
+		secretValue, ok := userSecrets[ref.Name]
+		if !ok {
+			return str, fmt.Errorf("secret '%s' not found for user '%s'", ref.Name, ref.User)
+		}
+		if len(secretValue) > 0 {
+			r.values = append(r.values, secretValue)
+		}
+		str = strings.Replace(str, placeholder, secretValue, -1)
+	}
+	return str, nil
+}
+
+// containsString returns true if any string in given parameters contains a given substring
+func containsString(node interface{}, substr string) bool {
+	switch value := node.(type) {
+	case string:
+		return strings.Contains(value, substr)
+	case util.NestedParameterMap:
+		for _, v := range value {
+			if containsString(v, substr) {
+				return true
+			}
+		}
+	case map[string]interface{}:
+		for _, v := range value {
+			if containsString(v, substr) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, v := range value {
+			if containsString(v, substr) {
+				return true
+			}
+		}
+	}
+	return false
+}
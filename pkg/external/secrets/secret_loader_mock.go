@@ -14,6 +14,9 @@ func NewSecretLoaderMock() *SecretLoaderMock {
 
 // AddSecret adds a secret for a given user
 func (loader *SecretLoaderMock) AddSecret(userName string, secretName string, secretValue string) {
+	if loader.secrets[userName] == nil {
+		loader.secrets[userName] = make(map[string]string)
+	}
 	loader.secrets[userName][secretName] = secretValue
 }
 
@@ -0,0 +1,26 @@
+package secrets
+
+// SecretLoaderMultipleSources allows to combine different secret sources into a single loader. Secrets of a user get
+// merged from all sources, and if the same secret is defined in several of them, the one from the last source wins
+type SecretLoaderMultipleSources struct {
+	loaders []SecretLoader
+}
+
+// NewSecretLoaderMultipleSources returns new SecretLoaderMultipleSources
+func NewSecretLoaderMultipleSources(loaders []SecretLoader) *SecretLoaderMultipleSources {
+	return &SecretLoaderMultipleSources{loaders: loaders}
+}
+
+// LoadSecretsByUserName loads secrets for a single user from all sources
+func (loader *SecretLoaderMultipleSources) LoadSecretsByUserName(user string) map[string]string {
+	var result map[string]string
+	for _, l := range loader.loaders {
+		for name, value := range l.LoadSecretsByUserName(user) {
+			if result == nil {
+				result = make(map[string]string)
+			}
+			result[name] = value
+		}
+	}
+	return result
+}
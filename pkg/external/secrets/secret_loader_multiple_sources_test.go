@@ -0,0 +1,22 @@
+package secrets
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecretLoaderMultipleSources(t *testing.T) {
+	first := NewSecretLoaderMock()
+	first.AddSecret("alice", "token", "oldtoken")
+	first.AddSecret("alice", "password", "alicepassword")
+
+	second := NewSecretLoaderMock()
+	second.AddSecret("alice", "token", "newtoken")
+	second.AddSecret("bob", "token", "bobtoken")
+
+	loader := NewSecretLoaderMultipleSources([]SecretLoader{first, second})
+	assert.Equal(t, map[string]string{"token": "newtoken", "password": "alicepassword"}, loader.LoadSecretsByUserName("alice"), "Secrets should be merged, with the last source taking precedence")
+	assert.Equal(t, map[string]string{"token": "bobtoken"}, loader.LoadSecretsByUserName("bob"), "Secrets should be loaded from any source")
+	assert.Nil(t, loader.LoadSecretsByUserName("carol"), "User without secrets should have no secrets")
+}
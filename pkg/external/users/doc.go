@@ -1,2 +1,3 @@
-// Package users implements support for retrieving Users and their labels from external sources (LDAP, File).
+// Package users implements support for retrieving Users and their labels from external sources (LDAP, File, Webhook,
+// OpenID Connect).
 package users
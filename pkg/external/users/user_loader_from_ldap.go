@@ -3,6 +3,7 @@ package users
 import (
 	"crypto/tls"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -14,11 +15,21 @@ import (
 	"gopkg.in/ldap.v2"
 )
 
+// ldapConn is a connection to LDAP server. It's satisfied by *ldap.Conn and allows to replace LDAP server in tests
+type ldapConn interface {
+	StartTLS(config *tls.Config) error
+	Bind(username, password string) error
+	Search(searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error)
+	Close()
+}
+
 // UserLoaderFromLDAP allows aptomi to load users from LDAP
 type UserLoaderFromLDAP struct {
 	cfg                  config.LDAP
 	cache                *cache.Cache
+	groupCache           *cache.Cache
 	domainAdminOverrides map[string]bool
+	dial                 func() (ldapConn, error)
 }
 
 // NewUserLoaderFromLDAP returns new UserLoaderFromLDAP, given location with LDAP configuration file (with host/port and mapping)
@@ -26,7 +37,11 @@ func NewUserLoaderFromLDAP(cfg config.LDAP, domainAdminOverrides map[string]bool
 	return &UserLoaderFromLDAP{
 		cfg:                  cfg,
 		cache:                cache.New(time.Minute, time.Minute),
+		groupCache:           cache.New(cfg.Groups.GetCacheTTL(), cfg.Groups.GetCacheTTL()),
 		domainAdminOverrides: domainAdminOverrides,
+		dial: func() (ldapConn, error) {
+			return ldap.Dial("tcp", fmt.Sprintf("%s:%d", cfg.Host, cfg.Port))
+		},
 	}
 }
 
@@ -78,7 +93,7 @@ func (loader *UserLoaderFromLDAP) Summary() string {
 
 // Does search on LDAP and returns entries
 func (loader *UserLoaderFromLDAP) ldapSearch() ([]*lang.User, error) {
-	l, err := ldap.Dial("tcp", fmt.Sprintf("%s:%d", loader.cfg.Host, loader.cfg.Port))
+	l, err := loader.dial()
 	if err != nil {
 		return nil, err
 	}
@@ -88,7 +103,7 @@ func (loader *UserLoaderFromLDAP) ldapSearch() ([]*lang.User, error) {
 		loader.cfg.BaseDN,
 		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
 		loader.cfg.Filter,
-		loader.cfg.GetAttributes(),
+		loader.attributes(),
 		nil,
 	)
 
@@ -99,7 +114,10 @@ func (loader *UserLoaderFromLDAP) ldapSearch() ([]*lang.User, error) {
 
 	result := []*lang.User{}
 	for _, entry := range searchResult.Entries {
-		user := loader.userFromLDAPEntry(entry)
+		user, err := loader.userFromLDAPEntry(l, entry)
+		if err != nil {
+			return nil, err
+		}
 		result = append(result, user)
 	}
 
@@ -108,7 +126,7 @@ func (loader *UserLoaderFromLDAP) ldapSearch() ([]*lang.User, error) {
 
 // Authenticates a user in ldap
 func (loader *UserLoaderFromLDAP) ldapAuthenticate(name, password string) (*lang.User, error) {
-	l, err := ldap.Dial("tcp", fmt.Sprintf("%s:%d", loader.cfg.Host, loader.cfg.Port))
+	l, err := loader.dial()
 	if err != nil {
 		return nil, err
 	}
@@ -125,7 +143,7 @@ func (loader *UserLoaderFromLDAP) ldapAuthenticate(name, password string) (*lang
 		loader.cfg.BaseDN,
 		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
 		fmt.Sprintf(loader.cfg.FilterByName, name),
-		loader.cfg.GetAttributes(),
+		loader.attributes(),
 		nil,
 	)
 
@@ -149,11 +167,19 @@ func (loader *UserLoaderFromLDAP) ldapAuthenticate(name, password string) (*lang
 		return nil, fmt.Errorf("LDAP bind failed for user '%s': %s", name, err)
 	}
 
-	user := loader.userFromLDAPEntry(entry)
-	return user, nil
+	return loader.userFromLDAPEntry(l, entry)
 }
 
-func (loader *UserLoaderFromLDAP) userFromLDAPEntry(entry *ldap.Entry) *lang.User {
+// Returns the list of attributes to be retrieved for every user from LDAP
+func (loader *UserLoaderFromLDAP) attributes() []string {
+	result := loader.cfg.GetAttributes()
+	if !loader.cfg.Groups.Disabled {
+		result = append(result, loader.cfg.Groups.GetAttribute())
+	}
+	return result
+}
+
+func (loader *UserLoaderFromLDAP) userFromLDAPEntry(l ldapConn, entry *ldap.Entry) (*lang.User, error) {
 	name := entry.GetAttributeValue(loader.cfg.LabelToAttributes["name"])
 	user := &lang.User{
 		Name:   name,
@@ -167,7 +193,112 @@ func (loader *UserLoaderFromLDAP) userFromLDAPEntry(entry *ldap.Entry) *lang.Use
 			}
 		}
 	}
-	return user
+
+	if !loader.cfg.Groups.Disabled {
+		groups, err := loader.resolveGroups(l, entry)
+		if err != nil {
+			return nil, fmt.Errorf("unable to resolve LDAP groups for user '%s': %s", name, err)
+		}
+		user.Groups = groups
+	}
+
+	return user, nil
+}
+
+// Resolves names of the groups a given LDAP entry is a member of. If nested groups are enabled, then groups of
+// groups are resolved as well (cycles in group membership are handled)
+func (loader *UserLoaderFromLDAP) resolveGroups(l ldapConn, entry *ldap.Entry) ([]string, error) {
+	queue := entry.GetAttributeValues(loader.cfg.Groups.GetAttribute())
+	seen := make(map[string]bool)
+	result := []string{}
+	for len(queue) > 0 {
+		groupDN := queue[0]
+		queue = queue[1:]
+		if seen[strings.ToLower(groupDN)] {
+			continue
+		}
+		seen[strings.ToLower(groupDN)] = true
+		result = append(result, ldapGroupName(groupDN, loader.cfg.Groups.NameBaseDN))
+
+		if loader.cfg.Groups.Nested {
+			parents, err := loader.groupParents(l, groupDN)
+			if err != nil {
+				return nil, err
+			}
+			queue = append(queue, parents...)
+		}
+	}
+
+	sort.Strings(result)
+	return result, nil
+}
+
+// Returns DNs of the groups a given group is a member of. Results are cached, so that the same group doesn't get
+// looked up for every user
+func (loader *UserLoaderFromLDAP) groupParents(l ldapConn, groupDN string) ([]string, error) {
+	cachedParents, found := loader.groupCache.Get(strings.ToLower(groupDN))
+	if found {
+		return cachedParents.([]string), nil
+	}
+
+	searchRequest := ldap.NewSearchRequest(
+		groupDN,
+		ldap.ScopeBaseObject, ldap.NeverDerefAliases, 0, 0, false,
+		"(objectClass=*)",
+		[]string{loader.cfg.Groups.GetAttribute()},
+		nil,
+	)
+
+	parents := []string{}
+	searchResult, err := l.Search(searchRequest)
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+		return nil, err
+	}
+	if err == nil {
+		for _, entry := range searchResult.Entries {
+			parents = append(parents, entry.GetAttributeValues(loader.cfg.Groups.GetAttribute())...)
+		}
+	}
+
+	loader.groupCache.Set(strings.ToLower(groupDN), parents, cache.DefaultExpiration)
+	return parents, nil
+}
+
+// Returns group name from its DN. Groups, which are direct children of a given base DN, are named by the value of
+// their RDN (e.g. 'developers' for 'cn=developers,ou=groups,o=aptomiOrg' with 'ou=groups,o=aptomiOrg' base DN), as
+// RDNs of the entries with the same parent are unique. All other groups are named by their full DN, so groups with the
+// same RDN in different OUs don't collide
+func ldapGroupName(groupDN string, nameBaseDN string) string {
+	if len(nameBaseDN) <= 0 {
+		return groupDN
+	}
+	dn, err := ldap.ParseDN(groupDN)
+	if err != nil {
+		return groupDN
+	}
+	baseDN, err := ldap.ParseDN(nameBaseDN)
+	if err != nil || len(dn.RDNs) != len(baseDN.RDNs)+1 || len(dn.RDNs[0].Attributes) != 1 {
+		return groupDN
+	}
+	for i, rdn := range baseDN.RDNs {
+		if !ldapRDNEqual(rdn, dn.RDNs[i+1]) {
+			return groupDN
+		}
+	}
+	return dn.RDNs[0].Attributes[0].Value
+}
+
+// Returns true if given RDNs are equal (attribute types and values are not case sensitive)
+func ldapRDNEqual(rdn *ldap.RelativeDN, other *ldap.RelativeDN) bool {
+	if len(rdn.Attributes) != len(other.Attributes) {
+		return false
+	}
+	for i, attr := range rdn.Attributes {
+		if !strings.EqualFold(attr.Type, other.Attributes[i].Type) || !strings.EqualFold(attr.Value, other.Attributes[i].Value) {
+			return false
+		}
+	}
+	return true
 }
 
 func ldapValue(value string) string {
@@ -1,12 +1,17 @@
 package users
 
 import (
+	"crypto/tls"
+	"fmt"
+	"regexp"
+	"sort"
 	"strings"
 	"testing"
 
 	"github.com/Aptomi/aptomi/pkg/config"
 	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/ldap.v2"
 )
 
 var integrationTestsLDAP = config.LDAP{
@@ -34,7 +39,76 @@ func TestUserLoaderFromLDAP(t *testing.T) {
 	}
 	userLoaderDir := NewUserLoaderFromFile("../../testdata/ldap/users.yaml", make(map[string]bool))
 	userLoaderLDAP := NewUserLoaderFromLDAP(integrationTestsLDAP, make(map[string]bool))
+	checkUserLoaderFromLDAP(t, userLoaderDir, userLoaderLDAP)
+}
+
+func TestUserLoaderFromLDAPStandIn(t *testing.T) {
+	userLoaderDir := NewUserLoaderFromFile("../../testdata/ldap/users.yaml", make(map[string]bool))
+	directory := newFakeLDAP(userLoaderDir.LoadUsersAll(), integrationTestsLDAP, nil)
+	userLoaderLDAP := newUserLoaderFromFakeLDAP(integrationTestsLDAP, directory)
+	checkUserLoaderFromLDAP(t, userLoaderDir, userLoaderLDAP)
+}
+
+func TestUserLoaderFromLDAPGroups(t *testing.T) {
+	userLoaderDir := NewUserLoaderFromFile("../../testdata/ldap/users.yaml", make(map[string]bool))
+	memberOf := map[string][]string{
+		"cn=Alice,ou=people,o=aptomiOrg":          {"cn=developers,ou=groups,o=aptomiOrg"},
+		"cn=Carol,ou=people,o=aptomiOrg":          {"cn=mobile,ou=groups,o=aptomiOrg"},
+		"cn=Sam,ou=people,o=aptomiOrg":            {"cn=ops,ou=groups,o=aptomiOrg", "cn=removed,ou=groups,o=aptomiOrg"},
+		"cn=mobile,ou=groups,o=aptomiOrg":         {"cn=developers,ou=groups,o=aptomiOrg"},
+		"cn=developers,ou=groups,o=aptomiOrg":     {"cn=engineering,ou=groups,o=aptomiOrg"},
+		"cn=ops,ou=groups,o=aptomiOrg":            {"cn=engineering,ou=groups,o=aptomiOrg", "cn=admins,ou=groups,o=aptomiOrg"},
+		"cn=admins,ou=groups,o=aptomiOrg":         {"cn=ops,ou=groups,o=aptomiOrg"},
+		"cn=engineering,ou=groups,o=aptomiOrg":    {},
+		"cn=unused-group,ou=groups,o=aptomiOrg":   {"cn=engineering,ou=groups,o=aptomiOrg"},
+		"cn=unused-group-2,ou=groups,o=aptomiOrg": {},
+		"cn=Bob,ou=people,o=aptomiOrg":            {"cn=admins,ou=contractors,o=aptomiOrg"},
+	}
+	directory := newFakeLDAP(userLoaderDir.LoadUsersAll(), integrationTestsLDAP, memberOf)
+
+	// without name base DN groups are named by their full DN
+	{
+		userLoaderLDAP := newUserLoaderFromFakeLDAP(integrationTestsLDAP, directory)
+		assert.Equal(t, []string{"cn=developers,ou=groups,o=aptomiOrg"}, userLoaderLDAP.LoadUserByName("alice").Groups, "Groups should be named by full DN")
+		assert.Equal(t, []string{"cn=admins,ou=contractors,o=aptomiOrg"}, userLoaderLDAP.LoadUserByName("bob").Groups, "Groups should be named by full DN")
+	}
+
+	// only direct groups
+	cfg := integrationTestsLDAP
+	cfg.Groups.NameBaseDN = "ou=Groups,o=aptomiOrg"
+	{
+		userLoaderLDAP := newUserLoaderFromFakeLDAP(cfg, directory)
+		assert.Equal(t, []string{"developers"}, userLoaderLDAP.LoadUserByName("alice").Groups, "Direct groups should be resolved")
+		assert.Equal(t, []string{"mobile"}, userLoaderLDAP.LoadUserByName("carol").Groups, "Direct groups should be resolved")
+		assert.Equal(t, []string{"ops", "removed"}, userLoaderLDAP.LoadUserByName("sam").Groups, "Direct groups should be resolved")
+		assert.Equal(t, []string{"cn=admins,ou=contractors,o=aptomiOrg"}, userLoaderLDAP.LoadUserByName("bob").Groups, "Groups outside of name base DN should not collide with groups in it")
+	}
+
+	// nested groups (including cycles and groups, which don't exist in LDAP)
+	cfg.Groups.Nested = true
+	userLoaderLDAP := newUserLoaderFromFakeLDAP(cfg, directory)
+	assert.Equal(t, []string{"developers", "engineering"}, userLoaderLDAP.LoadUserByName("alice").Groups, "Nested groups should be resolved")
+	assert.Equal(t, []string{"developers", "engineering", "mobile"}, userLoaderLDAP.LoadUserByName("carol").Groups, "Nested groups should be resolved")
+	assert.Equal(t, []string{"admins", "engineering", "ops", "removed"}, userLoaderLDAP.LoadUserByName("sam").Groups, "Nested groups should be resolved")
+	assert.Empty(t, userLoaderLDAP.LoadUserByName("john").Groups, "User without groups should have no groups")
+
+	// groups are resolved on authentication as well and group lookups are cached
+	lookups := directory.groupLookups
+	user, err := userLoaderLDAP.Authenticate("Carol", "carol")
+	assert.NoError(t, err, "Authentication should be successful")
+	if assert.NotNil(t, user, "User should be returned as a result of authentication") {
+		assert.Equal(t, []string{"developers", "engineering", "mobile"}, user.Groups, "Nested groups should be resolved on authentication")
+	}
+	assert.Equal(t, lookups, directory.groupLookups, "Group lookups should be cached")
+
+	// groups can be disabled
+	cfg.Groups.Disabled = true
+	userLoaderLDAP = newUserLoaderFromFakeLDAP(cfg, directory)
+	assert.Empty(t, userLoaderLDAP.LoadUserByName("alice").Groups, "Groups should not be resolved when disabled")
+}
 
+func checkUserLoaderFromLDAP(t *testing.T, userLoaderDir UserLoader, userLoaderLDAP UserLoader) {
+	t.Helper()
 	usersDir := userLoaderDir.LoadUsersAll()
 	usersLDAP := userLoaderLDAP.LoadUsersAll()
 	assert.Equal(t, len(usersDir.Users), len(usersLDAP.Users), "Correct number of users should be loaded from LDAP")
@@ -98,3 +172,133 @@ func compareUsers(t *testing.T, uDir *lang.User, uLDAP *lang.User) {
 		}
 	}
 }
+
+func newUserLoaderFromFakeLDAP(cfg config.LDAP, directory *fakeLDAP) UserLoader {
+	loader := NewUserLoaderFromLDAP(cfg, make(map[string]bool)).(*UserLoaderFromLDAP)
+	loader.dial = func() (ldapConn, error) {
+		return directory, nil
+	}
+	return loader
+}
+
+// fakeLDAP is an in-process LDAP stand-in, which serves users (with password == lowercase name) and groups. It
+// supports only conjunctions of equality and presence filters, which is enough for the configurations used in tests
+type fakeLDAP struct {
+	entries      map[string]*ldap.Entry
+	passwords    map[string]string
+	groupLookups int
+}
+
+var fakeLDAPFilterRegex = regexp.MustCompile(`\(([^()&|!=]+)=([^()]*)\)`)
+
+// newFakeLDAP creates LDAP stand-in from a given set of users (labels get mapped back to LDAP attributes and label
+// 'ldapDN' is used as user DN). Parameter memberOf defines groups of users and groups by their DN
+func newFakeLDAP(users *lang.GlobalUsers, cfg config.LDAP, memberOf map[string][]string) *fakeLDAP {
+	directory := &fakeLDAP{
+		entries:   make(map[string]*ldap.Entry),
+		passwords: make(map[string]string),
+	}
+	addEntry := func(dn string, attrs map[string][]string) {
+		entry := &ldap.Entry{DN: dn}
+		for name, values := range attrs {
+			entry.Attributes = append(entry.Attributes, &ldap.EntryAttribute{Name: name, Values: values})
+		}
+		directory.entries[strings.ToLower(dn)] = entry
+	}
+
+	for _, user := range users.Users {
+		dn := user.Labels["ldapDN"]
+		attrs := map[string][]string{"objectClass": {"organizationalPerson"}}
+		for label, attr := range cfg.LabelToAttributes {
+			if label == "name" {
+				attrs[attr] = []string{user.Name}
+			} else if value, ok := user.Labels[label]; ok {
+				attrs[attr] = []string{value}
+			}
+		}
+		if groups, ok := memberOf[dn]; ok {
+			attrs[cfg.Groups.GetAttribute()] = groups
+		}
+		addEntry(dn, attrs)
+		directory.passwords[strings.ToLower(dn)] = strings.ToLower(user.Name)
+	}
+
+	for dn, groups := range memberOf {
+		if _, exist := directory.entries[strings.ToLower(dn)]; !exist {
+			addEntry(dn, map[string][]string{
+				"objectClass":             {"groupOfNames"},
+				cfg.Groups.GetAttribute(): groups,
+			})
+		}
+	}
+
+	return directory
+}
+
+func (directory *fakeLDAP) StartTLS(config *tls.Config) error {
+	return nil
+}
+
+func (directory *fakeLDAP) Bind(username, password string) error {
+	expected, ok := directory.passwords[strings.ToLower(username)]
+	if !ok || expected != password {
+		return fmt.Errorf("invalid credentials for '%s'", username)
+	}
+	return nil
+}
+
+func (directory *fakeLDAP) Search(searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	result := &ldap.SearchResult{}
+	if searchRequest.Scope == ldap.ScopeBaseObject {
+		directory.groupLookups++
+		entry, ok := directory.entries[strings.ToLower(searchRequest.BaseDN)]
+		if !ok {
+			return nil, ldap.NewError(ldap.LDAPResultNoSuchObject, fmt.Errorf("no such object: %s", searchRequest.BaseDN))
+		}
+		result.Entries = append(result.Entries, filterAttributes(entry, searchRequest.Attributes))
+		return result, nil
+	}
+
+	dns := []string{}
+	for dn := range directory.entries {
+		dns = append(dns, dn)
+	}
+	sort.Strings(dns)
+	for _, dn := range dns {
+		entry := directory.entries[dn]
+		if strings.HasSuffix(dn, strings.ToLower(searchRequest.BaseDN)) && matchesFilter(entry, searchRequest.Filter) {
+			result.Entries = append(result.Entries, filterAttributes(entry, searchRequest.Attributes))
+		}
+	}
+	return result, nil
+}
+
+func (directory *fakeLDAP) Close() {
+}
+
+func matchesFilter(entry *ldap.Entry, filter string) bool {
+	for _, match := range fakeLDAPFilterRegex.FindAllStringSubmatch(filter, -1) {
+		found := false
+		for _, value := range entry.GetAttributeValues(match[1]) {
+			if match[2] == "*" || strings.EqualFold(value, match[2]) {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func filterAttributes(entry *ldap.Entry, attributes []string) *ldap.Entry {
+	result := &ldap.Entry{DN: entry.DN}
+	for _, attr := range entry.Attributes {
+		for _, name := range attributes {
+			if strings.EqualFold(attr.Name, name) {
+				result.Attributes = append(result.Attributes, attr)
+			}
+		}
+	}
+	return result
+}
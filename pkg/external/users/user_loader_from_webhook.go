@@ -0,0 +1,214 @@
+package users
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/patrickmn/go-cache"
+	log "github.com/sirupsen/logrus"
+)
+
+// webhookRetryInterval is the initial interval between attempts to load users from webhook, while it's unavailable.
+// It gets doubled after every failed attempt, up to the cache TTL
+const webhookRetryInterval = time.Second
+
+// webhookUsers is the list of users returned by webhook
+type webhookUsers struct {
+	Users []*webhookUser `json:"users"`
+}
+
+// webhookUser is a single user returned by webhook
+type webhookUser struct {
+	Name        string            `json:"name"`
+	Labels      map[string]string `json:"labels"`
+	Groups      []string          `json:"groups"`
+	DomainAdmin bool              `json:"domainAdmin"`
+}
+
+// webhookAuthRequest is sent to webhook in order to authenticate a user
+type webhookAuthRequest struct {
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+// UserLoaderFromWebhook allows aptomi to load users from an HTTP endpoint (e.g. in-house user directory). Webhook
+// should respond to GET requests with {"users": [{"name": ..., "labels": {...}, "groups": [...]}]}. If auth URL is
+// configured, users get authenticated by POSTing {"name": ..., "password": ...} to it, and any response other than
+// 200 OK means that authentication has failed. Domain admin flag returned by webhook is only honored if it's allowed in
+// the config
+type UserLoaderFromWebhook struct {
+	cfg                  config.UserWebhook
+	client               *http.Client
+	cache                *cache.Cache
+	domainAdminOverrides map[string]bool
+
+	// last is the last successfully loaded list of users, which is used if webhook is unavailable
+	mutex    sync.Mutex
+	last     *lang.GlobalUsers
+	failures uint
+}
+
+// NewUserLoaderFromWebhook returns new UserLoaderFromWebhook, given webhook configuration
+func NewUserLoaderFromWebhook(cfg config.UserWebhook, domainAdminOverrides map[string]bool) UserLoader {
+	return &UserLoaderFromWebhook{
+		cfg:                  cfg,
+		client:               &http.Client{Timeout: cfg.GetTimeout()},
+		cache:                cache.New(cfg.GetCacheTTL(), cfg.GetCacheTTL()),
+		domainAdminOverrides: domainAdminOverrides,
+	}
+}
+
+// LoadUsersAll loads all users
+func (loader *UserLoaderFromWebhook) LoadUsersAll() *lang.GlobalUsers {
+	// this can be called concurrently by the engine, so it needs to be thread safe
+	cachedUsers, _ := loader.cache.Get("webhookUsers")
+	if cachedUsers != nil {
+		return cachedUsers.(*lang.GlobalUsers)
+	}
+
+	loader.mutex.Lock()
+	defer loader.mutex.Unlock()
+
+	// users might have been loaded while we were waiting for the lock
+	cachedUsers, _ = loader.cache.Get("webhookUsers")
+	if cachedUsers != nil {
+		return cachedUsers.(*lang.GlobalUsers)
+	}
+
+	result, err := loader.fetchUsers()
+	if err != nil {
+		if loader.last == nil {
+			// we need user data, but they cannot be loaded from webhook. for now, let's panic
+			panic(err)
+		}
+
+		// keep using previously loaded users for a while, so that webhook doesn't get called on every load
+		loader.failures++
+		retryIn := loader.getRetryInterval()
+		log.Errorf("Unable to load users from webhook %s, using previously loaded users for %s: %s", loader.cfg.URL, retryIn, err)
+		loader.cache.Set("webhookUsers", loader.last, retryIn)
+		return loader.last
+	}
+
+	loader.last = result
+	loader.failures = 0
+	loader.cache.Set("webhookUsers", result, cache.DefaultExpiration)
+	return result
+}
+
+// getRetryInterval returns how long to wait before loading users from webhook again after a given number of failed
+// attempts. It's doubled after every attempt, but never exceeds the cache TTL
+func (loader *UserLoaderFromWebhook) getRetryInterval() time.Duration {
+	result := webhookRetryInterval
+	for i := uint(1); i < loader.failures && result < loader.cfg.GetCacheTTL(); i++ {
+		result *= 2
+	}
+	if result > loader.cfg.GetCacheTTL() {
+		result = loader.cfg.GetCacheTTL()
+	}
+	return result
+}
+
+// LoadUserByName loads a single user by name
+func (loader *UserLoaderFromWebhook) LoadUserByName(name string) *lang.User {
+	return loader.LoadUsersAll().Users[strings.ToLower(name)]
+}
+
+// Authenticate authenticates a user by username/password via webhook auth URL
+func (loader *UserLoaderFromWebhook) Authenticate(name, password string) (*lang.User, error) {
+	user := loader.LoadUserByName(name)
+	if user == nil {
+		return nil, fmt.Errorf("user '%s' does not exist", name)
+	}
+	if len(loader.cfg.AuthURL) <= 0 {
+		return nil, fmt.Errorf("user '%s' can't log in with password", name)
+	}
+
+	body, err := json.Marshal(&webhookAuthRequest{Name: user.Name, Password: password})
+	if err != nil {
+		return nil, fmt.Errorf("error while creating auth request: %s", err)
+	}
+	response, err := loader.do(http.MethodPost, loader.cfg.AuthURL, body)
+	if err != nil {
+		return nil, fmt.Errorf("error while authenticating user '%s' via webhook: %s", name, err)
+	}
+	defer response.Body.Close() // nolint: errcheck
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("incorrect password for user '%s'", name)
+	}
+	return user, nil
+}
+
+// Summary returns summary as string
+func (loader *UserLoaderFromWebhook) Summary() string {
+	return strconv.Itoa(len(loader.LoadUsersAll().Users)) + " (webhook)"
+}
+
+// fetchUsers retrieves all users from webhook
+func (loader *UserLoaderFromWebhook) fetchUsers() (*lang.GlobalUsers, error) {
+	response, err := loader.do(http.MethodGet, loader.cfg.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error while loading users from webhook: %s", err)
+	}
+	defer response.Body.Close() // nolint: errcheck
+
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading users from webhook: %s", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("webhook responded with %s: %s", response.Status, string(data))
+	}
+
+	users := &webhookUsers{}
+	err = json.Unmarshal(data, users)
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing users from webhook: %s", err)
+	}
+
+	result := &lang.GlobalUsers{Users: make(map[string]*lang.User)}
+	for _, u := range users.Users {
+		if len(u.Name) <= 0 {
+			continue
+		}
+		user := &lang.User{
+			Name:        u.Name,
+			Labels:      u.Labels,
+			Groups:      u.Groups,
+			DomainAdmin: u.DomainAdmin && loader.cfg.AllowDomainAdmin,
+		}
+		if user.Labels == nil {
+			user.Labels = make(map[string]string)
+		}
+		if _, exist := loader.domainAdminOverrides[strings.ToLower(user.Name)]; exist {
+			user.DomainAdmin = true
+		}
+		result.Users[strings.ToLower(user.Name)] = user
+	}
+	return result, nil
+}
+
+func (loader *UserLoaderFromWebhook) do(method string, url string, body []byte) (*http.Response, error) {
+	request, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", "application/json")
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if len(loader.cfg.Token) > 0 {
+		request.Header.Set("Authorization", "Bearer "+loader.cfg.Token)
+	}
+	return loader.client.Do(request)
+}
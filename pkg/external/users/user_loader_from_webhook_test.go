@@ -0,0 +1,106 @@
+package users
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestWebhook(t *testing.T, available *bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Header.Get("Authorization") != "Bearer webhooktoken" {
+			writer.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if !*available {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		switch request.URL.Path {
+		case "/users":
+			_, err := writer.Write([]byte(`{"users": [
+				{"name": "Alice", "labels": {"team": "dev"}, "groups": ["developers"]},
+				{"name": "bob", "domainAdmin": true},
+				{"name": "admin"}
+			]}`))
+			assert.NoError(t, err, "Users should be written")
+		case "/auth":
+			authReq := &webhookAuthRequest{}
+			assert.NoError(t, json.NewDecoder(request.Body).Decode(authReq), "Auth request should be valid JSON")
+			if authReq.Name != "Alice" || authReq.Password != "alicepassword" {
+				writer.WriteHeader(http.StatusForbidden)
+			}
+		default:
+			writer.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestUserLoaderFromWebhook(t *testing.T) {
+	available := true
+	webhook := newTestWebhook(t, &available)
+	defer webhook.Close()
+
+	loader := NewUserLoaderFromWebhook(config.UserWebhook{URL: webhook.URL + "/users", AuthURL: webhook.URL + "/auth", Token: "webhooktoken"}, map[string]bool{"admin": true})
+	assert.Equal(t, 3, len(loader.LoadUsersAll().Users), "All users should be loaded")
+
+	alice := loader.LoadUserByName("alice")
+	if assert.NotNil(t, alice, "User should be loaded by name") {
+		assert.Equal(t, "dev", alice.Labels["team"], "User labels should be loaded")
+		assert.Equal(t, []string{"developers"}, alice.Groups, "User groups should be loaded")
+		assert.False(t, alice.DomainAdmin, "User should not be domain admin")
+	}
+	assert.False(t, loader.LoadUserByName("bob").DomainAdmin, "Domain admin flag should be ignored unless allowed")
+	assert.True(t, loader.LoadUserByName("admin").DomainAdmin, "Domain admin override should be applied")
+
+	allowed := NewUserLoaderFromWebhook(config.UserWebhook{URL: webhook.URL + "/users", Token: "webhooktoken", AllowDomainAdmin: true}, nil)
+	assert.True(t, allowed.LoadUserByName("bob").DomainAdmin, "Domain admin flag should be loaded if allowed")
+	assert.NotNil(t, loader.LoadUserByName("admin").Labels, "Labels should never be nil")
+
+	user, err := loader.Authenticate("alice", "alicepassword")
+	assert.NoError(t, err, "User should be authenticated via webhook")
+	assert.Equal(t, "Alice", user.Name, "Authenticated user should be returned")
+	_, err = loader.Authenticate("alice", "wrong")
+	assert.Error(t, err, "User with incorrect password should not be authenticated")
+	_, err = loader.Authenticate("carol", "carolpassword")
+	assert.Error(t, err, "Non-existing user should not be authenticated")
+}
+
+func TestUserLoaderFromWebhookUnavailable(t *testing.T) {
+	available := true
+	webhook := newTestWebhook(t, &available)
+	defer webhook.Close()
+
+	loader := NewUserLoaderFromWebhook(config.UserWebhook{URL: webhook.URL + "/users", Token: "webhooktoken"}, nil).(*UserLoaderFromWebhook)
+	assert.Equal(t, 3, len(loader.LoadUsersAll().Users), "All users should be loaded")
+
+	// previously loaded users should be used while webhook is unavailable
+	available = false
+	loader.cache.Flush()
+	assert.Equal(t, 3, len(loader.LoadUsersAll().Users), "Previously loaded users should be used")
+
+	// and they should be cached for a while, so webhook doesn't get called on every load
+	item, found := loader.cache.Items()["webhookUsers"]
+	if assert.True(t, found, "Previously loaded users should be cached") {
+		assert.True(t, time.Until(time.Unix(0, item.Expiration)) <= webhookRetryInterval, "Previously loaded users should be cached until the next retry")
+	}
+	for failures, expected := range []time.Duration{webhookRetryInterval, webhookRetryInterval, 2 * webhookRetryInterval, 4 * webhookRetryInterval} {
+		loader.failures = uint(failures)
+		assert.Equal(t, expected, loader.getRetryInterval(), "Retry interval should be doubled after every failure")
+	}
+	loader.failures = 100
+	assert.Equal(t, loader.cfg.GetCacheTTL(), loader.getRetryInterval(), "Retry interval should not exceed cache TTL")
+
+	_, err := loader.Authenticate("alice", "alicepassword")
+	assert.Error(t, err, "Users should not be able to log in if auth URL is not set")
+
+	// webhook which has never been available can't provide users
+	unavailable := NewUserLoaderFromWebhook(config.UserWebhook{URL: webhook.URL + "/users"}, nil)
+	assert.Panics(t, func() { unavailable.LoadUsersAll() }, "Loading users from unavailable webhook should panic")
+}
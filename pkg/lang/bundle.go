@@ -17,6 +17,19 @@ var TypeBundle = &runtime.TypeInfo{
 	Constructor: func() runtime.Object { return &Bundle{} },
 }
 
+const (
+	// DeletionPolicyDelete means that component instances get destroyed in the cloud once they are no longer needed
+	DeletionPolicyDelete = "delete"
+
+	// DeletionPolicyOrphan means that component instances get removed from the actual state once they are no longer
+	// needed, but keep running in the cloud
+	DeletionPolicyOrphan = "orphan"
+
+	// DeletionPolicyProtect means that component instances don't get destroyed once they are no longer needed, until
+	// the protection is removed from the component
+	DeletionPolicyProtect = "protect"
+)
+
 // Bundle defines individual bundle in Aptomi. The idea is that bundles get defined by different teams. Those
 // teams define bundle-specific consumption rules of how others can consume their bundles.
 //
@@ -33,6 +46,9 @@ type Bundle struct {
 	// Components is the list of components bundle consists of
 	Components []*BundleComponent `validate:"dive"`
 
+	// Rollout is an optional strategy of rolling out updates of bundle code components across their instances
+	Rollout *Rollout `yaml:"rollout,omitempty" validate:"omitempty"`
+
 	// Lazily evaluated fields (all components topologically sorted). Use via getter
 	componentsOrderedOnce sync.Once
 	componentsOrderedErr  error
@@ -66,6 +82,14 @@ type BundleComponent struct {
 	// Dependencies represent cross-component dependencies within a given bundle. Component may need other components
 	// within that bundle to exist, before it gets instantiated
 	Dependencies []string `yaml:"dependencies,omitempty" validate:"dive,identifier"`
+
+	// DeletionPolicy defines what happens with component instances once they are no longer needed (e.g. claim got
+	// removed). It can be either delete (default), orphan or protect
+	DeletionPolicy string `yaml:"deletion-policy,omitempty" validate:"omitempty,deletionPolicy"`
+
+	// Hooks is an optional set of hooks, which run before/after component instances get created, updated or deleted
+	// (e.g. database migrations or smoke tests). Can only be set on code components
+	Hooks *Hooks `yaml:"hooks,omitempty" validate:"omitempty"`
 }
 
 // Code with type and parameters, used to instantiate/update/delete component instances
@@ -80,6 +104,14 @@ type Code struct {
 	Params util.NestedParameterMap `validate:"omitempty,templateNestedMap"`
 }
 
+// GetDeletionPolicy returns deletion policy of the component, which is delete by default
+func (component *BundleComponent) GetDeletionPolicy() string {
+	if len(component.DeletionPolicy) <= 0 {
+		return DeletionPolicyDelete
+	}
+	return component.DeletionPolicy
+}
+
 // Matches checks if component criteria is satisfied
 func (component *BundleComponent) Matches(params *expression.Parameters, cache *expression.Cache) (bool, error) {
 	if component.Criteria == nil {
@@ -1,6 +1,8 @@
 package lang
 
 import (
+	"time"
+
 	"github.com/Aptomi/aptomi/pkg/runtime"
 )
 
@@ -29,4 +31,27 @@ type Claim struct {
 
 	// Labels which are provided by the user.
 	Labels map[string]string `yaml:"labels,omitempty" validate:"omitempty,labels"`
+
+	// TTL is an optional time-to-live for the claim. When set, the claim will be considered expired once TTL has
+	// passed since the last time it was updated in the policy, and it will be automatically deleted by Aptomi.
+	TTL time.Duration `yaml:"ttl,omitempty"`
+
+	// Expires is an optional point in time after which the claim will be automatically deleted by Aptomi.
+	// If TTL is set, Expires gets calculated automatically when the claim is added or updated.
+	Expires *time.Time `yaml:"expires,omitempty"`
+}
+
+// UpdateExpiration sets expiration time of the claim based on its TTL, counting from the given point in time.
+// If TTL is not set, it does nothing.
+func (claim *Claim) UpdateExpiration(now time.Time) {
+	if claim.TTL <= 0 {
+		return
+	}
+	expires := now.Add(claim.TTL)
+	claim.Expires = &expires
+}
+
+// IsExpired returns true if the claim has an expiration time and it has already passed
+func (claim *Claim) IsExpired(now time.Time) bool {
+	return claim.Expires != nil && !now.Before(*claim.Expires)
 }
@@ -0,0 +1,34 @@
+package lang
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClaimExpiration(t *testing.T) {
+	now := time.Date(2018, time.March, 10, 14, 30, 0, 0, time.UTC)
+
+	// claim without TTL and expiration time never expires
+	claim := &Claim{}
+	claim.UpdateExpiration(now)
+	assert.Nil(t, claim.Expires, "Claim without TTL should not get expiration time")
+	assert.False(t, claim.IsExpired(now.Add(365*24*time.Hour)), "Claim without expiration time should never expire")
+
+	// claim with TTL expires once TTL has passed
+	claim = &Claim{TTL: time.Hour}
+	claim.UpdateExpiration(now)
+	assert.Equal(t, now.Add(time.Hour), *claim.Expires, "Claim expiration time should be calculated from TTL")
+	assert.False(t, claim.IsExpired(now.Add(59*time.Minute)), "Claim should not be expired before TTL has passed")
+	assert.True(t, claim.IsExpired(now.Add(time.Hour)), "Claim should be expired once TTL has passed")
+
+	// updating claim extends its expiration time
+	claim.UpdateExpiration(now.Add(30 * time.Minute))
+	assert.False(t, claim.IsExpired(now.Add(time.Hour)), "Claim expiration time should be extended on update")
+
+	// claim with explicit expiration time
+	expires := now.Add(-time.Minute)
+	claim = &Claim{Expires: &expires}
+	assert.True(t, claim.IsExpired(now), "Claim with expiration time in the past should be expired")
+}
@@ -2,6 +2,8 @@ package expression
 
 import (
 	"fmt"
+	"reflect"
+	"strings"
 
 	"github.com/Aptomi/aptomi/pkg/errors"
 	"github.com/ralekseenkov/govaluate"
@@ -11,40 +13,140 @@ import (
 type Expression struct {
 	expressionStr      string
 	expressionCompiled *govaluate.EvaluableExpression
+
+	// optionalParams is a set of parameters, which are allowed to be missing during evaluation (e.g. passed to has())
+	optionalParams map[string]bool
 }
 
 // NewExpression compiles an expression and returns the result in Expression struct
 // Parameter expressionStr must follow syntax defined by https://github.com/Knetic/govaluate
+// In addition to that, it can call any of the functions returned by Functions()
 func NewExpression(expressionStr string) (*Expression, error) {
-	functions := map[string]govaluate.ExpressionFunction{
-		"in": func(args ...interface{}) (interface{}, error) {
-			if len(args) == 0 {
-				return nil, fmt.Errorf("can't evaluate in() function when zero arguments supplied")
-			}
-			v := args[0]
-			for i := 1; i < len(args); i++ {
-				if v == args[i] {
-					return true, nil
-				}
-			}
-			return false, nil
-		},
+	unknown := UnknownFunctions(expressionStr)
+	if len(unknown) > 0 {
+		names := []string{}
+		for _, f := range Functions() {
+			names = append(names, f.Name)
+		}
+		return nil, fmt.Errorf("unable to compile expression '%s': unknown function %s(), supported functions are: %s", expressionStr, unknown[0], strings.Join(names, ", "))
 	}
 
-	expressionCompiled, err := govaluate.NewEvaluableExpressionWithFunctions(expressionStr, functions)
+	expressionCompiled, err := govaluate.NewEvaluableExpressionWithFunctions(expressionStr, govaluateFunctions)
 	if err != nil {
 		return nil, fmt.Errorf("unable to compile expression '%s': %s", expressionStr, err)
 	}
+
+	optionalParams, err := checkFunctionCalls(expressionCompiled.Tokens())
+	if err != nil {
+		return nil, fmt.Errorf("unable to compile expression '%s': %s", expressionStr, err)
+	}
+
 	return &Expression{
 		expressionStr:      expressionStr,
 		expressionCompiled: expressionCompiled,
+		optionalParams:     optionalParams,
 	}, nil
 }
 
+// checkFunctionCalls verifies that all functions in the list of compiled tokens are called with the correct number of
+// arguments. It also returns the set of parameters which are passed directly to has(), so they can be missing
+func checkFunctionCalls(tokens []govaluate.ExpressionToken) (map[string]bool, error) {
+	optionalParams := make(map[string]bool)
+	for i, token := range tokens {
+		if token.Kind != govaluate.FUNCTION {
+			continue
+		}
+		f := functionImplMap[reflect.ValueOf(token.Value).Pointer()]
+		if f == nil {
+			return nil, fmt.Errorf("unknown function called")
+		}
+
+		// count arguments (function token is always followed by an opening clause)
+		args, depth := 0, 0
+		for j := i + 1; j < len(tokens); j++ {
+			switch tokens[j].Kind {
+			case govaluate.CLAUSE:
+				depth++
+			case govaluate.CLAUSE_CLOSE:
+				depth--
+			case govaluate.SEPARATOR:
+				if depth == 1 {
+					args++
+				}
+			}
+			if depth == 0 {
+				if j > i+2 {
+					args++
+				}
+				break
+			}
+		}
+
+		err := f.checkArgs(args)
+		if err != nil {
+			return nil, err
+		}
+
+		// has(label) should receive a special value instead of failing, when label is not defined
+		if f.Name == "has" && i+3 < len(tokens) && tokens[i+2].Kind == govaluate.VARIABLE && tokens[i+3].Kind == govaluate.CLAUSE_CLOSE {
+			optionalParams[tokens[i+2].Value.(string)] = true
+		}
+	}
+	return optionalParams, nil
+}
+
+// evalParameters allows to retrieve parameters during expression evaluation, while treating optional
+// parameters differently if they are missing
+type evalParameters struct {
+	params         *Parameters
+	optionalParams map[string]bool
+}
+
+// Get returns a parameter by its name
+func (p evalParameters) Get(name string) (interface{}, error) {
+	if p.params != nil {
+		if value, ok := (*p.params)[name]; ok {
+			return value, nil
+		}
+	}
+	if p.optionalParams[name] {
+		return missingValue, nil
+	}
+	return nil, &govaluate.MissingParameterError{Name: name}
+}
+
+// Variables returns the list of variables referenced in the expression
+func (expression *Expression) Variables() []string {
+	result := []string{}
+	for _, token := range expression.expressionCompiled.Tokens() {
+		if token.Kind == govaluate.VARIABLE {
+			result = append(result, token.Value.(string))
+		}
+	}
+	return result
+}
+
+// IsConstant returns true if the expression doesn't refer to any variables and doesn't call any functions which
+// depend on current time. I.e. it always gets evaluated to the same value
+func (expression *Expression) IsConstant() bool {
+	for _, token := range expression.expressionCompiled.Tokens() {
+		if token.Kind == govaluate.VARIABLE {
+			return false
+		}
+		if token.Kind == govaluate.FUNCTION {
+			f := functionImplMap[reflect.ValueOf(token.Value).Pointer()]
+			if f == nil || f.timeBased {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 // EvaluateAsBool evaluates a compiled boolean expression given a set of named parameters
 func (expression *Expression) EvaluateAsBool(params *Parameters) (bool, error) {
 	// Evaluate
-	result, err := expression.expressionCompiled.Evaluate(*params)
+	result, err := expression.expressionCompiled.Eval(evalParameters{params: params, optionalParams: expression.optionalParams})
 	if err != nil {
 		// Return false and swallow the error if we encountered a missing parameter
 		if _, ok := err.(*govaluate.MissingParameterError); ok {
@@ -2,6 +2,7 @@ package expression
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -88,7 +89,7 @@ func TestExpressions(t *testing.T) {
 		{"in(foo, 10, 20, 30)", ResTrue},
 		{"in(a, 'valueOfX', 'valueOfY', 'valueOfZ')", ResFalse},
 		{"in(a, )", ResCompileError},
-		{"in()", ResEvalError},
+		{"in()", ResCompileError},
 		{"in(5)", ResFalse},
 
 		// check when expression involves a missing label
@@ -127,3 +128,174 @@ func TestExpressions(t *testing.T) {
 		evaluateWithCache(t, test.expression, params, test.result, cache)
 	}
 }
+
+func TestTimeFunctions(t *testing.T) {
+	// Saturday, 14:30
+	now = func() time.Time {
+		return time.Date(2018, time.March, 10, 14, 30, 0, 0, time.UTC)
+	}
+	defer func() {
+		now = time.Now
+	}()
+
+	params := NewParams(nil, nil)
+
+	tests := []struct {
+		expression string
+		result     int
+	}{
+		{"hour() == 14", ResTrue},
+		{"hour() >= 9 && hour() < 18", ResTrue},
+		{"hour() < 9", ResFalse},
+		{"minute() == 30", ResTrue},
+		{"weekday() == 'Saturday'", ResTrue},
+		{"in(weekday(), 'Saturday', 'Sunday')", ResTrue},
+		{"in(weekday(), 'Monday', 'Tuesday', 'Wednesday', 'Thursday', 'Friday')", ResFalse},
+		{"hour(5) == 14", ResCompileError},
+	}
+
+	for _, test := range tests {
+		evaluate(t, test.expression, params, test.result)
+	}
+}
+
+func TestFunctions(t *testing.T) {
+	params := NewParams(
+		map[string]string{
+			"name":    "Alice-Dev",
+			"team":    "platform",
+			"version": "1.4.2",
+			"cpu":     "1.5",
+			"num":     "10",
+			"enabled": "true",
+		},
+		map[string]interface{}{
+			"Teams":  []interface{}{"platform", "analytics"},
+			"Single": []interface{}{"platform"},
+			"Empty":  []interface{}{},
+			"groups": []interface{}{"Developers", "mobile-dev"},
+		},
+	)
+
+	tests := []struct {
+		expression string
+		result     int
+	}{
+		// has
+		{"has(team)", ResTrue},
+		{"has(missingLabel)", ResFalse},
+		{"!has(missingLabel)", ResTrue},
+		{"!has(missingLabel) || missingLabel == 'x'", ResTrue},
+		{"has()", ResCompileError},
+		{"has(team, name)", ResCompileError},
+
+		// matches
+		{"matches(name, '^Alice-.*$')", ResTrue},
+		{"matches(name, '^Bob-.*$')", ResFalse},
+		{"matches(num, '^[0-9]+$')", ResTrue},
+		{"matches(name, '(')", ResEvalError},
+		{"matches(name)", ResCompileError},
+
+		// startsWith / endsWith
+		{"startsWith(name, 'Alice')", ResTrue},
+		{"startsWith(name, 'Bob')", ResFalse},
+		{"endsWith(name, '-Dev')", ResTrue},
+		{"endsWith(name, '-Prod')", ResFalse},
+		{"startsWith(name, 'a', 'b')", ResCompileError},
+
+		// contains
+		{"contains(Teams, team)", ResTrue},
+		{"contains(Teams, 'marketing')", ResFalse},
+		{"contains(Single, 'platform')", ResTrue},
+		{"contains(Empty, 'platform')", ResFalse},
+		{"contains('platform, analytics', team)", ResTrue},
+		{"contains('1, 5, 10', num)", ResTrue},
+		{"contains('1, 5', num)", ResFalse},
+		{"contains(Teams)", ResCompileError},
+
+		// inGroup
+		{"inGroup(groups, 'developers')", ResTrue},
+		{"inGroup(groups, 'Mobile-Dev') && inGroup(groups, 'Developers')", ResTrue},
+		{"inGroup(groups, 'ops')", ResFalse},
+		{"inGroup(Empty, 'developers')", ResFalse},
+		{"inGroup(groups)", ResCompileError},
+
+		// lower / upper
+		{"lower(name) == 'alice-dev'", ResTrue},
+		{"upper(team) == 'PLATFORM'", ResTrue},
+		{"lower(enabled) == 'true'", ResTrue},
+		{"lower(name, team) == 'x'", ResCompileError},
+
+		// number
+		{"number(cpu) > 1", ResTrue},
+		{"number(cpu) + number(num) == 11.5", ResTrue},
+		{"number('2.5') < 2", ResFalse},
+		{"number(name) > 1", ResEvalError},
+
+		// semverCompare
+		{"semverCompare(version, '>= 1.2.0')", ResTrue},
+		{"semverCompare(version, '~1.4')", ResTrue},
+		{"semverCompare(version, '< 1.0')", ResFalse},
+		{"semverCompare(name, '>= 1.0')", ResEvalError},
+		{"semverCompare(version, 'abc')", ResEvalError},
+
+		// functions as part of other expressions
+		{"in(lower(team), 'platform', 'ops') && startsWith(name, 'Alice')", ResTrue},
+		{"upper(lower(name)) == 'ALICE-DEV'", ResTrue},
+
+		// unknown functions
+		{"unknownFunction(name)", ResCompileError},
+		{"name == 'unknownFunction(name)'", ResFalse},
+	}
+
+	// Evaluate without cache
+	for _, test := range tests {
+		evaluate(t, test.expression, params, test.result)
+	}
+
+	cache := NewCache()
+	for _, test := range tests {
+		evaluateWithCache(t, test.expression, params, test.result, cache)
+	}
+}
+
+func TestUnknownFunctions(t *testing.T) {
+	assert.Empty(t, UnknownFunctions("in(a, 'b') && lower(c) == 'd'"))
+	assert.Empty(t, UnknownFunctions("a == 'foo(bar)'"))
+	assert.Equal(t, []string{"foo", "bar"}, UnknownFunctions("foo(a) && lower(bar(b)) == 'c'"))
+}
+
+func TestFunctionsList(t *testing.T) {
+	names := []string{}
+	for _, f := range Functions() {
+		assert.Equal(t, f, GetFunction(f.Name), "Function should be retrievable by name: %s", f.Name)
+		assert.NotEmpty(t, f.Usage, "Function should have usage: %s", f.Name)
+		assert.NotEmpty(t, f.Description, "Function should have description: %s", f.Name)
+		names = append(names, f.Name)
+	}
+	assert.Contains(t, names, "in")
+	assert.Contains(t, names, "has")
+	assert.Nil(t, GetFunction("unknownFunction"))
+}
+
+func TestExpressionVariables(t *testing.T) {
+	tests := []struct {
+		expression string
+		variables  []string
+		constant   bool
+	}{
+		{"a == 'b' && has(c)", []string{"a", "c"}, false},
+		{"Claim.Name == 'test'", []string{"Claim.Name"}, false},
+		{"1 > 2 || lower('A') == 'a'", []string{}, true},
+		{"false", []string{}, true},
+		{"hour() > 5", []string{}, false},
+	}
+	for _, test := range tests {
+		expr, err := NewExpression(test.expression)
+		if !assert.NoError(t, err, "Expression should compile: %s", test.expression) {
+			continue
+		}
+		assert.Equal(t, test.variables, expr.Variables(), "Expression variables: %s", test.expression)
+		assert.Equal(t, test.constant, expr.IsConstant(), "Expression constant: %s", test.expression)
+	}
+}
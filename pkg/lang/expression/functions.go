@@ -0,0 +1,363 @@
+package expression
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Masterminds/semver"
+	"github.com/ralekseenkov/govaluate"
+)
+
+// Function describes a function, which can be called from expressions
+type Function struct {
+	// Name is the name of the function
+	Name string
+
+	// Usage is a short example of how the function should be called
+	Usage string
+
+	// Description is a human-readable description of what the function does
+	Description string
+
+	// MinArgs is the minimum number of arguments the function accepts
+	MinArgs int
+
+	// MaxArgs is the maximum number of arguments the function accepts (-1 means unlimited)
+	MaxArgs int
+
+	// impl is the actual implementation of the function
+	impl govaluate.ExpressionFunction
+
+	// timeBased is true if the function result depends on current time
+	timeBased bool
+}
+
+// checkArgs returns an error if the function can't be called with the given number of arguments
+func (f *Function) checkArgs(cnt int) error {
+	if cnt < f.MinArgs || (f.MaxArgs >= 0 && cnt > f.MaxArgs) {
+		return fmt.Errorf("function %s() called with %d argument(s), usage: %s", f.Name, cnt, f.Usage)
+	}
+	return nil
+}
+
+// now returns current time and is used by time-based expression functions. It can be overridden in tests
+var now = time.Now
+
+// missingParameter is a type of the value, which gets passed to has() in place of a label that is not defined
+type missingParameter struct{}
+
+var missingValue = missingParameter{}
+
+// regexCache is a thread-safe cache of compiled regular expressions used by matches()
+var regexCache = sync.Map{}
+
+// functions is a list of all functions, which are supported in expressions
+var functions = []*Function{
+	{
+		Name:        "in",
+		Usage:       "in(value, candidate1, candidate2, ...)",
+		Description: "Returns true if value is equal to any of the candidates",
+		MinArgs:     1,
+		MaxArgs:     -1,
+		impl: func(args ...interface{}) (interface{}, error) {
+			v := args[0]
+			for i := 1; i < len(args); i++ {
+				if v == args[i] {
+					return true, nil
+				}
+			}
+			return false, nil
+		},
+	},
+	{
+		Name:        "has",
+		Usage:       "has(label)",
+		Description: "Returns true if label is defined",
+		MinArgs:     1,
+		MaxArgs:     1,
+		impl: func(args ...interface{}) (interface{}, error) {
+			return args[0] != missingValue, nil
+		},
+	},
+	{
+		Name:        "matches",
+		Usage:       "matches(value, 'regex')",
+		Description: "Returns true if value matches a given regular expression",
+		MinArgs:     2,
+		MaxArgs:     2,
+		impl: func(args ...interface{}) (interface{}, error) {
+			str, err := toStrings("matches", args...)
+			if err != nil {
+				return nil, err
+			}
+			var re *regexp.Regexp
+			if reCached, ok := regexCache.Load(str[1]); ok {
+				re = reCached.(*regexp.Regexp) // nolint: errcheck
+			} else {
+				re, err = regexp.Compile(str[1])
+				if err != nil {
+					return nil, fmt.Errorf("matches() called with invalid regular expression '%s': %s", str[1], err)
+				}
+				regexCache.Store(str[1], re)
+			}
+			return re.MatchString(str[0]), nil
+		},
+	},
+	{
+		Name:        "startsWith",
+		Usage:       "startsWith(value, 'prefix')",
+		Description: "Returns true if value starts with a given prefix",
+		MinArgs:     2,
+		MaxArgs:     2,
+		impl: func(args ...interface{}) (interface{}, error) {
+			str, err := toStrings("startsWith", args...)
+			if err != nil {
+				return nil, err
+			}
+			return strings.HasPrefix(str[0], str[1]), nil
+		},
+	},
+	{
+		Name:        "endsWith",
+		Usage:       "endsWith(value, 'suffix')",
+		Description: "Returns true if value ends with a given suffix",
+		MinArgs:     2,
+		MaxArgs:     2,
+		impl: func(args ...interface{}) (interface{}, error) {
+			str, err := toStrings("endsWith", args...)
+			if err != nil {
+				return nil, err
+			}
+			return strings.HasSuffix(str[0], str[1]), nil
+		},
+	},
+	{
+		Name:        "contains",
+		Usage:       "contains(list, value)",
+		Description: "Returns true if list (or a comma-separated string) contains a given value",
+		MinArgs:     2,
+		MaxArgs:     2,
+		impl: func(args ...interface{}) (interface{}, error) {
+			// govaluate flattens a list passed as the first argument, so the value is always the last one
+			value := args[len(args)-1]
+			list := args[:len(args)-1]
+			if len(list) == 1 {
+				if str, ok := list[0].(string); ok {
+					list = []interface{}{}
+					for _, item := range strings.Split(str, ",") {
+						list = append(list, strings.TrimSpace(item))
+					}
+				}
+			}
+			for _, item := range list {
+				if item == value {
+					return true, nil
+				}
+				str, err := toStrings("contains", item, value)
+				if err == nil && str[0] == str[1] {
+					return true, nil
+				}
+			}
+			return false, nil
+		},
+	},
+	{
+		Name:        "inGroup",
+		Usage:       "inGroup(groups, 'name')",
+		Description: "Returns true if list of user groups contains a given group (group names are not case sensitive)",
+		MinArgs:     2,
+		MaxArgs:     2,
+		impl: func(args ...interface{}) (interface{}, error) {
+			// govaluate flattens a list passed as the first argument, so the group name is always the last one
+			str, err := toStrings("inGroup", args...)
+			if err != nil {
+				return nil, err
+			}
+			group := str[len(str)-1]
+			for _, item := range str[:len(str)-1] {
+				if strings.EqualFold(item, group) {
+					return true, nil
+				}
+			}
+			return false, nil
+		},
+	},
+	{
+		Name:        "lower",
+		Usage:       "lower(value)",
+		Description: "Returns value converted to lower case",
+		MinArgs:     1,
+		MaxArgs:     1,
+		impl: func(args ...interface{}) (interface{}, error) {
+			str, err := toStrings("lower", args...)
+			if err != nil {
+				return nil, err
+			}
+			return strings.ToLower(str[0]), nil
+		},
+	},
+	{
+		Name:        "upper",
+		Usage:       "upper(value)",
+		Description: "Returns value converted to upper case",
+		MinArgs:     1,
+		MaxArgs:     1,
+		impl: func(args ...interface{}) (interface{}, error) {
+			str, err := toStrings("upper", args...)
+			if err != nil {
+				return nil, err
+			}
+			return strings.ToUpper(str[0]), nil
+		},
+	},
+	{
+		Name:        "number",
+		Usage:       "number(value)",
+		Description: "Returns value parsed as a number (e.g. '1.5' becomes 1.5)",
+		MinArgs:     1,
+		MaxArgs:     1,
+		impl: func(args ...interface{}) (interface{}, error) {
+			if value, ok := args[0].(float64); ok {
+				return value, nil
+			}
+			str, err := toStrings("number", args...)
+			if err != nil {
+				return nil, err
+			}
+			value, err := strconv.ParseFloat(strings.TrimSpace(str[0]), 64)
+			if err != nil {
+				return nil, fmt.Errorf("number() called with '%s', which is not a number", str[0])
+			}
+			return value, nil
+		},
+	},
+	{
+		Name:        "semverCompare",
+		Usage:       "semverCompare(version, '>= 1.2.0')",
+		Description: "Returns true if version satisfies a given semantic version constraint",
+		MinArgs:     2,
+		MaxArgs:     2,
+		impl: func(args ...interface{}) (interface{}, error) {
+			str, err := toStrings("semverCompare", args...)
+			if err != nil {
+				return nil, err
+			}
+			constraint, err := semver.NewConstraint(str[1])
+			if err != nil {
+				return nil, fmt.Errorf("semverCompare() called with invalid constraint '%s': %s", str[1], err)
+			}
+			version, err := semver.NewVersion(str[0])
+			if err != nil {
+				return nil, fmt.Errorf("semverCompare() called with invalid version '%s': %s", str[0], err)
+			}
+			return constraint.Check(version), nil
+		},
+	},
+	{
+		Name:        "hour",
+		Usage:       "hour()",
+		Description: "Returns current hour (0-23)",
+		MinArgs:     0,
+		MaxArgs:     0,
+		timeBased:   true,
+		impl: func(args ...interface{}) (interface{}, error) {
+			return float64(now().Hour()), nil
+		},
+	},
+	{
+		Name:        "minute",
+		Usage:       "minute()",
+		Description: "Returns current minute (0-59)",
+		MinArgs:     0,
+		MaxArgs:     0,
+		timeBased:   true,
+		impl: func(args ...interface{}) (interface{}, error) {
+			return float64(now().Minute()), nil
+		},
+	},
+	{
+		Name:        "weekday",
+		Usage:       "weekday()",
+		Description: "Returns current day of the week (e.g. 'Monday')",
+		MinArgs:     0,
+		MaxArgs:     0,
+		timeBased:   true,
+		impl: func(args ...interface{}) (interface{}, error) {
+			return now().Weekday().String(), nil
+		},
+	},
+}
+
+// functionMap is a map of all supported functions, keyed by their names
+var functionMap = make(map[string]*Function)
+
+// functionImplMap is a map of all supported functions, keyed by pointers to their implementation
+var functionImplMap = make(map[uintptr]*Function)
+
+// govaluateFunctions is a map of all supported functions in a form which can be passed to govaluate
+var govaluateFunctions = make(map[string]govaluate.ExpressionFunction)
+
+func init() {
+	for _, f := range functions {
+		functionMap[f.Name] = f
+		functionImplMap[reflect.ValueOf(f.impl).Pointer()] = f
+		govaluateFunctions[f.Name] = f.impl
+	}
+}
+
+// Functions returns the list of all functions, which can be called from expressions, sorted by name
+func Functions() []*Function {
+	result := make([]*Function, len(functions))
+	copy(result, functions)
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// GetFunction returns a function with a given name or nil, if the function is not supported in expressions
+func GetFunction(name string) *Function {
+	return functionMap[name]
+}
+
+var (
+	quotedStringRegex = regexp.MustCompile(`'[^']*'|"[^"]*"|\[[^\]]*\]`)
+	functionCallRegex = regexp.MustCompile(`([a-zA-Z_][a-zA-Z0-9_.]*)\s*\(`)
+)
+
+// UnknownFunctions returns the list of functions called in a given expression, which are not supported
+func UnknownFunctions(expressionStr string) []string {
+	result := []string{}
+	stripped := quotedStringRegex.ReplaceAllString(expressionStr, "''")
+	for _, match := range functionCallRegex.FindAllStringSubmatch(stripped, -1) {
+		if GetFunction(match[1]) == nil {
+			result = append(result, match[1])
+		}
+	}
+	return result
+}
+
+// toStrings converts all function arguments to strings. Numbers and bools will get converted to their string
+// representation, while other types will result in an error
+func toStrings(name string, args ...interface{}) ([]string, error) {
+	result := make([]string, len(args))
+	for i, arg := range args {
+		switch value := arg.(type) {
+		case string:
+			result[i] = value
+		case float64:
+			result[i] = strconv.FormatFloat(value, 'f', -1, 64)
+		case bool:
+			result[i] = strconv.FormatBool(value)
+		default:
+			return nil, fmt.Errorf("%s() can't be called with argument of type %T", name, arg)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,94 @@
+package lang
+
+import (
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/util"
+)
+
+const (
+	// HookPreCreate runs before component instance gets created
+	HookPreCreate = "pre-create"
+
+	// HookPostCreate runs after component instance has been created
+	HookPostCreate = "post-create"
+
+	// HookPreUpdate runs before component instance gets updated (e.g. database migration)
+	HookPreUpdate = "pre-update"
+
+	// HookPostUpdate runs after component instance has been updated
+	HookPostUpdate = "post-update"
+
+	// HookPreDelete runs before component instance gets deleted (e.g. backup)
+	HookPreDelete = "pre-delete"
+)
+
+const (
+	// DefaultHookTimeout is the default amount of time to wait for a hook to complete
+	DefaultHookTimeout = 5 * time.Minute
+
+	// DefaultHookInterval is the default interval between hook completion checks
+	DefaultHookInterval = 5 * time.Second
+)
+
+// Hooks is a set of hooks, which run at different phases of a code component instance lifecycle. If a hook fails or
+// doesn't complete in time, the corresponding create/update/delete of a component instance fails as well
+type Hooks struct {
+	// PreCreate runs before component instance gets created
+	PreCreate *Hook `yaml:"pre-create,omitempty" validate:"omitempty"`
+
+	// PostCreate runs after component instance has been created (e.g. smoke tests)
+	PostCreate *Hook `yaml:"post-create,omitempty" validate:"omitempty"`
+
+	// PreUpdate runs before component instance gets updated (e.g. database migration)
+	PreUpdate *Hook `yaml:"pre-update,omitempty" validate:"omitempty"`
+
+	// PostUpdate runs after component instance has been updated
+	PostUpdate *Hook `yaml:"post-update,omitempty" validate:"omitempty"`
+
+	// PreDelete runs before component instance gets deleted
+	PreDelete *Hook `yaml:"pre-delete,omitempty" validate:"omitempty"`
+}
+
+// GetHooksMap returns a map of all defined hooks: phase -> hook
+func (hooks *Hooks) GetHooksMap() map[string]*Hook {
+	result := make(map[string]*Hook)
+	if hooks == nil {
+		return result
+	}
+	for phase, hook := range map[string]*Hook{
+		HookPreCreate:  hooks.PreCreate,
+		HookPostCreate: hooks.PostCreate,
+		HookPreUpdate:  hooks.PreUpdate,
+		HookPostUpdate: hooks.PostUpdate,
+		HookPreDelete:  hooks.PreDelete,
+	} {
+		if hook != nil {
+			result[phase] = hook
+		}
+	}
+	return result
+}
+
+// Hook is a piece of code, which runs to completion at a certain phase of component instance lifecycle (e.g. k8s Job
+// running database migration). Hook gets deployed via code plugin of a given type, considered completed once plugin
+// reports it as ready, and then gets destroyed
+type Hook struct {
+	// Type represents code type of the hook (e.g. "helm"). It determines the plugin that will get executed for it
+	Type string `validate:"required,codetype"`
+
+	// Params define parameters that will be passed down to the deployment plugin. They follow the same text template
+	// syntax as code params
+	Params util.NestedParameterMap `validate:"omitempty,templateNestedMap"`
+
+	// Timeout is how long to wait for the hook to complete
+	Timeout time.Duration `yaml:"timeout,omitempty" validate:"omitempty,min=0"`
+}
+
+// GetTimeout returns the amount of time to wait for the hook to complete
+func (hook *Hook) GetTimeout() time.Duration {
+	if hook.Timeout <= 0 {
+		return DefaultHookTimeout
+	}
+	return hook.Timeout
+}
@@ -0,0 +1,27 @@
+package lang
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHooksMap(t *testing.T) {
+	var hooks *Hooks
+	assert.Empty(t, hooks.GetHooksMap(), "There should be no hooks if they are not defined")
+
+	hooks = &Hooks{
+		PreUpdate:  &Hook{Type: "helm"},
+		PostCreate: &Hook{Type: "raw"},
+	}
+	result := hooks.GetHooksMap()
+	assert.Equal(t, 2, len(result), "Only defined hooks should be returned")
+	assert.Equal(t, hooks.PreUpdate, result[HookPreUpdate], "Pre-update hook")
+	assert.Equal(t, hooks.PostCreate, result[HookPostCreate], "Post-create hook")
+}
+
+func TestHookDefaults(t *testing.T) {
+	assert.Equal(t, DefaultHookTimeout, (&Hook{}).GetTimeout(), "Default hook timeout")
+	assert.Equal(t, time.Minute, (&Hook{Timeout: time.Minute}).GetTimeout(), "Hook timeout")
+}
@@ -0,0 +1,416 @@
+package lang
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/Aptomi/aptomi/pkg/lang/expression"
+	"github.com/Aptomi/aptomi/pkg/lang/template"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/util"
+)
+
+// Names of the checks performed by PolicyLinter
+const (
+	LintUnreachableContext  = "unreachable-context"
+	LintUnusedService       = "unused-service"
+	LintUnusedBundle        = "unused-bundle"
+	LintRuleNeverMatches    = "rule-never-matches"
+	LintUnusedLabel         = "unused-label"
+	LintDuplicateRuleWeight = "duplicate-rule-weight"
+)
+
+// PolicyLintWarning is a potential problem in the policy, which doesn't make the policy invalid, but most likely
+// indicates a mistake made by the policy author
+type PolicyLintWarning struct {
+	// Check is the name of the check which produced the warning
+	Check string
+
+	// Object is the key of the policy object the warning relates to
+	Object string
+
+	// Message is a human-readable description of the problem
+	Message string
+}
+
+// String returns a human-readable representation of the warning
+func (warning *PolicyLintWarning) String() string {
+	return fmt.Sprintf("%s: %s (%s)", warning.Object, warning.Message, warning.Check)
+}
+
+// GetDefaultColumns returns default set of columns to be displayed
+func (warning *PolicyLintWarning) GetDefaultColumns() []string {
+	return []string{"Object", "Check", "Message"}
+}
+
+// AsColumns returns PolicyLintWarning representation as columns
+func (warning *PolicyLintWarning) AsColumns() map[string]string {
+	return map[string]string{
+		"Object":  warning.Object,
+		"Check":   warning.Check,
+		"Message": warning.Message,
+	}
+}
+
+// PolicyLinter performs a set of checks on the policy, looking for issues which are not hard validation errors (e.g.
+// unused objects, unreachable contexts). It's assumed that the policy has already been validated
+type PolicyLinter struct {
+	policy   *Policy
+	warnings []*PolicyLintWarning
+}
+
+// NewPolicyLinter creates a new PolicyLinter
+func NewPolicyLinter(policy *Policy) *PolicyLinter {
+	return &PolicyLinter{policy: policy}
+}
+
+// Lint runs all checks on the policy and returns the list of warnings, sorted by object and check name
+func (linter *PolicyLinter) Lint() []*PolicyLintWarning {
+	linter.warnings = []*PolicyLintWarning{}
+
+	linter.checkContexts()
+	linter.checkUnusedServices()
+	linter.checkUnusedBundles()
+	linter.checkRules()
+	linter.checkUnusedLabels()
+
+	sort.SliceStable(linter.warnings, func(i, j int) bool {
+		if linter.warnings[i].Object != linter.warnings[j].Object {
+			return linter.warnings[i].Object < linter.warnings[j].Object
+		}
+		if linter.warnings[i].Check != linter.warnings[j].Check {
+			return linter.warnings[i].Check < linter.warnings[j].Check
+		}
+		return linter.warnings[i].Message < linter.warnings[j].Message
+	})
+	return linter.warnings
+}
+
+// Lint performs all lint checks on the policy and returns the list of warnings
+func (policy *Policy) Lint() []*PolicyLintWarning {
+	return NewPolicyLinter(policy).Lint()
+}
+
+func (linter *PolicyLinter) addWarning(obj Base, check string, format string, args ...interface{}) {
+	linter.warnings = append(linter.warnings, &PolicyLintWarning{
+		Check:   check,
+		Object:  runtime.KeyForStorable(obj),
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// checks that every context within a service can be matched, i.e. it's not shadowed by one of the previous contexts
+// and its own criteria can be evaluated to true
+func (linter *PolicyLinter) checkContexts() {
+	for _, obj := range linter.policy.GetObjectsByKind(TypeService.Kind) {
+		service := obj.(*Service) // nolint: errcheck
+		for i, context := range service.Contexts {
+			if reason := criteriaNeverMatches(context.Criteria); len(reason) > 0 {
+				linter.addWarning(service, LintUnreachableContext, "context '%s' will never be matched: %s", context.Name, reason)
+				continue
+			}
+			for _, prev := range service.Contexts[:i] {
+				if criteriaAlwaysMatches(prev.Criteria) {
+					linter.addWarning(service, LintUnreachableContext, "context '%s' will never be matched, because it's shadowed by context '%s', which always matches", context.Name, prev.Name)
+					break
+				}
+				if reflect.DeepEqual(prev.Criteria, context.Criteria) {
+					linter.addWarning(service, LintUnreachableContext, "context '%s' will never be matched, because it's shadowed by context '%s' with the same criteria", context.Name, prev.Name)
+					break
+				}
+			}
+		}
+	}
+}
+
+// checks that every service is either claimed or used by one of the bundles
+func (linter *PolicyLinter) checkUnusedServices() {
+	used := make(map[string]bool)
+	for _, obj := range linter.policy.GetObjectsByKind(TypeClaim.Kind) {
+		claim := obj.(*Claim) // nolint: errcheck
+		linter.markUsed(used, TypeService.Kind, claim.Service, claim.Namespace)
+	}
+	for _, obj := range linter.policy.GetObjectsByKind(TypeBundle.Kind) {
+		bundle := obj.(*Bundle) // nolint: errcheck
+		for _, component := range bundle.Components {
+			if len(component.Service) > 0 {
+				linter.markUsed(used, TypeService.Kind, component.Service, bundle.Namespace)
+			}
+		}
+	}
+
+	for _, obj := range linter.policy.GetObjectsByKind(TypeService.Kind) {
+		if !used[runtime.KeyForStorable(obj)] {
+			linter.addWarning(obj, LintUnusedService, "service has no claims and is not used by any bundle")
+		}
+	}
+}
+
+// checks that every bundle is referenced by at least one service context
+func (linter *PolicyLinter) checkUnusedBundles() {
+	used := make(map[string]bool)
+	for _, obj := range linter.policy.GetObjectsByKind(TypeService.Kind) {
+		service := obj.(*Service) // nolint: errcheck
+		for _, context := range service.Contexts {
+			if context.Allocation != nil {
+				linter.markUsed(used, TypeBundle.Kind, context.Allocation.Bundle, service.Namespace)
+			}
+		}
+	}
+
+	for _, obj := range linter.policy.GetObjectsByKind(TypeBundle.Kind) {
+		if !used[runtime.KeyForStorable(obj)] {
+			linter.addWarning(obj, LintUnusedBundle, "bundle is not used by any service")
+		}
+	}
+}
+
+func (linter *PolicyLinter) markUsed(used map[string]bool, kind string, locator string, currentNs string) {
+	obj, err := linter.policy.GetObject(kind, locator, currentNs)
+	if err == nil && obj != nil {
+		used[runtime.KeyForStorable(obj.(Base))] = true
+	}
+}
+
+// checks that rule criteria can be evaluated to true and that rules within a namespace have distinct weights, so
+// the order in which they get applied is well-defined
+func (linter *PolicyLinter) checkRules() {
+	for _, policyNS := range linter.policy.Namespace {
+		rulesByWeight := make(map[int][]*Rule)
+		for _, rule := range policyNS.Rules {
+			if reason := criteriaNeverMatches(rule.Criteria); len(reason) > 0 {
+				linter.addWarning(rule, LintRuleNeverMatches, "rule will never be applied: %s", reason)
+			}
+			rulesByWeight[rule.Weight] = append(rulesByWeight[rule.Weight], rule)
+		}
+
+		for weight, rules := range rulesByWeight {
+			if len(rules) <= 1 {
+				continue
+			}
+			for _, rule := range rules {
+				others := []string{}
+				for _, other := range rules {
+					if other != rule {
+						others = append(others, other.Name)
+					}
+				}
+				sort.Strings(others)
+				linter.addWarning(rule, LintDuplicateRuleWeight, "rule has the same weight %d as %s, the order in which they are applied is undefined", weight, strings.Join(others, ", "))
+			}
+		}
+	}
+}
+
+// checks that every label which gets set in the policy is referenced in at least one expression or template
+func (linter *PolicyLinter) checkUnusedLabels() {
+	refs := newLabelReferences()
+
+	// collect labels referenced in criteria and templates
+	for _, obj := range linter.policy.GetObjectsByKind(TypeRule.Kind) {
+		refs.addCriteria(obj.(*Rule).Criteria)
+	}
+	for _, obj := range linter.policy.GetObjectsByKind(TypeACLRule.Kind) {
+		refs.addCriteria(obj.(*ACLRule).Criteria)
+	}
+	for _, obj := range linter.policy.GetObjectsByKind(TypeACLRole.Kind) {
+		privileges := obj.(*ACLRole).Privileges
+		if privileges == nil {
+			continue
+		}
+		for _, privilege := range privileges.NamespaceObjects {
+			if privilege != nil {
+				refs.addCriteria(privilege.Selector)
+			}
+		}
+		for _, privilege := range privileges.GlobalObjects {
+			if privilege != nil {
+				refs.addCriteria(privilege.Selector)
+			}
+		}
+	}
+	for _, obj := range linter.policy.GetObjectsByKind(TypeService.Kind) {
+		for _, context := range obj.(*Service).Contexts {
+			refs.addCriteria(context.Criteria)
+			if context.Allocation != nil {
+				for _, key := range context.Allocation.Keys {
+					refs.addTemplate(key)
+				}
+			}
+		}
+	}
+	for _, obj := range linter.policy.GetObjectsByKind(TypeBundle.Kind) {
+		for _, component := range obj.(*Bundle).Components {
+			refs.addCriteria(component.Criteria)
+			refs.addTemplateMap(component.Discovery)
+			if component.Code != nil {
+				refs.addTemplateMap(component.Code.Params)
+			}
+		}
+	}
+
+	// if all labels are referenced at once (e.g. passed to a template function), then we can't say which are unused
+	if refs.all {
+		return
+	}
+
+	// report every object which sets a label that is never referenced
+	checkSet := func(obj Base, labels map[string]string) {
+		for _, name := range util.GetSortedStringKeys(labels) {
+			if name != LabelTarget && !refs.labels[name] {
+				linter.addWarning(obj, LintUnusedLabel, "label '%s' is set, but never used in any expression or template", name)
+			}
+		}
+	}
+	for _, obj := range linter.policy.GetObjectsByKind(TypeRule.Kind) {
+		rule := obj.(*Rule) // nolint: errcheck
+		if rule.Actions != nil {
+			checkSet(rule, rule.Actions.ChangeLabels["set"])
+		}
+	}
+	for _, obj := range linter.policy.GetObjectsByKind(TypeService.Kind) {
+		service := obj.(*Service) // nolint: errcheck
+		checkSet(service, service.ChangeLabels["set"])
+		for _, context := range service.Contexts {
+			checkSet(service, context.ChangeLabels["set"])
+		}
+	}
+	for _, obj := range linter.policy.GetObjectsByKind(TypeClaim.Kind) {
+		claim := obj.(*Claim) // nolint: errcheck
+		checkSet(claim, claim.Labels)
+	}
+}
+
+// labelReferences is a set of labels referenced in the policy
+type labelReferences struct {
+	labels map[string]bool
+	all    bool
+}
+
+func newLabelReferences() *labelReferences {
+	return &labelReferences{labels: make(map[string]bool)}
+}
+
+func (refs *labelReferences) addCriteria(criteria *Criteria) {
+	if criteria == nil {
+		return
+	}
+	for _, list := range [][]string{criteria.RequireAll, criteria.RequireAny, criteria.RequireNone} {
+		for _, exprStr := range list {
+			expr, err := expression.NewExpression(exprStr)
+			if err != nil {
+				continue
+			}
+			for _, name := range expr.Variables() {
+				refs.labels[name] = true
+			}
+		}
+	}
+}
+
+func (refs *labelReferences) addTemplate(templateStr string) {
+	tmpl, err := template.NewTemplate(templateStr)
+	if err != nil {
+		return
+	}
+	for _, ref := range tmpl.References() {
+		parts := strings.Split(ref, ".")
+		if parts[0] != "Labels" {
+			continue
+		}
+		if len(parts) == 1 {
+			refs.all = true
+		} else {
+			refs.labels[parts[1]] = true
+		}
+	}
+}
+
+func (refs *labelReferences) addTemplateMap(params interface{}) {
+	switch value := params.(type) {
+	case string:
+		refs.addTemplate(value)
+	case util.NestedParameterMap:
+		for _, v := range value {
+			refs.addTemplateMap(v)
+		}
+	case map[string]interface{}:
+		for _, v := range value {
+			refs.addTemplateMap(v)
+		}
+	}
+}
+
+// constantValue returns the value of an expression and true, if the expression is constant (i.e. always gets
+// evaluated to the same value). Otherwise, it returns false as the second value
+func constantValue(exprStr string) (bool, bool) {
+	expr, err := expression.NewExpression(exprStr)
+	if err != nil || !expr.IsConstant() {
+		return false, false
+	}
+	value, err := expr.EvaluateAsBool(expression.NewParams(nil, nil))
+	if err != nil {
+		return false, false
+	}
+	return value, true
+}
+
+// criteriaNeverMatches returns the reason why given criteria can never be evaluated to true, or an empty string if
+// it's not known statically
+func criteriaNeverMatches(criteria *Criteria) string {
+	if criteria == nil {
+		return ""
+	}
+	for _, exprStr := range criteria.RequireAll {
+		if value, ok := constantValue(exprStr); ok && !value {
+			return fmt.Sprintf("require-all expression '%s' is always false", exprStr)
+		}
+		for _, exprNoneStr := range criteria.RequireNone {
+			if strings.TrimSpace(exprStr) == strings.TrimSpace(exprNoneStr) {
+				return fmt.Sprintf("expression '%s' is present in both require-all and require-none", exprStr)
+			}
+		}
+	}
+	for _, exprStr := range criteria.RequireNone {
+		if value, ok := constantValue(exprStr); ok && value {
+			return fmt.Sprintf("require-none expression '%s' is always true", exprStr)
+		}
+	}
+	if len(criteria.RequireAny) > 0 {
+		for _, exprStr := range criteria.RequireAny {
+			if value, ok := constantValue(exprStr); !ok || value {
+				return ""
+			}
+		}
+		return "all require-any expressions are always false"
+	}
+	return ""
+}
+
+// criteriaAlwaysMatches returns true if given criteria will always be evaluated to true
+func criteriaAlwaysMatches(criteria *Criteria) bool {
+	if criteria == nil {
+		return true
+	}
+	for _, exprStr := range criteria.RequireAll {
+		if value, ok := constantValue(exprStr); !ok || !value {
+			return false
+		}
+	}
+	for _, exprStr := range criteria.RequireNone {
+		if value, ok := constantValue(exprStr); !ok || value {
+			return false
+		}
+	}
+	if len(criteria.RequireAny) > 0 {
+		for _, exprStr := range criteria.RequireAny {
+			if value, ok := constantValue(exprStr); ok && value {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,111 @@
+package lang
+
+import (
+	"testing"
+
+	"github.com/Aptomi/aptomi/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func makeLintPolicy() *Policy {
+	policy := NewPolicy()
+
+	// bundle which is used by a service, with code params referencing 'replicas' label
+	bundle := makeBundle("bundle", Nil)
+	bundle.Components = []*BundleComponent{
+		{
+			Name: "component",
+			Code: &Code{
+				Type:   "helm",
+				Params: util.NestedParameterMap{"replicas": "{{ .Labels.replicas }}"},
+			},
+		},
+	}
+
+	// service with contexts: 'default' always matches and shadows everything below it
+	service := makeService("service", Nil, "bundle")
+	service.Contexts = []*Context{
+		{
+			Name:       "prod",
+			Criteria:   &Criteria{RequireAll: []string{"env == 'prod'"}},
+			Allocation: &Allocation{Bundle: "bundle"},
+		},
+		{
+			Name:       "prod-again",
+			Criteria:   &Criteria{RequireAll: []string{"env == 'prod'"}},
+			Allocation: &Allocation{Bundle: "bundle"},
+		},
+		{
+			Name:       "never",
+			Criteria:   &Criteria{RequireAll: []string{"1 > 2"}},
+			Allocation: &Allocation{Bundle: "bundle"},
+		},
+		{
+			Name:         "default",
+			ChangeLabels: NewLabelOperationsSetSingleLabel("replicas", "1"),
+			Allocation:   &Allocation{Bundle: "bundle"},
+		},
+		{
+			Name:       "unreachable",
+			Criteria:   &Criteria{RequireAll: []string{"env == 'dev'"}},
+			Allocation: &Allocation{Bundle: "bundle"},
+		},
+	}
+
+	// claim with an unused label
+	claim := makeClaim("service")
+	claim.Labels = map[string]string{"env": "prod", "unused": "true"}
+
+	// service and bundle, which are not used by anything
+	unusedBundle := makeBundle("unused-bundle", Nil)
+	unusedService := makeService("unused-service", Nil, "")
+
+	// rules with the same weight, one of them can never match
+	rule1 := makeRule(10, "", 0, "replicas")
+	rule1.Name = "rule1"
+	rule2 := makeRule(10, "", 0, LabelTarget)
+	rule2.Name = "rule2"
+	rule2.Criteria = &Criteria{RequireAll: []string{"env == 'prod'"}, RequireNone: []string{"env == 'prod'"}}
+	rule3 := makeRule(20, "", 0, LabelTarget)
+	rule3.Name = "rule3"
+	rule3.Criteria = &Criteria{RequireAny: []string{"false", "1 == 2"}}
+
+	for _, obj := range []Base{bundle, service, claim, unusedBundle, unusedService, rule1, rule2, rule3} {
+		err := policy.AddObject(obj)
+		if err != nil {
+			panic(err)
+		}
+	}
+	return policy
+}
+
+func TestPolicyLint(t *testing.T) {
+	policy := makeLintPolicy()
+	if !assert.NoError(t, policy.Validate(), "Policy should be valid") {
+		return
+	}
+
+	warnings := map[string][]string{}
+	for _, warning := range policy.Lint() {
+		warnings[warning.Check] = append(warnings[warning.Check], warning.String())
+	}
+
+	assert.Equal(t, 3, len(warnings[LintUnreachableContext]), "Unreachable contexts: %v", warnings[LintUnreachableContext])
+	assert.Contains(t, warnings[LintUnreachableContext], "main/service/service: context 'prod-again' will never be matched, because it's shadowed by context 'prod' with the same criteria (unreachable-context)")
+	assert.Contains(t, warnings[LintUnreachableContext], "main/service/service: context 'never' will never be matched: require-all expression '1 > 2' is always false (unreachable-context)")
+	assert.Contains(t, warnings[LintUnreachableContext], "main/service/service: context 'unreachable' will never be matched, because it's shadowed by context 'default', which always matches (unreachable-context)")
+
+	assert.Equal(t, []string{"main/service/unused-service: service has no claims and is not used by any bundle (unused-service)"}, warnings[LintUnusedService])
+	assert.Equal(t, []string{"main/bundle/unused-bundle: bundle is not used by any service (unused-bundle)"}, warnings[LintUnusedBundle])
+	assert.Equal(t, []string{"main/claim/claim: label 'unused' is set, but never used in any expression or template (unused-label)"}, warnings[LintUnusedLabel])
+
+	assert.Equal(t, 2, len(warnings[LintRuleNeverMatches]), "Rules never matching: %v", warnings[LintRuleNeverMatches])
+	assert.Equal(t, 2, len(warnings[LintDuplicateRuleWeight]), "Duplicate rule weights: %v", warnings[LintDuplicateRuleWeight])
+	assert.Contains(t, warnings[LintDuplicateRuleWeight], "main/rule/rule1: rule has the same weight 10 as rule2, the order in which they are applied is undefined (duplicate-rule-weight)")
+
+	// once all labels are passed to a template, none of them should be reported as unused
+	policy.Namespace["main"].Bundles["bundle"].Components[0].Code.Params["all"] = "{{ toYaml .Labels }}"
+	for _, warning := range policy.Lint() {
+		assert.NotEqual(t, LintUnusedLabel, warning.Check, "Unexpected warning: %s", warning)
+	}
+}
@@ -0,0 +1,159 @@
+package lang
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/lang/expression"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/util/cron"
+)
+
+// TypeMaintenance is an informational data structure with Kind and Constructor for Maintenance
+var TypeMaintenance = &runtime.TypeInfo{
+	Kind:        "maintenance-window",
+	Storable:    true,
+	Versioned:   true,
+	Constructor: func() runtime.Object { return &Maintenance{} },
+}
+
+// Maintenance defines when changes are allowed to be made to a set of clusters. Clusters are selected by their
+// labels. Actions against selected clusters will be held by the engine until one of the maintenance windows opens,
+// and will be held during all change freeze periods.
+//
+// Maintenance objects are global and must be always defined in the 'system' namespace. If several of them match the
+// same cluster, all of them apply (i.e. actions are allowed only when none of them holds them)
+type Maintenance struct {
+	runtime.TypeKind `yaml:",inline"`
+	Metadata         `validate:"required"`
+
+	// Clusters is a criteria, which gets evaluated against cluster labels to select clusters this object applies to.
+	// It's an optional field, so if it's nil then all clusters will be selected
+	Clusters *Criteria `yaml:",omitempty" validate:"omitempty"`
+
+	// Windows is a list of recurring maintenance windows. If it's not empty, actions against selected clusters will
+	// only be allowed while one of the windows is open
+	Windows []*MaintenanceWindow `yaml:",omitempty" validate:"dive"`
+
+	// Freezes is a list of change freeze periods, during which all actions against selected clusters will be held
+	Freezes []*MaintenanceFreeze `yaml:",omitempty" validate:"dive"`
+}
+
+// MaintenanceWindow is a recurring maintenance window, which opens according to a cron-like schedule and stays
+// open for a given amount of time
+type MaintenanceWindow struct {
+	// Schedule is a cron expression (minute, hour, day of month, month, day of week), defining when window opens.
+	// It's evaluated in UTC
+	Schedule string `validate:"required,cron"`
+
+	// Duration is how long window stays open
+	Duration time.Duration `validate:"required,min=0"`
+}
+
+// MaintenanceFreeze is a change freeze period, defined by its start and end time (in RFC3339 format)
+type MaintenanceFreeze struct {
+	// From is the time when freeze starts
+	From string `validate:"required,timestamp"`
+
+	// To is the time when freeze ends
+	To string `validate:"required,timestamp"`
+
+	// Reason is an optional human-readable reason for the freeze
+	Reason string `yaml:",omitempty"`
+}
+
+// Matches returns true if a given cluster is selected by this maintenance object
+func (maintenance *Maintenance) Matches(cluster *Cluster, cache *expression.Cache) (bool, error) {
+	if maintenance.Clusters == nil {
+		return true, nil
+	}
+	return maintenance.Clusters.allows(expression.NewParams(cluster.Labels, nil), cache)
+}
+
+// GetHoldReason returns the reason why actions against clusters selected by this maintenance object must be held at
+// a given time. If actions are allowed, empty string is returned
+func (maintenance *Maintenance) GetHoldReason(now time.Time) (string, error) {
+	now = now.UTC()
+
+	// check change freezes first, as they take precedence over maintenance windows
+	for _, freeze := range maintenance.Freezes {
+		from, to, err := freeze.parse()
+		if err != nil {
+			return "", err
+		}
+		if !now.Before(from) && now.Before(to) {
+			reason := fmt.Sprintf("change freeze '%s/%s' until %s", maintenance.Namespace, maintenance.Name, to.Format(time.RFC3339))
+			if len(freeze.Reason) > 0 {
+				reason = fmt.Sprintf("%s (%s)", reason, freeze.Reason)
+			}
+			return reason, nil
+		}
+	}
+
+	if len(maintenance.Windows) <= 0 {
+		return "", nil
+	}
+
+	// check that one of the maintenance windows is open
+	var next time.Time
+	for _, window := range maintenance.Windows {
+		schedule, err := cron.Parse(window.Schedule)
+		if err != nil {
+			return "", err
+		}
+
+		// window is open if it has been opened within its duration before now
+		opened := schedule.Next(now.Add(-window.Duration))
+		if !opened.IsZero() && !opened.After(now) {
+			return "", nil
+		}
+
+		opens := schedule.Next(now)
+		if !opens.IsZero() && (next.IsZero() || opens.Before(next)) {
+			next = opens
+		}
+	}
+
+	reason := fmt.Sprintf("outside of maintenance window '%s/%s'", maintenance.Namespace, maintenance.Name)
+	if !next.IsZero() {
+		reason = fmt.Sprintf("%s, next window opens at %s", reason, next.Format(time.RFC3339))
+	}
+	return reason, nil
+}
+
+// parse returns start and end time of the freeze
+func (freeze *MaintenanceFreeze) parse() (time.Time, time.Time, error) {
+	from, err := time.Parse(time.RFC3339, freeze.From)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("can't parse start time of change freeze: %s", err)
+	}
+	to, err := time.Parse(time.RFC3339, freeze.To)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("can't parse end time of change freeze: %s", err)
+	}
+	return from, to, nil
+}
+
+// GetMaintenanceHoldReason returns the reason why actions against a given cluster must be held at a given time,
+// according to all maintenance objects in the policy. If actions are allowed, empty string is returned
+func (policy *Policy) GetMaintenanceHoldReason(cluster *Cluster, now time.Time, cache *expression.Cache) (string, error) {
+	for _, obj := range policy.GetObjectsByKind(TypeMaintenance.Kind) {
+		maintenance := obj.(*Maintenance) // nolint: errcheck
+		matches, err := maintenance.Matches(cluster, cache)
+		if err != nil {
+			return "", fmt.Errorf("unable to evaluate clusters criteria of maintenance window '%s/%s': %s", maintenance.Namespace, maintenance.Name, err)
+		}
+		if !matches {
+			continue
+		}
+
+		reason, err := maintenance.GetHoldReason(now)
+		if err != nil {
+			return "", fmt.Errorf("unable to check maintenance window '%s/%s': %s", maintenance.Namespace, maintenance.Name, err)
+		}
+		if len(reason) > 0 {
+			return reason, nil
+		}
+	}
+	return "", nil
+}
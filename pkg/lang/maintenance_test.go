@@ -0,0 +1,62 @@
+package lang
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaintenanceHoldReason(t *testing.T) {
+	maintenance := makeMaintenance(runtime.SystemNS, "0 22 * * 6", 4*time.Hour, "2018-12-20T00:00:00Z", "2019-01-05T00:00:00Z")
+	maintenance.Freezes[0].Reason = "holidays"
+
+	// Saturday, 22:00 - window has just opened
+	saturday := time.Date(2018, time.March, 10, 22, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		time   time.Time
+		reason string
+	}{
+		{saturday, ""},
+		{saturday.Add(3*time.Hour + 59*time.Minute), ""},
+		{saturday.Add(4 * time.Hour), "outside of maintenance window 'system/maintenance', next window opens at 2018-03-17T22:00:00Z"},
+		{saturday.Add(-time.Minute), "outside of maintenance window 'system/maintenance', next window opens at 2018-03-10T22:00:00Z"},
+		{time.Date(2018, time.December, 22, 23, 0, 0, 0, time.UTC), "change freeze 'system/maintenance' until 2019-01-05T00:00:00Z (holidays)"},
+	}
+	for _, tc := range testCases {
+		reason, err := maintenance.GetHoldReason(tc.time)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.reason, reason, "hold reason at %s", tc.time)
+	}
+}
+
+func TestPolicyMaintenanceHoldReason(t *testing.T) {
+	policy := NewPolicy()
+
+	maintenance := makeMaintenance(runtime.SystemNS, "", 0, "2018-12-20T00:00:00Z", "2019-01-05T00:00:00Z")
+	maintenance.Clusters = &Criteria{RequireAll: []string{"env == 'prod'"}}
+	assert.NoError(t, policy.AddObject(maintenance))
+
+	clusterProd := makeCluster("kubernetes", runtime.SystemNS)
+	clusterProd.Labels = map[string]string{"env": "prod"}
+	clusterDev := makeCluster("kubernetes", runtime.SystemNS)
+	clusterDev.Labels = map[string]string{"env": "dev"}
+
+	// cluster matching the criteria is held during the freeze
+	frozen := time.Date(2018, time.December, 22, 23, 0, 0, 0, time.UTC)
+	reason, err := policy.GetMaintenanceHoldReason(clusterProd, frozen, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "change freeze 'system/maintenance' until 2019-01-05T00:00:00Z", reason)
+
+	// other clusters are not affected
+	reason, err = policy.GetMaintenanceHoldReason(clusterDev, frozen, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, reason)
+
+	// and nothing is held after the freeze is over
+	reason, err = policy.GetMaintenanceHoldReason(clusterProd, frozen.AddDate(0, 1, 0), nil)
+	assert.NoError(t, err)
+	assert.Empty(t, reason)
+}
@@ -11,6 +11,8 @@ var (
 		TypeCluster,
 		TypeRule,
 		TypeACLRule,
+		TypeACLRole,
+		TypeMaintenance,
 	}
 
 	policyObjectsMap = make(map[runtime.Kind]bool)
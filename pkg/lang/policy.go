@@ -46,16 +46,20 @@ func (policy *Policy) getCachedACLResolver() *ACLResolver {
 	policy.aclMutex.Lock()
 	defer policy.aclMutex.Unlock()
 	if policy.aclResolver == nil {
-		systemNamespace := policy.Namespace[runtime.SystemNS]
-		if systemNamespace != nil {
-			policy.aclResolver = NewACLResolver(systemNamespace.ACLRules)
-		} else {
-			policy.aclResolver = NewACLResolver(make(map[string]*ACLRule))
-		}
+		policy.aclResolver = policy.NewACLResolver()
 	}
 	return policy.aclResolver
 }
 
+// NewACLResolver returns a new ACLResolver for ACL rules and ACL roles defined in the policy
+func (policy *Policy) NewACLResolver() *ACLResolver {
+	systemNamespace := policy.Namespace[runtime.SystemNS]
+	if systemNamespace == nil {
+		return NewACLResolver(make(map[string]*ACLRule), make(map[string]*ACLRole))
+	}
+	return NewACLResolver(systemNamespace.ACLRules, systemNamespace.ACLRoles)
+}
+
 // View returns a policy view object, which allows to make all policy operations on behalf of a certain user
 // Policy view object will enforce all ACLs, allowing the user to only perform actions which he is allowed to perform
 // All ACL rules should be loaded and added to the policy before this method gets called
@@ -73,8 +77,8 @@ func (policy *Policy) AddObject(obj Base) error {
 	}
 	err := policyNamespace.addObject(obj)
 
-	// if we just added ACLRule, we need to invalidate cached aclResolver
-	if obj.GetKind() == TypeACLRule.Kind {
+	// if we just added ACLRule or ACLRole, we need to invalidate cached aclResolver
+	if obj.GetKind() == TypeACLRule.Kind || obj.GetKind() == TypeACLRole.Kind {
 		policy.invalidateCachedACLResolver()
 	}
 
@@ -88,7 +92,14 @@ func (policy *Policy) RemoveObject(obj Base) bool {
 		return false
 	}
 
-	return policyNamespace.removeObject(obj)
+	removed := policyNamespace.removeObject(obj)
+
+	// if we just removed ACLRule or ACLRole, we need to invalidate cached aclResolver
+	if removed && (obj.GetKind() == TypeACLRule.Kind || obj.GetKind() == TypeACLRole.Kind) {
+		policy.invalidateCachedACLResolver()
+	}
+
+	return removed
 }
 
 // GetObjectsByKind returns all objects in a policy with a given kind, across all namespaces
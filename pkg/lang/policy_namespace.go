@@ -10,25 +10,29 @@ import (
 // PolicyNamespace describes a specific namespace within Aptomi policy.
 // All policy objects get placed in the appropriate maps and structs within PolicyNamespace.
 type PolicyNamespace struct {
-	Name     string              `validate:"identifier"`
-	Bundles  map[string]*Bundle  `validate:"dive"`
-	Services map[string]*Service `validate:"dive"`
-	Clusters map[string]*Cluster `validate:"dive"`
-	Rules    map[string]*Rule    `validate:"dive"`
-	ACLRules map[string]*ACLRule `validate:"dive"`
-	Claims   map[string]*Claim   `validate:"dive"`
+	Name        string                  `validate:"identifier"`
+	Bundles     map[string]*Bundle      `validate:"dive"`
+	Services    map[string]*Service     `validate:"dive"`
+	Clusters    map[string]*Cluster     `validate:"dive"`
+	Rules       map[string]*Rule        `validate:"dive"`
+	ACLRules    map[string]*ACLRule     `validate:"dive"`
+	ACLRoles    map[string]*ACLRole     `validate:"dive"`
+	Claims      map[string]*Claim       `validate:"dive"`
+	Maintenance map[string]*Maintenance `validate:"dive"`
 }
 
 // NewPolicyNamespace creates a new PolicyNamespace
 func NewPolicyNamespace(name string) *PolicyNamespace {
 	return &PolicyNamespace{
-		Name:     name,
-		Bundles:  make(map[string]*Bundle),
-		Services: make(map[string]*Service),
-		Clusters: make(map[string]*Cluster),
-		Rules:    make(map[string]*Rule),
-		ACLRules: make(map[string]*ACLRule),
-		Claims:   make(map[string]*Claim),
+		Name:        name,
+		Bundles:     make(map[string]*Bundle),
+		Services:    make(map[string]*Service),
+		Clusters:    make(map[string]*Cluster),
+		Rules:       make(map[string]*Rule),
+		ACLRules:    make(map[string]*ACLRule),
+		ACLRoles:    make(map[string]*ACLRole),
+		Claims:      make(map[string]*Claim),
+		Maintenance: make(map[string]*Maintenance),
 	}
 }
 
@@ -63,8 +67,12 @@ func (policyNamespace *PolicyNamespace) addObject(obj Base) error {
 		policyNamespace.Rules[obj.GetName()] = obj.(*Rule) // nolint: errcheck
 	case TypeACLRule.Kind:
 		policyNamespace.ACLRules[obj.GetName()] = obj.(*ACLRule) // nolint: errcheck
+	case TypeACLRole.Kind:
+		policyNamespace.ACLRoles[obj.GetName()] = obj.(*ACLRole) // nolint: errcheck
 	case TypeClaim.Kind:
 		policyNamespace.Claims[obj.GetName()] = obj.(*Claim) // nolint: errcheck
+	case TypeMaintenance.Kind:
+		policyNamespace.Maintenance[obj.GetName()] = obj.(*Maintenance) // nolint: errcheck
 	default:
 		return fmt.Errorf("not supported by PolicyNamespace.addObject(): unknown kind %s", kind)
 	}
@@ -98,11 +106,21 @@ func (policyNamespace *PolicyNamespace) removeObject(obj Base) bool {
 			delete(policyNamespace.ACLRules, obj.GetName())
 			return true
 		}
+	case TypeACLRole.Kind:
+		if _, exist := policyNamespace.ACLRoles[obj.GetName()]; exist {
+			delete(policyNamespace.ACLRoles, obj.GetName())
+			return true
+		}
 	case TypeClaim.Kind:
 		if _, exist := policyNamespace.Claims[obj.GetName()]; exist {
 			delete(policyNamespace.Claims, obj.GetName())
 			return true
 		}
+	case TypeMaintenance.Kind:
+		if _, exist := policyNamespace.Maintenance[obj.GetName()]; exist {
+			delete(policyNamespace.Maintenance, obj.GetName())
+			return true
+		}
 	}
 
 	return false
@@ -131,10 +149,18 @@ func (policyNamespace *PolicyNamespace) getObjectsByKind(kind string) []Base {
 		for _, rule := range policyNamespace.ACLRules {
 			result = append(result, rule)
 		}
+	case TypeACLRole.Kind:
+		for _, role := range policyNamespace.ACLRoles {
+			result = append(result, role)
+		}
 	case TypeClaim.Kind:
 		for _, claim := range policyNamespace.Claims {
 			result = append(result, claim)
 		}
+	case TypeMaintenance.Kind:
+		for _, maintenance := range policyNamespace.Maintenance {
+			result = append(result, maintenance)
+		}
 	default:
 		panic(fmt.Sprintf("not supported by PolicyNamespace.getObjectsByKind(): unknown kind %s", kind))
 	}
@@ -165,10 +191,18 @@ func (policyNamespace *PolicyNamespace) getObject(kind string, name string) (run
 		if result, ok = policyNamespace.ACLRules[name]; !ok {
 			return nil, nil
 		}
+	case TypeACLRole.Kind:
+		if result, ok = policyNamespace.ACLRoles[name]; !ok {
+			return nil, nil
+		}
 	case TypeClaim.Kind:
 		if result, ok = policyNamespace.Claims[name]; !ok {
 			return nil, nil
 		}
+	case TypeMaintenance.Kind:
+		if result, ok = policyNamespace.Maintenance[name]; !ok {
+			return nil, nil
+		}
 	default:
 		return nil, fmt.Errorf("not supported by PolicyNamespace.getObject(): unknown kind %s, %s", kind, name)
 	}
@@ -29,57 +29,89 @@ func NewPolicyView(policy *Policy, user *User, resolver *ACLResolver) *PolicyVie
 // AddObject adds an object into the policy. When you add objects to the policy, they get added to the corresponding
 // Namespace. If error occurs (e.g. object has an unknown kind, etc) then the error will be returned
 func (view *PolicyView) AddObject(obj Base) error {
-	privilege, err := view.Resolver.GetUserPrivileges(view.User, obj)
+	err := view.ManageObject(obj)
 	if err != nil {
 		return err
 	}
-	if !privilege.Manage {
-		return fmt.Errorf("user '%s' doesn't have ACL permissions to manage object '%s/%s/%s'", view.User.Name, obj.GetNamespace(), obj.GetKind(), obj.GetName())
-	}
 	return view.Policy.AddObject(obj)
 }
 
 // ViewObject checks if user has permissions to view a given object. If user has no permissions, then ACL error
 // will be returned
 func (view *PolicyView) ViewObject(obj Base) error {
-	privilege, err := view.Resolver.GetUserPrivileges(view.User, obj)
+	return view.checkVerb(ACLVerbView, obj)
+}
+
+// ManageObject checks if user has permissions to create a given object or to update it, if it already exists in the
+// policy. When object gets updated, user should be allowed to update both existing and updated versions of it (e.g.
+// so that an object can't be moved out of the scope of role selector). If user has no permissions, then ACL error
+// will be returned
+func (view *PolicyView) ManageObject(obj Base) error {
+	existing, err := view.getExistingObject(obj)
 	if err != nil {
 		return err
 	}
-	if !privilege.View {
-		return fmt.Errorf("user '%s' doesn't have ACL permissions to view object '%s/%s/%s'", view.User.Name, obj.GetNamespace(), obj.GetKind(), obj.GetName())
+	if existing == nil {
+		return view.checkVerb(ACLVerbCreate, obj)
 	}
-	return nil
+	err = view.checkVerb(ACLVerbUpdate, existing)
+	if err != nil {
+		return err
+	}
+	return view.checkVerb(ACLVerbUpdate, obj)
 }
 
-// ManageObject checks if user has permissions to manage a given object. If user has no permissions, then ACL error
-// will be returned
-func (view *PolicyView) ManageObject(obj Base) error {
-	privilege, err := view.Resolver.GetUserPrivileges(view.User, obj)
+// DeleteObject checks if user has permissions to delete a given object. If object exists in the policy, permissions
+// get checked against the existing version of it. If user has no permissions, then ACL error will be returned
+func (view *PolicyView) DeleteObject(obj Base) error {
+	existing, err := view.getExistingObject(obj)
 	if err != nil {
 		return err
 	}
-	if !privilege.Manage {
-		return fmt.Errorf("user '%s' doesn't have ACL permissions to manage object '%s/%s/%s'", view.User.Name, obj.GetNamespace(), obj.GetKind(), obj.GetName())
+	if existing != nil {
+		obj = existing
 	}
-	return nil
+	return view.checkVerb(ACLVerbDelete, obj)
 }
 
-// CanConsume returns if user has permissions to consume a given service.
-// If a user can declare a claim in a given namespace, then he can essentially can consume the service
+// ApproveObject checks if user has permissions to approve or reject changes of a given object. If user has no
+// permissions, then ACL error will be returned
+func (view *PolicyView) ApproveObject(obj Base) error {
+	return view.checkVerb(ACLVerbApprove, obj)
+}
+
+// CanConsume returns if user has permissions to consume a given service
 func (view *PolicyView) CanConsume(service *Service) (bool, error) {
-	obj := &Claim{
-		TypeKind: TypeClaim.GetTypeKind(),
-		Metadata: Metadata{
-			Namespace: service.GetNamespace(),
-		},
-	}
-	privilege, err := view.Resolver.GetUserPrivileges(view.User, obj)
+	privilege, err := view.Resolver.GetUserPrivileges(view.User, service)
 	if err != nil {
 		return false, err
 	}
-	if !privilege.Manage {
+	if !privilege.Allows(ACLVerbConsume) {
 		return false, fmt.Errorf("user '%s' doesn't have ACL permissions to consume service '%s/%s'", view.User.Name, service.GetNamespace(), service.GetName())
 	}
 	return true, nil
 }
+
+// checkVerb checks if user is allowed to perform a given verb on a given object
+func (view *PolicyView) checkVerb(verb string, obj Base) error {
+	privilege, err := view.Resolver.GetUserPrivileges(view.User, obj)
+	if err != nil {
+		return err
+	}
+	if !privilege.Allows(verb) {
+		return fmt.Errorf("user '%s' doesn't have ACL permissions to %s object '%s/%s/%s'", view.User.Name, verb, obj.GetNamespace(), obj.GetKind(), obj.GetName())
+	}
+	return nil
+}
+
+// getExistingObject returns the current version of a given object from the policy, or nil if it doesn't exist
+func (view *PolicyView) getExistingObject(obj Base) (Base, error) {
+	if view.Policy.Namespace[obj.GetNamespace()] == nil {
+		return nil, nil
+	}
+	existing, err := view.Policy.GetObject(obj.GetKind(), obj.GetName(), obj.GetNamespace())
+	if err != nil || existing == nil {
+		return nil, err
+	}
+	return existing.(Base), nil
+}
@@ -93,12 +93,12 @@ func TestPolicyViewManageACLRules(t *testing.T) {
 			TypeKind: TypeACLRule.GetTypeKind(),
 			Metadata: Metadata{
 				Namespace: runtime.SystemNS,
-				Name:      "custom_" + NamespaceAdmin.ID,
+				Name:      "custom_" + NamespaceAdmin.Name,
 			},
 			Weight:   1000,
 			Criteria: &Criteria{RequireAll: []string{"role == 'custom'"}},
 			Actions: &ACLRuleActions{
-				AddRole: map[string]string{NamespaceAdmin.ID: "test"},
+				AddRole: map[string]string{NamespaceAdmin.Name: "test"},
 			},
 		},
 	}
@@ -127,7 +127,7 @@ func makeEmptyPolicyWithACL() *Policy {
 			Weight:   100,
 			Criteria: &Criteria{RequireAll: []string{"is_domain_admin"}},
 			Actions: &ACLRuleActions{
-				AddRole: map[string]string{DomainAdmin.ID: namespaceAll},
+				AddRole: map[string]string{DomainAdmin.Name: namespaceAll},
 			},
 		},
 		// namespace admins for 'main' namespace
@@ -140,7 +140,7 @@ func makeEmptyPolicyWithACL() *Policy {
 			Weight:   200,
 			Criteria: &Criteria{RequireAll: []string{"is_namespace_admin"}},
 			Actions: &ACLRuleActions{
-				AddRole: map[string]string{NamespaceAdmin.ID: "main"},
+				AddRole: map[string]string{NamespaceAdmin.Name: "main"},
 			},
 		},
 		// service consumers for 'main' namespace
@@ -153,7 +153,7 @@ func makeEmptyPolicyWithACL() *Policy {
 			Weight:   300,
 			Criteria: &Criteria{RequireAll: []string{"is_consumer"}},
 			Actions: &ACLRuleActions{
-				AddRole: map[string]string{ServiceConsumer.ID: "main"},
+				AddRole: map[string]string{ServiceConsumer.Name: "main"},
 			},
 		},
 	}
@@ -166,3 +166,65 @@ func makeEmptyPolicyWithACL() *Policy {
 	}
 	return policy
 }
+
+func TestPolicyViewCustomRoleWithSelector(t *testing.T) {
+	policy := makeEmptyPolicyWithACL()
+
+	// custom role, which allows to manage claims of team 'x' only
+	role := &ACLRole{
+		TypeKind: TypeACLRole.GetTypeKind(),
+		Metadata: Metadata{
+			Namespace: runtime.SystemNS,
+			Name:      "team-x-claims",
+		},
+		Privileges: &Privileges{
+			NamespaceObjects: map[string]*Privilege{
+				TypeClaim.Kind: {
+					Verbs:    []string{ACLVerbView, ACLVerbCreate, ACLVerbUpdate, ACLVerbDelete},
+					Selector: &Criteria{RequireAll: []string{"team == 'x'"}},
+				},
+			},
+		},
+	}
+	rule := &ACLRule{
+		TypeKind: TypeACLRule.GetTypeKind(),
+		Metadata: Metadata{
+			Namespace: runtime.SystemNS,
+			Name:      "is_team_x",
+		},
+		Weight:   400,
+		Criteria: &Criteria{RequireAll: []string{"team == 'x'"}},
+		Actions: &ACLRuleActions{
+			AddRole: map[string]string{role.Name: "main"},
+		},
+	}
+	for _, obj := range []Base{role, rule} {
+		assert.NoError(t, policy.AddObject(obj), "Policy.AddObject() should work correctly")
+	}
+	assert.NoError(t, policy.Validate(), "Policy with custom ACL role should be valid")
+
+	makeClaim := func(name string, namespace string, team string) *Claim {
+		return &Claim{
+			TypeKind: TypeClaim.GetTypeKind(),
+			Metadata: Metadata{Namespace: namespace, Name: name},
+			User:     "1",
+			Service:  "service",
+			Labels:   map[string]string{"team": team},
+		}
+	}
+
+	view := policy.View(&User{Name: "1", Labels: map[string]string{"team": "x"}})
+	assert.NoError(t, view.ManageObject(makeClaim("claim-x", "main", "x")), "User should be able to create claims of team x")
+	assert.Error(t, view.ManageObject(makeClaim("claim-y", "main", "y")), "User should not be able to create claims of other teams")
+	assert.Error(t, view.ManageObject(makeClaim("claim-x", "other", "x")), "User should not be able to create claims in other namespaces")
+
+	// claim can't be moved out of the scope of role selector
+	assert.NoError(t, view.AddObject(makeClaim("claim-x", "main", "x")), "User should be able to add claims of team x")
+	assert.Error(t, view.ManageObject(makeClaim("claim-x", "main", "y")), "User should not be able to change team of a claim")
+	assert.NoError(t, view.DeleteObject(makeClaim("claim-x", "main", "x")), "User should be able to delete claims of team x")
+
+	// role doesn't grant other verbs
+	service := &Service{TypeKind: TypeService.GetTypeKind(), Metadata: Metadata{Namespace: "main", Name: "service"}}
+	assert.Error(t, view.ManageObject(service), "User should not be able to manage services")
+	assert.Error(t, view.ApproveObject(service), "User should not be able to approve changes")
+}
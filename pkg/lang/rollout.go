@@ -0,0 +1,95 @@
+package lang
+
+import (
+	"time"
+)
+
+const (
+	// RolloutStrategyCanary updates a single component instance first, waits for it to become ready and only then
+	// proceeds with the rest of instances (in batches, if batch percentage is set)
+	RolloutStrategyCanary = "canary"
+
+	// RolloutStrategyBatch updates component instances in batches, each batch containing a given percentage of
+	// instances. Next batch starts only when all instances in the previous batch have been updated and became ready
+	RolloutStrategyBatch = "batch"
+)
+
+const (
+	// DefaultRolloutReadyTimeout is the default amount of time to wait for an updated component instance to become ready
+	DefaultRolloutReadyTimeout = 5 * time.Minute
+
+	// DefaultRolloutReadyInterval is the default interval between component instance readiness checks
+	DefaultRolloutReadyInterval = 5 * time.Second
+)
+
+// Rollout defines how updates of code components get rolled out across their instances (e.g. instances of the
+// same component running in different clusters). It can be set on a bundle or on a service. If set on both,
+// the one defined on the service takes precedence.
+//
+// Rollout is applied only to updates of existing component instances. If an instance fails to update or doesn't
+// become ready in time, the rollout halts and the rest of instances will not be updated.
+type Rollout struct {
+	// Strategy is a rollout strategy (canary or batch)
+	Strategy string `validate:"required,rolloutStrategy"`
+
+	// BatchPercent is the percentage of component instances which get updated in a single batch. For canary strategy
+	// it applies to the instances which get updated after the canary. If not set, canary strategy will update all
+	// remaining instances at once, while batch strategy will update instances one by one
+	BatchPercent int `yaml:"batch-percent,omitempty" validate:"omitempty,min=1,max=100"`
+
+	// ReadyTimeout is how long to wait for an updated component instance to become ready
+	ReadyTimeout time.Duration `yaml:"ready-timeout,omitempty" validate:"omitempty,min=0"`
+
+	// ReadyInterval is how often to check whether an updated component instance became ready
+	ReadyInterval time.Duration `yaml:"ready-interval,omitempty" validate:"omitempty,min=0"`
+}
+
+// GetReadyTimeout returns the amount of time to wait for an updated component instance to become ready
+func (rollout *Rollout) GetReadyTimeout() time.Duration {
+	if rollout.ReadyTimeout <= 0 {
+		return DefaultRolloutReadyTimeout
+	}
+	return rollout.ReadyTimeout
+}
+
+// GetReadyInterval returns the interval between component instance readiness checks
+func (rollout *Rollout) GetReadyInterval() time.Duration {
+	if rollout.ReadyInterval <= 0 {
+		return DefaultRolloutReadyInterval
+	}
+	return rollout.ReadyInterval
+}
+
+// GetBatchSizes splits a given number of component instances into batches according to the rollout strategy and
+// returns the number of instances in each batch
+func (rollout *Rollout) GetBatchSizes(instances int) []int {
+	result := []int{}
+	if instances <= 0 {
+		return result
+	}
+
+	// canary goes first in its own batch
+	remaining := instances
+	if rollout.Strategy == RolloutStrategyCanary {
+		result = append(result, 1)
+		remaining--
+	}
+
+	// calculate batch size for the remaining instances
+	batchSize := 1
+	if rollout.BatchPercent > 0 {
+		batchSize = (instances*rollout.BatchPercent + 99) / 100
+	} else if rollout.Strategy == RolloutStrategyCanary {
+		batchSize = remaining
+	}
+
+	for remaining > 0 {
+		size := batchSize
+		if size > remaining {
+			size = remaining
+		}
+		result = append(result, size)
+		remaining -= size
+	}
+	return result
+}
@@ -0,0 +1,33 @@
+package lang
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRolloutBatchSizes(t *testing.T) {
+	tests := []struct {
+		rollout   *Rollout
+		instances int
+		expected  []int
+	}{
+		{&Rollout{Strategy: RolloutStrategyCanary}, 0, []int{}},
+		{&Rollout{Strategy: RolloutStrategyCanary}, 1, []int{1}},
+		{&Rollout{Strategy: RolloutStrategyCanary}, 5, []int{1, 4}},
+		{&Rollout{Strategy: RolloutStrategyCanary, BatchPercent: 50}, 5, []int{1, 3, 1}},
+		{&Rollout{Strategy: RolloutStrategyBatch}, 3, []int{1, 1, 1}},
+		{&Rollout{Strategy: RolloutStrategyBatch, BatchPercent: 25}, 10, []int{3, 3, 3, 1}},
+		{&Rollout{Strategy: RolloutStrategyBatch, BatchPercent: 100}, 4, []int{4}},
+		{&Rollout{Strategy: RolloutStrategyBatch, BatchPercent: 1}, 3, []int{1, 1, 1}},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, test.rollout.GetBatchSizes(test.instances), "Batch sizes for %d instance(s), rollout: %+v", test.instances, test.rollout)
+	}
+}
+
+func TestRolloutDefaults(t *testing.T) {
+	rollout := &Rollout{Strategy: RolloutStrategyCanary}
+	assert.Equal(t, DefaultRolloutReadyTimeout, rollout.GetReadyTimeout())
+	assert.Equal(t, DefaultRolloutReadyInterval, rollout.GetReadyInterval())
+}
@@ -67,161 +67,3 @@ func GetACLRulesSortedByWeight(rules map[string]*ACLRule) []*ACLRule {
 	sort.Sort(aclRuleSorter(result))
 	return result
 }
-
-// ACLRole is a struct for defining user roles and their privileges.
-// Aptomi has 4 built-in user roles: domain admin, namespace admin, service consumer, and nobody.
-// Domain admin has full access rights to all namespaces. It can manage global objects in 'system' namespace (clusters,
-// rules, and ACL rules).
-// Namespace admin has full access right to a given set of namespaces, but it cannot global objects in 'system' namespace (clusters,
-// rules, and ACL rules).
-// Service consumer can only consume services within a given set of namespaces. Service consumption is treated as capability
-// to instantiate services in a given namespace.
-// Nobody cannot do anything except viewing the policy.
-type ACLRole struct {
-	ID         string
-	Name       string
-	Privileges *Privileges
-}
-
-// Privileges defines a set of privileges for a particular role in Aptomi
-type Privileges struct {
-	// AllNamespaces, when set to true, indicated that user privileges apply to all namespaces. Otherwise it applies
-	// to a set of given namespaces
-	AllNamespaces bool
-
-	// NamespaceObjects specifies whether or not this role can view/manage a certain object kind within a non-system namespace
-	NamespaceObjects map[string]*Privilege
-
-	// GlobalObjects specifies whether or not this role can view/manage a certain object kind within a system namespace
-	GlobalObjects map[string]*Privilege
-}
-
-// Returns privileges for a given object
-func (privileges *Privileges) getObjectPrivileges(obj Base) *Privilege {
-	var result *Privilege
-	if obj.GetNamespace() == runtime.SystemNS {
-		result = privileges.GlobalObjects[obj.GetKind()]
-	} else {
-		result = privileges.NamespaceObjects[obj.GetKind()]
-	}
-	if result == nil {
-		return noAccess
-	}
-	return result
-}
-
-// Privilege is a unit of privilege for any single given object
-type Privilege struct {
-	// View indicates whether or not a user can view an object (R)
-	View bool
-
-	// Manage indicates whether or not a user can manage an object, i.e. perform operations (CUD)
-	Manage bool
-}
-
-// Full access privilege
-var fullAccess = &Privilege{
-	View:   true,
-	Manage: true,
-}
-
-// View access privilege
-var viewAccess = &Privilege{
-	View: true,
-}
-
-// No access privilege
-var noAccess = &Privilege{}
-
-// DomainAdmin is a built-in domain admin role
-var DomainAdmin = &ACLRole{
-	ID:   "domain-admin",
-	Name: "Domain Admin",
-	Privileges: &Privileges{
-		AllNamespaces: true,
-		NamespaceObjects: map[string]*Privilege{
-			TypeBundle.Kind:  fullAccess,
-			TypeService.Kind: fullAccess,
-			TypeClaim.Kind:   fullAccess,
-			TypeRule.Kind:    fullAccess,
-		},
-		GlobalObjects: map[string]*Privilege{
-			TypeCluster.Kind: fullAccess,
-			TypeRule.Kind:    fullAccess,
-			TypeACLRule.Kind: fullAccess,
-		},
-	},
-}
-
-// NamespaceAdmin is a built-in admin role
-var NamespaceAdmin = &ACLRole{
-	ID:   "namespace-admin",
-	Name: "Namespace Admin",
-	Privileges: &Privileges{
-		NamespaceObjects: map[string]*Privilege{
-			TypeBundle.Kind:  fullAccess,
-			TypeService.Kind: fullAccess,
-			TypeClaim.Kind:   fullAccess,
-			TypeRule.Kind:    fullAccess,
-		},
-		GlobalObjects: map[string]*Privilege{
-			TypeCluster.Kind: viewAccess,
-			TypeRule.Kind:    viewAccess,
-			TypeACLRule.Kind: viewAccess,
-		},
-	},
-}
-
-// ServiceConsumer is a built-in service consumer role
-var ServiceConsumer = &ACLRole{
-	ID:   "service-consumer",
-	Name: "Service Consumer",
-	Privileges: &Privileges{
-		NamespaceObjects: map[string]*Privilege{
-			TypeBundle.Kind:  viewAccess,
-			TypeService.Kind: viewAccess,
-			TypeClaim.Kind:   fullAccess,
-			TypeRule.Kind:    viewAccess,
-		},
-		GlobalObjects: map[string]*Privilege{
-			TypeCluster.Kind: viewAccess,
-			TypeRule.Kind:    viewAccess,
-			TypeACLRule.Kind: viewAccess,
-		},
-	},
-}
-
-// Nobody role
-var nobody = &ACLRole{
-	ID:   "nobody",
-	Name: "Nobody",
-	Privileges: &Privileges{
-		NamespaceObjects: map[string]*Privilege{
-			TypeBundle.Kind:  viewAccess,
-			TypeService.Kind: viewAccess,
-			TypeClaim.Kind:   viewAccess,
-			TypeRule.Kind:    viewAccess,
-		},
-		GlobalObjects: map[string]*Privilege{
-			TypeCluster.Kind: viewAccess,
-			TypeRule.Kind:    viewAccess,
-			TypeACLRule.Kind: viewAccess,
-		},
-	},
-}
-
-// ACLRolesOrderedList represents the ordered list of ACL roles (from most "powerful" to least "powerful")
-var ACLRolesOrderedList = []*ACLRole{
-	DomainAdmin,
-	NamespaceAdmin,
-	ServiceConsumer,
-	nobody,
-}
-
-// ACLRolesMap represents the map of ACL roles (Role ID -> Role)
-var ACLRolesMap = map[string]*ACLRole{
-	DomainAdmin.ID:     DomainAdmin,
-	NamespaceAdmin.ID:  NamespaceAdmin,
-	ServiceConsumer.ID: ServiceConsumer,
-	nobody.ID:          nobody,
-}
@@ -6,14 +6,15 @@ import (
 
 // ACLRuleActions is a set of actions that can be performed by a ACL rule, assigning permissions to access namespaces
 type ACLRuleActions struct {
-	// AddRole is a map with role ID as key, while value is a set of comma-separated namespaces to which this role applies
+	// AddRole is a map with role name as key (either built-in role or user-defined ACLRole), while value is a set of
+	// comma-separated namespaces to which this role applies
 	AddRole map[string]string `yaml:"add-role,omitempty" validate:"omitempty,addRoleNS"`
 }
 
-// ApplyActions applies rule actions and updates result
-func (rule *ACLRule) ApplyActions(roleMap map[string]map[string]bool) {
+// ApplyActions applies rule actions and updates result, given all available roles
+func (rule *ACLRule) ApplyActions(roleMap map[string]map[string]bool, roles map[string]*ACLRole) {
 	for roleID, namespaceList := range rule.Actions.AddRole {
-		role := ACLRolesMap[roleID]
+		role := roles[roleID]
 		if role == nil {
 			// skip non-existing roles
 			continue
@@ -32,7 +33,7 @@ func (rule *ACLRule) ApplyActions(roleMap map[string]map[string]bool) {
 		}
 
 		// if role covers all namespaces, mark it as well
-		if role.Privileges.AllNamespaces {
+		if role.Privileges != nil && role.Privileges.AllNamespaces {
 			nsMap[namespaceAll] = true
 		}
 	}
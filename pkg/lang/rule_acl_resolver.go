@@ -11,42 +11,71 @@ import (
 // objects they access
 type ACLResolver struct {
 	aclRules     []*ACLRule
+	aclRoles     map[string]*ACLRole
 	cache        *expression.Cache
 	roleMapCache sync.Map
 }
 
-// NewACLResolver creates a new ACLResolver
-func NewACLResolver(aclRules map[string]*ACLRule) *ACLResolver {
+// NewACLResolver creates a new ACLResolver, given ACL rules and user-defined ACL roles. Built-in roles are always
+// available and can't be overridden by user-defined ones
+func NewACLResolver(aclRules map[string]*ACLRule, aclRoles map[string]*ACLRole) *ACLResolver {
+	roles := make(map[string]*ACLRole)
+	for name, role := range aclRoles {
+		roles[name] = role
+	}
+	for name, role := range ACLRolesMap {
+		roles[name] = role
+	}
+
 	return &ACLResolver{
 		aclRules:     GetACLRulesSortedByWeight(aclRules),
+		aclRoles:     roles,
 		cache:        expression.NewCache(),
 		roleMapCache: sync.Map{},
 	}
 }
 
-// GetUserPrivileges is a main method which determines privileges that a given user has for a given object
+// GetUserPrivileges is a main method which determines privileges that a given user has for a given object. User gets
+// all verbs granted by the roles, which apply to the object namespace and whose selectors match object labels
 func (resolver *ACLResolver) GetUserPrivileges(user *User, obj Base) (*Privilege, error) {
 	roleMap, err := resolver.GetUserRoleMap(user)
 	if err != nil {
 		return nil, err
 	}
 
-	// figure out which role's privileges apply
-	for _, role := range ACLRolesOrderedList {
-		namespaceSpan := roleMap[role.ID]
-		if namespaceSpan[namespaceAll] || namespaceSpan[obj.GetNamespace()] {
-			return role.Privileges.getObjectPrivileges(obj), nil
+	// figure out which roles apply. everyone gets privileges of the 'nobody' role
+	roles := []*ACLRole{nobody}
+	for roleName, namespaceSpan := range roleMap {
+		role := resolver.aclRoles[roleName]
+		if role != nil && (namespaceSpan[namespaceAll] || namespaceSpan[obj.GetNamespace()]) {
+			roles = append(roles, role)
+		}
+	}
+
+	// combine verbs from all applicable roles
+	verbs := make(map[string]bool)
+	for _, role := range roles {
+		privilege := role.Privileges.getObjectPrivileges(obj)
+		applies, errSelector := privilege.appliesTo(obj, resolver.cache)
+		if errSelector != nil {
+			return nil, fmt.Errorf("unable to evaluate selector of role '%s' for object '%s/%s/%s': %s", role.Name, obj.GetNamespace(), obj.GetKind(), obj.GetName(), errSelector)
+		}
+		if applies {
+			for _, verb := range privilege.Verbs {
+				verbs[verb] = true
+			}
 		}
 	}
 
-	return nobody.Privileges.getObjectPrivileges(obj), nil
+	return newPrivilege(verbs), nil
 }
 
-// GetUserRoleMap returns the map role ID -> to which namespaces this role applies, for a given user.
+// GetUserRoleMap returns the map role name -> to which namespaces this role applies, for a given user.
 // Note that user may have multiple roles at the same time. E.g.
 // - domain admin (i.e. for all namespaces within Aptomi domain)
 // - namespace admin for a set of given namespaces
 // - service consumer for a set of given namespaces
+// - user-defined roles for a set of given namespaces
 func (resolver *ACLResolver) GetUserRoleMap(user *User) (map[string]map[string]bool, error) {
 	roleMapCached, ok := resolver.roleMapCache.Load(user.Name)
 	if ok {
@@ -56,18 +85,18 @@ func (resolver *ACLResolver) GetUserRoleMap(user *User) (map[string]map[string]b
 	roleMap := make(map[string]map[string]bool)
 	if user.DomainAdmin {
 		// this user is explicitly specified as domain admin
-		roleMap[DomainAdmin.ID] = make(map[string]bool)
-		roleMap[DomainAdmin.ID][namespaceAll] = true
+		roleMap[DomainAdmin.Name] = make(map[string]bool)
+		roleMap[DomainAdmin.Name][namespaceAll] = true
 	} else {
 		// we need to run this user through ACL list
-		params := expression.NewParams(user.Labels, nil)
+		params := expression.NewParams(user.Labels, map[string]interface{}{UserGroupsParam: user.GroupsParam()})
 		for _, rule := range resolver.aclRules {
 			matched, err := rule.Matches(params, resolver.cache)
 			if err != nil {
 				return nil, fmt.Errorf("unable to resolve role for user '%s': %s", user.Name, err)
 			}
 			if matched {
-				rule.ApplyActions(roleMap)
+				rule.ApplyActions(roleMap, resolver.aclRoles)
 			}
 		}
 	}
@@ -37,13 +37,13 @@ func runACLTests(testCases []aclTestCase, rules []*ACLRule, t *testing.T) {
 	for _, rule := range rules {
 		aclRules[rule.GetName()] = rule
 	}
-	resolver := NewACLResolver(aclRules)
+	resolver := NewACLResolver(aclRules, nil)
 	for _, tc := range testCases {
 		roleMap, err := resolver.GetUserRoleMap(tc.user)
 		if !assert.NoError(t, err, "User role map should be retrieved successfully") {
 			continue
 		}
-		if !assert.Equal(t, tc.expected, roleMap[tc.role.ID][tc.namespace], "User role map should be correct") {
+		if !assert.Equal(t, tc.expected, roleMap[tc.role.Name][tc.namespace], "User role map should be correct") {
 			tc.print(t)
 		}
 
@@ -71,7 +71,7 @@ func TestAclResolver(t *testing.T) {
 			Weight:   100,
 			Criteria: &Criteria{RequireAll: []string{"is_domain_admin"}},
 			Actions: &ACLRuleActions{
-				AddRole: map[string]string{DomainAdmin.ID: namespaceAll},
+				AddRole: map[string]string{DomainAdmin.Name: namespaceAll},
 			},
 		},
 		// namespace admins for 'main' namespace
@@ -84,7 +84,7 @@ func TestAclResolver(t *testing.T) {
 			Weight:   200,
 			Criteria: &Criteria{RequireAll: []string{"is_namespace_admin"}},
 			Actions: &ACLRuleActions{
-				AddRole: map[string]string{NamespaceAdmin.ID: "main"},
+				AddRole: map[string]string{NamespaceAdmin.Name: "main"},
 			},
 		},
 		// service consumers for 'main2' namespace
@@ -97,7 +97,7 @@ func TestAclResolver(t *testing.T) {
 			Weight:   300,
 			Criteria: &Criteria{RequireAll: []string{"is_consumer"}},
 			Actions: &ACLRuleActions{
-				AddRole: map[string]string{ServiceConsumer.ID: "main1, main2 ,main3,main4"},
+				AddRole: map[string]string{ServiceConsumer.Name: "main1, main2 ,main3,main4"},
 			},
 		},
 		// bogus rule
@@ -178,3 +178,41 @@ func TestAclResolverAdminUser(t *testing.T) {
 	}
 	runACLTests(testCases, rules, t)
 }
+
+func TestAclResolverUserGroups(t *testing.T) {
+	var rules = []*ACLRule{
+		{
+			TypeKind: TypeACLRule.GetTypeKind(),
+			Metadata: Metadata{
+				Namespace: runtime.SystemNS,
+				Name:      "ops_group",
+			},
+			Weight:   100,
+			Criteria: &Criteria{RequireAll: []string{"inGroup(groups, 'ops')"}},
+			Actions: &ACLRuleActions{
+				AddRole: map[string]string{NamespaceAdmin.Name: "main"},
+			},
+		},
+	}
+	testCases := []aclTestCase{
+		{
+			user:      &User{Name: "1", Groups: []string{"developers", "Ops"}},
+			role:      NamespaceAdmin,
+			namespace: "main",
+			expected:  true,
+		},
+		{
+			user:      &User{Name: "2", Groups: []string{"developers"}},
+			role:      NamespaceAdmin,
+			namespace: "main",
+			expected:  false,
+		},
+		{
+			user:      &User{Name: "3"},
+			role:      NamespaceAdmin,
+			namespace: "main",
+			expected:  false,
+		},
+	}
+	runACLTests(testCases, rules, t)
+}
@@ -0,0 +1,265 @@
+package lang
+
+import (
+	"github.com/Aptomi/aptomi/pkg/lang/expression"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+)
+
+// ACL verbs, which can be granted for policy objects
+const (
+	// ACLVerbView allows to view an object
+	ACLVerbView = "view"
+
+	// ACLVerbCreate allows to create an object
+	ACLVerbCreate = "create"
+
+	// ACLVerbUpdate allows to update an existing object
+	ACLVerbUpdate = "update"
+
+	// ACLVerbDelete allows to delete an object
+	ACLVerbDelete = "delete"
+
+	// ACLVerbConsume allows to consume a service (i.e. to instantiate it by declaring a claim)
+	ACLVerbConsume = "consume"
+
+	// ACLVerbApprove allows to approve or reject revisions, which change a service in a given namespace
+	ACLVerbApprove = "approve"
+)
+
+// ACLVerbs is the list of all ACL verbs
+var ACLVerbs = []string{ACLVerbView, ACLVerbCreate, ACLVerbUpdate, ACLVerbDelete, ACLVerbConsume, ACLVerbApprove}
+
+// ACLRole is a struct for defining user roles and their privileges. Roles get assigned to users via ACL rules.
+// Aptomi has 4 built-in user roles: domain admin, namespace admin, service consumer, and nobody.
+// Domain admin has full access rights to all namespaces. It can manage global objects in 'system' namespace (clusters,
+// rules, ACL rules, ACL roles, and maintenance windows).
+// Namespace admin has full access right to a given set of namespaces, but it cannot global objects in 'system' namespace (clusters,
+// rules, ACL rules, ACL roles, and maintenance windows).
+// Service consumer can only consume services within a given set of namespaces. Service consumption is treated as capability
+// to instantiate services in a given namespace.
+// Nobody cannot do anything except viewing the policy.
+//
+// Additional roles can be defined by domain admins in 'system' namespace of the policy, granting a set of verbs
+// per object kind. Every user can view the whole policy regardless of roles, so roles grant additional verbs only
+type ACLRole struct {
+	runtime.TypeKind `yaml:",inline"`
+	Metadata         `validate:"required"`
+
+	// Description is a human-readable description of the role
+	Description string `yaml:",omitempty"`
+
+	// Privileges define which verbs the role allows for which object kinds
+	Privileges *Privileges `validate:"required"`
+}
+
+// TypeACLRole is an informational data structure with Kind and Constructor for ACLRole
+var TypeACLRole = &runtime.TypeInfo{
+	Kind:        "aclrole",
+	Storable:    true,
+	Versioned:   true,
+	Constructor: func() runtime.Object { return &ACLRole{} },
+}
+
+// Privileges defines a set of privileges for a particular role in Aptomi
+type Privileges struct {
+	// AllNamespaces, when set to true, indicated that user privileges apply to all namespaces. Otherwise it applies
+	// to a set of given namespaces
+	AllNamespaces bool `yaml:"all-namespaces,omitempty"`
+
+	// NamespaceObjects specifies what this role can do with a certain object kind within a non-system namespace
+	NamespaceObjects map[string]*Privilege `yaml:"namespace-objects,omitempty" validate:"dive"`
+
+	// GlobalObjects specifies what this role can do with a certain object kind within a system namespace
+	GlobalObjects map[string]*Privilege `yaml:"global-objects,omitempty" validate:"dive"`
+}
+
+// Returns privileges for a given object
+func (privileges *Privileges) getObjectPrivileges(obj Base) *Privilege {
+	if privileges == nil {
+		return noAccess
+	}
+	var result *Privilege
+	if obj.GetNamespace() == runtime.SystemNS {
+		result = privileges.GlobalObjects[obj.GetKind()]
+	} else {
+		result = privileges.NamespaceObjects[obj.GetKind()]
+	}
+	if result == nil {
+		return noAccess
+	}
+	return result
+}
+
+// Privilege is a unit of privilege for any single given object kind
+type Privilege struct {
+	// Verbs is the list of allowed verbs
+	Verbs []string `validate:"dive,aclVerb"`
+
+	// Selector, if set, restricts privilege to objects with labels matching it (e.g. only claims with team=x)
+	Selector *Criteria `yaml:",omitempty" validate:"omitempty"`
+}
+
+// Allows returns true if privilege allows a given verb
+func (privilege *Privilege) Allows(verb string) bool {
+	for _, allowed := range privilege.Verbs {
+		if allowed == verb {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns whether privilege applies to a given object, i.e. object labels match its selector
+func (privilege *Privilege) appliesTo(obj Base, cache *expression.Cache) (bool, error) {
+	if privilege.Selector == nil {
+		return true, nil
+	}
+	return privilege.Selector.allows(expression.NewParams(getObjectLabels(obj), nil), cache)
+}
+
+// Returns privilege, which allows a given set of verbs
+func newPrivilege(verbs map[string]bool) *Privilege {
+	result := &Privilege{}
+	for _, verb := range ACLVerbs {
+		if verbs[verb] {
+			result.Verbs = append(result.Verbs, verb)
+		}
+	}
+	return result
+}
+
+// Returns labels of a given object, which can be referred to from privilege selectors
+func getObjectLabels(obj Base) map[string]string {
+	switch o := obj.(type) {
+	case *Claim:
+		return o.Labels
+	case *Bundle:
+		return o.Labels
+	case *Cluster:
+		return o.Labels
+	}
+	return nil
+}
+
+// Full access privilege
+var fullAccess = &Privilege{
+	Verbs: ACLVerbs,
+}
+
+// View access privilege
+var viewAccess = &Privilege{
+	Verbs: []string{ACLVerbView},
+}
+
+// View and consume access privilege
+var consumeAccess = &Privilege{
+	Verbs: []string{ACLVerbView, ACLVerbConsume},
+}
+
+// No access privilege
+var noAccess = &Privilege{}
+
+// DomainAdmin is a built-in domain admin role
+var DomainAdmin = &ACLRole{
+	TypeKind:    TypeACLRole.GetTypeKind(),
+	Metadata:    Metadata{Namespace: runtime.SystemNS, Name: "domain-admin"},
+	Description: "Domain Admin",
+	Privileges: &Privileges{
+		AllNamespaces: true,
+		NamespaceObjects: map[string]*Privilege{
+			TypeBundle.Kind:  fullAccess,
+			TypeService.Kind: fullAccess,
+			TypeClaim.Kind:   fullAccess,
+			TypeRule.Kind:    fullAccess,
+		},
+		GlobalObjects: map[string]*Privilege{
+			TypeCluster.Kind:     fullAccess,
+			TypeRule.Kind:        fullAccess,
+			TypeACLRule.Kind:     fullAccess,
+			TypeACLRole.Kind:     fullAccess,
+			TypeMaintenance.Kind: fullAccess,
+		},
+	},
+}
+
+// NamespaceAdmin is a built-in admin role
+var NamespaceAdmin = &ACLRole{
+	TypeKind:    TypeACLRole.GetTypeKind(),
+	Metadata:    Metadata{Namespace: runtime.SystemNS, Name: "namespace-admin"},
+	Description: "Namespace Admin",
+	Privileges: &Privileges{
+		NamespaceObjects: map[string]*Privilege{
+			TypeBundle.Kind:  fullAccess,
+			TypeService.Kind: fullAccess,
+			TypeClaim.Kind:   fullAccess,
+			TypeRule.Kind:    fullAccess,
+		},
+		GlobalObjects: map[string]*Privilege{
+			TypeCluster.Kind:     viewAccess,
+			TypeRule.Kind:        viewAccess,
+			TypeACLRule.Kind:     viewAccess,
+			TypeACLRole.Kind:     viewAccess,
+			TypeMaintenance.Kind: viewAccess,
+		},
+	},
+}
+
+// ServiceConsumer is a built-in service consumer role
+var ServiceConsumer = &ACLRole{
+	TypeKind:    TypeACLRole.GetTypeKind(),
+	Metadata:    Metadata{Namespace: runtime.SystemNS, Name: "service-consumer"},
+	Description: "Service Consumer",
+	Privileges: &Privileges{
+		NamespaceObjects: map[string]*Privilege{
+			TypeBundle.Kind:  viewAccess,
+			TypeService.Kind: consumeAccess,
+			TypeClaim.Kind:   fullAccess,
+			TypeRule.Kind:    viewAccess,
+		},
+		GlobalObjects: map[string]*Privilege{
+			TypeCluster.Kind:     viewAccess,
+			TypeRule.Kind:        viewAccess,
+			TypeACLRule.Kind:     viewAccess,
+			TypeACLRole.Kind:     viewAccess,
+			TypeMaintenance.Kind: viewAccess,
+		},
+	},
+}
+
+// Nobody role
+var nobody = &ACLRole{
+	TypeKind:    TypeACLRole.GetTypeKind(),
+	Metadata:    Metadata{Namespace: runtime.SystemNS, Name: "nobody"},
+	Description: "Nobody",
+	Privileges: &Privileges{
+		NamespaceObjects: map[string]*Privilege{
+			TypeBundle.Kind:  viewAccess,
+			TypeService.Kind: viewAccess,
+			TypeClaim.Kind:   viewAccess,
+			TypeRule.Kind:    viewAccess,
+		},
+		GlobalObjects: map[string]*Privilege{
+			TypeCluster.Kind:     viewAccess,
+			TypeRule.Kind:        viewAccess,
+			TypeACLRule.Kind:     viewAccess,
+			TypeACLRole.Kind:     viewAccess,
+			TypeMaintenance.Kind: viewAccess,
+		},
+	},
+}
+
+// ACLRolesOrderedList represents the ordered list of built-in ACL roles (from most "powerful" to least "powerful")
+var ACLRolesOrderedList = []*ACLRole{
+	DomainAdmin,
+	NamespaceAdmin,
+	ServiceConsumer,
+	nobody,
+}
+
+// ACLRolesMap represents the map of built-in ACL roles (Role name -> Role)
+var ACLRolesMap = map[string]*ACLRole{
+	DomainAdmin.Name:     DomainAdmin,
+	NamespaceAdmin.Name:  NamespaceAdmin,
+	ServiceConsumer.Name: ServiceConsumer,
+	nobody.Name:          nobody,
+}
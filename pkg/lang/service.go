@@ -30,6 +30,10 @@ type Service struct {
 	// Contexts contains an ordered list of contexts within a service. When allocating an instance, Aptomi will pick
 	// and instantiate the first context which matches the criteria
 	Contexts []*Context `validate:"dive"`
+
+	// Rollout is an optional strategy of rolling out updates of code components across their instances. If set, it
+	// overrides rollout strategies defined in all bundles this service gets fulfilled with
+	Rollout *Rollout `yaml:"rollout,omitempty" validate:"omitempty"`
 }
 
 // Context represents a single context within a service.
@@ -66,6 +70,12 @@ type Allocation struct {
 	// resolved into a user's team name. And, since users from different teams will have different keys, every team
 	// will get their own bundle instance from Aptomi
 	Keys []string `yaml:"keys,omitempty" validate:"dive,template"`
+
+	// CreateBeforeDestroy defines how bundle instances get replaced when allocation keys change. By default, old
+	// instance gets deleted and the new one gets created in an arbitrary order. If it's set to true, the new instance
+	// will be created first and verified to be ready, then claims will be moved over to it, and only then the old
+	// instance will be deleted
+	CreateBeforeDestroy bool `yaml:"create-before-destroy,omitempty"`
 }
 
 // Matches checks if context criteria is satisfied
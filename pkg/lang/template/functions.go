@@ -0,0 +1,153 @@
+package template
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	t "text/template"
+
+	"github.com/Masterminds/sprig"
+	"gopkg.in/yaml.v2"
+)
+
+// nonHermeticFunctions is a list of sprig functions, which are not available in Aptomi templates. They either
+// expose server environment or may return different results for the same input, which would result in components
+// being endlessly updated on every policy resolution
+var nonHermeticFunctions = []string{
+	"date",
+	"date_in_zone",
+	"date_modify",
+	"now",
+	"htmlDate",
+	"htmlDateInZone",
+	"dateInZone",
+	"dateModify",
+	"ago",
+	"randAlphaNum",
+	"randAlpha",
+	"randAscii",
+	"randNumeric",
+	"shuffle",
+	"uuidv4",
+	"genPrivateKey",
+	"derivePassword",
+	"buildCustomCert",
+	"genCA",
+	"genSelfSignedCert",
+	"genSignedCert",
+	"env",
+	"expandenv",
+}
+
+// makeTextFuncMap returns a set of functions available in text templates. It includes string, list, dict, encoding
+// and other functions from sprig library (https://masterminds.github.io/sprig/), as well as Aptomi-specific functions
+func makeTextFuncMap() t.FuncMap {
+	result := sprig.TxtFuncMap()
+	for _, name := range nonHermeticFunctions {
+		delete(result, name)
+	}
+
+	result["default"] = defaultFunc
+	result["required"] = requiredFunc
+	result["toYaml"] = toYamlFunc
+	result["toJson"] = toJSONFunc
+	result["sha256sum"] = sha256sumFunc
+	result["sortAlpha"] = sortAlphaFunc
+
+	return result
+}
+
+// defaultFunc returns the first argument if the second one is empty. If called with a single argument, it
+// returns an empty string if it's not defined
+func defaultFunc(args ...interface{}) interface{} {
+	if len(args) == 0 || len(args) > 2 {
+		// will fail text template execution
+		return nil
+	}
+
+	// if one argument, return it
+	if len(args) == 1 {
+		value := args[0]
+		if value == nil {
+			return ""
+		}
+		return value
+	}
+
+	// otherwise first argument is default value and the second is actual value
+	arg := args[0]
+	value := args[1]
+	if isEmpty(value) {
+		return arg
+	}
+	return value
+}
+
+// requiredFunc fails template evaluation with a given message if value is empty
+func requiredFunc(message string, value interface{}) (interface{}, error) {
+	if isEmpty(value) {
+		return nil, fmt.Errorf("%s", message)
+	}
+	return value, nil
+}
+
+// toYamlFunc serializes value into YAML
+func toYamlFunc(value interface{}) (string, error) {
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("unable to serialize value to yaml: %s", err)
+	}
+	return strings.TrimSuffix(string(data), "\n"), nil
+}
+
+// toJSONFunc serializes value into JSON
+func toJSONFunc(value interface{}) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("unable to serialize value to json: %s", err)
+	}
+	return string(data), nil
+}
+
+// sha256sumFunc returns hex-encoded SHA256 hash of a given string
+func sha256sumFunc(value string) string {
+	hash := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(hash[:])
+}
+
+// sortAlphaFunc returns a sorted copy of a given list. Unlike sprig version, it doesn't modify the original list,
+// which may be shared between template evaluations
+func sortAlphaFunc(list interface{}) []string {
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return []string{fmt.Sprintf("%v", list)}
+	}
+	result := make([]string, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		result[i] = fmt.Sprintf("%v", v.Index(i).Interface())
+	}
+	sort.Strings(result)
+	return result
+}
+
+// isEmpty returns true if value is nil, an empty string/slice/map or false
+func isEmpty(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
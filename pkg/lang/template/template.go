@@ -3,9 +3,9 @@ package template
 import (
 	"bytes"
 	"fmt"
-	"reflect"
 	"strings"
 	t "text/template"
+	"text/template/parse"
 
 	"github.com/Aptomi/aptomi/pkg/errors"
 )
@@ -17,43 +17,7 @@ type Template struct {
 }
 
 // Custom functions
-var textFuncMap = t.FuncMap{
-	"default": func(args ...interface{}) interface{} {
-		if len(args) == 0 || len(args) > 2 {
-			// will fail text template execution
-			return nil
-		}
-
-		// if one argument, return it
-		if len(args) == 1 {
-			value := args[0]
-			if value == nil {
-				return ""
-			}
-			return value
-		}
-
-		// otherwise first argument is default value and the second is actual value
-		arg := args[0]
-		value := args[1]
-		if value == nil {
-			return arg
-		}
-
-		v := reflect.ValueOf(value)
-		switch v.Kind() {
-		case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
-			if v.Len() == 0 {
-				return arg
-			}
-		case reflect.Bool:
-			if !v.Bool() {
-				return arg
-			}
-		}
-		return value
-	},
-}
+var textFuncMap = makeTextFuncMap()
 
 // NewTemplate compiles a text template and returns the result in Template struct
 // Parameter templateStr must follow syntax defined by text/template
@@ -68,6 +32,63 @@ func NewTemplate(templateStr string) (*Template, error) {
 	}, nil
 }
 
+// References returns the list of fields referenced in the template relative to its root (e.g. "Labels.name"). Fields
+// referenced within range/with blocks are returned as they appear, since the scope there is not known statically
+func (template *Template) References() []string {
+	result := []string{}
+	if template.templateCompiled.Tree != nil {
+		collectReferences(template.templateCompiled.Tree.Root, &result)
+	}
+	return result
+}
+
+func collectReferences(node parse.Node, result *[]string) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			collectReferences(child, result)
+		}
+	case *parse.ActionNode:
+		collectReferences(n.Pipe, result)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			collectReferences(cmd, result)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			collectReferences(arg, result)
+		}
+	case *parse.FieldNode:
+		*result = append(*result, strings.Join(n.Ident, "."))
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			*result = append(*result, strings.Join(n.Ident[1:], "."))
+		}
+	case *parse.ChainNode:
+		collectReferences(n.Node, result)
+	case *parse.IfNode:
+		collectBranchReferences(&n.BranchNode, result)
+	case *parse.RangeNode:
+		collectBranchReferences(&n.BranchNode, result)
+	case *parse.WithNode:
+		collectBranchReferences(&n.BranchNode, result)
+	case *parse.TemplateNode:
+		collectReferences(n.Pipe, result)
+	}
+}
+
+func collectBranchReferences(n *parse.BranchNode, result *[]string) {
+	collectReferences(n.Pipe, result)
+	collectReferences(n.List, result)
+	collectReferences(n.ElseList, result)
+}
+
 // Evaluate evaluates a compiled text template given a set named parameters
 func (template *Template) Evaluate(params *Parameters) (string, error) {
 	// Evaluate
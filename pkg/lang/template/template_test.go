@@ -94,3 +94,91 @@ func TestTemplateEvaluation(t *testing.T) {
 	}
 
 }
+
+func TestTemplateFunctions(t *testing.T) {
+	params := NewParams(struct {
+		Labels interface{}
+		List   interface{}
+		Map    interface{}
+	}{
+		map[string]string{
+			"name":  "Demo Service",
+			"empty": "",
+		},
+		[]string{"b", "a", "c"},
+		map[string]interface{}{
+			"replicas": 2,
+			"image":    "nginx",
+		},
+	})
+
+	tests := []struct {
+		template       string
+		result         int
+		expectedString string
+	}{
+		// strings
+		{"{{ lower .Labels.name }}", ResSuccess, "demo service"},
+		{"{{ upper .Labels.name }}", ResSuccess, "DEMO SERVICE"},
+		{"{{ .Labels.name | replace \" \" \"-\" | lower }}", ResSuccess, "demo-service"},
+		{"{{ trunc 4 .Labels.name }}", ResSuccess, "Demo"},
+		{"{{ quote .Labels.name }}", ResSuccess, "\"Demo Service\""},
+		{"{{ indent 2 \"a\\nb\" }}", ResSuccess, "  a\n  b"},
+
+		// lists
+		{"{{ join \",\" .List }}", ResSuccess, "b,a,c"},
+		{"{{ .List | sortAlpha | join \",\" }}", ResSuccess, "a,b,c"},
+		{"{{ list 1 2 3 | last }}", ResSuccess, "3"},
+
+		// dicts
+		{"{{ index (dict \"a\" \"1\" \"b\" \"2\") \"b\" }}", ResSuccess, "2"},
+		{"{{ keys .Map | sortAlpha | join \",\" }}", ResSuccess, "image,replicas"},
+
+		// encoding
+		{"{{ b64enc \"aptomi\" }}", ResSuccess, "YXB0b21p"},
+		{"{{ b64dec \"YXB0b21p\" }}", ResSuccess, "aptomi"},
+		{"{{ sha256sum \"aptomi\" }}", ResSuccess, "a056c1cb017571c866c4bcda8ca03902bc24dc3ac032f4695319ab04cc6bf5fc"},
+		{"{{ toJson .Map }}", ResSuccess, "{\"image\":\"nginx\",\"replicas\":2}"},
+		{"{{ toYaml .Map }}", ResSuccess, "image: nginx\nreplicas: 2"},
+		{"{{ toYaml .Map | indent 2 }}", ResSuccess, "  image: nginx\n  replicas: 2"},
+
+		// required
+		{"{{ required \"name is required\" .Labels.name }}", ResSuccess, "Demo Service"},
+		{"{{ required \"empty is required\" .Labels.empty }}", ResEvalError, ""},
+		{"{{ required \"missing is required\" .Labels.missing }}", ResEvalError, ""},
+
+		// non-hermetic functions are not available
+		{"{{ env \"HOME\" }}", ResCompileError, ""},
+		{"{{ now }}", ResCompileError, ""},
+		{"{{ randAlpha 5 }}", ResCompileError, ""},
+	}
+
+	for _, test := range tests {
+		evaluate(t, test.template, test.result, test.expectedString, params)
+	}
+
+	cache := NewCache()
+	for _, test := range tests {
+		evaluateWithCache(t, test.template, test.result, test.expectedString, params, cache)
+	}
+}
+
+func TestTemplateReferences(t *testing.T) {
+	tests := []struct {
+		template   string
+		references []string
+	}{
+		{"plain text", []string{}},
+		{"{{ .Labels.name }}-{{ .User.Secrets.token | upper }}", []string{"Labels.name", "User.Secrets.token"}},
+		{"{{ if .Labels.a }}{{ .Labels.b }}{{ else }}{{ default \"x\" .Labels.c }}{{ end }}", []string{"Labels.a", "Labels.b", "Labels.c"}},
+		{"{{ range $k, $v := .Labels }}{{ $k }}={{ $.Discovery.instance }}{{ end }}", []string{"Labels", "Discovery.instance"}},
+		{"{{ toYaml .Labels }}", []string{"Labels"}},
+	}
+	for _, test := range tests {
+		tmpl, err := NewTemplate(test.template)
+		if !assert.NoError(t, err, "Template should compile: %s", test.template) {
+			continue
+		}
+		assert.Equal(t, test.references, tmpl.References(), "Template references: %s", test.template)
+	}
+}
@@ -1,5 +1,9 @@
 package lang
 
+// UserGroupsParam is the name of the parameter, under which user groups are exposed to expressions (e.g.
+// inGroup(groups, 'developers'))
+const UserGroupsParam = "groups"
+
 // User represents a user in Aptomi. It has a unique Name and a set of Labels,
 // Users can be retrieved from multiple sources (e.g. file, LDAP, AD, etc)
 type User struct {
@@ -12,12 +16,24 @@ type User struct {
 	// Labels is a set of 'name'->'value' string labels, attached to the user
 	Labels map[string]string
 
+	// Groups is a list of groups the user is a member of (e.g. resolved from LDAP, including nested groups)
+	Groups []string
+
 	// DomainAdmin is a special bool flag, which allows to mark certain users as domain admins. It's useful for Aptomi
 	// bootstrap process, when someone needs to upload ACL rules into Aptomi (but his role is not defined in ACL,
 	// because ACL list is empty when Aptomi is first installed)
 	DomainAdmin bool
 }
 
+// GroupsParam returns user groups in a form, which can be passed as a parameter to expressions
+func (user *User) GroupsParam() []interface{} {
+	result := make([]interface{}, len(user.Groups))
+	for i, group := range user.Groups {
+		result[i] = group
+	}
+	return result
+}
+
 // GlobalUsers contains the map of users by their name
 type GlobalUsers struct {
 	// Users is a map[name] -> *User
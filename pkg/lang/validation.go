@@ -6,11 +6,13 @@ import (
 	"reflect"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/Aptomi/aptomi/pkg/lang/expression"
 	"github.com/Aptomi/aptomi/pkg/lang/template"
 	"github.com/Aptomi/aptomi/pkg/runtime"
 	"github.com/Aptomi/aptomi/pkg/util"
+	"github.com/Aptomi/aptomi/pkg/util/cron"
 	english "github.com/go-playground/locales/en"
 	"github.com/go-playground/universal-translator"
 	"gopkg.in/go-playground/validator.v9"
@@ -24,6 +26,8 @@ var (
 	codeTypes       = []string{"helm", "raw"}
 	labelOpsKeys    = []string{"set", "remove"}
 	allowReject     = []string{"allow", "reject"}
+	rolloutStrategy = []string{RolloutStrategyCanary, RolloutStrategyBatch}
+	deletionPolicy  = []string{DeletionPolicyDelete, DeletionPolicyOrphan, DeletionPolicyProtect}
 )
 
 // Custom type for context key, so we don't have to use 'string' directly
@@ -72,11 +76,18 @@ func NewPolicyValidator(policy *Policy) *PolicyValidator {
 	result.RegisterValidationCtx("labelOperations", validateLabelOperations)     // nolint: errcheck
 	result.RegisterValidationCtx("allowReject", validateAllowRejectAction)       // nolint: errcheck
 	result.RegisterValidationCtx("addRoleNS", validateACLRoleActionMap)          // nolint: errcheck
+	result.RegisterValidationCtx("aclVerb", validateACLVerb)                     // nolint: errcheck
+	result.RegisterValidationCtx("rolloutStrategy", validateRolloutStrategy)     // nolint: errcheck
+	result.RegisterValidationCtx("deletionPolicy", validateDeletionPolicy)       // nolint: errcheck
+	result.RegisterValidationCtx("cron", validateCron)                           // nolint: errcheck
+	result.RegisterValidationCtx("timestamp", validateTimestamp)                 // nolint: errcheck
 
 	// validators with context containing policy
 	result.RegisterStructValidation(validateRule, Rule{})
 	result.RegisterStructValidation(validateACLRule, ACLRule{})
+	result.RegisterStructValidation(validateACLRole, ACLRole{})
 	result.RegisterStructValidation(validateCluster, Cluster{})
+	result.RegisterStructValidation(validateMaintenance, Maintenance{})
 	result.RegisterStructValidationCtx(validateBundle, Bundle{})
 	result.RegisterStructValidationCtx(validateClaim, Claim{})
 	result.RegisterStructValidationCtx(validateService, Service{})
@@ -111,6 +122,30 @@ func NewPolicyValidator(policy *Policy) *PolicyValidator {
 			tag:         "allowReject",
 			translation: fmt.Sprintf("'{0}' is not valid, must be in %s", allowReject),
 		},
+		{
+			tag:         "rolloutStrategy",
+			translation: fmt.Sprintf("'{0}' is not valid, must be in %s", rolloutStrategy),
+		},
+		{
+			tag:         "deletionPolicy",
+			translation: fmt.Sprintf("'{0}' is not valid, must be in %s", deletionPolicy),
+		},
+		{
+			tag:         "cron",
+			translation: fmt.Sprintf("'{0}' is not a valid cron expression (minute, hour, day of month, month, day of week)"),
+		},
+		{
+			tag:         "timestamp",
+			translation: fmt.Sprintf("'{0}' is not a valid timestamp, must be in RFC3339 format (e.g. 2006-01-02T15:04:05Z)"),
+		},
+		{
+			tag:         "freezePeriod",
+			translation: fmt.Sprintf("'{0}' is not valid, freeze must end after it starts"),
+		},
+		{
+			tag:         "maintenanceActions",
+			translation: fmt.Sprintf("is a required field (at least one window or freeze must be specified)"),
+		},
 		{
 			tag:         "systemNS",
 			translation: fmt.Sprintf("'{0}' is not valid, must always be '%s'", runtime.SystemNS),
@@ -141,7 +176,19 @@ func NewPolicyValidator(policy *Policy) *PolicyValidator {
 		},
 		{
 			tag:         "addRoleNS",
-			translation: fmt.Sprintf("is not a valid role assignment map (key must be in %s, namespace list must be comma-separated identifiers/wildcards)", util.GetSortedStringKeys(ACLRolesMap)),
+			translation: fmt.Sprintf("is not a valid role assignment map (key must be in %s or a name of ACL role defined in '%s' namespace, namespace list must be comma-separated identifiers/wildcards)", util.GetSortedStringKeys(ACLRolesMap), runtime.SystemNS),
+		},
+		{
+			tag:         "aclVerb",
+			translation: fmt.Sprintf("'{0}' is not a valid ACL verb (must be in %s)", ACLVerbs),
+		},
+		{
+			tag:         "aclRoleBuiltin",
+			translation: fmt.Sprintf("'{0}' is not valid, it clashes with built-in ACL role (%s)", util.GetSortedStringKeys(ACLRolesMap)),
+		},
+		{
+			tag:         "aclRoleKind",
+			translation: "'{0}' is not a valid kind of policy object",
 		},
 		{
 			tag:         "exists",
@@ -151,6 +198,10 @@ func NewPolicyValidator(policy *Policy) *PolicyValidator {
 			tag:         "codeServiceSingle",
 			translation: fmt.Sprintf("component '{0}' should either be code or service"),
 		},
+		{
+			tag:         "hooksCode",
+			translation: fmt.Sprintf("component '{0}' can only have hooks if it's code"),
+		},
 		{
 			tag:         "unique",
 			translation: fmt.Sprintf("'{0}' is not unique"),
@@ -252,6 +303,33 @@ func validateCodeType(ctx context.Context, fl validator.FieldLevel) bool {
 	return validateInStringArray(ctx, codeTypes, fl)
 }
 
+// checks if a given string is a valid rollout strategy
+func validateRolloutStrategy(ctx context.Context, fl validator.FieldLevel) bool {
+	return validateInStringArray(ctx, rolloutStrategy, fl)
+}
+
+// checks if a given string is a valid ACL verb
+func validateACLVerb(ctx context.Context, fl validator.FieldLevel) bool {
+	return validateInStringArray(ctx, ACLVerbs, fl)
+}
+
+// checks if a given string is a valid deletion policy
+func validateDeletionPolicy(ctx context.Context, fl validator.FieldLevel) bool {
+	return validateInStringArray(ctx, deletionPolicy, fl)
+}
+
+// checks if a given string is a valid cron expression
+func validateCron(ctx context.Context, fl validator.FieldLevel) bool {
+	_, err := cron.Parse(fl.Field().String())
+	return err == nil
+}
+
+// checks if a given string is a valid timestamp in RFC3339 format
+func validateTimestamp(ctx context.Context, fl validator.FieldLevel) bool {
+	_, err := time.Parse(time.RFC3339, fl.Field().String())
+	return err == nil
+}
+
 // checks if a given string is valid identifier
 func validateIdentifier(ctx context.Context, fl validator.FieldLevel) bool {
 	return isIdentifier(fl.Field().String())
@@ -306,8 +384,7 @@ func validateLabelOperations(ctx context.Context, fl validator.FieldLevel) bool
 func validateACLRoleActionMap(ctx context.Context, fl validator.FieldLevel) bool {
 	addRoleMap := fl.Field().Interface().(map[string]string) // nolint: errcheck
 	for roleID, namespaceList := range addRoleMap {
-		role := ACLRolesMap[roleID]
-		if role == nil {
+		if !isACLRoleDefined(ctx, roleID) {
 			return false
 		}
 
@@ -322,6 +399,19 @@ func validateACLRoleActionMap(ctx context.Context, fl validator.FieldLevel) bool
 	return true
 }
 
+// checks if ACL role is either built-in or defined in system namespace of the policy
+func isACLRoleDefined(ctx context.Context, roleID string) bool {
+	if ACLRolesMap[roleID] != nil {
+		return true
+	}
+	policy, ok := ctx.Value(policyKey).(*Policy)
+	if !ok || policy == nil {
+		return false
+	}
+	systemNamespace := policy.Namespace[runtime.SystemNS]
+	return systemNamespace != nil && systemNamespace.ACLRoles[roleID] != nil
+}
+
 // checks if a given map[string]string is a valid map of labels
 func validateLabels(ctx context.Context, fl validator.FieldLevel) bool {
 	names := fl.Field().MapKeys()
@@ -352,6 +442,12 @@ func validateBundle(ctx context.Context, sl validator.StructLevel) {
 			return
 		}
 
+		// hooks can only be defined for code components
+		if component.Hooks != nil && component.Code == nil {
+			sl.ReportError(component.Name, fmt.Sprintf("Component[%s].Hooks", component.Name), "", "hooksCode", "")
+			return
+		}
+
 		// if service is set, it should point to an existing service
 		if len(component.Service) > 0 {
 			obj, err := policy.GetObject(TypeService.Kind, component.Service, bundle.Namespace)
@@ -449,6 +545,33 @@ func validateACLRule(sl validator.StructLevel) {
 	}
 }
 
+// checks if ACL role is valid
+func validateACLRole(sl validator.StructLevel) {
+	role := sl.Current().Addr().Interface().(*ACLRole) // nolint: errcheck
+	if role.Namespace != runtime.SystemNS {
+		sl.ReportError(role.Namespace, "Namespace", "", "systemNS", "")
+	}
+
+	// ACL role should not override any of the built-in roles
+	if ACLRolesMap[role.Name] != nil {
+		sl.ReportError(role.Name, "Name", "", "aclRoleBuiltin", "")
+	}
+
+	// privileges can only be granted for known policy objects
+	if role.Privileges != nil {
+		for _, kind := range util.GetSortedStringKeys(role.Privileges.NamespaceObjects) {
+			if !policyObjectsMap[kind] {
+				sl.ReportError(kind, "Privileges.NamespaceObjects", "", "aclRoleKind", "")
+			}
+		}
+		for _, kind := range util.GetSortedStringKeys(role.Privileges.GlobalObjects) {
+			if !policyObjectsMap[kind] {
+				sl.ReportError(kind, "Privileges.GlobalObjects", "", "aclRoleKind", "")
+			}
+		}
+	}
+}
+
 // checks if cluster is valid
 func validateCluster(sl validator.StructLevel) {
 	cluster := sl.Current().Addr().Interface().(*Cluster) // nolint: errcheck
@@ -457,6 +580,28 @@ func validateCluster(sl validator.StructLevel) {
 	}
 }
 
+// checks if maintenance window is valid
+func validateMaintenance(sl validator.StructLevel) {
+	maintenance := sl.Current().Addr().Interface().(*Maintenance) // nolint: errcheck
+	if maintenance.Namespace != runtime.SystemNS {
+		sl.ReportError(maintenance.Namespace, "Namespace", "", "systemNS", "")
+	}
+
+	// maintenance window should have at least one window or freeze
+	if len(maintenance.Windows) <= 0 && len(maintenance.Freezes) <= 0 {
+		sl.ReportError(maintenance.Windows, "Windows", "", "maintenanceActions", "")
+		return
+	}
+
+	// every freeze should end after it starts
+	for idx, freeze := range maintenance.Freezes {
+		from, to, err := freeze.parse()
+		if err == nil && !to.After(from) {
+			sl.ReportError(freeze.To, fmt.Sprintf("Freezes[%d].To", idx), "", "freezePeriod", "")
+		}
+	}
+}
+
 func isIdentifier(id string) bool {
 	ok, err := regexp.MatchString(identifierRegex, id)
 	return ok && err == nil
@@ -3,6 +3,7 @@ package lang
 import (
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/Aptomi/aptomi/pkg/lang/yaml"
 	"github.com/Aptomi/aptomi/pkg/runtime"
@@ -70,6 +71,73 @@ func TestPolicyValidationBundle(t *testing.T) {
 		bundle.Components = components
 		runValidationTests(t, ResFailure, false, []Base{bundle, service})
 	}
+
+	// Bundle Rollout
+	rolloutTestsPass := []*Rollout{
+		{Strategy: RolloutStrategyCanary},
+		{Strategy: RolloutStrategyBatch, BatchPercent: 25},
+		{Strategy: RolloutStrategyCanary, BatchPercent: 100, ReadyTimeout: time.Minute},
+	}
+	for _, rollout := range rolloutTestsPass {
+		bundle := makeBundle("bundle", Empty)
+		bundle.Rollout = rollout
+		runValidationTests(t, ResSuccess, false, []Base{bundle})
+	}
+	rolloutTestsFail := []*Rollout{
+		{},
+		{Strategy: "blue-green"},
+		{Strategy: RolloutStrategyBatch, BatchPercent: 101},
+		{Strategy: RolloutStrategyBatch, BatchPercent: -5},
+		{Strategy: RolloutStrategyCanary, ReadyTimeout: -time.Minute},
+	}
+	for _, rollout := range rolloutTestsFail {
+		bundle := makeBundle("bundle", Empty)
+		bundle.Rollout = rollout
+		runValidationTests(t, ResFailure, false, []Base{bundle})
+	}
+
+	// Bundle Component Deletion Policy
+	for _, deletionPolicy := range []string{"", DeletionPolicyDelete, DeletionPolicyOrphan, DeletionPolicyProtect} {
+		bundle := makeBundle("bundle", Empty)
+		bundle.Components = makeBundleComponents(1, "", 0, 0)
+		bundle.Components[0].DeletionPolicy = deletionPolicy
+		runValidationTests(t, ResSuccess, false, []Base{bundle})
+	}
+	for _, deletionPolicy := range []string{"destroy", "Protect"} {
+		bundle := makeBundle("bundle", Empty)
+		bundle.Components = makeBundleComponents(1, "", 0, 0)
+		bundle.Components[0].DeletionPolicy = deletionPolicy
+		runValidationTests(t, ResFailure, false, []Base{bundle})
+	}
+
+	// Bundle Component Hooks
+	hooksTestsPass := []*Hooks{
+		{},
+		{PreUpdate: &Hook{Type: "helm", Params: util.NestedParameterMap{"chartName": "migrate-{{ .Labels.name }}"}}},
+		{PostCreate: &Hook{Type: "raw"}, PreDelete: &Hook{Type: "helm", Timeout: time.Minute}},
+	}
+	for _, hooks := range hooksTestsPass {
+		bundle := makeBundle("bundle", Empty)
+		bundle.Components = makeBundleComponents(1, "", 0, 0)
+		bundle.Components[0].Hooks = hooks
+		runValidationTests(t, ResSuccess, false, []Base{bundle})
+	}
+	hooksTestsFail := []*Hooks{
+		{PreUpdate: &Hook{}},
+		{PreUpdate: &Hook{Type: "unknown"}},
+		{PostCreate: &Hook{Type: "helm", Params: util.NestedParameterMap{"chartName": "{{ .Labels.name "}}},
+		{PreDelete: &Hook{Type: "helm", Timeout: -time.Minute}},
+	}
+	for _, hooks := range hooksTestsFail {
+		bundle := makeBundle("bundle", Empty)
+		bundle.Components = makeBundleComponents(1, "", 0, 0)
+		bundle.Components[0].Hooks = hooks
+		runValidationTests(t, ResFailure, false, []Base{bundle})
+	}
+	bundle := makeBundle("bundle", Empty)
+	bundle.Components = makeBundleComponents(1, service.Name, Nil, 0)
+	bundle.Components[0].Hooks = &Hooks{PreUpdate: &Hook{Type: "helm"}}
+	runValidationTests(t, ResFailure, false, []Base{bundle, service})
 }
 
 func TestPolicyValidationService(t *testing.T) {
@@ -121,10 +189,13 @@ func TestPolicyValidationRule(t *testing.T) {
 		makeRule(1, "true", 0, "labelName"),
 		makeRule(20, "", 1, Reject),
 		makeRule(100, "specialname + specialvalue == 'b'", 2, Reject),
+		makeRule(100, "has(specialname) && startsWith(lower(specialname), 'a')", 2, Reject),
 	})
 	runValidationTests(t, ResFailure, true, []Base{
 		makeRule(-1, "true", 0, "labelName"),                               // negative weight
 		makeRule(100, "specialname + '123')(((", 0, "labelName"),           // bad expression
+		makeRule(100, "unknownFunction(specialname)", 0, "labelName"),      // unknown function
+		makeRule(100, "startsWith(specialname)", 0, "labelName"),           // wrong number of function arguments
 		makeRule(100, "true", Empty, ""),                                   // no actions specified
 		makeRule(100, "true", Nil, ""),                                     // actions = nil
 		makeRule(100, "specialname + specialvalue == 'b'", 2, "notreject"), // action is not (allow, reject)
@@ -143,6 +214,29 @@ func TestPolicyValidationACLRule(t *testing.T) {
 	})
 }
 
+func TestPolicyValidationACLRole(t *testing.T) {
+	// ACL roles (Namespace, Kinds & Verbs)
+	runValidationTests(t, ResSuccess, true, []Base{
+		makeACLRole(runtime.SystemNS, "role", TypeClaim.Kind, ACLVerbCreate, "team == 'x'"),
+		makeACLRole(runtime.SystemNS, "role", TypeService.Kind, ACLVerbApprove, ""),
+	})
+	runValidationTests(t, ResFailure, true, []Base{
+		makeACLRole("main", "role", TypeClaim.Kind, ACLVerbCreate, ""),
+		makeACLRole(runtime.SystemNS, DomainAdmin.Name, TypeClaim.Kind, ACLVerbCreate, ""),
+		makeACLRole(runtime.SystemNS, "role", "unknown", ACLVerbCreate, ""),
+		makeACLRole(runtime.SystemNS, "role", TypeClaim.Kind, "unknown", ""),
+		makeACLRole(runtime.SystemNS, "role", TypeClaim.Kind, ACLVerbCreate, "team == "),
+	})
+
+	// ACL rules can refer to ACL roles defined in the policy
+	rule := makeACLRule(0)
+	rule.Actions.AddRole["role"] = "main"
+	runValidationTests(t, ResSuccess, false, []Base{
+		makeACLRole(runtime.SystemNS, "role", TypeClaim.Kind, ACLVerbCreate, ""),
+		rule,
+	})
+}
+
 func TestPolicyValidationCluster(t *testing.T) {
 	// Clusters (Identifiers & Config)
 	runValidationTests(t, ResSuccess, true, []Base{
@@ -154,6 +248,23 @@ func TestPolicyValidationCluster(t *testing.T) {
 	})
 }
 
+func TestPolicyValidationMaintenance(t *testing.T) {
+	// Maintenance windows (Schedules & Freezes)
+	runValidationTests(t, ResSuccess, true, []Base{
+		makeMaintenance(runtime.SystemNS, "0 22 * * 6", time.Hour, "", ""),
+		makeMaintenance(runtime.SystemNS, "", 0, "2018-12-20T00:00:00Z", "2019-01-05T00:00:00Z"),
+		makeMaintenance(runtime.SystemNS, "*/30 1-5 * * 1,3", 30*time.Minute, "2018-12-20T00:00:00Z", "2019-01-05T00:00:00Z"),
+	})
+	runValidationTests(t, ResFailure, true, []Base{
+		makeMaintenance("main", "0 22 * * 6", time.Hour, "", ""),                                 // not in system namespace
+		makeMaintenance(runtime.SystemNS, "", 0, "", ""),                                         // no windows and no freezes
+		makeMaintenance(runtime.SystemNS, "0 25 * * 6", time.Hour, "", ""),                       // bad schedule
+		makeMaintenance(runtime.SystemNS, "0 22 * * 6", 0, "", ""),                               // no duration
+		makeMaintenance(runtime.SystemNS, "", 0, "2018-12-20", "2019-01-05T00:00:00Z"),           // bad timestamp
+		makeMaintenance(runtime.SystemNS, "", 0, "2019-01-05T00:00:00Z", "2018-12-20T00:00:00Z"), // freeze ends before it starts
+	})
+}
+
 func runValidationTests(t *testing.T, result int, every bool, objects []Base) {
 	t.Helper()
 
@@ -246,7 +357,7 @@ func makeACLRule(actionNum int) *ACLRule {
 	}
 	switch actionNum {
 	case 0:
-		rule.Actions = &ACLRuleActions{AddRole: map[string]string{DomainAdmin.ID: namespaceAll, ServiceConsumer.ID: "main1, main2 ,main3,main4"}}
+		rule.Actions = &ACLRuleActions{AddRole: map[string]string{DomainAdmin.Name: namespaceAll, ServiceConsumer.Name: "main1, main2 ,main3,main4"}}
 	case Empty:
 		rule.Actions = &ACLRuleActions{}
 	case Nil:
@@ -259,6 +370,23 @@ func makeACLRule(actionNum int) *ACLRule {
 	return rule
 }
 
+func makeACLRole(namespace string, name string, kind string, verb string, selector string) *ACLRole {
+	privilege := &Privilege{Verbs: []string{ACLVerbView, verb}}
+	if len(selector) > 0 {
+		privilege.Selector = &Criteria{RequireAll: []string{selector}}
+	}
+	return &ACLRole{
+		TypeKind: TypeACLRole.GetTypeKind(),
+		Metadata: Metadata{
+			Namespace: namespace,
+			Name:      name,
+		},
+		Privileges: &Privileges{
+			NamespaceObjects: map[string]*Privilege{kind: privilege},
+		},
+	}
+}
+
 func makeService(name string, labelOpsNum int, pointToBundle string) *Service {
 	service := &Service{
 		TypeKind: TypeService.GetTypeKind(),
@@ -314,6 +442,23 @@ func makeCluster(clusterType, ns string) *Cluster {
 	}
 }
 
+func makeMaintenance(ns string, schedule string, duration time.Duration, from string, to string) *Maintenance {
+	maintenance := &Maintenance{
+		TypeKind: TypeMaintenance.GetTypeKind(),
+		Metadata: Metadata{
+			Namespace: ns,
+			Name:      "maintenance",
+		},
+	}
+	if len(schedule) > 0 || duration > 0 {
+		maintenance.Windows = []*MaintenanceWindow{{Schedule: schedule, Duration: duration}}
+	}
+	if len(from) > 0 || len(to) > 0 {
+		maintenance.Freezes = []*MaintenanceFreeze{{From: from, To: to}}
+	}
+	return maintenance
+}
+
 func makeBundle(name string, labelNum int) *Bundle {
 	bundle := &Bundle{
 		TypeKind: TypeBundle.GetTypeKind(),
@@ -0,0 +1,152 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/lang"
+)
+
+// InProgressError is returned by RunWithContext, when ctx gets cancelled or its deadline expires while the operation
+// is still running in background. It's not a timeout, as the operation may still complete successfully
+type InProgressError struct {
+	// Key identifies the object the operation is being performed on (e.g. helm release)
+	Key string
+
+	// Err is the reason why the operation has been abandoned
+	Err error
+}
+
+// Error returns an error message for InProgressError
+func (err *InProgressError) Error() string {
+	if len(err.Key) <= 0 {
+		return fmt.Sprintf("operation aborted (%s), but it's still in progress", err.Err)
+	}
+	return fmt.Sprintf("operation on '%s' aborted (%s), but it's still in progress", err.Key, err.Err)
+}
+
+// IsInProgress returns true if a given error is InProgressError
+func IsInProgress(err error) bool {
+	_, ok := err.(*InProgressError)
+	return ok
+}
+
+var (
+	// inProgress contains operations, which have been abandoned, but are still running (key -> closed when done)
+	inProgress      = make(map[string]chan struct{})
+	inProgressMutex sync.Mutex
+)
+
+// RunWithContext runs a given function and waits for it to complete. If ctx gets cancelled or its deadline expires
+// before that, it returns InProgressError right away. It's intended to be used by plugins to wrap calls to client
+// libraries, which don't support cancellation. Note that the function will keep running in background in that case.
+//
+// If key is not empty and the previous operation with the same key has been abandoned, but it's still running, then the
+// next one waits for it to complete before starting (e.g. so that a retry doesn't start a second operation on the same
+// helm release)
+func RunWithContext(ctx context.Context, key string, f func() error) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("operation aborted: %s", err)
+	}
+
+	if len(key) > 0 {
+		err := WaitInProgress(ctx, key)
+		if err != nil {
+			return err
+		}
+	}
+
+	done := make(chan error, 1)
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+
+		// make sure we are converting panics into errors, as nobody else will recover them in this go routine
+		defer func() {
+			if err := recover(); err != nil {
+				done <- fmt.Errorf("panic: %s\n%s", err, string(debug.Stack()))
+			}
+		}()
+		done <- f()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		select {
+		case err := <-done:
+			// operation has completed right before ctx got cancelled
+			return err
+		default:
+		}
+		if len(key) > 0 {
+			markInProgress(key, finished)
+		}
+		return &InProgressError{Key: key, Err: ctx.Err()}
+	}
+}
+
+// OperationKey returns the key for RunWithContext, which identifies operations on a given deployment in a given cluster
+func OperationKey(cluster *lang.Cluster, deployName string) string {
+	return strings.Join([]string{cluster.Namespace, cluster.Name, deployName}, "/")
+}
+
+// WaitInProgress waits until the abandoned operation with a given key completes. Plugins should call it before looking
+// at the state of the object, which they are going to change (e.g. helm release). If ctx gets cancelled or its deadline
+// expires while waiting, InProgressError is returned
+func WaitInProgress(ctx context.Context, key string) error {
+	for {
+		inProgressMutex.Lock()
+		finished, ok := inProgress[key]
+		inProgressMutex.Unlock()
+		if !ok {
+			return nil
+		}
+
+		select {
+		case <-finished:
+		case <-ctx.Done():
+			return &InProgressError{Key: key, Err: ctx.Err()}
+		}
+	}
+}
+
+// markInProgress records abandoned operation with a given key, until it completes
+func markInProgress(key string, finished chan struct{}) {
+	inProgressMutex.Lock()
+	inProgress[key] = finished
+	inProgressMutex.Unlock()
+
+	go func() {
+		<-finished
+		inProgressMutex.Lock()
+		if inProgress[key] == finished {
+			delete(inProgress, key)
+		}
+		inProgressMutex.Unlock()
+	}()
+}
+
+// TimeoutSeconds returns timeout in seconds for a client library operation, which has its own timeout. It's a given
+// timeout, but no more than the time remaining until ctx deadline, so the operation doesn't keep running long after
+// the action it belongs to has been aborted. It's never less than a second, unless neither timeout nor ctx deadline is
+// set, in which case zero is returned
+func TimeoutSeconds(ctx context.Context, timeout time.Duration) int64 {
+	deadline, hasDeadline := ctx.Deadline()
+	if hasDeadline {
+		remaining := time.Until(deadline)
+		if timeout <= 0 || remaining < timeout {
+			timeout = remaining
+		}
+	}
+	seconds := int64(timeout / time.Second)
+	if (hasDeadline || timeout > 0) && seconds <= 0 {
+		seconds = 1
+	}
+	return seconds
+}
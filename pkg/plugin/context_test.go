@@ -0,0 +1,63 @@
+package plugin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunWithContextInProgress(t *testing.T) {
+	release := make(chan struct{})
+	running := 0
+
+	// operation gets abandoned once deadline expires, but it keeps running
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := RunWithContext(ctx, "cluster/release", func() error {
+		running++
+		<-release
+		running--
+		return nil
+	})
+	assert.True(t, IsInProgress(err), "Abandoned operation should be reported as in progress, got: %s", err)
+
+	// next operation with the same key should wait for the abandoned one, unless its own deadline expires
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = RunWithContext(ctx, "cluster/release", func() error {
+		return nil
+	})
+	assert.True(t, IsInProgress(err), "Operation should not start while abandoned one is in progress, got: %s", err)
+
+	// operation with another key should not wait
+	assert.NoError(t, RunWithContext(context.Background(), "cluster/other", func() error { return nil }))
+
+	// once the abandoned operation completes, the next one should start
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+	}()
+	err = RunWithContext(context.Background(), "cluster/release", func() error {
+		assert.Equal(t, 0, running, "Operations with the same key should not run concurrently")
+		return nil
+	})
+	assert.NoError(t, err)
+}
+
+func TestTimeoutSeconds(t *testing.T) {
+	assert.Equal(t, int64(300), TimeoutSeconds(context.Background(), 5*time.Minute))
+	assert.Equal(t, int64(0), TimeoutSeconds(context.Background(), 0))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	assert.True(t, TimeoutSeconds(ctx, 5*time.Minute) <= 10, "Timeout should not exceed ctx deadline")
+	assert.True(t, TimeoutSeconds(ctx, 0) <= 10, "Timeout should be taken from ctx deadline")
+	assert.Equal(t, int64(5), TimeoutSeconds(ctx, 5*time.Second))
+
+	ctx, cancel = context.WithDeadline(context.Background(), time.Now().Add(-time.Minute))
+	defer cancel()
+	assert.Equal(t, int64(1), TimeoutSeconds(ctx, 5*time.Minute), "Timeout should be positive after ctx deadline")
+	assert.Equal(t, int64(1), TimeoutSeconds(ctx, 0), "Timeout should be positive after ctx deadline")
+}
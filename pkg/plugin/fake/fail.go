@@ -1,6 +1,7 @@
 package fake
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/Aptomi/aptomi/pkg/plugin"
@@ -35,29 +36,29 @@ func (plugin *failCodePlugin) fail(action string, deployName string) error {
 	return fmt.Errorf(msg)
 }
 
-func (plugin *failCodePlugin) Create(invocation *plugin.CodePluginInvocationParams) error {
+func (plugin *failCodePlugin) Create(ctx context.Context, invocation *plugin.CodePluginInvocationParams) error {
 	invocation.EventLog.NewEntry().Infof("[+] %s", invocation.DeployName)
 	return plugin.fail("create", invocation.DeployName)
 }
 
-func (plugin *failCodePlugin) Update(invocation *plugin.CodePluginInvocationParams) error {
+func (plugin *failCodePlugin) Update(ctx context.Context, invocation *plugin.CodePluginInvocationParams) error {
 	invocation.EventLog.NewEntry().Infof("[*] %s", invocation.DeployName)
 	return plugin.fail("update", invocation.DeployName)
 }
 
-func (plugin *failCodePlugin) Destroy(invocation *plugin.CodePluginInvocationParams) error {
+func (plugin *failCodePlugin) Destroy(ctx context.Context, invocation *plugin.CodePluginInvocationParams) error {
 	invocation.EventLog.NewEntry().Infof("[-] %s", invocation.DeployName)
 	return plugin.fail("delete", invocation.DeployName)
 }
 
-func (plugin *failCodePlugin) Endpoints(invocation *plugin.CodePluginInvocationParams) (map[string]string, error) {
+func (plugin *failCodePlugin) Endpoints(ctx context.Context, invocation *plugin.CodePluginInvocationParams) (map[string]string, error) {
 	return make(map[string]string), nil
 }
 
-func (plugin *failCodePlugin) Resources(invocation *plugin.CodePluginInvocationParams) (plugin.Resources, error) {
+func (plugin *failCodePlugin) Resources(ctx context.Context, invocation *plugin.CodePluginInvocationParams) (plugin.Resources, error) {
 	return nil, nil
 }
 
-func (plugin *failCodePlugin) Status(invocation *plugin.CodePluginInvocationParams) (bool, error) {
+func (plugin *failCodePlugin) Status(ctx context.Context, invocation *plugin.CodePluginInvocationParams) (bool, error) {
 	return false, nil
 }
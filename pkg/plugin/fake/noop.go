@@ -1,6 +1,7 @@
 package fake
 
 import (
+	"context"
 	"time"
 
 	"github.com/Aptomi/aptomi/pkg/plugin"
@@ -27,6 +28,18 @@ func NewNoOpCodePlugin(sleepTime time.Duration) plugin.CodePlugin {
 	}
 }
 
+// sleep sleeps a given time amount, unless ctx gets cancelled
+func (plugin *noOpPlugin) sleep(ctx context.Context) error {
+	timer := time.NewTimer(plugin.sleepTime)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
 func (plugin *noOpPlugin) Validate() error {
 	return nil
 }
@@ -35,32 +48,31 @@ func (plugin *noOpPlugin) Cleanup() error {
 	return nil
 }
 
-func (plugin *noOpPlugin) Create(invocation *plugin.CodePluginInvocationParams) error {
-	time.Sleep(plugin.sleepTime)
-	return nil
+func (plugin *noOpPlugin) Create(ctx context.Context, invocation *plugin.CodePluginInvocationParams) error {
+	return plugin.sleep(ctx)
 }
 
-func (plugin *noOpPlugin) Update(invocation *plugin.CodePluginInvocationParams) error {
-	time.Sleep(plugin.sleepTime)
-	return nil
+func (plugin *noOpPlugin) Update(ctx context.Context, invocation *plugin.CodePluginInvocationParams) error {
+	return plugin.sleep(ctx)
 }
 
-func (plugin *noOpPlugin) Destroy(invocation *plugin.CodePluginInvocationParams) error {
-	time.Sleep(plugin.sleepTime)
-	return nil
+func (plugin *noOpPlugin) Destroy(ctx context.Context, invocation *plugin.CodePluginInvocationParams) error {
+	return plugin.sleep(ctx)
 }
 
-func (plugin *noOpPlugin) Endpoints(invocation *plugin.CodePluginInvocationParams) (map[string]string, error) {
-	time.Sleep(plugin.sleepTime)
+func (plugin *noOpPlugin) Endpoints(ctx context.Context, invocation *plugin.CodePluginInvocationParams) (map[string]string, error) {
+	if err := plugin.sleep(ctx); err != nil {
+		return nil, err
+	}
 	return map[string]string{
 		"http": "endpoint_fake",
 	}, nil
 }
 
-func (plugin *noOpPlugin) Resources(invocation *plugin.CodePluginInvocationParams) (plugin.Resources, error) {
+func (plugin *noOpPlugin) Resources(ctx context.Context, invocation *plugin.CodePluginInvocationParams) (plugin.Resources, error) {
 	return nil, nil
 }
 
-func (plugin *noOpPlugin) Status(invocation *plugin.CodePluginInvocationParams) (bool, error) {
+func (plugin *noOpPlugin) Status(ctx context.Context, invocation *plugin.CodePluginInvocationParams) (bool, error) {
 	return true, nil
 }
@@ -1,6 +1,7 @@
 package helm
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -14,6 +15,7 @@ import (
 	"gopkg.in/yaml.v2"
 	"k8s.io/helm/pkg/helm"
 	"k8s.io/helm/pkg/kube"
+	"k8s.io/helm/pkg/proto/hapi/services"
 )
 
 // Plugin represents Helm code plugin for Kubernetes cluster
@@ -70,16 +72,16 @@ func (p *Plugin) Cleanup() error {
 }
 
 // Create implements creation of a new component instance in the cloud by deploying a Helm chart
-func (p *Plugin) Create(invocation *plugin.CodePluginInvocationParams) error {
-	return p.createOrUpdate(invocation, true)
+func (p *Plugin) Create(ctx context.Context, invocation *plugin.CodePluginInvocationParams) error {
+	return p.createOrUpdate(ctx, invocation, true)
 }
 
 // Update implements update of an existing component instance in the cloud by updating parameters of a helm chart
-func (p *Plugin) Update(invocation *plugin.CodePluginInvocationParams) error {
-	return p.createOrUpdate(invocation, false)
+func (p *Plugin) Update(ctx context.Context, invocation *plugin.CodePluginInvocationParams) error {
+	return p.createOrUpdate(ctx, invocation, false)
 }
 
-func (p *Plugin) createOrUpdate(invocation *plugin.CodePluginInvocationParams, create bool) error {
+func (p *Plugin) createOrUpdate(ctx context.Context, invocation *plugin.CodePluginInvocationParams, create bool) error {
 	err := p.init(invocation.EventLog)
 	if err != nil {
 		return err
@@ -100,6 +102,12 @@ func (p *Plugin) createOrUpdate(invocation *plugin.CodePluginInvocationParams, c
 		return err
 	}
 
+	// if the previous operation on the release has been abandoned, let it complete before looking at the release
+	err = plugin.WaitInProgress(ctx, plugin.OperationKey(p.cluster, invocation.DeployName))
+	if err != nil {
+		return err
+	}
+
 	releaseName := getReleaseName(invocation.DeployName)
 	chartRepo, chartName, chartVersion, err := getHelmReleaseInfo(invocation.Params)
 	if err != nil {
@@ -135,16 +143,17 @@ func (p *Plugin) createOrUpdate(invocation *plugin.CodePluginInvocationParams, c
 			// Print installation line on info level
 			invocation.EventLog.NewEntry().Infof("Installing Helm release '%s', chart '%s', cluster: '%s'", releaseName, chartName, cluster.Name)
 
-			_, err = helmClient.InstallRelease(
-				chartPath,
-				namespace,
-				helm.ReleaseName(releaseName),
-				helm.ValueOverrides(helmParams),
-				helm.InstallReuseName(true),
-				helm.InstallTimeout(int64(p.config.Timeout)),
-			)
-
-			return err
+			return plugin.RunWithContext(ctx, plugin.OperationKey(p.cluster, invocation.DeployName), func() error {
+				_, installErr := helmClient.InstallRelease(
+					chartPath,
+					namespace,
+					helm.ReleaseName(releaseName),
+					helm.ValueOverrides(helmParams),
+					helm.InstallReuseName(true),
+					helm.InstallTimeout(plugin.TimeoutSeconds(ctx, p.config.Timeout)),
+				)
+				return installErr
+			})
 		}
 	}
 
@@ -162,12 +171,17 @@ func (p *Plugin) createOrUpdate(invocation *plugin.CodePluginInvocationParams, c
 		return fmt.Errorf("it's not allowed to change namespace of the release %s (was %s, requested %s)", releaseName, status.Namespace, namespace)
 	}
 
-	newRelease, err := helmClient.UpdateRelease(
-		releaseName,
-		chartPath,
-		helm.UpdateValueOverrides(helmParams),
-		helm.UpgradeTimeout(int64(p.config.Timeout)),
-	)
+	var newRelease *services.UpdateReleaseResponse
+	err = plugin.RunWithContext(ctx, plugin.OperationKey(p.cluster, invocation.DeployName), func() error {
+		var updateErr error
+		newRelease, updateErr = helmClient.UpdateRelease(
+			releaseName,
+			chartPath,
+			helm.UpdateValueOverrides(helmParams),
+			helm.UpgradeTimeout(plugin.TimeoutSeconds(ctx, p.config.Timeout)),
+		)
+		return updateErr
+	})
 	if err != nil {
 		return err
 	}
@@ -199,7 +213,7 @@ func (p *Plugin) createOrUpdate(invocation *plugin.CodePluginInvocationParams, c
 }
 
 // Destroy implements destruction of an existing component instance in the cloud by running "helm delete" on the corresponding helm chart
-func (p *Plugin) Destroy(invocation *plugin.CodePluginInvocationParams) error {
+func (p *Plugin) Destroy(ctx context.Context, invocation *plugin.CodePluginInvocationParams) error {
 	err := p.init(invocation.EventLog)
 	if err != nil {
 		return err
@@ -211,16 +225,18 @@ func (p *Plugin) Destroy(invocation *plugin.CodePluginInvocationParams) error {
 
 	invocation.EventLog.NewEntry().Infof("Deleting Helm release '%s'", releaseName)
 
-	_, err = helmClient.DeleteRelease(
-		releaseName,
-		helm.DeletePurge(true),
-		helm.DeleteTimeout(int64(p.config.Timeout)),
-	)
-	return err
+	return plugin.RunWithContext(ctx, plugin.OperationKey(p.cluster, invocation.DeployName), func() error {
+		_, deleteErr := helmClient.DeleteRelease(
+			releaseName,
+			helm.DeletePurge(true),
+			helm.DeleteTimeout(plugin.TimeoutSeconds(ctx, p.config.Timeout)),
+		)
+		return deleteErr
+	})
 }
 
 // Endpoints returns map from port type to url for all services of the current chart
-func (p *Plugin) Endpoints(invocation *plugin.CodePluginInvocationParams) (map[string]string, error) {
+func (p *Plugin) Endpoints(ctx context.Context, invocation *plugin.CodePluginInvocationParams) (map[string]string, error) {
 	err := p.init(invocation.EventLog)
 	if err != nil {
 		return nil, err
@@ -240,11 +256,21 @@ func (p *Plugin) Endpoints(invocation *plugin.CodePluginInvocationParams) (map[s
 		return nil, fmt.Errorf("error while looking for Helm release %s: %s", releaseName, err)
 	}
 
-	return p.kube.EndpointsForManifests(namespace, invocation.DeployName, currRelease.Release.Manifest, invocation.EventLog)
+	var endpoints map[string]string
+	err = plugin.RunWithContext(ctx, "", func() error {
+		var runErr error
+		endpoints, runErr = p.kube.EndpointsForManifests(namespace, invocation.DeployName, currRelease.Release.Manifest, invocation.EventLog)
+		return runErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return endpoints, nil
 }
 
 // Resources returns list of all resources (like services, config maps, etc.) deployed into the cluster by specified component instance
-func (p *Plugin) Resources(invocation *plugin.CodePluginInvocationParams) (plugin.Resources, error) {
+func (p *Plugin) Resources(ctx context.Context, invocation *plugin.CodePluginInvocationParams) (plugin.Resources, error) {
 	err := p.init(invocation.EventLog)
 	if err != nil {
 		return nil, err
@@ -264,11 +290,21 @@ func (p *Plugin) Resources(invocation *plugin.CodePluginInvocationParams) (plugi
 		return nil, fmt.Errorf("error while looking for Helm release %s: %s", releaseName, err)
 	}
 
-	return p.kube.ResourcesForManifest(namespace, invocation.DeployName, currRelease.Release.Manifest, invocation.EventLog)
+	var resources plugin.Resources
+	err = plugin.RunWithContext(ctx, "", func() error {
+		var runErr error
+		resources, runErr = p.kube.ResourcesForManifest(namespace, invocation.DeployName, currRelease.Release.Manifest, invocation.EventLog)
+		return runErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resources, nil
 }
 
 // Status returns readiness of all resources (like services, config maps, etc.) deployed into the cluster by specified component instance
-func (p *Plugin) Status(invocation *plugin.CodePluginInvocationParams) (bool, error) {
+func (p *Plugin) Status(ctx context.Context, invocation *plugin.CodePluginInvocationParams) (bool, error) {
 	err := p.init(invocation.EventLog)
 	if err != nil {
 		return false, err
@@ -288,5 +324,15 @@ func (p *Plugin) Status(invocation *plugin.CodePluginInvocationParams) (bool, er
 		return false, fmt.Errorf("error while looking for Helm release %s: %s", releaseName, err)
 	}
 
-	return p.kube.ReadinessStatusForManifest(namespace, invocation.DeployName, currRelease.Release.Manifest, invocation.EventLog)
+	var ready bool
+	err = plugin.RunWithContext(ctx, "", func() error {
+		var runErr error
+		ready, runErr = p.kube.ReadinessStatusForManifest(namespace, invocation.DeployName, currRelease.Release.Manifest, invocation.EventLog)
+		return runErr
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return ready, nil
 }
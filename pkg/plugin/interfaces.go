@@ -1,6 +1,8 @@
 package plugin
 
 import (
+	"context"
+
 	"github.com/Aptomi/aptomi/pkg/config"
 	"github.com/Aptomi/aptomi/pkg/event"
 	"github.com/Aptomi/aptomi/pkg/lang"
@@ -34,15 +36,18 @@ type ClusterPluginConstructor func(cluster *lang.Cluster, cfg config.Plugins) (C
 
 // CodePlugin is a definition of deployment plugin which takes care of creating, updating and destroying
 // component instances in the cloud. It's created for specific cluster and enforcement cycle or API call.
+//
+// All methods accept a context, which gets cancelled when the operation has to be aborted (e.g. its deadline has
+// been reached or revision has been cancelled). Plugins should return as soon as possible in that case.
 type CodePlugin interface {
 	Base
 
-	Create(*CodePluginInvocationParams) error
-	Update(*CodePluginInvocationParams) error
-	Destroy(*CodePluginInvocationParams) error
-	Endpoints(*CodePluginInvocationParams) (map[string]string, error)
-	Resources(*CodePluginInvocationParams) (Resources, error)
-	Status(*CodePluginInvocationParams) (bool, error)
+	Create(context.Context, *CodePluginInvocationParams) error
+	Update(context.Context, *CodePluginInvocationParams) error
+	Destroy(context.Context, *CodePluginInvocationParams) error
+	Endpoints(context.Context, *CodePluginInvocationParams) (map[string]string, error)
+	Resources(context.Context, *CodePluginInvocationParams) (Resources, error)
+	Status(context.Context, *CodePluginInvocationParams) (bool, error)
 }
 
 // ParamTargetSuffix it's a plugin-specific parameter, which is additionally specifies where the code should reside (in case of k8s and Helm, it's a string consisting of k8s namespace)
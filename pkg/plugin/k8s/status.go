@@ -1,10 +1,12 @@
 package k8s
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/Aptomi/aptomi/pkg/event"
 	"github.com/Aptomi/aptomi/pkg/util"
+	batch "k8s.io/api/batch/v1"
 	"k8s.io/api/core/v1"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -41,7 +43,7 @@ func (p *Plugin) ReadinessStatusForManifest(namespace, deployName, targetManifes
 
 		var statusErr error
 
-		// todo some objects are missing in this check like DaemonSet, Job, ReplicationController, etc.
+		// todo some objects are missing in this check like DaemonSet, ReplicationController, etc.
 		switch kind := info.Mapping.GroupVersionKind.Kind; kind {
 		case "Service": // nolint: goconst
 			svc, getErr := kubeClient.CoreV1().Services(namespace).Get(info.Name, meta.GetOptions{})
@@ -55,6 +57,16 @@ func (p *Plugin) ReadinessStatusForManifest(namespace, deployName, targetManifes
 				return false, getErr
 			}
 			ready = isPersistentVolumeClaimReady(pvc)
+		case "Job":
+			job, getErr := kubeClient.BatchV1().Jobs(namespace).Get(info.Name, meta.GetOptions{})
+			if getErr != nil {
+				return false, getErr
+			}
+			ready, statusErr = isJobComplete(job)
+			if statusErr != nil {
+				// job is not going to complete, so there is no point in waiting for it
+				return false, statusErr
+			}
 		case "Deployment":
 			//deployment, getErr := kubeClient.AppsV1beta1().Deployments(p.Namespace).Get(info.Name, meta.GetOptions{})
 			//if getErr != nil {
@@ -100,6 +112,23 @@ func isPersistentVolumeClaimReady(pvc *v1.PersistentVolumeClaim) bool {
 	return pvc.Status.Phase == v1.ClaimBound
 }
 
+// isJobComplete returns true if job has completed successfully and an error if job has failed (e.g. when it's used
+// as a hook and its backoff limit got exceeded)
+func isJobComplete(job *batch.Job) (bool, error) {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != v1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batch.JobComplete:
+			return true, nil
+		case batch.JobFailed:
+			return false, fmt.Errorf("job '%s' failed: %s", job.Name, condition.Message)
+		}
+	}
+	return false, nil
+}
+
 func isReadyUsingStatusViewer(internalClientSet kubernetes.Interface, groupKind schema.GroupKind, namespace, name string) (bool, error) {
 	statusViewer, err := kubectl.StatusViewerFor(groupKind, internalClientSet)
 	if err != nil {
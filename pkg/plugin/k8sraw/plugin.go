@@ -1,6 +1,7 @@
 package k8sraw
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -61,7 +62,7 @@ func (p *Plugin) Cleanup() error {
 }
 
 // Create implements creation of a new component instance in the cloud by deploying raw k8s objects
-func (p *Plugin) Create(invocation *plugin.CodePluginInvocationParams) error {
+func (p *Plugin) Create(ctx context.Context, invocation *plugin.CodePluginInvocationParams) error {
 	err := p.init()
 	if err != nil {
 		return err
@@ -84,7 +85,9 @@ func (p *Plugin) Create(invocation *plugin.CodePluginInvocationParams) error {
 
 	client := p.kube.NewHelmKube(invocation.DeployName, invocation.EventLog)
 
-	err = client.Create(namespace, strings.NewReader(targetManifest), 42, false)
+	err = plugin.RunWithContext(ctx, plugin.OperationKey(p.cluster, invocation.DeployName), func() error {
+		return client.Create(namespace, strings.NewReader(targetManifest), 42, false)
+	})
 	if err != nil {
 		return err
 	}
@@ -93,7 +96,7 @@ func (p *Plugin) Create(invocation *plugin.CodePluginInvocationParams) error {
 }
 
 // Update implements update of an existing component instance in the cloud by updating raw k8s objects
-func (p *Plugin) Update(invocation *plugin.CodePluginInvocationParams) error {
+func (p *Plugin) Update(ctx context.Context, invocation *plugin.CodePluginInvocationParams) error {
 	err := p.init()
 	if err != nil {
 		return err
@@ -109,6 +112,12 @@ func (p *Plugin) Update(invocation *plugin.CodePluginInvocationParams) error {
 		return fmt.Errorf("namespace is a mandatory parameter")
 	}
 
+	// if the previous operation on the objects has been abandoned, let it complete before loading the manifest
+	err = plugin.WaitInProgress(ctx, plugin.OperationKey(p.cluster, invocation.DeployName))
+	if err != nil {
+		return err
+	}
+
 	currentManifest, err := p.loadManifest(kubeClient, invocation.DeployName)
 	if err != nil {
 		return err
@@ -121,7 +130,9 @@ func (p *Plugin) Update(invocation *plugin.CodePluginInvocationParams) error {
 
 	client := p.kube.NewHelmKube(invocation.DeployName, invocation.EventLog)
 
-	err = client.Update(namespace, strings.NewReader(currentManifest), strings.NewReader(targetManifest), false, false, 42, false)
+	err = plugin.RunWithContext(ctx, plugin.OperationKey(p.cluster, invocation.DeployName), func() error {
+		return client.Update(namespace, strings.NewReader(currentManifest), strings.NewReader(targetManifest), false, false, 42, false)
+	})
 	if err != nil {
 		return err
 	}
@@ -130,7 +141,7 @@ func (p *Plugin) Update(invocation *plugin.CodePluginInvocationParams) error {
 }
 
 // Destroy implements destruction of an existing component instance in the cloud by deleting raw k8s objects
-func (p *Plugin) Destroy(invocation *plugin.CodePluginInvocationParams) error {
+func (p *Plugin) Destroy(ctx context.Context, invocation *plugin.CodePluginInvocationParams) error {
 	err := p.init()
 	if err != nil {
 		return err
@@ -153,7 +164,9 @@ func (p *Plugin) Destroy(invocation *plugin.CodePluginInvocationParams) error {
 
 	client := p.kube.NewHelmKube(invocation.DeployName, invocation.EventLog)
 
-	err = client.Delete(namespace, strings.NewReader(deleteManifest))
+	err = plugin.RunWithContext(ctx, plugin.OperationKey(p.cluster, invocation.DeployName), func() error {
+		return client.Delete(namespace, strings.NewReader(deleteManifest))
+	})
 	if err != nil {
 		return err
 	}
@@ -162,7 +175,7 @@ func (p *Plugin) Destroy(invocation *plugin.CodePluginInvocationParams) error {
 }
 
 // Endpoints returns map from port type to url for all services of the deployed raw k8s objects
-func (p *Plugin) Endpoints(invocation *plugin.CodePluginInvocationParams) (map[string]string, error) {
+func (p *Plugin) Endpoints(ctx context.Context, invocation *plugin.CodePluginInvocationParams) (map[string]string, error) {
 	err := p.init()
 	if err != nil {
 		return nil, err
@@ -178,11 +191,21 @@ func (p *Plugin) Endpoints(invocation *plugin.CodePluginInvocationParams) (map[s
 		return nil, fmt.Errorf("manifest is a mandatory parameter")
 	}
 
-	return p.kube.EndpointsForManifests(namespace, invocation.DeployName, targetManifest, invocation.EventLog)
+	var endpoints map[string]string
+	err = plugin.RunWithContext(ctx, "", func() error {
+		var runErr error
+		endpoints, runErr = p.kube.EndpointsForManifests(namespace, invocation.DeployName, targetManifest, invocation.EventLog)
+		return runErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return endpoints, nil
 }
 
 // Resources returns list of all resources (like services, config maps, etc.) deployed into the cluster by specified component instance
-func (p *Plugin) Resources(invocation *plugin.CodePluginInvocationParams) (plugin.Resources, error) {
+func (p *Plugin) Resources(ctx context.Context, invocation *plugin.CodePluginInvocationParams) (plugin.Resources, error) {
 	err := p.init()
 	if err != nil {
 		return nil, err
@@ -198,11 +221,21 @@ func (p *Plugin) Resources(invocation *plugin.CodePluginInvocationParams) (plugi
 		return nil, fmt.Errorf("manifest is a mandatory parameter")
 	}
 
-	return p.kube.ResourcesForManifest(namespace, invocation.DeployName, targetManifest, invocation.EventLog)
+	var resources plugin.Resources
+	err = plugin.RunWithContext(ctx, "", func() error {
+		var runErr error
+		resources, runErr = p.kube.ResourcesForManifest(namespace, invocation.DeployName, targetManifest, invocation.EventLog)
+		return runErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resources, nil
 }
 
 // Status returns readiness of all resources (like services, config maps, etc.) deployed into the cluster by specified component instance
-func (p *Plugin) Status(invocation *plugin.CodePluginInvocationParams) (bool, error) {
+func (p *Plugin) Status(ctx context.Context, invocation *plugin.CodePluginInvocationParams) (bool, error) {
 	err := p.init()
 	if err != nil {
 		return false, err
@@ -218,5 +251,15 @@ func (p *Plugin) Status(invocation *plugin.CodePluginInvocationParams) (bool, er
 		return false, fmt.Errorf("manifest is a mandatory parameter")
 	}
 
-	return p.kube.ReadinessStatusForManifest(namespace, invocation.DeployName, targetManifest, invocation.EventLog)
+	var ready bool
+	err = plugin.RunWithContext(ctx, "", func() error {
+		var runErr error
+		ready, runErr = p.kube.ReadinessStatusForManifest(namespace, invocation.DeployName, targetManifest, invocation.EventLog)
+		return runErr
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return ready, nil
 }
@@ -0,0 +1,70 @@
+package registry
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/runtime/store"
+)
+
+// SaveAuditEntry appends entry to the audit log
+func (reg *defaultRegistry) SaveAuditEntry(entry *engine.AuditEntry) error {
+	_, err := reg.store.Save(entry)
+	if err != nil {
+		return fmt.Errorf("error while saving audit entry '%s': %s", entry.ID, err)
+	}
+
+	return nil
+}
+
+// auditEntriesBatchSize is the number of audit entries loaded from the store at once when looking for matching entries
+const auditEntriesBatchSize = 1000
+
+// GetAuditEntries returns audit entries matching a given filter, ordered from the oldest to the most recent one. Entry
+// IDs are ordered by time, so only entries within the filter time range get loaded, from the most recent to the oldest
+// one in batches, until the limit is reached
+func (reg *defaultRegistry) GetAuditEntries(filter *engine.AuditFilter) ([]*engine.AuditEntry, error) {
+	prefix := runtime.KeyFromParts(runtime.SystemNS, engine.TypeAuditEntry.Kind, runtime.EmptyName) + runtime.KeySeparator
+	from, to := "", ""
+	if !filter.Since.IsZero() {
+		from = prefix + engine.AuditEntryIDPrefix(filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		// IDs of entries created at the given time are prefixed by it, so the range should end right after them
+		to = prefix + engine.AuditEntryIDPrefix(filter.Until.Add(time.Nanosecond))
+	}
+
+	result := []*engine.AuditEntry{}
+	for filter.Limit <= 0 || len(result) < filter.Limit {
+		var entries []*engine.AuditEntry
+		err := reg.store.Find(engine.TypeAuditEntry.Kind, &entries, store.WithKeyPrefix(prefix), store.WithKeyRange(from, to), store.WithLimit(auditEntriesBatchSize), store.WithDescendingOrder())
+		if err != nil {
+			return nil, fmt.Errorf("error while getting audit entries: %s", err)
+		}
+
+		for _, entry := range entries {
+			if filter.Matches(entry) {
+				result = append(result, entry)
+			}
+			if filter.Limit > 0 && len(result) >= filter.Limit {
+				break
+			}
+		}
+
+		if len(entries) < auditEntriesBatchSize {
+			break
+		}
+
+		// next batch ends right before the oldest entry of this one
+		to = runtime.KeyForStorable(entries[len(entries)-1])
+	}
+
+	// entries have been loaded from the most recent to the oldest one
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+
+	return result, nil
+}
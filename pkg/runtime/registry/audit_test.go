@@ -0,0 +1,110 @@
+package registry
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/runtime/store"
+	"github.com/stretchr/testify/assert"
+)
+
+// auditStoreMock keeps audit entries in memory and supports searching them with key prefix, range, limit and order
+type auditStoreMock struct {
+	entries map[string]*engine.AuditEntry
+	loaded  int
+}
+
+func (s *auditStoreMock) Close() error {
+	return nil
+}
+
+func (s *auditStoreMock) Save(storable runtime.Storable, opts ...store.SaveOpt) (bool, error) {
+	s.entries[runtime.KeyForStorable(storable)] = storable.(*engine.AuditEntry)
+	return true, nil
+}
+
+func (s *auditStoreMock) Find(kind runtime.Kind, result interface{}, opts ...store.FindOpt) error {
+	findOpts := store.NewFindOpts(opts)
+	from, to := findOpts.GetKeyRange()
+
+	keys := []string{}
+	for key := range s.entries {
+		if strings.HasPrefix(key, findOpts.GetKeyPrefix()) && (from == "" || key >= from) && (to == "" || key < to) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	if findOpts.IsDescending() {
+		sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+	}
+	if findOpts.GetLimit() > 0 && len(keys) > findOpts.GetLimit() {
+		keys = keys[:findOpts.GetLimit()]
+	}
+
+	v := reflect.ValueOf(result).Elem()
+	for _, key := range keys {
+		v.Set(reflect.Append(v, reflect.ValueOf(s.entries[key])))
+	}
+	s.loaded += len(keys)
+	return nil
+}
+
+func (s *auditStoreMock) Delete(kind runtime.Kind, key runtime.Key) error {
+	delete(s.entries, key)
+	return nil
+}
+
+func TestGetAuditEntries(t *testing.T) {
+	s := &auditStoreMock{entries: make(map[string]*engine.AuditEntry)}
+	reg := New(s)
+
+	// record entries a second apart, every 10th of them made by bob
+	start := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	count := 2500
+	for i := 0; i < count; i++ {
+		user := "alice"
+		if i%10 == 0 {
+			user = "bob"
+		}
+		entry := engine.NewAuditEntry(engine.AuditActionPolicyUpdate, user)
+		entry.Time = start.Add(time.Duration(i) * time.Second)
+		entry.ID = engine.AuditEntryIDPrefix(entry.Time) + "-0000"
+		assert.NoError(t, reg.SaveAuditEntry(entry), "Audit entry should be saved")
+	}
+
+	testCases := []struct {
+		filter    *engine.AuditFilter
+		expected  int
+		first     int
+		maxLoaded int
+	}{
+		{&engine.AuditFilter{}, count, 0, count},
+		{&engine.AuditFilter{Limit: 10}, 10, count - 10, auditEntriesBatchSize},
+		{&engine.AuditFilter{User: "bob", Limit: 150}, 150, count - 1500, 2 * auditEntriesBatchSize},
+		{&engine.AuditFilter{Since: start.Add(100 * time.Second), Until: start.Add(199 * time.Second)}, 100, 100, 100},
+		{&engine.AuditFilter{Until: start.Add(99 * time.Second), Limit: 10}, 10, 90, 100},
+		{&engine.AuditFilter{Since: start.Add(time.Duration(count) * time.Second)}, 0, 0, 0},
+	}
+	for _, tc := range testCases {
+		s.loaded = 0
+		entries, err := reg.GetAuditEntries(tc.filter)
+		if !assert.NoError(t, err, "Audit entries should be retrieved for filter %+v", tc.filter) {
+			continue
+		}
+		if !assert.Equal(t, tc.expected, len(entries), "Correct number of audit entries should be retrieved for filter %+v", tc.filter) {
+			continue
+		}
+		if len(entries) > 0 {
+			assert.Equal(t, start.Add(time.Duration(tc.first)*time.Second), entries[0].Time, "Audit entries should start with correct one for filter %+v", tc.filter)
+		}
+		for i := 1; i < len(entries); i++ {
+			assert.True(t, entries[i-1].ID < entries[i].ID, "Audit entries should be ordered from the oldest to the most recent one")
+		}
+		assert.True(t, s.loaded <= tc.maxLoaded, "No more than %d audit entries should be loaded for filter %+v, but %d loaded", tc.maxLoaded, tc.filter, s.loaded)
+	}
+}
@@ -0,0 +1,42 @@
+package registry
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/runtime/store"
+)
+
+// GetOIDCUser returns user, who has logged in via OpenID Connect provider, or nil if the user has never logged in
+func (reg *defaultRegistry) GetOIDCUser(name string) (*engine.OIDCUser, error) {
+	var user *engine.OIDCUser
+	err := reg.store.Find(engine.TypeOIDCUser.Kind, &user, store.WithKey(runtime.KeyFromParts(runtime.SystemNS, engine.TypeOIDCUser.Kind, strings.ToLower(name))))
+	if err != nil {
+		return nil, fmt.Errorf("error while getting OpenID Connect user '%s': %s", name, err)
+	}
+
+	return user, nil
+}
+
+// GetAllOIDCUsers returns all users, who have logged in via OpenID Connect provider
+func (reg *defaultRegistry) GetAllOIDCUsers() ([]*engine.OIDCUser, error) {
+	var users []*engine.OIDCUser
+	err := reg.store.Find(engine.TypeOIDCUser.Kind, &users, store.WithKeyPrefix(runtime.KeyFromParts(runtime.SystemNS, engine.TypeOIDCUser.Kind, runtime.EmptyName)+runtime.KeySeparator))
+	if err != nil {
+		return nil, fmt.Errorf("error while getting all OpenID Connect users: %s", err)
+	}
+
+	return users, nil
+}
+
+// SaveOIDCUser creates or updates user, who has logged in via OpenID Connect provider
+func (reg *defaultRegistry) SaveOIDCUser(user *engine.OIDCUser) error {
+	_, err := reg.store.Save(user)
+	if err != nil {
+		return fmt.Errorf("error while saving OpenID Connect user '%s': %s", user.Name, err)
+	}
+
+	return nil
+}
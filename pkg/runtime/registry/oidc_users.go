@@ -0,0 +1,134 @@
+package registry
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/external/users"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/patrickmn/go-cache"
+)
+
+// oidcUserLoaderCacheTTL is how long users logged in via OpenID Connect provider are cached for. Users are loaded many
+// times during a single policy resolution and on every request authenticated with ID token
+const oidcUserLoaderCacheTTL = 10 * time.Second
+
+// OIDCUserLoader loads users, who have logged in via OpenID Connect provider. Users and their labels are mapped from
+// ID token claims, so a user becomes known to aptomi only after logging in. Users are stored in the registry and
+// their labels get refreshed on every login. Names of users from other sources can't be taken over this way, except
+// for users provisioned by identity provider (e.g. via SCIM), who log in as themselves
+type OIDCUserLoader struct {
+	registry             OIDCRegistry
+	otherSources         users.UserLoader
+	provisioned          ProvisionedUserLoader
+	domainAdminOverrides map[string]bool
+	cache                *cache.Cache
+}
+
+// NewOIDCUserLoader returns user loader, which loads users logged in via OpenID Connect provider from the registry.
+// Users with the same names as users from other sources are not allowed to log in via OpenID Connect provider. Users
+// provisioned by identity provider are allowed to log in, and they are loaded from provisioned user loader rather than
+// recorded, while deactivated ones are not allowed to log in. Both loaders can be nil
+func NewOIDCUserLoader(registry OIDCRegistry, otherSources users.UserLoader, provisioned ProvisionedUserLoader, domainAdminOverrides map[string]bool) *OIDCUserLoader {
+	return &OIDCUserLoader{
+		registry:             registry,
+		otherSources:         otherSources,
+		provisioned:          provisioned,
+		domainAdminOverrides: domainAdminOverrides,
+		cache:                cache.New(oidcUserLoaderCacheTTL, oidcUserLoaderCacheTTL),
+	}
+}
+
+// AddUser records a user, who has logged in via OpenID Connect provider. It fails if a user with the same name
+// comes from another source, so that identity provider can't be used to log in as that user. If the user has been
+// provisioned by identity provider, then it doesn't get recorded, as it's already known
+func (loader *OIDCUserLoader) AddUser(user *lang.User) error {
+	provisioned, err := loader.check(user.Name)
+	if err != nil || provisioned != nil {
+		return err
+	}
+
+	err = loader.registry.SaveOIDCUser(engine.NewOIDCUser(user))
+	loader.cache.Flush()
+	return err
+}
+
+// LoggedInUser returns a user with a given name, who has logged in via OpenID Connect provider before or has been
+// provisioned by identity provider. Unlike AddUser, it doesn't record anything, so it can be used on every request
+// authenticated with ID token
+func (loader *OIDCUserLoader) LoggedInUser(name string) (*lang.User, error) {
+	provisioned, err := loader.check(name)
+	if err != nil || provisioned != nil {
+		return provisioned, err
+	}
+
+	user := loader.LoadUserByName(name)
+	if user == nil {
+		return nil, fmt.Errorf("user '%s' should log in via OpenID Connect provider first", name)
+	}
+	return user, nil
+}
+
+// check returns an error if a user with a given name is not allowed to log in via OpenID Connect provider. If the
+// user has been provisioned by identity provider, it gets returned
+func (loader *OIDCUserLoader) check(name string) (*lang.User, error) {
+	if loader.provisioned != nil {
+		if user := loader.provisioned.LoadUserByName(name); user != nil {
+			return user, nil
+		}
+		if loader.provisioned.IsDeactivated(name) {
+			return nil, fmt.Errorf("user '%s' has been deactivated by identity provider", name)
+		}
+	}
+	if loader.otherSources != nil && loader.otherSources.LoadUserByName(name) != nil {
+		return nil, fmt.Errorf("user '%s' comes from another user source and can't log in via OpenID Connect provider", name)
+	}
+	return nil, nil
+}
+
+// LoadUsersAll loads all users
+func (loader *OIDCUserLoader) LoadUsersAll() *lang.GlobalUsers {
+	cachedUsers, found := loader.cache.Get("oidcUsers")
+	if found {
+		return cachedUsers.(*lang.GlobalUsers)
+	}
+
+	oidcUsers, err := loader.registry.GetAllOIDCUsers()
+	if err != nil {
+		// we need user data, but they cannot be loaded from the registry. for now, let's panic
+		panic(err)
+	}
+
+	result := &lang.GlobalUsers{Users: make(map[string]*lang.User)}
+	for _, oidcUser := range oidcUsers {
+		result.Users[strings.ToLower(oidcUser.Name)] = loader.user(oidcUser)
+	}
+	loader.cache.Set("oidcUsers", result, cache.DefaultExpiration)
+	return result
+}
+
+// LoadUserByName loads a single user by name
+func (loader *OIDCUserLoader) LoadUserByName(name string) *lang.User {
+	return loader.LoadUsersAll().Users[strings.ToLower(name)]
+}
+
+func (loader *OIDCUserLoader) user(oidcUser *engine.OIDCUser) *lang.User {
+	user := oidcUser.User()
+	if _, exist := loader.domainAdminOverrides[strings.ToLower(user.Name)]; exist {
+		user.DomainAdmin = true
+	}
+	return user
+}
+
+// Authenticate always fails, as users can only log in via OpenID Connect provider
+func (loader *OIDCUserLoader) Authenticate(name, password string) (*lang.User, error) {
+	return nil, fmt.Errorf("user '%s' can only log in via OpenID Connect provider", name)
+}
+
+// Summary returns summary as string
+func (loader *OIDCUserLoader) Summary() string {
+	return strconv.Itoa(len(loader.LoadUsersAll().Users)) + " (oidc)"
+}
@@ -0,0 +1,150 @@
+package registry
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/external/scim"
+	"github.com/Aptomi/aptomi/pkg/external/users"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/stretchr/testify/assert"
+)
+
+type oidcRegistryMock struct {
+	users map[string]*engine.OIDCUser
+}
+
+func (reg *oidcRegistryMock) GetOIDCUser(name string) (*engine.OIDCUser, error) {
+	return reg.users[strings.ToLower(name)], nil
+}
+
+func (reg *oidcRegistryMock) GetAllOIDCUsers() ([]*engine.OIDCUser, error) {
+	result := []*engine.OIDCUser{}
+	for _, user := range reg.users {
+		result = append(result, user)
+	}
+	return result, nil
+}
+
+func (reg *oidcRegistryMock) SaveOIDCUser(user *engine.OIDCUser) error {
+	reg.users[user.GetName()] = user
+	return nil
+}
+
+func TestOIDCUserLoader(t *testing.T) {
+	reg := &oidcRegistryMock{users: make(map[string]*engine.OIDCUser)}
+	loader := NewOIDCUserLoader(reg, nil, nil, map[string]bool{"admin@example.com": true})
+	assert.Equal(t, 0, len(loader.LoadUsersAll().Users), "Users should be unknown until they log in")
+
+	assert.NoError(t, loader.AddUser(&lang.User{Name: "Alice@example.com", Labels: map[string]string{"team": "dev"}}))
+	assert.NoError(t, loader.AddUser(&lang.User{Name: "admin@example.com"}))
+	assert.Equal(t, 2, len(loader.LoadUsersAll().Users), "Logged in users should be loaded")
+
+	alice := loader.LoadUserByName("alice@EXAMPLE.com")
+	if assert.NotNil(t, alice, "User should be loaded by name") {
+		assert.Equal(t, "dev", alice.Labels["team"], "User labels should be loaded")
+		assert.False(t, alice.DomainAdmin, "User should not be domain admin")
+	}
+	assert.True(t, loader.LoadUserByName("admin@example.com").DomainAdmin, "Domain admin override should be applied")
+
+	// labels get refreshed on every login
+	assert.NoError(t, loader.AddUser(&lang.User{Name: "alice@example.com", Labels: map[string]string{"team": "ops"}}))
+	assert.Equal(t, "ops", loader.LoadUserByName("Alice@example.com").Labels["team"], "User labels should be refreshed on login")
+	assert.Equal(t, 2, len(loader.LoadUsersAll().Users), "User should be recorded only once")
+
+	// users are stored in the registry, so they are known to a new loader (e.g. after restart)
+	restarted := NewOIDCUserLoader(reg, nil, nil, map[string]bool{"admin@example.com": true})
+	assert.Equal(t, 2, len(restarted.LoadUsersAll().Users), "Users should be loaded from the registry after restart")
+	assert.Equal(t, "ops", restarted.LoadUserByName("alice@example.com").Labels["team"], "User labels should be loaded from the registry after restart")
+
+	// users who have logged in are looked up without being recorded again
+	_, err := restarted.LoggedInUser("bob@example.com")
+	assert.Error(t, err, "User should not be known before logging in")
+	user, err := restarted.LoggedInUser("Alice@example.com")
+	if assert.NoError(t, err, "User should be known after logging in") {
+		assert.Equal(t, "ops", user.Labels["team"], "User labels should be loaded")
+	}
+
+	// users from other sources can't log in via OpenID Connect provider
+	others := users.NewUserLoaderMock()
+	others.AddUser(&lang.User{Name: "bob@example.com", DomainAdmin: true})
+	loader = NewOIDCUserLoader(reg, others, nil, nil)
+	assert.Error(t, loader.AddUser(&lang.User{Name: "Bob@example.com"}), "User from another source should not be taken over")
+	assert.Nil(t, loader.LoadUserByName("bob@example.com"), "User from another source should not be recorded")
+	_, err = loader.LoggedInUser("bob@example.com")
+	assert.Error(t, err, "User from another source should not be accepted")
+
+	_, err = loader.Authenticate("alice@example.com", "password")
+	assert.Error(t, err, "Users should not be able to log in with password")
+}
+
+type scimRegistryMock struct {
+	resources []*engine.SCIMResource
+}
+
+func (reg *scimRegistryMock) GetSCIMResource(resourceType string, id string) (*engine.SCIMResource, error) {
+	for _, resource := range reg.resources {
+		if resource.ResourceType == resourceType && resource.ID == id {
+			return resource, nil
+		}
+	}
+	return nil, nil
+}
+
+func (reg *scimRegistryMock) GetSCIMResources(resourceType string) ([]*engine.SCIMResource, error) {
+	result := []*engine.SCIMResource{}
+	for _, resource := range reg.resources {
+		if resource.ResourceType == resourceType {
+			result = append(result, resource)
+		}
+	}
+	return result, nil
+}
+
+func (reg *scimRegistryMock) SaveSCIMResource(resource *engine.SCIMResource) error {
+	reg.resources = append(reg.resources, resource)
+	return nil
+}
+
+func (reg *scimRegistryMock) DeleteSCIMResource(resourceType string, id string) error {
+	return nil
+}
+
+func TestOIDCUserLoaderSCIMUsers(t *testing.T) {
+	scimReg := &scimRegistryMock{resources: []*engine.SCIMResource{
+		{ID: "1", ResourceType: scim.ResourceUser, Name: "carol@example.com", Data: `{"userName": "carol@example.com", "title": "lead"}`},
+		{ID: "2", ResourceType: scim.ResourceUser, Name: "dave@example.com", Data: `{"userName": "dave@example.com", "active": false}`},
+		{ID: "3", ResourceType: scim.ResourceGroup, Name: "developers", Data: `{"displayName": "developers", "members": [{"value": "1"}]}`},
+	}}
+	scimUsers := NewSCIMUserLoader(scimReg, config.SCIM{LabelToAttributes: map[string]string{"title": "title"}}, nil)
+
+	// SCIM users are loaded before users logged in via OpenID Connect provider, the same way as server does it
+	reg := &oidcRegistryMock{users: make(map[string]*engine.OIDCUser)}
+	loader := NewOIDCUserLoader(reg, users.NewUserLoaderMock(), scimUsers, nil)
+	all := users.NewUserLoaderMultipleSources([]users.UserLoader{scimUsers, loader})
+
+	// SCIM user logs in via OpenID Connect provider as itself
+	assert.NoError(t, loader.AddUser(&lang.User{Name: "Carol@example.com", Labels: map[string]string{"title": "intern"}}), "SCIM user should be able to log in via OpenID Connect provider")
+	assert.Equal(t, 0, len(reg.users), "SCIM user should not be recorded as OpenID Connect user")
+	carol := all.LoadUserByName("carol@example.com")
+	if assert.NotNil(t, carol, "SCIM user should be loaded after logging in via OpenID Connect provider") {
+		assert.Equal(t, "lead", carol.Labels["title"], "SCIM user labels should be used")
+		assert.Equal(t, []string{"developers"}, carol.Groups, "SCIM user groups should be used")
+	}
+
+	// deactivated SCIM user can't log in
+	assert.Error(t, loader.AddUser(&lang.User{Name: "dave@example.com"}), "Deactivated SCIM user should not be able to log in")
+	assert.Nil(t, all.LoadUserByName("dave@example.com"), "Deactivated SCIM user should not be loaded")
+	_, err := loader.LoggedInUser("dave@example.com")
+	assert.Error(t, err, "Deactivated SCIM user should not be accepted")
+	carol, err = loader.LoggedInUser("carol@example.com")
+	if assert.NoError(t, err, "SCIM user should be accepted") {
+		assert.Equal(t, "lead", carol.Labels["title"], "SCIM user labels should be used")
+	}
+
+	// users not provisioned via SCIM are still recorded
+	assert.NoError(t, loader.AddUser(&lang.User{Name: "erin@example.com"}))
+	assert.NotNil(t, all.LoadUserByName("erin@example.com"), "User logged in via OpenID Connect provider should be loaded")
+}
@@ -14,6 +14,11 @@ type Interface interface {
 	PolicyRegistry
 	RevisionRegistry
 	ActualStateRegistry
+	AuthRegistry
+	AuditRegistry
+	SecretRegistry
+	SCIMRegistry
+	OIDCRegistry
 }
 
 // PolicyRegistry represents database operations for Policy object
@@ -42,3 +47,45 @@ type ActualStateRegistry interface {
 	GetActualState() (*resolve.PolicyResolution, error)
 	NewActualStateUpdater(*resolve.PolicyResolution) actual.StateUpdater
 }
+
+// AuthRegistry represents database operations for service accounts and API tokens
+type AuthRegistry interface {
+	GetServiceAccount(name string) (*engine.ServiceAccount, error)
+	GetAllServiceAccounts() ([]*engine.ServiceAccount, error)
+	SaveServiceAccount(sa *engine.ServiceAccount) error
+	DeleteServiceAccount(name string, performedBy string) error
+	GetAPIToken(id string) (*engine.APIToken, error)
+	GetAllAPITokens() ([]*engine.APIToken, error)
+	SaveAPIToken(token *engine.APIToken) error
+	RevokeAPIToken(token *engine.APIToken, performedBy string) error
+}
+
+// AuditRegistry represents database operations for the audit log
+type AuditRegistry interface {
+	SaveAuditEntry(entry *engine.AuditEntry) error
+	GetAuditEntries(filter *engine.AuditFilter) ([]*engine.AuditEntry, error)
+}
+
+// SecretRegistry represents database operations for user secrets
+type SecretRegistry interface {
+	GetSecret(user string, name string) (*engine.Secret, error)
+	GetSecrets(user string) ([]*engine.Secret, error)
+	GetAllSecrets() ([]*engine.Secret, error)
+	SaveSecret(secret *engine.Secret) error
+	DeleteSecret(user string, name string) error
+}
+
+// SCIMRegistry represents database operations for users and groups pushed by identity providers via SCIM
+type SCIMRegistry interface {
+	GetSCIMResource(resourceType string, id string) (*engine.SCIMResource, error)
+	GetSCIMResources(resourceType string) ([]*engine.SCIMResource, error)
+	SaveSCIMResource(resource *engine.SCIMResource) error
+	DeleteSCIMResource(resourceType string, id string) error
+}
+
+// OIDCRegistry represents database operations for users, who have logged in via OpenID Connect provider
+type OIDCRegistry interface {
+	GetOIDCUser(name string) (*engine.OIDCUser, error)
+	GetAllOIDCUsers() ([]*engine.OIDCUser, error)
+	SaveOIDCUser(user *engine.OIDCUser) error
+}
@@ -51,12 +51,66 @@ func (updater *RevisionResultUpdaterImpl) AddSkipped() {
 	updater.save()
 }
 
+// AddTimedOut safely increments the number of timed out actions
+func (updater *RevisionResultUpdaterImpl) AddTimedOut() {
+	atomic.AddUint32(&updater.revision.Result.TimedOut, 1)
+	updater.save()
+}
+
+// AddHeld safely records that an action has been held for a given reason and saves the revision
+func (updater *RevisionResultUpdaterImpl) AddHeld(name string, reason string) {
+	updater.mutex.Lock()
+	updater.revision.Result.AddHeld(name, reason)
+	updater.mutex.Unlock()
+	updater.save()
+}
+
+// AddBlocked safely records that an action has been blocked for a given reason and saves the revision
+func (updater *RevisionResultUpdaterImpl) AddBlocked(name string, reason string) {
+	updater.mutex.Lock()
+	updater.revision.Result.AddBlocked(name, reason)
+	updater.mutex.Unlock()
+	updater.save()
+}
+
+// SetCancelled safely marks apply as cancelled and saves the revision
+func (updater *RevisionResultUpdaterImpl) SetCancelled() {
+	updater.mutex.Lock()
+	updater.revision.Result.Cancelled = true
+	updater.mutex.Unlock()
+	updater.save()
+}
+
+// AddAttempts safely records the number of attempts made for a retried action and saves the revision
+func (updater *RevisionResultUpdaterImpl) AddAttempts(name string, attempts int, exhausted bool) {
+	updater.mutex.Lock()
+	updater.revision.Result.AddAttempts(name, attempts, exhausted)
+	updater.mutex.Unlock()
+	updater.save()
+}
+
+// UpdateRollout safely updates progress of a rollout with a given name and saves the revision
+func (updater *RevisionResultUpdaterImpl) UpdateRollout(name string, update func(*action.RolloutResult)) {
+	updater.mutex.Lock()
+	updater.revision.Result.UpdateRollout(name, update)
+	updater.mutex.Unlock()
+	updater.save()
+}
+
 // Done saves the revision when all actions have been processed
 func (updater *RevisionResultUpdaterImpl) Done() *action.ApplyResult {
-	if updater.revision.Result.Success+updater.revision.Result.Failed+updater.revision.Result.Skipped != updater.revision.Result.Total {
-		panic(fmt.Sprintf("error while applying actions: %d (success) + %d (failed) + %d (skipped) != %d (total)", updater.revision.Result.Success, updater.revision.Result.Failed, updater.revision.Result.Skipped, updater.revision.Result.Total))
+	if updater.revision.Result.Processed() != updater.revision.Result.Total {
+		panic(fmt.Sprintf("error while applying actions: %d (success) + %d (failed) + %d (timed out) + %d (held) + %d (blocked) + %d (skipped) != %d (total)", updater.revision.Result.Success, updater.revision.Result.Failed, updater.revision.Result.TimedOut, updater.revision.Result.Held, updater.revision.Result.Blocked, updater.revision.Result.Skipped, updater.revision.Result.Total))
+	}
+	if updater.revision.Result.Cancelled {
+		// apply has been cancelled, so the revision will not be retried automatically
+		updater.revision.Status = engine.RevisionStatusCancelled
+	} else if updater.revision.Result.Exhausted > 0 {
+		// some actions kept failing after all retries, so the revision will not be retried automatically
+		updater.revision.Status = engine.RevisionStatusFailed
+	} else {
+		updater.revision.Status = engine.RevisionStatusCompleted
 	}
-	updater.revision.Status = engine.RevisionStatusCompleted
 	updater.revision.AppliedAt = time.Now()
 	updater.save()
 	return updater.revision.Result
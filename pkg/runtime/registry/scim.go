@@ -0,0 +1,59 @@
+package registry
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/runtime/store"
+)
+
+// GetSCIMResource returns SCIM resource of a given type with a given ID or nil if it doesn't exist
+func (reg *defaultRegistry) GetSCIMResource(resourceType string, id string) (*engine.SCIMResource, error) {
+	var resource *engine.SCIMResource
+	err := reg.store.Find(engine.TypeSCIMResource.Kind, &resource, store.WithKey(runtime.KeyFromParts(runtime.SystemNS, engine.TypeSCIMResource.Kind, engine.SCIMResourceName(resourceType, id))))
+	if err != nil {
+		return nil, fmt.Errorf("error while getting SCIM %s '%s': %s", resourceType, id, err)
+	}
+
+	return resource, nil
+}
+
+// GetSCIMResources returns all SCIM resources of a given type, sorted by creation time
+func (reg *defaultRegistry) GetSCIMResources(resourceType string) ([]*engine.SCIMResource, error) {
+	var resources []*engine.SCIMResource
+	err := reg.store.Find(engine.TypeSCIMResource.Kind, &resources, store.WithKeyPrefix(runtime.KeyFromParts(runtime.SystemNS, engine.TypeSCIMResource.Kind, engine.SCIMResourceName(resourceType, ""))))
+	if err != nil {
+		return nil, fmt.Errorf("error while getting SCIM %s resources: %s", resourceType, err)
+	}
+
+	sort.Slice(resources, func(i, j int) bool {
+		if resources[i].CreatedAt.Equal(resources[j].CreatedAt) {
+			return resources[i].ID < resources[j].ID
+		}
+		return resources[i].CreatedAt.Before(resources[j].CreatedAt)
+	})
+
+	return resources, nil
+}
+
+// SaveSCIMResource creates or updates SCIM resource
+func (reg *defaultRegistry) SaveSCIMResource(resource *engine.SCIMResource) error {
+	_, err := reg.store.Save(resource)
+	if err != nil {
+		return fmt.Errorf("error while saving SCIM %s '%s': %s", resource.ResourceType, resource.ID, err)
+	}
+
+	return nil
+}
+
+// DeleteSCIMResource deletes SCIM resource of a given type with a given ID
+func (reg *defaultRegistry) DeleteSCIMResource(resourceType string, id string) error {
+	err := reg.store.Delete(engine.TypeSCIMResource.Kind, runtime.KeyFromParts(runtime.SystemNS, engine.TypeSCIMResource.Kind, engine.SCIMResourceName(resourceType, id)))
+	if err != nil {
+		return fmt.Errorf("error while deleting SCIM %s '%s': %s", resourceType, id, err)
+	}
+
+	return nil
+}
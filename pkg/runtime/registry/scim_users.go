@@ -0,0 +1,151 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/external/scim"
+	"github.com/Aptomi/aptomi/pkg/external/users"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/patrickmn/go-cache"
+	log "github.com/sirupsen/logrus"
+)
+
+// scimUserLoaderCacheTTL is how long users mapped from SCIM resources are cached for. Users are loaded many times
+// during a single policy resolution, so it saves the registry from being queried over and over again
+const scimUserLoaderCacheTTL = 10 * time.Second
+
+// scimUserLoader exposes users pushed by identity providers via SCIM endpoint as users. Users get groups they are
+// members of, and labels mapped from SCIM attributes. Deactivated users are not loaded
+type scimUserLoader struct {
+	registry             SCIMRegistry
+	cfg                  config.SCIM
+	domainAdminOverrides map[string]bool
+	cache                *cache.Cache
+}
+
+// ProvisionedUserLoader loads users provisioned by identity provider (e.g. via SCIM). Such users log in via identity
+// provider, so it also tells which users have been deactivated there
+type ProvisionedUserLoader interface {
+	users.UserLoader
+
+	// IsDeactivated returns true if a user with a given name has been provisioned, but then deactivated
+	IsDeactivated(name string) bool
+}
+
+// scimUsers is a set of users mapped from SCIM resources, along with names of deactivated users
+type scimUsers struct {
+	users       *lang.GlobalUsers
+	deactivated map[string]bool
+}
+
+// NewSCIMUserLoader returns user loader, which loads users pushed via SCIM endpoint from the registry
+func NewSCIMUserLoader(registry SCIMRegistry, cfg config.SCIM, domainAdminOverrides map[string]bool) ProvisionedUserLoader {
+	return &scimUserLoader{
+		registry:             registry,
+		cfg:                  cfg,
+		domainAdminOverrides: domainAdminOverrides,
+		cache:                cache.New(scimUserLoaderCacheTTL, scimUserLoaderCacheTTL),
+	}
+}
+
+// LoadUsersAll loads all active SCIM users
+func (loader *scimUserLoader) LoadUsersAll() *lang.GlobalUsers {
+	return loader.load().users
+}
+
+// IsDeactivated returns true if SCIM user with a given name has been deactivated by identity provider
+func (loader *scimUserLoader) IsDeactivated(name string) bool {
+	return loader.load().deactivated[strings.ToLower(name)]
+}
+
+func (loader *scimUserLoader) load() *scimUsers {
+	cachedUsers, found := loader.cache.Get("scimUsers")
+	if found {
+		return cachedUsers.(*scimUsers)
+	}
+
+	userResources, err := loader.registry.GetSCIMResources(scim.ResourceUser)
+	if err != nil {
+		// we need user data, but they cannot be loaded from the registry. for now, let's panic
+		panic(err)
+	}
+	groupResources, err := loader.registry.GetSCIMResources(scim.ResourceGroup)
+	if err != nil {
+		panic(err)
+	}
+
+	groups := make(map[string][]string)
+	for _, group := range groupResources {
+		resource, parseErr := parseSCIMResource(group.Data)
+		if parseErr != nil {
+			log.Errorf("Unable to parse SCIM group '%s': %s", group.ID, parseErr)
+			continue
+		}
+		for _, id := range resource.MemberIDs() {
+			groups[id] = append(groups[id], group.Name)
+		}
+	}
+
+	result := &scimUsers{
+		users:       &lang.GlobalUsers{Users: make(map[string]*lang.User)},
+		deactivated: make(map[string]bool),
+	}
+	for _, u := range userResources {
+		resource, parseErr := parseSCIMResource(u.Data)
+		if parseErr != nil {
+			log.Errorf("Unable to parse SCIM user '%s': %s", u.ID, parseErr)
+			continue
+		}
+		if !resource.Active() {
+			result.deactivated[strings.ToLower(u.Name)] = true
+			continue
+		}
+
+		attrs := resource.Attributes()
+		user := &lang.User{
+			Name:   u.Name,
+			Labels: make(map[string]string),
+			Groups: groups[u.ID],
+		}
+		for label, attr := range loader.cfg.LabelToAttributes {
+			if value, ok := attrs[strings.ToLower(attr)]; ok {
+				user.Labels[label] = value
+			}
+		}
+		sort.Strings(user.Groups)
+		if _, exist := loader.domainAdminOverrides[strings.ToLower(user.Name)]; exist {
+			user.DomainAdmin = true
+		}
+		result.users.Users[strings.ToLower(user.Name)] = user
+	}
+
+	loader.cache.Set("scimUsers", result, cache.DefaultExpiration)
+	return result
+}
+
+// LoadUserByName loads a single SCIM user by name
+func (loader *scimUserLoader) LoadUserByName(name string) *lang.User {
+	return loader.LoadUsersAll().Users[strings.ToLower(name)]
+}
+
+// Authenticate always fails, as SCIM users have no passwords and should log in via identity provider
+func (loader *scimUserLoader) Authenticate(name, password string) (*lang.User, error) {
+	return nil, fmt.Errorf("user '%s' can only log in via identity provider", name)
+}
+
+// Summary returns summary as string
+func (loader *scimUserLoader) Summary() string {
+	return strconv.Itoa(len(loader.LoadUsersAll().Users)) + " (scim)"
+}
+
+func parseSCIMResource(data string) (scim.Resource, error) {
+	resource := scim.Resource{}
+	err := json.Unmarshal([]byte(data), &resource)
+	return resource, err
+}
@@ -0,0 +1,65 @@
+package registry
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/runtime/store"
+)
+
+// GetSecret returns secret with a given name of a given user or nil if it doesn't exist
+func (reg *defaultRegistry) GetSecret(user string, name string) (*engine.Secret, error) {
+	var secret *engine.Secret
+	err := reg.store.Find(engine.TypeSecret.Kind, &secret, store.WithKey(runtime.KeyFromParts(runtime.SystemNS, engine.TypeSecret.Kind, engine.SecretName(user, name))))
+	if err != nil {
+		return nil, fmt.Errorf("error while getting secret '%s' of user '%s': %s", name, user, err)
+	}
+
+	return secret, nil
+}
+
+// GetSecrets returns all secrets of a given user, sorted by name
+func (reg *defaultRegistry) GetSecrets(user string) ([]*engine.Secret, error) {
+	return reg.findSecrets(runtime.KeyFromParts(runtime.SystemNS, engine.TypeSecret.Kind, engine.SecretName(user, "")))
+}
+
+// GetAllSecrets returns secrets of all users, sorted by user and name
+func (reg *defaultRegistry) GetAllSecrets() ([]*engine.Secret, error) {
+	return reg.findSecrets(runtime.KeyFromParts(runtime.SystemNS, engine.TypeSecret.Kind, runtime.EmptyName) + runtime.KeySeparator)
+}
+
+func (reg *defaultRegistry) findSecrets(keyPrefix string) ([]*engine.Secret, error) {
+	var secrets []*engine.Secret
+	err := reg.store.Find(engine.TypeSecret.Kind, &secrets, store.WithKeyPrefix(keyPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("error while getting secrets: %s", err)
+	}
+
+	sort.Slice(secrets, func(i, j int) bool {
+		return secrets[i].GetName() < secrets[j].GetName()
+	})
+
+	return secrets, nil
+}
+
+// SaveSecret creates or updates secret
+func (reg *defaultRegistry) SaveSecret(secret *engine.Secret) error {
+	_, err := reg.store.Save(secret)
+	if err != nil {
+		return fmt.Errorf("error while saving secret '%s' of user '%s': %s", secret.Name, secret.User, err)
+	}
+
+	return nil
+}
+
+// DeleteSecret deletes secret with a given name of a given user
+func (reg *defaultRegistry) DeleteSecret(user string, name string) error {
+	err := reg.store.Delete(engine.TypeSecret.Kind, runtime.KeyFromParts(runtime.SystemNS, engine.TypeSecret.Kind, engine.SecretName(user, name)))
+	if err != nil {
+		return fmt.Errorf("error while deleting secret '%s' of user '%s': %s", name, user, err)
+	}
+
+	return nil
+}
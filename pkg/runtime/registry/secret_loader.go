@@ -0,0 +1,59 @@
+package registry
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/external/secrets"
+	"github.com/patrickmn/go-cache"
+	log "github.com/sirupsen/logrus"
+)
+
+// secretLoaderCacheTTL is how long decrypted secrets of a user are cached for. Secrets are loaded many times during
+// a single policy resolution, so it saves the registry from being queried over and over again
+const secretLoaderCacheTTL = 10 * time.Second
+
+// secretLoader exposes secrets stored encrypted in the registry to the engine
+type secretLoader struct {
+	registry SecretRegistry
+	envelope *secrets.Envelope
+	cache    *cache.Cache
+}
+
+// NewSecretLoader returns secret loader, which loads secrets from the registry and decrypts them with a given envelope
+func NewSecretLoader(registry SecretRegistry, envelope *secrets.Envelope) secrets.SecretLoader {
+	return &secretLoader{
+		registry: registry,
+		envelope: envelope,
+		cache:    cache.New(secretLoaderCacheTTL, secretLoaderCacheTTL),
+	}
+}
+
+// LoadSecretsByUserName loads and decrypts secrets of a single user. Secrets, which can't be decrypted (e.g. if master
+// key has been changed), are skipped
+func (loader *secretLoader) LoadSecretsByUserName(user string) map[string]string {
+	cached, found := loader.cache.Get(strings.ToLower(user))
+	if found {
+		return cached.(map[string]string)
+	}
+
+	userSecrets, err := loader.registry.GetSecrets(user)
+	if err != nil {
+		// we need secrets, but they cannot be loaded from the registry. for now, let's panic
+		panic(err)
+	}
+
+	result := make(map[string]string)
+	for _, secret := range userSecrets {
+		value, openErr := loader.envelope.Open(secret.Sealed, engine.SecretName(secret.User, secret.Name))
+		if openErr != nil {
+			log.Errorf("Unable to decrypt secret '%s' of user '%s': %s", secret.Name, secret.User, openErr)
+			continue
+		}
+		result[secret.Name] = value
+	}
+
+	loader.cache.Set(strings.ToLower(user), result, cache.DefaultExpiration)
+	return result
+}
@@ -0,0 +1,107 @@
+package registry
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/runtime/store"
+)
+
+// GetServiceAccount returns service account with a given name or nil if it doesn't exist
+func (reg *defaultRegistry) GetServiceAccount(name string) (*engine.ServiceAccount, error) {
+	var sa *engine.ServiceAccount
+	err := reg.store.Find(engine.TypeServiceAccount.Kind, &sa, store.WithKey(runtime.KeyFromParts(runtime.SystemNS, engine.TypeServiceAccount.Kind, name)))
+	if err != nil {
+		return nil, fmt.Errorf("error while getting service account '%s': %s", name, err)
+	}
+
+	return sa, nil
+}
+
+// GetAllServiceAccounts returns all service accounts
+func (reg *defaultRegistry) GetAllServiceAccounts() ([]*engine.ServiceAccount, error) {
+	var serviceAccounts []*engine.ServiceAccount
+	err := reg.store.Find(engine.TypeServiceAccount.Kind, &serviceAccounts, store.WithKeyPrefix(runtime.KeyFromParts(runtime.SystemNS, engine.TypeServiceAccount.Kind, runtime.EmptyName)+runtime.KeySeparator))
+	if err != nil {
+		return nil, fmt.Errorf("error while getting all service accounts: %s", err)
+	}
+
+	return serviceAccounts, nil
+}
+
+// SaveServiceAccount creates or updates service account
+func (reg *defaultRegistry) SaveServiceAccount(sa *engine.ServiceAccount) error {
+	_, err := reg.store.Save(sa)
+	if err != nil {
+		return fmt.Errorf("error while saving service account '%s': %s", sa.Name, err)
+	}
+
+	return nil
+}
+
+// DeleteServiceAccount deletes service account and revokes all of its API tokens
+func (reg *defaultRegistry) DeleteServiceAccount(name string, performedBy string) error {
+	tokens, err := reg.GetAllAPITokens()
+	if err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		if token.Owner == name && !token.Revoked {
+			err = reg.RevokeAPIToken(token, performedBy)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err = reg.store.Delete(engine.TypeServiceAccount.Kind, runtime.KeyFromParts(runtime.SystemNS, engine.TypeServiceAccount.Kind, name))
+	if err != nil {
+		return fmt.Errorf("error while deleting service account '%s': %s", name, err)
+	}
+
+	return nil
+}
+
+// GetAPIToken returns API token with a given ID or nil if it doesn't exist
+func (reg *defaultRegistry) GetAPIToken(id string) (*engine.APIToken, error) {
+	var token *engine.APIToken
+	err := reg.store.Find(engine.TypeAPIToken.Kind, &token, store.WithKey(runtime.KeyFromParts(runtime.SystemNS, engine.TypeAPIToken.Kind, id)))
+	if err != nil {
+		return nil, fmt.Errorf("error while getting API token '%s': %s", id, err)
+	}
+
+	return token, nil
+}
+
+// GetAllAPITokens returns all API tokens, including revoked and expired ones
+func (reg *defaultRegistry) GetAllAPITokens() ([]*engine.APIToken, error) {
+	var tokens []*engine.APIToken
+	err := reg.store.Find(engine.TypeAPIToken.Kind, &tokens, store.WithKeyPrefix(runtime.KeyFromParts(runtime.SystemNS, engine.TypeAPIToken.Kind, runtime.EmptyName)+runtime.KeySeparator))
+	if err != nil {
+		return nil, fmt.Errorf("error while getting all API tokens: %s", err)
+	}
+
+	return tokens, nil
+}
+
+// SaveAPIToken creates or updates API token
+func (reg *defaultRegistry) SaveAPIToken(token *engine.APIToken) error {
+	_, err := reg.store.Save(token)
+	if err != nil {
+		return fmt.Errorf("error while saving API token '%s': %s", token.ID, err)
+	}
+
+	return nil
+}
+
+// RevokeAPIToken marks API token as revoked. Revoked tokens are kept in the registry, so it's always possible to see
+// who has revoked them and when
+func (reg *defaultRegistry) RevokeAPIToken(token *engine.APIToken, performedBy string) error {
+	token.Revoked = true
+	token.RevokedBy = performedBy
+	token.RevokedAt = time.Now()
+
+	return reg.SaveAPIToken(token)
+}
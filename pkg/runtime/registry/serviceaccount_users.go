@@ -0,0 +1,58 @@
+package registry
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Aptomi/aptomi/pkg/external/users"
+	"github.com/Aptomi/aptomi/pkg/lang"
+)
+
+// serviceAccountUserLoader exposes service accounts stored in the registry as users, so they could be referred to from
+// ACL rules and own claims
+type serviceAccountUserLoader struct {
+	registry AuthRegistry
+}
+
+// NewServiceAccountUserLoader returns user loader, which loads service accounts from the registry
+func NewServiceAccountUserLoader(registry AuthRegistry) users.UserLoader {
+	return &serviceAccountUserLoader{registry: registry}
+}
+
+// LoadUsersAll loads all service accounts
+func (loader *serviceAccountUserLoader) LoadUsersAll() *lang.GlobalUsers {
+	serviceAccounts, err := loader.registry.GetAllServiceAccounts()
+	if err != nil {
+		// we need user data, but they cannot be loaded from the registry. for now, let's panic
+		panic(err)
+	}
+
+	result := &lang.GlobalUsers{Users: make(map[string]*lang.User)}
+	for _, sa := range serviceAccounts {
+		result.Users[strings.ToLower(sa.Name)] = sa.User()
+	}
+	return result
+}
+
+// LoadUserByName loads a single service account by name
+func (loader *serviceAccountUserLoader) LoadUserByName(name string) *lang.User {
+	sa, err := loader.registry.GetServiceAccount(strings.ToLower(name))
+	if err != nil {
+		panic(err)
+	}
+	if sa == nil {
+		return nil
+	}
+	return sa.User()
+}
+
+// Authenticate always fails, as service accounts can only use API tokens
+func (loader *serviceAccountUserLoader) Authenticate(name, password string) (*lang.User, error) {
+	return nil, fmt.Errorf("service account '%s' can only use API tokens", name)
+}
+
+// Summary returns summary as string
+func (loader *serviceAccountUserLoader) Summary() string {
+	return strconv.Itoa(len(loader.LoadUsersAll().Users)) + " (service accounts)"
+}
@@ -274,7 +274,29 @@ func (s *etcdStore) findByKeyPrefix(findOpts *store.FindOpts, info *runtime.Type
 		return fmt.Errorf("searching with key prefix is only supported for non versioned objects")
 	}
 
-	resp, err := s.client.KV.Get(context.TODO(), "/object"+"/"+findOpts.GetKeyPrefix(), etcd.WithPrefix())
+	// search within key prefix, narrowed down to key range if it's specified
+	start := "/object" + "/" + findOpts.GetKeyPrefix()
+	end := etcd.GetPrefixRangeEnd(start)
+	from, to := findOpts.GetKeyRange()
+	if from != "" && "/object"+"/"+from > start {
+		start = "/object" + "/" + from
+	}
+	if to != "" && "/object"+"/"+to < end {
+		end = "/object" + "/" + to
+	}
+	if start >= end {
+		return nil
+	}
+
+	etcdOpts := []etcd.OpOption{etcd.WithRange(end)}
+	if findOpts.GetLimit() > 0 {
+		etcdOpts = append(etcdOpts, etcd.WithLimit(int64(findOpts.GetLimit())))
+	}
+	if findOpts.IsDescending() {
+		etcdOpts = append(etcdOpts, etcd.WithSort(etcd.SortByKey, etcd.SortDescend))
+	}
+
+	resp, err := s.client.KV.Get(context.TODO(), start, etcdOpts...)
 	if err != nil {
 		return err
 	}
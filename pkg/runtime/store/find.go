@@ -16,6 +16,10 @@ type FindOpts struct {
 	fieldEqValues []interface{}
 	getLast       bool
 	getFirst      bool
+	keyRangeFrom  runtime.Key
+	keyRangeTo    runtime.Key
+	limit         int
+	descending    bool
 }
 
 // GetKeyPrefix returns key prefix to find objects with keys prefixed by it
@@ -23,6 +27,22 @@ func (opts *FindOpts) GetKeyPrefix() runtime.Key {
 	return opts.keyPrefix
 }
 
+// GetKeyRange returns range of keys [from, to) to find objects with keys prefixed by key prefix. Empty key means that
+// the range isn't bounded from that side
+func (opts *FindOpts) GetKeyRange() (from runtime.Key, to runtime.Key) {
+	return opts.keyRangeFrom, opts.keyRangeTo
+}
+
+// GetLimit returns max number of objects to find when searching with key prefix (0 means no limit)
+func (opts *FindOpts) GetLimit() int {
+	return opts.limit
+}
+
+// IsDescending returns true if objects found with key prefix should be returned in descending order of their keys
+func (opts *FindOpts) IsDescending() bool {
+	return opts.descending
+}
+
 // GetKey returns key to find objects with it
 func (opts *FindOpts) GetKey() runtime.Key {
 	return opts.key
@@ -168,3 +188,51 @@ func WithGetLast() FindOpt {
 		opts.getLast = true
 	}
 }
+
+// WithKeyRange defines range of keys [from, to) to find objects with keys prefixed with key prefix. Empty key means
+// that the range isn't bounded from that side
+func WithKeyRange(from runtime.Key, to runtime.Key) FindOpt {
+	return func(opts *FindOpts) {
+		if opts.keyPrefix == "" {
+			panic("can't use WithKeyRange without WithKeyPrefix (key prefix isn't set)")
+		}
+		if opts.keyRangeFrom != "" || opts.keyRangeTo != "" {
+			panic("can't use WithKeyRange more then one time")
+		}
+
+		opts.keyRangeFrom = from
+		opts.keyRangeTo = to
+	}
+}
+
+// WithLimit defines max number of objects to find with key prefix
+func WithLimit(limit int) FindOpt {
+	return func(opts *FindOpts) {
+		if opts.keyPrefix == "" {
+			panic("can't use WithLimit without WithKeyPrefix (key prefix isn't set)")
+		}
+		if limit <= 0 {
+			panic("can't use WithLimit with non-positive limit")
+		}
+		if opts.limit != 0 {
+			panic("can't use WithLimit more then one time")
+		}
+
+		opts.limit = limit
+	}
+}
+
+// WithDescendingOrder defines that objects found with key prefix should be returned in descending order of their keys
+// (e.g. to get the last objects with WithLimit)
+func WithDescendingOrder() FindOpt {
+	return func(opts *FindOpts) {
+		if opts.keyPrefix == "" {
+			panic("can't use WithDescendingOrder without WithKeyPrefix (key prefix isn't set)")
+		}
+		if opts.descending {
+			panic("can't use WithDescendingOrder more then one time")
+		}
+
+		opts.descending = true
+	}
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"fmt"
 	"runtime/debug"
 	"sync"
@@ -11,6 +12,7 @@ import (
 	"github.com/Aptomi/aptomi/pkg/engine/apply/action/component"
 	"github.com/Aptomi/aptomi/pkg/engine/resolve"
 	"github.com/Aptomi/aptomi/pkg/event"
+	"github.com/Aptomi/aptomi/pkg/external"
 	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/Aptomi/aptomi/pkg/plugin"
 	"github.com/Aptomi/aptomi/pkg/runtime"
@@ -67,33 +69,34 @@ func (server *Server) actualStateUpdate() error {
 	eventLog := event.NewLog(log.DebugLevel, fmt.Sprintf("update-%d", server.actualStateUpdateIdx)).AddConsoleHook(server.cfg.GetLogLevel())
 
 	// Load endpoints for all components
-	refreshEndpoints(desiredPolicy, actualState, server.registry.NewActualStateUpdater(actualState), server.updaterPluginRegistryFactory(), eventLog, server.cfg.Updater.MaxConcurrentActions, server.cfg.Updater.Noop)
+	refreshEndpoints(desiredPolicy, actualState, server.registry.NewActualStateUpdater(actualState), server.externalData, server.updaterPluginRegistryFactory(), eventLog, server.cfg.Updater.MaxConcurrentActions, server.cfg.Updater.Noop)
 
 	log.Infof("(update-%d) Actual state updated", server.actualStateUpdateIdx)
 
 	return nil
 }
 
-func refreshEndpoints(desiredPolicy *lang.Policy, actualState *resolve.PolicyResolution, actualStateUpdater actual.StateUpdater, plugins plugin.Registry, eventLog *event.Log, maxConcurrentActions int, noop bool) {
-	context := action.NewContext(
+func refreshEndpoints(desiredPolicy *lang.Policy, actualState *resolve.PolicyResolution, actualStateUpdater actual.StateUpdater, externalData *external.Data, plugins plugin.Registry, eventLog *event.Log, maxConcurrentActions int, noop bool) {
+	actionContext := action.NewContext(
+		context.Background(),
 		desiredPolicy,
 		nil, // not needed for endpoints action
 		actualStateUpdater,
-		nil, // not needed for endpoints action
+		externalData, // needed to resolve secrets in code params
 		plugins,
 		eventLog,
 	)
 
 	// make sure we are converting panics into errors
-	fn := action.WrapParallelWithLimit(maxConcurrentActions, func(act action.Interface) (errResult error) {
+	fn := action.WrapParallelWithLimit(maxConcurrentActions, func(actionCtx context.Context, act action.Interface) (errResult error) {
 		defer func() {
 			if err := recover(); err != nil {
 				errResult = fmt.Errorf("panic: %s\n%s", err, string(debug.Stack()))
 			}
 		}()
-		err := act.Apply(context)
+		err := act.Apply(actionContext.WithContext(actionCtx))
 		if err != nil {
-			context.EventLog.NewEntry().Errorf("error while applying action '%s': %s", act, err)
+			actionContext.EventLog.NewEntry().Errorf("error while applying action '%s': %s", act, err)
 		}
 		return err
 	})
@@ -120,7 +123,7 @@ func refreshEndpoints(desiredPolicy *lang.Policy, actualState *resolve.PolicyRes
 			defer wg.Done()
 
 			// if an error or panic happened in the action, we don't have to do anything special, we will just retry it next time
-			fn(act) // nolint: errcheck
+			fn(actionContext.Ctx, act) // nolint: errcheck
 		}(act)
 	}
 
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/engine/apply/action"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
+	"github.com/Aptomi/aptomi/pkg/util"
+)
+
+// getRequiredApproval checks whether a given action plan affects any component instances protected by approval rules.
+// If so, it returns an approval request which has to be granted before the plan gets applied. Otherwise it returns nil
+func (server *Server) getRequiredApproval(actionPlan *action.Plan, desiredState *resolve.PolicyResolution, actualState *resolve.PolicyResolution) *engine.RevisionApproval {
+	namespaces := make(map[string]bool)
+	clusters := make(map[string]bool)
+	for key, node := range actionPlan.NodeMap {
+		if len(node.Actions) <= 0 {
+			continue
+		}
+
+		// component instance is present in desired state, unless it's being deleted
+		instance := desiredState.ComponentInstanceMap[key]
+		if instance == nil {
+			instance = actualState.ComponentInstanceMap[key]
+		}
+		if instance == nil {
+			continue
+		}
+
+		cik := instance.Metadata.Key
+		if server.cfg.Approval.IsRequired(cik.Namespace, cik.ClusterNameSpace, cik.ClusterName) {
+			namespaces[cik.Namespace] = true
+			clusters[cik.ClusterNameSpace+"/"+cik.ClusterName] = true
+		}
+	}
+
+	if len(namespaces) <= 0 {
+		return nil
+	}
+
+	return &engine.RevisionApproval{
+		Namespaces:  util.GetSortedStringKeys(namespaces),
+		Clusters:    util.GetSortedStringKeys(clusters),
+		RequestedAt: time.Now(),
+	}
+}
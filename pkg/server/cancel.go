@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/engine/apply/action"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	log "github.com/sirupsen/logrus"
+)
+
+// revisionProcessing represents a revision which is currently being processed by the desired state enforcer
+type revisionProcessing struct {
+	gen         runtime.Generation
+	cancel      context.CancelFunc
+	cancelledBy string
+	cancelledAt time.Time
+}
+
+// startRevisionProcessing picks the revision for processing, resets its status and registers it as being processed.
+// It returns the revision and the context, which gets cancelled when user cancels the revision
+func (server *Server) startRevisionProcessing() (*engine.Revision, context.Context, error) {
+	server.revisionProcessingMutex.Lock()
+	defer server.revisionProcessingMutex.Unlock()
+
+	// get the revision for processing
+	revision, err := server.getRevisionForProcessing()
+	if err != nil {
+		return nil, nil, fmt.Errorf("can't pick revision for processing: %s", err)
+	}
+	if revision == nil {
+		return nil, nil, nil
+	}
+
+	// reset revision status and result
+	revision.Status = engine.RevisionStatusWaiting
+	revision.Result = &action.ApplyResult{}
+	err = server.registry.UpdateRevision(revision)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to update revision: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	server.revisionProcessing = &revisionProcessing{
+		gen:    revision.GetGeneration(),
+		cancel: cancel,
+	}
+
+	return revision, ctx, nil
+}
+
+// finishRevisionProcessing unregisters the revision being processed and records who cancelled it, if it has been
+// cancelled while being applied
+func (server *Server) finishRevisionProcessing(revision *engine.Revision) {
+	server.revisionProcessingMutex.Lock()
+	defer server.revisionProcessingMutex.Unlock()
+
+	processing := server.revisionProcessing
+	if processing == nil {
+		return
+	}
+	server.revisionProcessing = nil
+	processing.cancel()
+
+	if revision.Status == engine.RevisionStatusCancelled && len(processing.cancelledBy) > 0 {
+		revision.CancelledBy = processing.cancelledBy
+		revision.CancelledAt = processing.cancelledAt
+		err := server.registry.UpdateRevision(revision)
+		if err != nil {
+			log.Errorf("unable to update cancelled revision %d: %s", revision.GetGeneration(), err)
+		}
+	}
+}
+
+// cancelRevision cancels revision with a given generation on behalf of a given user. If revision is being applied
+// right now, no new actions will be started and the actions in progress will get cancelled. Revision will then get
+// cancelled status once the enforcer is done with it. Otherwise, revision gets cancelled immediately
+func (server *Server) cancelRevision(gen runtime.Generation, user string) (*engine.Revision, error) {
+	// take the same mutex as revision approval, so that revision can't get approved and cancelled at the same time
+	server.policyAndRevisionUpdateMutex.Lock()
+	defer server.policyAndRevisionUpdateMutex.Unlock()
+
+	server.revisionProcessingMutex.Lock()
+	defer server.revisionProcessingMutex.Unlock()
+
+	revision, err := server.registry.GetRevision(gen)
+	if err != nil {
+		return nil, fmt.Errorf("error while getting revision: %s", err)
+	}
+	if revision == nil {
+		return nil, nil
+	}
+	if !revision.IsCancellable() {
+		return nil, fmt.Errorf("revision %d can't be cancelled (status: %s)", revision.GetGeneration(), revision.Status)
+	}
+
+	// revision is being processed by the enforcer, so let it know that it has to stop
+	processing := server.revisionProcessing
+	if processing != nil && processing.gen == revision.GetGeneration() {
+		log.Infof("Cancelling revision %d, which is being applied (requested by '%s')", revision.GetGeneration(), user)
+		processing.cancelledBy = user
+		processing.cancelledAt = time.Now()
+		processing.cancel()
+		return revision, nil
+	}
+
+	// revision is not being processed, so just mark it as cancelled
+	log.Infof("Cancelling revision %d (requested by '%s')", revision.GetGeneration(), user)
+	revision.Status = engine.RevisionStatusCancelled
+	revision.CancelledBy = user
+	revision.CancelledAt = time.Now()
+	err = server.registry.UpdateRevision(revision)
+	if err != nil {
+		return nil, fmt.Errorf("error while updating revision: %s", err)
+	}
+
+	return revision, nil
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
+	"github.com/Aptomi/aptomi/pkg/event"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// claimExpirerUser is a name of the system user, on behalf of which expired claims get deleted from the policy
+	claimExpirerUser = "system:ttl"
+)
+
+func (server *Server) claimExpireLoop() error {
+	for {
+		err := server.claimExpire()
+		if err != nil {
+			log.Errorf("error while deleting expired claims: %s", err)
+		}
+
+		time.Sleep(server.cfg.ClaimExpirer.Interval)
+	}
+}
+
+func (server *Server) claimExpire() error {
+	server.claimExpireIdx++
+
+	defer func() {
+		if err := recover(); err != nil {
+			log.Errorf("panic while deleting expired claims: %s", err)
+			log.Errorf(string(debug.Stack()))
+		}
+	}()
+
+	// Make sure to take the mutex shared with API, so that expired claims get deleted and the new revision gets created
+	// without policy being changed via API in between
+	server.policyAndRevisionUpdateMutex.Lock()
+	defer server.policyAndRevisionUpdateMutex.Unlock()
+
+	// Get desired policy
+	desiredPolicy, _, err := server.registry.GetPolicy(runtime.LastOrEmptyGen)
+	if err != nil {
+		return fmt.Errorf("error while getting last policy: %s", err)
+	}
+
+	// if policy is not found, it means it somehow was not initialized correctly. let's return error
+	if desiredPolicy == nil {
+		return fmt.Errorf("last policy is nil, does not exist in the registry")
+	}
+
+	// find all expired claims
+	now := time.Now()
+	expired := []lang.Base{}
+	for _, obj := range desiredPolicy.GetObjectsByKind(lang.TypeClaim.Kind) {
+		claim := obj.(*lang.Claim) // nolint: errcheck
+		if claim.IsExpired(now) {
+			expired = append(expired, claim)
+		}
+	}
+	if len(expired) <= 0 {
+		return nil
+	}
+
+	// delete expired claims from the policy
+	changed, policyData, err := server.registry.DeleteFromPolicy(expired, claimExpirerUser)
+	if err != nil {
+		return fmt.Errorf("error while deleting expired claims from the policy: %s", err)
+	}
+	if !changed {
+		return nil
+	}
+
+	// resolve updated policy
+	policyUpdated, policyGen, err := server.registry.GetPolicy(policyData.GetGeneration())
+	if err != nil {
+		return fmt.Errorf("error while getting updated policy: %s", err)
+	}
+	eventLog := event.NewLog(log.DebugLevel, fmt.Sprintf("expire-%d", server.claimExpireIdx)).AddConsoleHook(server.cfg.GetLogLevel())
+	desiredState := resolve.NewPolicyResolver(policyUpdated, server.externalData, eventLog).ResolveAllClaims()
+	err = desiredState.Validate(policyUpdated)
+	if err != nil {
+		return fmt.Errorf("error while resolving policy gen %d after deleting expired claims: %s", policyGen, err)
+	}
+
+	// create a new revision, so expired claims get destroyed by the enforcer
+	revision, err := server.registry.NewRevision(policyGen, desiredState, false)
+	if err != nil {
+		return fmt.Errorf("unable to create new revision for policy gen %d: %s", policyGen, err)
+	}
+
+	// record deleted claims into the audit log
+	entry := engine.NewAuditEntry(engine.AuditActionClaimExpire, claimExpirerUser)
+	for _, claim := range expired {
+		entry.Objects = append(entry.Objects, runtime.KeyFromParts(claim.GetNamespace(), claim.GetKind(), claim.GetName()))
+	}
+	entry.PolicyGen = policyGen
+	entry.RevisionGen = revision.GetGeneration()
+	server.auditLog.Record(entry)
+
+	log.Infof("(expire-%d) Deleted %d expired claims, policy gen %d", server.claimExpireIdx, len(expired), policyGen)
+
+	// trigger enforcement right away
+	server.runDesiredStateEnforcement <- true
+
+	return nil
+}
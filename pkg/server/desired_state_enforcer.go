@@ -17,6 +17,12 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+const (
+	// enforcerUser is a name of the system user, on behalf of which desired state enforcement gets recorded into the
+	// audit log
+	enforcerUser = "system:enforcer"
+)
+
 func (server *Server) desiredStateEnforceLoop() error {
 	server.desiredStateEnforcements = prometheus.NewCounter(
 		prometheus.CounterOpts{
@@ -57,6 +63,7 @@ func (server *Server) desiredStateEnforceLoop() error {
 
 func (server *Server) getRevisionForProcessing() (*engine.Revision, error) {
 	// we are processing revision sequentially, so let's get the first unprocessed revision from the database
+	// revisions which are pending approval or have been rejected are not considered unprocessed, so they are skipped
 	revision, err := server.registry.GetFirstUnprocessedRevision()
 	if err != nil {
 		return nil, fmt.Errorf("unable to load first unprocessed revision: %s", err)
@@ -78,10 +85,8 @@ func (server *Server) getRevisionForProcessing() (*engine.Revision, error) {
 		return nil, fmt.Errorf("unable to load latest revision: %s", err)
 	}
 
-	// now, given that we retrieved the last revision, when do we need to retry it? in one of two cases:
-	// - it's either in error status (something really bad happened)
-	// - it completed, but some actions failed and they need to be retried
-	if lastRevision != nil && (lastRevision.Status == engine.RevisionStatusError || (lastRevision.Status == engine.RevisionStatusCompleted && lastRevision.Result.Failed > 0)) {
+	// now, given that we retrieved the last revision, do we need to retry it?
+	if lastRevision != nil && lastRevision.NeedsRetry() {
 		log.Infof("(enforce-%d) Found last revision %d which needs to be retried", server.desiredStateEnforcementIdx, lastRevision.GetGeneration())
 		return lastRevision, nil
 	}
@@ -104,22 +109,15 @@ func (server *Server) desiredStateEnforce() error {
 		}
 	}()
 
-	// get the revision for processing
-	revision, err := server.getRevisionForProcessing()
+	// get the revision for processing, it can be cancelled by user until we are done with it
+	revision, ctx, err := server.startRevisionProcessing()
 	if err != nil {
-		return fmt.Errorf("can't pick revision for processing: %s", err)
+		return err
 	}
 	if revision == nil {
 		return nil
 	}
-
-	// reset revision status and result
-	revision.Status = engine.RevisionStatusWaiting
-	revision.Result = &action.ApplyResult{}
-	revErr := server.registry.UpdateRevision(revision)
-	if revErr != nil {
-		return fmt.Errorf("unable to update revision: %s", revErr)
-	}
+	defer server.finishRevisionProcessing(revision)
 
 	// load the corresponding policy
 	policy, policyGen, err := server.registry.GetPolicy(revision.PolicyGen)
@@ -149,6 +147,22 @@ func (server *Server) desiredStateEnforce() error {
 
 	// policy changes while no actions needed to achieve desired state
 	actionCnt := stateDiff.ActionPlan.NumberOfActions()
+
+	// if action plan affects protected namespaces or clusters, it has to be approved before it can be applied
+	// (unless revision has been cancelled already, then no actions will be applied anyway)
+	if actionCnt > 0 && !revision.IsApproved() && ctx.Err() == nil {
+		approval := server.getRequiredApproval(stateDiff.ActionPlan, desiredState, actualState)
+		if approval != nil {
+			revision.Status = engine.RevisionStatusPendingApproval
+			revision.Approval = approval
+			revErr := server.registry.UpdateRevision(revision)
+			if revErr != nil {
+				return fmt.Errorf("unable to update revision: %s", revErr)
+			}
+			log.Infof("(enforce-%d) Revision %d, policy gen %d: %d actions require manual approval (namespaces: %s, clusters: %s)", server.desiredStateEnforcementIdx, revision.GetGeneration(), policyGen, actionCnt, approval.Namespaces, approval.Clusters)
+			return nil
+		}
+	}
 	if actionCnt > 0 {
 		log.Infof("(enforce-%d) Revision %d, policy gen %d: %d actions need to be applied", server.desiredStateEnforcementIdx, revision.GetGeneration(), policyGen, actionCnt)
 	} else {
@@ -166,7 +180,13 @@ func (server *Server) desiredStateEnforce() error {
 	pluginRegistry := server.enforcerPluginRegistryFactory()
 	applyLog := event.NewLog(log.DebugLevel, fmt.Sprintf("enforce-%d-apply", server.desiredStateEnforcementIdx)).AddConsoleHook(server.cfg.GetLogLevel())
 	applier := apply.NewEngineApply(policy, desiredState, server.registry.NewActualStateUpdater(actualState), server.externalData, pluginRegistry, stateDiff.ActionPlan, applyLog, server.registry.NewRevisionResultUpdater(revision))
-	_, _ = applier.Apply(server.cfg.Enforcer.MaxConcurrentActions)
+	_, _ = applier.Apply(ctx, &action.ApplyOptions{
+		RetryPolicy:          server.getRetryPolicy(policy, desiredState, actualState),
+		Timeout:              server.getTimeoutFunc(policy, desiredState, actualState),
+		Hold:                 server.getHoldFunc(policy, desiredState, actualState, time.Now()),
+		MaxConcurrentActions: server.cfg.Enforcer.MaxConcurrentActions,
+		Scheduling:           server.getSchedulingPolicy(stateDiff.ActionPlan, desiredState, actualState),
+	})
 
 	// save apply log
 	revision.ApplyLog = applyLog.AsAPIEvents()
@@ -175,7 +195,32 @@ func (server *Server) desiredStateEnforce() error {
 		return fmt.Errorf("error while saving revision with apply log: %s", saveErr)
 	}
 
-	log.Infof("(enforce-%d) Revision %d processed (actions: %d succeeded, %d failed, %d skipped)", server.desiredStateEnforcementIdx, revision.GetGeneration(), revision.Result.Success, revision.Result.Failed, revision.Result.Skipped)
+	log.Infof("(enforce-%d) Revision %d processed (actions: %d succeeded, %d failed, %d timed out, %d held, %d blocked, %d skipped, %d retried)", server.desiredStateEnforcementIdx, revision.GetGeneration(), revision.Result.Success, revision.Result.Failed, revision.Result.TimedOut, revision.Result.Held, revision.Result.Blocked, revision.Result.Skipped, revision.Result.Retried)
+	if revision.Result.Held > 0 {
+		log.Infof("(enforce-%d) Revision %d: %d actions have been held due to maintenance windows, they will be retried later", server.desiredStateEnforcementIdx, revision.GetGeneration(), revision.Result.Held)
+	}
+	if revision.Result.Blocked > 0 {
+		log.Infof("(enforce-%d) Revision %d: %d actions have been blocked due to deletion protection, they will not be retried", server.desiredStateEnforcementIdx, revision.GetGeneration(), revision.Result.Blocked)
+	}
+	if revision.Status == engine.RevisionStatusFailed {
+		log.Warningf("(enforce-%d) Revision %d failed: %d actions kept failing after all retries, it will not be retried automatically", server.desiredStateEnforcementIdx, revision.GetGeneration(), revision.Result.Exhausted)
+	}
+	if revision.Status == engine.RevisionStatusCancelled {
+		log.Warningf("(enforce-%d) Revision %d has been cancelled, it will not be retried automatically", server.desiredStateEnforcementIdx, revision.GetGeneration())
+	}
+
+	// record enforcement into the audit log, unless there was nothing to do
+	if actionCnt > 0 {
+		entry := engine.NewAuditEntry(engine.AuditActionEnforce, enforcerUser)
+		entry.PolicyGen = policyGen
+		entry.RevisionGen = revision.GetGeneration()
+		if revision.Status == engine.RevisionStatusCancelled {
+			entry.Fail("revision has been cancelled")
+		} else if revision.Result.Failed > 0 || revision.Result.TimedOut > 0 {
+			entry.Fail(fmt.Sprintf("%d actions failed, %d timed out", revision.Result.Failed, revision.Result.TimedOut))
+		}
+		server.auditLog.Record(entry)
+	}
 
 	// let's try again immediately until no actions were successfully applied
 	if revision.Result.Success > 0 {
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/engine/apply/action"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/lang/expression"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+)
+
+// getHoldFunc returns a function, which holds actions against clusters which are outside of their maintenance windows
+// or in a change freeze at a given time. Actions against all other clusters are not held
+func (server *Server) getHoldFunc(policy *lang.Policy, desiredState *resolve.PolicyResolution, actualState *resolve.PolicyResolution, now time.Time) action.HoldFunc {
+	if len(policy.GetObjectsByKind(lang.TypeMaintenance.Kind)) <= 0 {
+		return action.NoHold()
+	}
+
+	// figure out upfront which clusters are held, as the hold function is called concurrently
+	cache := expression.NewCache()
+	reasons := make(map[runtime.Key]string)
+	for _, obj := range policy.GetObjectsByKind(lang.TypeCluster.Kind) {
+		cluster := obj.(*lang.Cluster) // nolint: errcheck
+		reason, err := policy.GetMaintenanceHoldReason(cluster, now, cache)
+		if err != nil {
+			reason = fmt.Sprintf("unable to check maintenance windows: %s", err)
+		}
+		if len(reason) > 0 {
+			reasons[runtime.KeyForStorable(cluster)] = fmt.Sprintf("cluster '%s' is held: %s", cluster.Name, reason)
+		}
+	}
+
+	return func(key string, act action.Interface) string {
+		cluster := getCluster(policy, key, desiredState, actualState)
+		if cluster == nil {
+			return ""
+		}
+		return reasons[runtime.KeyForStorable(cluster)]
+	}
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/engine/apply/action"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
+	"github.com/Aptomi/aptomi/pkg/lang"
+)
+
+const (
+	// defaultRetryInterval is the delay before the first retry of a failed action, if it's not set in config
+	defaultRetryInterval = time.Second
+
+	// minRetryInterval is the minimum allowed delay between retries of a failed action
+	minRetryInterval = 100 * time.Millisecond
+)
+
+// getRetryPolicy returns a function, which determines how failed actions get retried based on enforcer config.
+// Retry config is matched by action kind and by type of the cluster, where the corresponding component instance resides
+func (server *Server) getRetryPolicy(policy *lang.Policy, desiredState *resolve.PolicyResolution, actualState *resolve.PolicyResolution) action.RetryPolicyFunc {
+	if len(server.cfg.Enforcer.Retry) <= 0 {
+		return action.NoRetry()
+	}
+
+	return func(key string, act action.Interface) *action.RetryPolicy {
+		retry := server.cfg.Enforcer.GetRetry(act.GetKind(), getClusterType(policy, key, desiredState, actualState))
+		if retry == nil {
+			return nil
+		}
+
+		interval := retry.Interval
+		if interval <= 0 {
+			interval = defaultRetryInterval
+		} else if interval < minRetryInterval {
+			interval = minRetryInterval
+		}
+
+		return &action.RetryPolicy{
+			MaxAttempts: retry.MaxAttempts,
+			Interval:    interval,
+			MaxInterval: retry.MaxInterval,
+		}
+	}
+}
+
+// getClusterType returns type of the cluster, where component instance with a given key resides. If it can't be
+// determined, empty string is returned
+func getClusterType(policy *lang.Policy, key string, desiredState *resolve.PolicyResolution, actualState *resolve.PolicyResolution) string {
+	cluster := getCluster(policy, key, desiredState, actualState)
+	if cluster == nil {
+		return ""
+	}
+	return cluster.Type
+}
+
+// getCluster returns the cluster, where component instance with a given key resides. If it can't be determined, nil
+// is returned
+func getCluster(policy *lang.Policy, key string, desiredState *resolve.PolicyResolution, actualState *resolve.PolicyResolution) *lang.Cluster {
+	instance := desiredState.ComponentInstanceMap[key]
+	if instance == nil {
+		instance = actualState.ComponentInstanceMap[key]
+	}
+	if instance == nil {
+		return nil
+	}
+
+	clusterObj, err := policy.GetObject(lang.TypeCluster.Kind, instance.Metadata.Key.ClusterName, instance.Metadata.Key.ClusterNameSpace)
+	if err != nil || clusterObj == nil {
+		return nil
+	}
+	return clusterObj.(*lang.Cluster) // nolint: errcheck
+}
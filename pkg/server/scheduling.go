@@ -0,0 +1,23 @@
+package server
+
+import (
+	"github.com/Aptomi/aptomi/pkg/engine/apply"
+	"github.com/Aptomi/aptomi/pkg/engine/apply/action"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
+)
+
+// getSchedulingPolicy returns scheduling policy for a given action plan based on enforcer config. If scheduling is
+// not configured, nil is returned and actions are applied in the order they become ready
+func (server *Server) getSchedulingPolicy(actionPlan *action.Plan, desiredState *resolve.PolicyResolution, actualState *resolve.PolicyResolution) *action.SchedulingPolicy {
+	scheduling := server.cfg.Enforcer.Scheduling
+	if !scheduling.IsEnabled() {
+		return nil
+	}
+
+	return &action.SchedulingPolicy{
+		MaxPerCluster:   scheduling.MaxPerCluster,
+		MaxPerNamespace: scheduling.MaxPerNamespace,
+		Order:           scheduling.Order,
+		NodeInfo:        apply.GetSchedulingNodeInfo(actionPlan, desiredState, actualState, scheduling.PriorityLabel),
+	}
+}
@@ -1,17 +1,21 @@
 package server
 
 import (
+	"crypto/tls"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"os"
 	"os/signal"
 	"runtime/pprof"
 	"runtime/trace"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/Aptomi/aptomi/pkg/api"
 	"github.com/Aptomi/aptomi/pkg/api/middleware"
+	"github.com/Aptomi/aptomi/pkg/audit"
 	"github.com/Aptomi/aptomi/pkg/config"
 	"github.com/Aptomi/aptomi/pkg/external"
 	"github.com/Aptomi/aptomi/pkg/external/secrets"
@@ -27,6 +31,7 @@ import (
 	"github.com/Aptomi/aptomi/pkg/runtime/store"
 	"github.com/Aptomi/aptomi/pkg/runtime/store/etcd"
 	"github.com/Aptomi/aptomi/pkg/server/ui"
+	"github.com/Aptomi/aptomi/pkg/util/certs"
 	"github.com/gorilla/handlers"
 	"github.com/julienschmidt/httprouter"
 	"github.com/prometheus/client_golang/prometheus"
@@ -43,7 +48,10 @@ type Server struct {
 	backgroundErrors chan string
 
 	externalData *external.Data
+	oidcUsers    *registry.OIDCUserLoader
 	registry     registry.Interface
+	auditLog     *audit.Log
+	secrets      *secrets.Envelope
 
 	httpServer *http.Server
 
@@ -51,10 +59,19 @@ type Server struct {
 	desiredStateEnforcementIdx    uint
 	enforcerPluginRegistryFactory plugin.RegistryFactory
 
+	revisionProcessing      *revisionProcessing
+	revisionProcessingMutex sync.Mutex
+
+	// policyAndRevisionUpdateMutex is shared with API, so that policy and revision changes made by the server (e.g.
+	// deletion of expired claims) don't interleave with the ones made via API
+	policyAndRevisionUpdateMutex sync.Mutex
+
 	runActualStateUpdate         chan bool
 	actualStateUpdateIdx         uint
 	updaterPluginRegistryFactory plugin.RegistryFactory
 
+	claimExpireIdx uint
+
 	desiredStateEnforcements        prometheus.Counter
 	desiredStateEnforcementDuration prometheus.Histogram
 }
@@ -77,14 +94,16 @@ func (server *Server) Start() {
 	// Init server
 	server.initProfiling()
 	server.initRegistry()
+	server.initAuditLog()
 	server.initExternalData()
 	server.initPluginRegistryFactory()
 	server.initPolicyOnFirstRun()
 
-	// Start API, UI, Enforcer and ActualStateUpdater
+	// Start API, UI, Enforcer, ActualStateUpdater and ClaimExpirer
 	server.startHTTPServer()
 	server.startDesiredStateEnforcer()
 	server.startActualStateUpdater()
+	server.startClaimExpirer()
 
 	// Wait for jobs to complete (it essentially hangs forever)
 	server.wait()
@@ -114,10 +133,69 @@ func (server *Server) initExternalData() {
 	for _, file := range server.cfg.Users.File {
 		userLoaders = append(userLoaders, users.NewUserLoaderFromFile(file, server.cfg.DomainAdminOverrides))
 	}
+	for _, webhook := range server.cfg.Users.Webhook {
+		userLoaders = append(userLoaders, users.NewUserLoaderFromWebhook(webhook, server.cfg.DomainAdminOverrides))
+	}
+	var provisionedUsers registry.ProvisionedUserLoader
+	if server.cfg.Users.SCIM != nil {
+		provisionedUsers = registry.NewSCIMUserLoader(server.registry, *server.cfg.Users.SCIM, server.cfg.DomainAdminOverrides)
+		userLoaders = append(userLoaders, provisionedUsers)
+	}
+	userLoaders = append(userLoaders, registry.NewServiceAccountUserLoader(server.registry))
+	if server.cfg.Auth.OIDC != nil {
+		// users logging in via OpenID Connect provider are added last, so configured user sources take precedence. They
+		// also can't log in with names of users from configured sources, except for users provisioned by identity
+		// provider (SCIM), who log in as themselves
+		otherSources := make([]users.UserLoader, 0, len(userLoaders))
+		for _, loader := range userLoaders {
+			if loader != provisionedUsers {
+				otherSources = append(otherSources, loader)
+			}
+		}
+		server.oidcUsers = registry.NewOIDCUserLoader(server.registry, users.NewUserLoaderMultipleSources(otherSources), provisionedUsers, server.cfg.DomainAdminOverrides)
+		userLoaders = append(userLoaders, server.oidcUsers)
+	}
+
+	secretLoaders := make([]secrets.SecretLoader, 0)
+	if len(server.cfg.SecretsDir) > 0 {
+		log.Warnf("Loading secrets from directory %s is deprecated, secrets should be stored in the secret store", server.cfg.SecretsDir)
+		secretLoaders = append(secretLoaders, secrets.NewSecretLoaderFromDir(server.cfg.SecretsDir))
+	}
+	if server.cfg.Secrets.Enabled() {
+		// secrets from the secret store are added last, so they take precedence over secrets from directory
+		server.secrets = newSecretEnvelope(server.cfg.Secrets)
+		secretLoaders = append(secretLoaders, registry.NewSecretLoader(server.registry, server.secrets))
+	}
+
 	server.externalData = external.NewData(
 		users.NewUserLoaderMultipleSources(userLoaders),
-		secrets.NewSecretLoaderFromDir(server.cfg.SecretsDir),
+		secrets.NewSecretLoaderMultipleSources(secretLoaders),
 	)
+
+	// fingerprints of secret values are keyed with the token signing secret, so they can't be used to guess secrets
+	server.externalData.SecretFingerprintKey = secrets.NewFingerprintKey(server.cfg.Auth.Secret)
+}
+
+// newSecretEnvelope creates envelope for encrypting secrets with the master key from a given config
+func newSecretEnvelope(cfg config.Secrets) *secrets.Envelope {
+	key := cfg.Key
+	if len(key) <= 0 {
+		data, err := ioutil.ReadFile(cfg.KeyFile)
+		if err != nil {
+			panic(fmt.Sprintf("can't read secret store master key file: %s", err))
+		}
+		key = string(data)
+	}
+
+	masterKey, err := secrets.ParseKey(key)
+	if err != nil {
+		panic(fmt.Sprintf("can't parse secret store master key: %s", err))
+	}
+	envelope, err := secrets.NewEnvelope(masterKey)
+	if err != nil {
+		panic(fmt.Sprintf("can't create secret store envelope: %s", err))
+	}
+	return envelope
 }
 
 func (server *Server) initProfiling() {
@@ -174,6 +252,25 @@ func (server *Server) initRegistry() {
 	server.registry = registry.New(etcdStore)
 }
 
+func (server *Server) initAuditLog() {
+	sinks := []audit.Sink{}
+	if len(server.cfg.Audit.File) > 0 {
+		sink, err := audit.NewFileSink(server.cfg.Audit.File)
+		if err != nil {
+			panic(fmt.Sprintf("can't create audit log file sink: %s", err))
+		}
+		sinks = append(sinks, sink)
+	}
+	if server.cfg.Audit.Syslog != nil {
+		sink, err := audit.NewSyslogSink(server.cfg.Audit.Syslog.Network, server.cfg.Audit.Syslog.Address, server.cfg.Audit.Syslog.Tag)
+		if err != nil {
+			panic(fmt.Sprintf("can't create audit log syslog sink: %s", err))
+		}
+		sinks = append(sinks, sink)
+	}
+	server.auditLog = audit.NewLog(server.registry, sinks...)
+}
+
 func (server *Server) initPluginRegistryFactory() {
 	fn := func(noop bool, noopSleep time.Duration) func() plugin.Registry {
 		return func() plugin.Registry {
@@ -221,11 +318,13 @@ func (server *Server) startHTTPServer() {
 		log.Warnf("The auth.secret not specified in config, using insecure default one")
 	}
 
-	api.Serve(router, server.registry, server.externalData, server.enforcerPluginRegistryFactory, server.cfg.Auth.Secret, server.cfg.GetLogLevel(), server.runDesiredStateEnforcement)
+	api.Serve(router, server.registry, server.externalData, server.enforcerPluginRegistryFactory, server.cfg.Auth, server.oidcUsers, server.cfg.Users.SCIM, server.auditLog, server.secrets, server.cfg.GetLogLevel(), server.runDesiredStateEnforcement, server.cancelRevision, &server.policyAndRevisionUpdateMutex)
 	server.serveUI(router)
 
 	var handler http.Handler = router
 
+	handler = middleware.NewRateLimitHandler(prometheusSvcName, server.cfg.RateLimit, api.NewRequestKeyFunc(server.cfg.Auth.Secret, server.registry), handler)
+
 	// todo write to logrus
 	handler = handlers.CombinedLoggingHandler(os.Stdout, handler) // todo(slukjanov): make it at least somehow configurable - for example, select file to write to with rotation
 	handler = middleware.NewMetricsHandler(prometheusSvcName, handler)
@@ -240,12 +339,59 @@ func (server *Server) startHTTPServer() {
 		ReadTimeout:  30 * time.Second,
 	}
 
+	if server.cfg.TLS.Enabled() {
+		server.httpServer.TLSConfig = server.newTLSConfig()
+	}
+
 	// Start HTTP server
 	server.runInBackground("HTTP Server / API", true, func() {
+		if server.httpServer.TLSConfig != nil {
+			// certificate is provided by TLS config, which allows it to be reloaded
+			panic(server.httpServer.ListenAndServeTLS("", ""))
+		}
 		panic(server.httpServer.ListenAndServe())
 	})
 }
 
+func (server *Server) newTLSConfig() *tls.Config {
+	cfg := server.cfg.TLS
+	reloader, err := certs.NewReloader(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		panic(fmt.Sprintf("can't load TLS certificate: %s", err))
+	}
+
+	tlsConfig := &tls.Config{
+		GetCertificate: reloader.GetCertificate,
+		MinVersion:     tls.VersionTLS12,
+	}
+
+	if len(cfg.ClientCAFile) > 0 {
+		caReloader, caErr := certs.NewCertPoolReloader(cfg.ClientCAFile)
+		if caErr != nil {
+			panic(fmt.Sprintf("can't load TLS client CA: %s", caErr))
+		}
+		tlsConfig.ClientCAs = caReloader.GetCertPool()
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		if cfg.RequireClientCert {
+			tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+
+		// client CA pool gets reloaded once CA file changes, so every connection gets config with the current one
+		baseConfig := tlsConfig.Clone()
+		tlsConfig.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			clientConfig := baseConfig.Clone()
+			clientConfig.ClientCAs = caReloader.GetCertPool()
+			return clientConfig, nil
+		}
+	} else if cfg.RequireClientCert {
+		panic("TLS client CA should be specified in order to require client certificates")
+	}
+
+	log.Infof("API will be served over TLS (client certificate auth enabled: %t)", tlsConfig.ClientCAs != nil)
+
+	return tlsConfig
+}
+
 func (server *Server) serveUI(router *httprouter.Router) {
 	if !server.cfg.UI.Enable {
 		log.Infof("UI isn't enabled. UI will not be served")
@@ -285,3 +431,11 @@ func (server *Server) startActualStateUpdater() {
 		})
 	}
 }
+
+func (server *Server) startClaimExpirer() {
+	if !server.cfg.ClaimExpirer.Disabled {
+		server.runInBackground("Claim Expirer", true, func() {
+			panic(server.claimExpireLoop())
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"time"
+
+	"github.com/Aptomi/aptomi/pkg/engine/apply/action"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
+	"github.com/Aptomi/aptomi/pkg/lang"
+)
+
+// getTimeoutFunc returns a function, which determines deadlines for actions based on enforcer config. Deadline is
+// matched by code type of the component, which corresponding component instance belongs to
+func (server *Server) getTimeoutFunc(policy *lang.Policy, desiredState *resolve.PolicyResolution, actualState *resolve.PolicyResolution) action.TimeoutFunc {
+	if server.cfg.Enforcer.ActionTimeout <= 0 && len(server.cfg.Enforcer.CodeTimeouts) <= 0 {
+		return action.NoTimeout()
+	}
+
+	return func(key string, act action.Interface) time.Duration {
+		return server.cfg.Enforcer.GetTimeout(getCodeType(policy, key, desiredState, actualState))
+	}
+}
+
+// getCodeType returns code type of the component, which component instance with a given key belongs to. If it can't
+// be determined (e.g. it's not a code component), empty string is returned
+func getCodeType(policy *lang.Policy, key string, desiredState *resolve.PolicyResolution, actualState *resolve.PolicyResolution) string {
+	instance := desiredState.ComponentInstanceMap[key]
+	if instance == nil {
+		instance = actualState.ComponentInstanceMap[key]
+	}
+	if instance == nil {
+		return ""
+	}
+
+	bundleObj, err := policy.GetObject(lang.TypeBundle.Kind, instance.Metadata.Key.BundleName, instance.Metadata.Key.Namespace)
+	if err != nil || bundleObj == nil {
+		return ""
+	}
+	component := bundleObj.(*lang.Bundle).GetComponentsMap()[instance.Metadata.Key.ComponentName] // nolint: errcheck
+	if component == nil || component.Code == nil {
+		return ""
+	}
+	return component.Code.Type
+}
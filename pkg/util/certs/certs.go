@@ -0,0 +1,181 @@
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// checkInterval is how often files are checked for changes on disk
+const checkInterval = 10 * time.Second
+
+// watchedFiles keeps track of modification time of a set of files, checking them at most once per interval, so
+// frequent callers (e.g. every TLS handshake) don't hit the file system each time
+type watchedFiles struct {
+	files     []string
+	interval  time.Duration
+	checkedAt time.Time
+	modTime   time.Time
+}
+
+// changed returns the latest modification time of the files and whether they need to be reloaded. It returns false
+// without checking files if they have been checked less than interval ago
+func (watched *watchedFiles) changed() (time.Time, bool, error) {
+	now := time.Now()
+	if now.Sub(watched.checkedAt) < watched.interval {
+		return watched.modTime, false, nil
+	}
+	watched.checkedAt = now
+
+	modTime, err := lastModified(watched.files...)
+	if err != nil {
+		return modTime, false, err
+	}
+	return modTime, !modTime.Equal(watched.modTime), nil
+}
+
+// Reloader holds TLS certificate and key pair loaded from files, and reloads them once the files change on disk. It
+// allows to rotate server certificate without restarting the server
+type Reloader struct {
+	certFile string
+	keyFile  string
+
+	mutex   sync.Mutex
+	watched *watchedFiles
+	cert    *tls.Certificate
+}
+
+// NewReloader loads TLS certificate and key pair from given files and returns Reloader for them
+func NewReloader(certFile string, keyFile string) (*Reloader, error) {
+	reloader := &Reloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		watched:  &watchedFiles{files: []string{certFile, keyFile}, interval: checkInterval},
+	}
+	modTime, err := lastModified(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	err = reloader.load(modTime)
+	if err != nil {
+		return nil, err
+	}
+	return reloader, nil
+}
+
+// GetCertificate returns the current certificate, reloading it if certificate or key files have been changed. If
+// reload fails, then previously loaded certificate keeps being used. It can be used as tls.Config.GetCertificate
+func (reloader *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	reloader.mutex.Lock()
+	defer reloader.mutex.Unlock()
+
+	modTime, changed, err := reloader.watched.changed()
+	if err == nil && changed {
+		err = reloader.load(modTime)
+	}
+	if err != nil {
+		log.Warnf("Error while reloading TLS certificate %s, using previously loaded one: %s", reloader.certFile, err)
+	}
+
+	return reloader.cert, nil
+}
+
+// load reads certificate and key pair from files
+func (reloader *Reloader) load(modTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(reloader.certFile, reloader.keyFile)
+	if err != nil {
+		return fmt.Errorf("error while loading TLS certificate %s and key %s: %s", reloader.certFile, reloader.keyFile, err)
+	}
+	reloader.cert = &cert
+	reloader.watched.modTime = modTime
+	return nil
+}
+
+// CertPoolReloader holds pool of CA certificates loaded from a PEM file, and reloads it once the file changes on disk.
+// It allows to rotate client CA without restarting the server
+type CertPoolReloader struct {
+	caFile string
+
+	mutex   sync.Mutex
+	watched *watchedFiles
+	pool    *x509.CertPool
+}
+
+// NewCertPoolReloader loads pool of CA certificates from a given PEM file and returns CertPoolReloader for it
+func NewCertPoolReloader(caFile string) (*CertPoolReloader, error) {
+	reloader := &CertPoolReloader{
+		caFile:  caFile,
+		watched: &watchedFiles{files: []string{caFile}, interval: checkInterval},
+	}
+	modTime, err := lastModified(caFile)
+	if err != nil {
+		return nil, err
+	}
+	err = reloader.load(modTime)
+	if err != nil {
+		return nil, err
+	}
+	return reloader, nil
+}
+
+// GetCertPool returns the current pool of CA certificates, reloading it if CA file has been changed. If reload fails,
+// then previously loaded pool keeps being used
+func (reloader *CertPoolReloader) GetCertPool() *x509.CertPool {
+	reloader.mutex.Lock()
+	defer reloader.mutex.Unlock()
+
+	modTime, changed, err := reloader.watched.changed()
+	if err == nil && changed {
+		err = reloader.load(modTime)
+	}
+	if err != nil {
+		log.Warnf("Error while reloading CA certificate %s, using previously loaded one: %s", reloader.caFile, err)
+	}
+
+	return reloader.pool
+}
+
+// load reads pool of CA certificates from file
+func (reloader *CertPoolReloader) load(modTime time.Time) error {
+	pool, err := LoadCertPool(reloader.caFile)
+	if err != nil {
+		return err
+	}
+	reloader.pool = pool
+	reloader.watched.modTime = modTime
+	return nil
+}
+
+// lastModified returns the latest modification time of given files
+func lastModified(files ...string) (time.Time, error) {
+	result := time.Time{}
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return result, err
+		}
+		if info.ModTime().After(result) {
+			result = info.ModTime()
+		}
+	}
+	return result, nil
+}
+
+// LoadCertPool returns pool of CA certificates loaded from a given PEM file
+func LoadCertPool(caFile string) (*x509.CertPool, error) {
+	data, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading CA certificate %s: %s", caFile, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no valid CA certificates found in %s", caFile)
+	}
+	return pool, nil
+}
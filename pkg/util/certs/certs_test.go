@@ -0,0 +1,146 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeCert(t *testing.T, dir string, commonName string, modTime time.Time) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if !assert.NoError(t, err, "Key should be generated") {
+		t.FailNow()
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if !assert.NoError(t, err, "Certificate should be created") {
+		t.FailNow()
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if !assert.NoError(t, err, "Key should be marshalled") {
+		t.FailNow()
+	}
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	assert.NoError(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	assert.NoError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	assert.NoError(t, os.Chtimes(certFile, modTime, modTime))
+	assert.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+	return certFile, keyFile
+}
+
+func commonName(t *testing.T, reloader *Reloader) string {
+	t.Helper()
+	cert, err := reloader.GetCertificate(nil)
+	if !assert.NoError(t, err, "Certificate should be returned") {
+		return ""
+	}
+	parsed, err := x509.ParseCertificate(cert.Certificate[0])
+	if !assert.NoError(t, err, "Certificate should be parsed") {
+		return ""
+	}
+	return parsed.Subject.CommonName
+}
+
+func TestReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aptomi-certs-test")
+	if !assert.NoError(t, err, "Temp dir should be created") {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	now := time.Now()
+	certFile, keyFile := writeCert(t, dir, "first", now.Add(-time.Minute))
+	reloader, err := NewReloader(certFile, keyFile)
+	if !assert.NoError(t, err, "Reloader should be created") {
+		t.FailNow()
+	}
+	assert.Equal(t, "first", commonName(t, reloader), "Initial certificate should be loaded")
+
+	// files aren't checked again until check interval passes
+	writeCert(t, dir, "second", now)
+	assert.Equal(t, "first", commonName(t, reloader), "Certificate should not be reloaded before check interval passes")
+
+	// certificate gets reloaded once files change
+	reloader.watched.interval = 0
+	assert.Equal(t, "second", commonName(t, reloader), "Certificate should be reloaded")
+
+	// broken certificate doesn't replace the loaded one
+	assert.NoError(t, ioutil.WriteFile(certFile, []byte("broken"), 0600))
+	assert.NoError(t, os.Chtimes(certFile, now.Add(time.Minute), now.Add(time.Minute)))
+	assert.Equal(t, "second", commonName(t, reloader), "Previously loaded certificate should be used")
+
+	// missing files are an error on creation
+	_, err = NewReloader(filepath.Join(dir, "missing.pem"), keyFile)
+	assert.Error(t, err, "Reloader should not be created for missing files")
+}
+
+func TestLoadCertPool(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aptomi-certs-test")
+	if !assert.NoError(t, err, "Temp dir should be created") {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	certFile, keyFile := writeCert(t, dir, "ca", time.Now())
+	pool, err := LoadCertPool(certFile)
+	assert.NoError(t, err, "CA pool should be loaded")
+	assert.NotNil(t, pool, "CA pool should be loaded")
+
+	_, err = LoadCertPool(keyFile)
+	assert.Error(t, err, "CA pool without certificates should not be loaded")
+}
+
+func TestCertPoolReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aptomi-certs-test")
+	if !assert.NoError(t, err, "Temp dir should be created") {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	now := time.Now()
+	caFile, _ := writeCert(t, dir, "first", now.Add(-time.Minute))
+	reloader, err := NewCertPoolReloader(caFile)
+	if !assert.NoError(t, err, "CA pool reloader should be created") {
+		t.FailNow()
+	}
+	reloader.watched.interval = 0
+	pool := reloader.GetCertPool()
+	assert.NotNil(t, pool, "Initial CA pool should be loaded")
+	assert.True(t, pool == reloader.GetCertPool(), "CA pool should not be reloaded if file hasn't changed")
+
+	// pool gets reloaded once file changes
+	writeCert(t, dir, "second", now)
+	reloaded := reloader.GetCertPool()
+	assert.False(t, pool == reloaded, "CA pool should be reloaded")
+
+	// broken file doesn't replace the loaded pool
+	assert.NoError(t, ioutil.WriteFile(caFile, []byte("broken"), 0600))
+	assert.NoError(t, os.Chtimes(caFile, now.Add(time.Minute), now.Add(time.Minute)))
+	assert.True(t, reloaded == reloader.GetCertPool(), "Previously loaded CA pool should be used")
+
+	// missing file is an error on creation
+	_, err = NewCertPoolReloader(filepath.Join(dir, "missing.pem"))
+	assert.Error(t, err, "CA pool reloader should not be created for missing file")
+}
@@ -0,0 +1,3 @@
+// Package certs provides helpers for loading TLS certificates, including server certificate, which gets reloaded
+// once it changes on disk.
+package certs
@@ -0,0 +1,156 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// field describes a single field of the cron expression and its allowed range of values
+type field struct {
+	name string
+	min  int
+	max  int
+}
+
+var fields = []field{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 6},
+}
+
+// maxSearchYears limits how far in the future Next will look for a matching time
+const maxSearchYears = 5
+
+// Schedule is a parsed cron expression in a standard 5-field format (minute, hour, day of month, month, day of week).
+// Every field supports '*', single values, ranges ('1-5'), lists ('1,3,5') and steps ('*/15', '0-30/10'). Day of week
+// is in 0-6 range, where 0 is Sunday. Schedule is always evaluated in the location of the time passed to it
+type Schedule struct {
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+
+	// domStar and dowStar are set when day of month / day of week are '*', to mimic standard cron behavior: if
+	// both of them are restricted, then time matches when either of them matches
+	domStar bool
+	dowStar bool
+}
+
+// Parse parses a given cron expression into a Schedule
+func Parse(spec string) (*Schedule, error) {
+	parts := strings.Fields(spec)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("expected %d fields in cron expression '%s', found %d", len(fields), spec, len(parts))
+	}
+
+	bits := make([]uint64, len(fields))
+	for idx, part := range parts {
+		value, err := parseField(part, fields[idx])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression '%s': %s", spec, err)
+		}
+		bits[idx] = value
+	}
+
+	return &Schedule{
+		minute:  bits[0],
+		hour:    bits[1],
+		dom:     bits[2],
+		month:   bits[3],
+		dow:     bits[4],
+		domStar: parts[2] == "*",
+		dowStar: parts[4] == "*",
+	}, nil
+}
+
+// parseField parses a single field of cron expression and returns a bit set of allowed values
+func parseField(value string, f field) (uint64, error) {
+	var result uint64
+	for _, item := range strings.Split(value, ",") {
+		step := 1
+		if idx := strings.Index(item, "/"); idx >= 0 {
+			var err error
+			step, err = strconv.Atoi(item[idx+1:])
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step in %s field: '%s'", f.name, item)
+			}
+			item = item[:idx]
+		}
+
+		from, to := f.min, f.max
+		if item != "*" {
+			bounds := strings.SplitN(item, "-", 2)
+			var err error
+			from, err = strconv.Atoi(bounds[0])
+			if err != nil {
+				return 0, fmt.Errorf("invalid value in %s field: '%s'", f.name, item)
+			}
+			to = from
+			if len(bounds) > 1 {
+				to, err = strconv.Atoi(bounds[1])
+				if err != nil {
+					return 0, fmt.Errorf("invalid value in %s field: '%s'", f.name, item)
+				}
+			}
+		}
+		if from < f.min || to > f.max || from > to {
+			return 0, fmt.Errorf("value out of range [%d-%d] in %s field: '%s'", f.min, f.max, f.name, item)
+		}
+
+		for v := from; v <= to; v += step {
+			result |= 1 << uint(v)
+		}
+	}
+	return result, nil
+}
+
+// Matches returns true if a given time (truncated to a minute) matches the schedule
+func (schedule *Schedule) Matches(t time.Time) bool {
+	return has(schedule.minute, t.Minute()) && has(schedule.hour, t.Hour()) && has(schedule.month, int(t.Month())) && schedule.matchesDay(t)
+}
+
+// Next returns the first time after a given time, which matches the schedule. If there is no such time within the
+// next few years (e.g. schedule refers to February 30), zero time is returned
+func (schedule *Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxSearchYears, 0, 0)
+	for t.Before(limit) {
+		if !has(schedule.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !schedule.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !has(schedule.hour, t.Hour()) {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if !has(schedule.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// matchesDay returns true if day of a given time matches both day of month and day of week fields
+func (schedule *Schedule) matchesDay(t time.Time) bool {
+	domMatch := has(schedule.dom, t.Day())
+	dowMatch := has(schedule.dow, int(t.Weekday()))
+	if schedule.domStar || schedule.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+func has(bits uint64, value int) bool {
+	return bits&(1<<uint(value)) != 0
+}
@@ -0,0 +1,71 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseInvalid(t *testing.T) {
+	specs := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 7",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+	}
+	for _, spec := range specs {
+		_, err := Parse(spec)
+		assert.Error(t, err, "cron expression should be invalid: '%s'", spec)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	// Saturday, 22:00
+	saturday := time.Date(2018, time.March, 10, 22, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		spec    string
+		time    time.Time
+		matches bool
+	}{
+		{"* * * * *", saturday, true},
+		{"0 22 * * 6", saturday, true},
+		{"0 22 * * 0-5", saturday, false},
+		{"*/15 22 * * *", saturday.Add(45 * time.Minute), true},
+		{"*/15 22 * * *", saturday.Add(50 * time.Minute), false},
+		{"0 1,22 10 3 *", saturday, true},
+		{"0 22 1 * 6", saturday, true},
+		{"0 22 1 * 0", saturday, false},
+	}
+	for _, tc := range testCases {
+		schedule, err := Parse(tc.spec)
+		if !assert.NoError(t, err, "cron expression should be valid: '%s'", tc.spec) {
+			continue
+		}
+		assert.Equal(t, tc.matches, schedule.Matches(tc.time), "cron expression '%s' at %s", tc.spec, tc.time)
+	}
+}
+
+func TestNext(t *testing.T) {
+	start := time.Date(2018, time.March, 10, 22, 0, 0, 0, time.UTC)
+
+	schedule, err := Parse("30 2 * * 1")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2018, time.March, 12, 2, 30, 0, 0, time.UTC), schedule.Next(start))
+
+	schedule, err = Parse("0 0 1 1 *")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC), schedule.Next(start))
+
+	schedule, err = Parse("0 0 30 2 *")
+	assert.NoError(t, err)
+	assert.True(t, schedule.Next(start).IsZero())
+}
@@ -1,6 +1,7 @@
 package retry
 
 import (
+	"context"
 	"fmt"
 	"time"
 )
@@ -52,3 +53,43 @@ func Do2(maxTime time.Duration, maxInterval time.Duration, f Func) bool {
 
 	return false
 }
+
+// Backoff retries provided function up to maxAttempts times, starting with a given delay interval and doubling it
+// after every attempt until it reaches maxInterval. It stops retrying once ctx gets cancelled. It returns the number of
+// attempts made and true if it's successfully completed
+func Backoff(ctx context.Context, maxAttempts int, interval time.Duration, maxInterval time.Duration, f Func) (int, bool) {
+	if interval < 100*time.Millisecond {
+		panic(fmt.Sprintf("retry.Backoff used with interval less then 1/10 second, it seems dangerous: %s", interval))
+	}
+	if maxInterval < interval {
+		maxInterval = interval
+	}
+
+	attempts := 0
+	for attempts < maxAttempts {
+		attempts++
+		if f() {
+			return attempts, true
+		}
+		if attempts >= maxAttempts {
+			break
+		}
+
+		// sleep, unless cancelled
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return attempts, false
+		case <-timer.C:
+		}
+
+		// double the interval until it reaches maxInterval
+		interval *= 2
+		if interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+
+	return attempts, false
+}
@@ -14,6 +14,9 @@ type GraphBuilder struct {
 	resolution   *resolve.PolicyResolution
 	externalData *external.Data
 
+	// View limits the graph to policy objects, which a certain user is allowed to view. If not set, everything is shown
+	view *lang.PolicyView
+
 	// Resulting graph
 	graph *Graph
 }
@@ -27,3 +30,14 @@ func NewGraphBuilder(policy *lang.Policy, resolution *resolve.PolicyResolution,
 		graph:        newGraph(),
 	}
 }
+
+// ForUser makes graph builder show only policy objects, which a given user is allowed to view
+func (b *GraphBuilder) ForUser(user *lang.User) *GraphBuilder {
+	b.view = b.policy.View(user)
+	return b
+}
+
+// canView returns true if a given object should be shown on the graph
+func (b *GraphBuilder) canView(obj lang.Base) bool {
+	return b.view == nil || b.view.ViewObject(obj) == nil
+}
@@ -24,6 +24,9 @@ func (b *GraphBuilder) ClaimResolutionWithFunc(cfg *ClaimResolutionCfg, exists f
 	// trace all claims
 	for _, claimObj := range b.policy.GetObjectsByKind(lang.TypeClaim.Kind) {
 		claim := claimObj.(*lang.Claim) // nolint: errcheck
+		if !b.canView(claim) {
+			continue
+		}
 		b.traceClaimResolution("", claim, nil, 0, cfg, exists)
 	}
 	return b.graph
@@ -86,6 +89,11 @@ func (b *GraphBuilder) traceClaimResolution(keySrc string, claim *lang.Claim, la
 				continue
 			}
 			bundle := bundleObj.(*lang.Bundle) // nolint: errcheck
+
+			// do not go any further, if user is not allowed to see what has been allocated
+			if !b.canView(service) || !b.canView(bundle) {
+				continue
+			}
 			svcInstNode := bundleInstanceNode{instance: instanceCurrent, bundle: bundle}
 
 			// let's see if we need to show last -> service -> bundleInstance, or skip service all together
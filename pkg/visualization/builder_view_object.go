@@ -8,6 +8,9 @@ import (
 
 // Object produces a graph which represents an object
 func (b *GraphBuilder) Object(obj runtime.Object) *Graph {
+	if base, ok := obj.(lang.Base); ok && !b.canView(base) {
+		return b.graph
+	}
 	if bundle, ok := obj.(*lang.Bundle); ok {
 		b.traceBundle(bundle, nil, "", 0, PolicyCfgDefault)
 	}
@@ -21,6 +24,10 @@ func (b *GraphBuilder) Object(obj runtime.Object) *Graph {
 }
 
 func (b *GraphBuilder) traceService(service *lang.Service, last graphNode, lastLabel string, level int, cfg *PolicyCfg) {
+	if !b.canView(service) {
+		return
+	}
+
 	// [last] -> service
 	ctrNode := serviceNode{service: service}
 	b.graph.addNode(ctrNode, level)
@@ -49,6 +56,10 @@ func (b *GraphBuilder) traceService(service *lang.Service, last graphNode, lastL
 }
 
 func (b *GraphBuilder) traceBundle(bundle *lang.Bundle, last graphNode, lastLabel string, level int, cfg *PolicyCfg) {
+	if !b.canView(bundle) {
+		return
+	}
+
 	svcNode := bundleNode{bundle: bundle}
 	b.graph.addNode(svcNode, level)
 	if last != nil {